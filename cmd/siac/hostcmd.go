@@ -26,10 +26,21 @@ You can revert this by running:
 	siac host config acceptingcontracts false
 You may also supply a specific address to be announced, e.g.:
 	siac host announce my-host-domain.com:9001
-Doing so will override the standard connectivity checks.`,
+Doing so will override the standard connectivity checks.
+Use --check to confirm that the host can reach itself through the announced
+address before announcing it, e.g. when the host is behind NAT.`,
 		Run: hostannouncecmd,
 	}
 
+	hostBackupCmd = &cobra.Command{
+		Use:   "backup [path]",
+		Short: "Back up the host's storage obligations",
+		Long: `Write the host's storage obligations to a backup file, along with a
+manifest of the storage folders that hold their data. The storage folders
+themselves are not part of the backup and have to be moved separately.`,
+		Run: wrap(hostbackupcmd),
+	}
+
 	hostCmd = &cobra.Command{
 		Use:   "host",
 		Short: "Perform host actions",
@@ -44,27 +55,56 @@ Doing so will override the standard connectivity checks.`,
 
 Available settings:
      acceptingcontracts:   boolean
+     archivedir:           string
+     archiveretention:     blocks
+     encryptsectors:       boolean
+     maintenanceend:       block height
+     maintenancestart:     block height
+     maxconcurrentproofs:  int
+     maxconfirmationdelay: blocks
+     maxcontractrevisions: int (per contract per day)
+     maxdisklatency:       milliseconds
      maxduration:          blocks
      maxdownloadbatchsize: bytes
+     maxobligations:       int
+     maxrenterrequestrate: int (per minute)
+     maxrentersessions:    int
      maxrevisebatchsize:   bytes
+     minduration:          blocks
+     minfilesize:          bytes
      netaddress:           string
+     reannounceinterval:   blocks
+     resubmissionjitter:   blocks
+     shutdowntimeout:      seconds
      windowsize:           blocks
 
      collateral:       currency
      collateralbudget: currency
      maxcollateral:    currency
 
+     trustedrentercollateral: currency
+
      mincontractprice:          currency
      mindownloadbandwidthprice: currency / TB
      minstorageprice:           currency / TB / Month
      minuploadbandwidthprice:   currency / TB
 
+     storagepricetiers: comma separated list of duration:currency / TB / Month
+
 Currency units can be specified, e.g. 10SC; run 'siac help wallet' for details.
 
-Durations (maxduration and windowsize) must be specified in either blocks (b),
+Durations (archiveretention, maxduration, minduration, reannounceinterval and windowsize) must be specified in either blocks (b),
 hours (h), days (d), or weeks (w). A block is approximately 10 minutes, so one
 hour is six blocks, a day is 144 blocks, and a week is 1008 blocks.
 
+Storage price tiers let the host charge a different storage price for longer
+contracts. The price of the tier with the longest duration that a contract
+reaches applies, and minstorageprice applies to contracts shorter than every
+tier. To charge 150SC for contracts of at least 12 weeks and 200SC for
+contracts of at least 24 weeks, run:
+	siac host config storagepricetiers 12w:150SC,24w:200SC
+Use "none" to remove all tiers.
+
 For a description of each parameter, see doc/API.md.
 
 To configure the host to accept new contracts, set acceptingcontracts to true:
@@ -119,8 +159,37 @@ other storage folders.`,
 	hostSectorCmd = &cobra.Command{
 		Use:   "sector",
 		Short: "Add or delete a sector (add not supported)",
-		Long: `Add or delete a sector. Adding is not currently supported. Note that
-deleting a sector may impact host revenue.`,
+		Long: `Add or delete a sector, or rotate the key that sectors are encrypted
+with. Adding is not currently supported. Note that deleting a sector may impact
+host revenue.`,
+	}
+
+	hostRestoreCmd = &cobra.Command{
+		Use:   "restore [path]",
+		Short: "Restore the host's storage obligations from a backup",
+		Long: `Load the storage obligations from a backup file into the host. The
+storage folders listed in the backup must already be in use by the host, and
+every sector of the open storage obligations is verified before anything is
+restored.`,
+		Run: wrap(hostrestorecmd),
+	}
+
+	hostQuoteCmd = &cobra.Command{
+		Use:   "quote [filesize] [duration]",
+		Short: "Quote the price of a contract",
+		Long: `Show the price that the host would charge for a contract storing filesize
+bytes for duration blocks, without forming the contract. The data is priced as
+if it were uploaded right after the contract is formed.`,
+		Run: wrap(hostquotecmd),
+	}
+
+	hostStorageProofCmd = &cobra.Command{
+		Use:   "storageproof [contractid]",
+		Short: "Submit the storage proof of a contract right away",
+		Long: `Rebuild the storage proof of a contract and submit it right away, rather
+than waiting for the host to submit it on its own. The proof window of the
+contract must be open.`,
+		Run: wrap(hoststorageproofcmd),
 	}
 
 	hostSectorDeleteCmd = &cobra.Command{
@@ -130,6 +199,15 @@ deleting a sector may impact host revenue.`,
 sector may impact host revenue.`,
 		Run: wrap(hostsectordeletecmd),
 	}
+
+	hostSectorRotateKeyCmd = &cobra.Command{
+		Use:   "rotatekey",
+		Short: "Rotate the sector encryption key",
+		Long: `Generate a new key for the host to encrypt sectors with. Sectors that are
+already stored stay encrypted with their old key until they are removed, old
+keys are discarded once they no longer protect any sector.`,
+		Run: wrap(hostsectorrotatekeycmd),
+	}
 )
 
 // hostcmd is the handler for the command `siac host`.
@@ -193,21 +271,42 @@ func hostcmd() {
 
 Host Internal Settings:
 	acceptingcontracts:   %v
+	archivedir:           %v
+	archiveretention:     %v Blocks
+	encryptsectors:       %v
+	maintenanceend:       %v
+	maintenancestart:     %v
+	maxconcurrentproofs:  %v
+	maxconfirmationdelay: %v Blocks
+	maxcontractrevisions: %v / Contract / Day
+	maxdisklatency:       %v ms
 	maxduration:          %v Weeks
 	maxdownloadbatchsize: %v
+	maxobligations:       %v
+	maxrenterrequestrate: %v / Minute
+	maxrentersessions:    %v
 	maxrevisebatchsize:   %v
+	minduration:          %v Weeks
+	minfilesize:          %v
 	netaddress:           %v
+	reannounceinterval:   %v Blocks
+	resubmissionjitter:   %v Blocks
+	shutdowntimeout:      %v Seconds
 	windowsize:           %v Hours
 
 	collateral:       %v / TB / Month
 	collateralbudget: %v
 	maxcollateral:    %v Per Contract
 
+	trustedrentercollateral: %v
+
 	mincontractprice:          %v
 	mindownloadbandwidthprice: %v / TB
 	minstorageprice:           %v / TB / Month
 	minuploadbandwidthprice:   %v / TB
 
+	storagepricetiers: %v
+
 Host Financials:
 	Contract Count:               %v
 	Transaction Fee Compensation: %v
@@ -217,9 +316,10 @@ Host Financials:
 	Storage Revenue:           %v
 	Potential Storage Revenue: %v
 
-	Locked Collateral: %v
-	Risked Collateral: %v
-	Lost Collateral:   %v
+	Locked Collateral:           %v
+	Remaining Collateral Budget: %v
+	Risked Collateral:           %v
+	Lost Collateral:             %v
 
 	Download Revenue:           %v
 	Potential Download Revenue: %v
@@ -237,20 +337,31 @@ RPC Stats:
 `,
 			connectabilityString,
 
-			yesNo(is.AcceptingContracts), periodUnits(is.MaxDuration),
+			yesNo(is.AcceptingContracts), is.ArchiveDir, is.ArchiveRetention,
+			yesNo(is.EncryptSectors),
+			is.MaintenanceEnd, is.MaintenanceStart, is.MaxConcurrentProofs,
+			is.MaxConfirmationDelay, is.MaxContractRevisions, is.MaxDiskLatency,
+			periodUnits(is.MaxDuration),
 			filesizeUnits(int64(is.MaxDownloadBatchSize)),
-			filesizeUnits(int64(is.MaxReviseBatchSize)), netaddr,
-			is.WindowSize/6,
+			is.MaxObligations, is.MaxRenterRequestRate, is.MaxRenterSessions,
+			filesizeUnits(int64(is.MaxReviseBatchSize)),
+			periodUnits(is.MinDuration),
+			filesizeUnits(int64(is.MinFileSize)), netaddr,
+			is.ReannounceInterval, is.ResubmissionJitter, is.ShutdownTimeout, is.WindowSize/6,
 
 			currencyUnits(is.Collateral.Mul(modules.BlockBytesPerMonthTerabyte)),
 			currencyUnits(is.CollateralBudget),
 			currencyUnits(is.MaxCollateral),
 
+			currencyUnits(is.TrustedRenterCollateral),
+
 			currencyUnits(is.MinContractPrice),
 			currencyUnits(is.MinDownloadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
 			currencyUnits(is.MinStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)),
 			currencyUnits(is.MinUploadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
 
+			storagePriceTiersUnits(is.StoragePriceTiers),
+
 			fm.ContractCount, currencyUnits(fm.ContractCompensation),
 			currencyUnits(fm.PotentialContractCompensation),
 			currencyUnits(fm.TransactionFeeExpenses),
@@ -259,6 +370,7 @@ RPC Stats:
 			currencyUnits(fm.PotentialStorageRevenue),
 
 			currencyUnits(fm.LockedStorageCollateral),
+			currencyUnits(fm.RemainingCollateralBudget),
 			currencyUnits(fm.RiskedStorageCollateral),
 			currencyUnits(fm.LostStorageCollateral),
 
@@ -303,6 +415,16 @@ RPC Stats:
 		fmt.Println("\nWarning:\n	Your wallet is locked. You must unlock your wallet for the host to function properly.")
 	}
 
+	// if sectors of resolved contracts could not be removed, print warning
+	if fm.PendingSectorRemovals > 0 {
+		fmt.Printf("\nWarning:\n	%v sectors of resolved contracts could not be removed. The removal is retried periodically, check the host log for errors.\n", fm.PendingSectorRemovals)
+	}
+
+	// if contracts have been waiting for confirmation for too long, print warning
+	if fm.ObligationsUnconfirmed > 0 {
+		fmt.Printf("\nWarning:\n	%v contracts have been waiting too long for their transactions to be confirmed. Check the connection of the host to the network and the transaction fees.\n", fm.ObligationsUnconfirmed)
+	}
+
 	fmt.Println("\nStorage Folders:")
 
 	// display storage folder info
@@ -323,13 +445,54 @@ RPC Stats:
 	w.Flush()
 }
 
+// storagePriceTiersUnits returns a human readable description of the storage
+// price tiers of the host.
+func storagePriceTiersUnits(tiers []modules.HostStoragePriceTier) string {
+	if len(tiers) == 0 {
+		return "none"
+	}
+	var descs []string
+	for _, tier := range tiers {
+		descs = append(descs, fmt.Sprintf("%v Weeks: %v / TB / Month", periodUnits(tier.MinDuration), currencyUnits(tier.StoragePrice.Mul(modules.BlockBytesPerMonthTerabyte))))
+	}
+	return strings.Join(descs, ", ")
+}
+
+// parseStoragePriceTiers converts a list of storage price tiers with
+// durations and prices in human readable units to the form expected by the
+// API.
+func parseStoragePriceTiers(tiers string) (string, error) {
+	if tiers == "none" {
+		return tiers, nil
+	}
+	var parsed []string
+	for _, tier := range strings.Split(tiers, ",") {
+		parts := strings.Split(tier, ":")
+		if len(parts) != 2 {
+			return "", fmt.Errorf("storage price tier %q is not of the form duration:price", tier)
+		}
+		duration, err := parsePeriod(parts[0])
+		if err != nil {
+			return "", err
+		}
+		hastings, err := parseCurrency(parts[1])
+		if err != nil {
+			return "", err
+		}
+		i, _ := new(big.Int).SetString(hastings, 10)
+		price := types.NewCurrency(i).Div(modules.BlockBytesPerMonthTerabyte)
+		parsed = append(parsed, duration+":"+price.String())
+	}
+	return strings.Join(parsed, ","), nil
+}
+
 // hostconfigcmd is the handler for the command `siac host config [setting] [value]`.
 // Modifies host settings.
 func hostconfigcmd(param, value string) {
 	var err error
 	switch param {
 	// currency (convert to hastings)
-	case "collateralbudget", "maxcollateral", "mincontractprice":
+	case "collateralbudget", "maxcollateral", "mincontractprice", "trustedrentercollateral":
 		value, err = parseCurrency(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
@@ -355,8 +518,15 @@ func hostconfigcmd(param, value string) {
 		c := types.NewCurrency(i).Div(modules.BlockBytesPerMonthTerabyte)
 		value = c.String()
 
+	// list of duration:currency/TB/month (convert to blocks:hastings/byte/block)
+	case "storagepricetiers":
+		value, err = parseStoragePriceTiers(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+
 	// bool (allow "yes" and "no")
-	case "acceptingcontracts":
+	case "acceptingcontracts", "encryptsectors":
 		switch strings.ToLower(value) {
 		case "yes":
 			value = "true"
@@ -365,14 +535,14 @@ func hostconfigcmd(param, value string) {
 		}
 
 	// duration (convert to blocks)
-	case "maxduration", "windowsize":
+	case "archiveretention", "maxduration", "minduration", "reannounceinterval", "windowsize":
 		value, err = parsePeriod(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
 		}
 
 	// other valid settings
-	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress":
+	case "archivedir", "maintenanceend", "maintenancestart", "maxconcurrentproofs", "maxconfirmationdelay", "maxcontractrevisions", "maxdisklatency", "maxdownloadbatchsize", "maxobligations", "maxrenterrequestrate", "maxrentersessions", "maxrevisebatchsize", "minfilesize", "netaddress", "resubmissionjitter", "shutdowntimeout":
 
 	// invalid settings
 	default:
@@ -398,7 +568,7 @@ func hostconfigcmd(param, value string) {
 
 // hostcontractcmd is the handler for the command `siac host contracts [type]`.
 func hostcontractcmd() {
-	cg, err := httpClient.HostContractInfoGet()
+	cg, err := httpClient.HostTaggedContractInfoGet(hostContractTags...)
 	if err != nil {
 		die("Could not fetch host contract info:", err)
 	}
@@ -413,10 +583,10 @@ func hostcontractcmd() {
 				currencyUnits(so.RiskedCollateral), currencyUnits(potentialRevenue), so.ExpirationHeight, currencyUnits(so.TransactionFeesAdded))
 		}
 	case "status":
-		fmt.Fprintf(w, "Obligation ID\tObligation Status\tExpiration Height\tOrigin Confirmed\tRevision Constructed\tRevision Confirmed\tProof Constructed\tProof Confirmed\n")
+		fmt.Fprintf(w, "Obligation ID\tObligation Status\tExpiration Height\tOrigin Confirmed\tRevision Constructed\tRevision Confirmed\tProof Constructed\tProof Confirmed\tConfirmation Delay\n")
 		for _, so := range cg.Contracts {
-			fmt.Fprintf(w, "%s\t%s\t%d\t%t\t%t\t%t\t%t\t%t\n", so.ObligationId, strings.TrimPrefix(so.ObligationStatus, "obligation"), so.ExpirationHeight, so.OriginConfirmed,
-				so.RevisionConstructed, so.RevisionConfirmed, so.ProofConstructed, so.ProofConfirmed)
+			fmt.Fprintf(w, "%s\t%s\t%d\t%t\t%t\t%t\t%t\t%t\t%d\n", so.ObligationId, strings.TrimPrefix(so.ObligationStatus, "obligation"), so.ExpirationHeight, so.OriginConfirmed,
+				so.RevisionConstructed, so.RevisionConfirmed, so.ProofConstructed, so.ProofConfirmed, so.ConfirmationDelay)
 		}
 	default:
 		die("\"" + hostContractOutputType + "\" is not a format")
@@ -429,10 +599,14 @@ func hostcontractcmd() {
 // announce as.
 func hostannouncecmd(cmd *cobra.Command, args []string) {
 	var err error
-	switch len(args) {
-	case 0:
+	switch {
+	case len(args) == 0 && hostAnnounceCheck:
+		err = httpClient.HostAnnounceCheckedPost("")
+	case len(args) == 0:
 		err = httpClient.HostAnnouncePost()
-	case 1:
+	case len(args) == 1 && hostAnnounceCheck:
+		err = httpClient.HostAnnounceCheckedPost(modules.NetAddress(args[0]))
+	case len(args) == 1:
 		err = httpClient.HostAnnounceAddrPost(modules.NetAddress(args[0]))
 	default:
 		cmd.UsageFunc()(cmd)
@@ -453,6 +627,24 @@ To revert this, run:
 	siac host config acceptingcontracts false`)
 }
 
+// hostbackupcmd writes the host's storage obligations to a backup file.
+func hostbackupcmd(path string) {
+	err := httpClient.HostBackupPost(abs(path))
+	if err != nil {
+		die("Could not back up host:", err)
+	}
+	fmt.Println("Backed up storage obligations to", abs(path))
+}
+
+// hostrestorecmd loads the host's storage obligations from a backup file.
+func hostrestorecmd(path string) {
+	err := httpClient.HostRestorePost(abs(path))
+	if err != nil {
+		die("Could not restore host:", err)
+	}
+	fmt.Println("Restored storage obligations from", abs(path))
+}
+
 // hostfolderaddcmd adds a folder to the host.
 func hostfolderaddcmd(path, size string) {
 	size, err := parseFilesize(size)
@@ -513,3 +705,71 @@ func hostsectordeletecmd(root string) {
 	}
 	fmt.Println("Deleted sector", root)
 }
+
+// hoststorageproofcmd submits the storage proof of a contract right away.
+func hoststorageproofcmd(contractID string) {
+	var hash crypto.Hash
+	err := hash.LoadString(contractID)
+	if err != nil {
+		die("Could not parse contract id:", err)
+	}
+	err = httpClient.HostStorageProofPost(types.FileContractID(hash))
+	if err != nil {
+		die("Could not submit storage proof:", err)
+	}
+	fmt.Println("Submitted storage proof for contract", contractID)
+}
+
+// hostquotecmd prints the price that the host would charge for a contract.
+func hostquotecmd(filesize, duration string) {
+	size, err := parseFilesize(filesize)
+	if err != nil {
+		die("Could not parse filesize:", err)
+	}
+	blocks, err := parsePeriod(duration)
+	if err != nil {
+		die("Could not parse duration:", err)
+	}
+	var fileSize uint64
+	var period types.BlockHeight
+	fmt.Sscan(size, &fileSize)
+	fmt.Sscan(blocks, &period)
+	var collateral types.Currency
+	if hostQuoteCollateral != "" {
+		hastings, err := parseCurrency(hostQuoteCollateral)
+		if err != nil {
+			die("Could not parse collateral:", err)
+		}
+		fmt.Sscan(hastings, &collateral)
+	}
+	q, err := httpClient.HostQuoteGet(fileSize, period, collateral)
+	if err != nil {
+		die("Could not get quote:", err)
+	}
+	fmt.Printf(`Contract Quote:
+	File Size: %v
+	Duration:  %v Blocks
+
+	Contract Price:    %v
+	Storage Revenue:   %v
+	Upload Revenue:    %v
+	Collateral:        %v
+	Risked Collateral: %v
+
+	Renter Cost:    %v
+	Contract Value: %v
+`, filesizeUnits(int64(q.FileSize)), q.Duration,
+		currencyUnits(q.ContractPrice), currencyUnits(q.StorageRevenue),
+		currencyUnits(q.UploadRevenue), currencyUnits(q.Collateral),
+		currencyUnits(q.RiskedCollateral), currencyUnits(q.RenterCost),
+		currencyUnits(q.Value))
+}
+
+// hostsectorrotatekeycmd generates a new sector encryption key for the host.
+func hostsectorrotatekeycmd() {
+	err := httpClient.HostSectorKeysRotatePost()
+	if err != nil {
+		die("Could not rotate sector key:", err)
+	}
+	fmt.Println("Rotated sector encryption key")
+}
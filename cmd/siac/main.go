@@ -13,12 +13,15 @@ import (
 
 var (
 	// Flags.
-	hostContractOutputType string // output type for host contracts
-	hostVerbose            bool   // display additional host info
-	initForce              bool   // destroy and reencrypt the wallet on init if it already exists
-	initPassword           bool   // supply a custom password when creating a wallet
-	renterListVerbose      bool   // Show additional info about uploaded files.
-	renterShowHistory      bool   // Show download history in addition to download queue.
+	hostAnnounceCheck      bool     // check that the host is reachable before announcing
+	hostContractOutputType string   // output type for host contracts
+	hostContractTags       []string // only show host contracts carrying these tags
+	hostQuoteCollateral    string   // collateral of the quoted host contract
+	hostVerbose            bool     // display additional host info
+	initForce              bool     // destroy and reencrypt the wallet on init if it already exists
+	initPassword           bool     // supply a custom password when creating a wallet
+	renterListVerbose      bool     // Show additional info about uploaded files.
+	renterShowHistory      bool     // Show download history in addition to download queue.
 )
 
 var (
@@ -88,11 +91,14 @@ func main() {
 	updateCmd.AddCommand(updateCheckCmd)
 
 	root.AddCommand(hostCmd)
-	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostFolderCmd, hostContractCmd, hostSectorCmd)
+	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostBackupCmd, hostFolderCmd, hostContractCmd, hostQuoteCmd, hostRestoreCmd, hostSectorCmd, hostStorageProofCmd)
 	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
-	hostSectorCmd.AddCommand(hostSectorDeleteCmd)
+	hostSectorCmd.AddCommand(hostSectorDeleteCmd, hostSectorRotateKeyCmd)
 	hostCmd.Flags().BoolVarP(&hostVerbose, "verbose", "v", false, "Display detailed host info")
 	hostContractCmd.Flags().StringVarP(&hostContractOutputType, "type", "t", "value", "Select output type")
+	hostContractCmd.Flags().StringSliceVar(&hostContractTags, "tag", nil, "Only show contracts carrying the tag, given as key or key=value")
+	hostQuoteCmd.Flags().StringVar(&hostQuoteCollateral, "collateral", "", "Collateral of the contract, defaults to the collateral the host would risk")
+	hostAnnounceCmd.Flags().BoolVarP(&hostAnnounceCheck, "check", "c", false, "Check that the host can reach itself through the address before announcing")
 
 	root.AddCommand(hostdbCmd)
 	hostdbCmd.AddCommand(hostdbViewCmd)
@@ -118,7 +124,7 @@ func main() {
 		renterDownloadsCmd, renterAllowanceCmd, renterSetAllowanceCmd,
 		renterContractsCmd, renterFilesListCmd, renterFilesRenameCmd,
 		renterFilesUploadCmd, renterUploadsCmd, renterExportCmd,
-		renterPricesCmd)
+		renterPricesCmd, renterBackupCmd, renterRestoreCmd)
 
 	renterContractsCmd.AddCommand(renterContractsViewCmd)
 	renterAllowanceCmd.AddCommand(renterAllowanceCancelCmd)
@@ -30,6 +30,16 @@ var (
 		Run:   wrap(renterallowancecmd),
 	}
 
+	renterBackupCmd = &cobra.Command{
+		Use:   "backup [path]",
+		Short: "Back up the renter's contracts and files",
+		Long: `Write the renter's contracts and the mapping of each file to the pieces
+stored on hosts to an encrypted backup file. The backup is encrypted with a key
+derived from the wallet seed, and can be restored with the same seed after the
+renter metadata has been lost.`,
+		Run: wrap(renterbackupcmd),
+	}
+
 	renterCmd = &cobra.Command{
 		Use:   "renter",
 		Short: "Perform renter actions",
@@ -103,6 +113,15 @@ var (
 		Run:   wrap(renterpricescmd),
 	}
 
+	renterRestoreCmd = &cobra.Command{
+		Use:   "restore [path]",
+		Short: "Restore the renter's contracts and files from a backup",
+		Long: `Load the contracts and files from a backup file into the renter. The
+wallet must be unlocked with the seed that the backup was made with. Contracts
+are only restored if they are still valid in the current consensus state.`,
+		Run: wrap(renterrestorecmd),
+	}
+
 	renterSetAllowanceCmd = &cobra.Command{
 		Use:   "setallowance [amount] [period] [hosts] [renew window]",
 		Short: "Set the allowance",
@@ -207,10 +226,19 @@ func renteruploadscmd() {
 	}
 	fmt.Println("Uploading", len(filteredFiles), "files:")
 	for _, file := range filteredFiles {
-		fmt.Printf("%13s  %s (uploading, %0.2f%%)\n", filesizeUnits(int64(file.Filesize)), file.SiaPath, file.UploadProgress)
+		fmt.Printf("%13s  %s (uploading, %0.2f%%, %v/%v chunks, ETA %s)\n", filesizeUnits(int64(file.Filesize)), file.SiaPath, file.UploadProgress, file.UploadedChunks, file.TotalChunks, uploadETAString(file.UploadETA))
 	}
 }
 
+// uploadETAString returns a human readable version of an upload ETA in
+// seconds, where -1 means that the ETA is unknown.
+func uploadETAString(eta int64) string {
+	if eta < 0 {
+		return "unknown"
+	}
+	return (time.Duration(eta) * time.Second).String()
+}
+
 // renterdownloadscmd is the handler for the command `siac renter downloads`.
 // Lists files currently downloading, and optionally previously downloaded
 // files if the -H or --history flag is specified.
@@ -588,6 +616,24 @@ func renterfilesuploadcmd(source, path string) {
 	}
 }
 
+// renterbackupcmd writes the renter's contracts and files to a backup file.
+func renterbackupcmd(path string) {
+	err := httpClient.RenterBackupPost(abs(path))
+	if err != nil {
+		die("Could not back up renter:", err)
+	}
+	fmt.Println("Backed up contracts and files to", abs(path))
+}
+
+// renterrestorecmd loads the renter's contracts and files from a backup file.
+func renterrestorecmd(path string) {
+	err := httpClient.RenterRestorePost(abs(path))
+	if err != nil {
+		die("Could not restore renter:", err)
+	}
+	fmt.Println("Restored contracts and files from", abs(path))
+}
+
 // renterpricescmd is the handler for the command `siac renter prices`, which
 // displays the prices of various storage operations.
 func renterpricescmd() {
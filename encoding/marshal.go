@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"reflect"
 )
@@ -31,7 +32,7 @@ type ErrObjectTooLarge uint64
 
 // Error implements the error interface.
 func (e ErrObjectTooLarge) Error() string {
-	return fmt.Sprintf("encoded object (>= %v bytes) exceeds size limit (%v bytes)", uint64(e), uint64(MaxObjectSize))
+	return fmt.Sprintf("encoded object (>= %v bytes) exceeds size limit", uint64(e))
 }
 
 // ErrSliceTooLarge is an error when encoded slice is too large.
@@ -197,19 +198,21 @@ func WriteFile(filename string, v interface{}) error {
 	return nil
 }
 
-// A Decoder reads and decodes values from an input stream.
+// A Decoder reads and decodes values from an input stream. Values are decoded
+// as they are read, so the encoded form never needs to be held in memory.
 type Decoder struct {
-	r io.Reader
-	n int
+	r   io.Reader
+	n   int
+	lim int
 }
 
 // Read implements the io.Reader interface. It also keeps track of the total
-// number of bytes decoded, and panics if that number exceeds a global
-// maximum.
+// number of bytes decoded, and panics if that number exceeds the decoder's
+// limit.
 func (d *Decoder) Read(p []byte) (int, error) {
 	n, err := d.r.Read(p)
 	// enforce an absolute maximum size limit
-	if d.n += n; d.n > MaxObjectSize {
+	if d.n += n; d.n > d.lim {
 		panic(ErrObjectTooLarge(d.n))
 	}
 	return n, err
@@ -257,7 +260,7 @@ func (d *Decoder) readN(n int) []byte {
 		if len(b) != n {
 			panic(io.ErrUnexpectedEOF)
 		}
-		if d.n += n; d.n > MaxObjectSize {
+		if d.n += n; d.n > d.lim {
 			panic(ErrObjectTooLarge(d.n))
 		}
 		return b
@@ -313,6 +316,7 @@ func (d *Decoder) decode(val reflect.Value) {
 		if strLen > MaxSliceSize {
 			panic("string is too large")
 		}
+		d.checkRemaining(strLen)
 		val.SetString(string(d.readN(int(strLen))))
 	case reflect.Slice:
 		// slices are variable length, but otherwise the same as arrays.
@@ -325,6 +329,11 @@ func (d *Decoder) decode(val reflect.Value) {
 		} else if sliceLen == 0 {
 			return
 		}
+		// every element of a non-empty type takes at least one byte to
+		// encode, so a slice can't be longer than the bytes that remain
+		if val.Type().Elem().Size() > 0 {
+			d.checkRemaining(sliceLen)
+		}
 		val.Set(reflect.MakeSlice(val.Type(), int(sliceLen), int(sliceLen)))
 		fallthrough
 	case reflect.Array:
@@ -353,9 +362,28 @@ func (d *Decoder) decode(val reflect.Value) {
 	}
 }
 
+// checkRemaining panics if fewer than n bytes may still be read before the
+// decoder reaches its limit. It is called before allocating memory for a
+// length-prefixed value, so that a malicious length prefix can't cause an
+// allocation much larger than the data that backs it.
+func (d *Decoder) checkRemaining(n uint64) {
+	if n > uint64(d.lim-d.n) {
+		panic(ErrObjectTooLarge(uint64(d.n) + n))
+	}
+}
+
 // NewDecoder returns a new decoder that reads from r.
 func NewDecoder(r io.Reader) *Decoder {
-	return &Decoder{r, 0}
+	return &Decoder{r, 0, MaxObjectSize}
+}
+
+// NewLimitedDecoder returns a new decoder that reads from r and refuses to
+// decode objects larger than maxLen bytes, rather than MaxObjectSize.
+func NewLimitedDecoder(r io.Reader, maxLen uint64) *Decoder {
+	if maxLen > math.MaxInt32 {
+		maxLen = math.MaxInt32
+	}
+	return &Decoder{r, 0, int(maxLen)}
 }
 
 // Unmarshal decodes the encoded value b and stores it in v, which must be a
@@ -3,6 +3,7 @@ package encoding
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 )
 
 // ReadPrefix reads an 8-byte length prefixes, followed by the number of bytes
@@ -23,13 +24,26 @@ func ReadPrefix(r io.Reader, maxLen uint64) ([]byte, error) {
 	return data, err
 }
 
-// ReadObject reads and decodes a length-prefixed and marshalled object.
+// ReadObject reads and decodes a length-prefixed and marshalled object. The
+// object is decoded directly from r rather than buffered in full first. Any
+// bytes covered by the prefix that are not used by the object are discarded.
 func ReadObject(r io.Reader, obj interface{}, maxLen uint64) error {
-	data, err := ReadPrefix(r, maxLen)
-	if err != nil {
+	prefix := make([]byte, 8)
+	if _, err := io.ReadFull(r, prefix); err != nil {
 		return err
 	}
-	return Unmarshal(data, obj)
+	dataLen := DecUint64(prefix)
+	if dataLen > maxLen {
+		return fmt.Errorf("length %d exceeds maxLen of %d", dataLen, maxLen)
+	}
+	lr := io.LimitReader(r, int64(dataLen))
+	err := NewLimitedDecoder(lr, dataLen).Decode(obj)
+	// consume the rest of the object so that r is left at the start of the
+	// next one
+	if _, discardErr := io.Copy(ioutil.Discard, lr); err == nil {
+		err = discardErr
+	}
+	return err
 }
 
 // WritePrefix writes a length-prefixed byte slice to w.
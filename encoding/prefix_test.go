@@ -3,6 +3,7 @@ package encoding
 import (
 	"bytes"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -94,6 +95,65 @@ func TestReadObject(t *testing.T) {
 	}
 }
 
+// TestReadObjectStream checks that ReadObject leaves the reader at the start
+// of the next object, even when an object does not use all of its prefixed
+// bytes or fails to decode.
+func TestReadObjectStream(t *testing.T) {
+	b := new(bytes.Buffer)
+	b.Write(EncUint64(14))
+	b.Write(append(EncUint64(3), "foo"...))
+	b.WriteString("bar") // unused by the object
+	b.Write(EncUint64(3))
+	b.WriteString("baz") // not a valid string
+	WriteObject(b, "qux")
+
+	var obj string
+	if err := ReadObject(b, &obj, 100); err != nil {
+		t.Fatal(err)
+	} else if obj != "foo" {
+		t.Fatalf("expected foo, got %s", obj)
+	}
+	if err := ReadObject(b, &obj, 100); err == nil {
+		t.Fatal("expected decoding error")
+	}
+	if err := ReadObject(b, &obj, 100); err != nil {
+		t.Fatal(err)
+	} else if obj != "qux" {
+		t.Fatalf("expected qux, got %s", obj)
+	}
+	if b.Len() != 0 {
+		t.Fatal("expected reader to be empty, got", b.Len(), "bytes")
+	}
+}
+
+// TestLimitedDecoder checks that a limited decoder refuses to allocate for a
+// length prefix that exceeds the bytes remaining in its limit.
+func TestLimitedDecoder(t *testing.T) {
+	// a slice prefix claiming more elements than there are bytes left
+	b := bytes.NewBuffer(EncUint64(1000))
+	err := NewLimitedDecoder(b, 100).Decode(new([]uint64))
+	if err == nil || !strings.Contains(err.Error(), "exceeds size limit") {
+		t.Error("expected size limit error, got", err)
+	}
+
+	// the same for strings
+	b = bytes.NewBuffer(EncUint64(1000))
+	err = NewLimitedDecoder(b, 100).Decode(new(string))
+	if err == nil || !strings.Contains(err.Error(), "exceeds size limit") {
+		t.Error("expected size limit error, got", err)
+	}
+
+	// objects within the limit decode normally
+	data := Marshal([]uint64{1, 2, 3})
+	var s []uint64
+	err = NewLimitedDecoder(bytes.NewReader(data), uint64(len(data))).Decode(&s)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(s) != 3 || s[2] != 3 {
+		t.Error("decoded wrong slice:", s)
+	}
+}
+
 func TestWritePrefix(t *testing.T) {
 	b := new(bytes.Buffer)
 
@@ -203,6 +203,12 @@ type (
 		// run any required closing routines.
 		Close() error
 
+		// ConsensusCheckpoint returns the id of the most recent consensus
+		// change checkpoint at or below the provided height, and the height
+		// of the blockchain after that change. Subscribing from the
+		// checkpoint skips all of the changes that came before it.
+		ConsensusCheckpoint(types.BlockHeight) (ConsensusChangeID, types.BlockHeight, bool)
+
 		// ConsensusSetSubscribe adds a subscriber to the list of subscribers
 		// and gives them every consensus change that has occurred since the
 		// change with the provided id. There are a few special cases,
@@ -234,6 +240,14 @@ type (
 		// risk of mining invalid blocks.
 		MinimumValidChildTimestamp(types.BlockID) (types.Timestamp, bool)
 
+		// SiacoinOutput returns the siacoin output with the given id, and a
+		// bool indicating whether the output is unspent at the current block.
+		SiacoinOutput(types.SiacoinOutputID) (types.SiacoinOutput, bool)
+
+		// SiafundOutput returns the siafund output with the given id, and a
+		// bool indicating whether the output is unspent at the current block.
+		SiafundOutput(types.SiafundOutputID) (types.SiafundOutput, bool)
+
 		// StorageProofSegment returns the segment to be used in the storage proof for
 		// a given file contract.
 		StorageProofSegment(types.FileContractID) (uint64, error)
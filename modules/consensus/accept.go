@@ -176,6 +176,10 @@ func (cs *ConsensusSet) addBlockToTree(tx *bolt.Tx, b types.Block, parent *proce
 	if err != nil {
 		return changeEntry{}, err
 	}
+	err = updateCheckpoints(tx, ce)
+	if err != nil {
+		return changeEntry{}, err
+	}
 	return ce, nil
 }
 
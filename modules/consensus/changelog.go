@@ -17,6 +17,8 @@ package consensus
 // the genesis block will call 'append' later on during initialization.
 
 import (
+	"encoding/binary"
+
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
@@ -35,6 +37,22 @@ var (
 	// ChangeLogTailID is a key that points to the id of the current changelog
 	// tail.
 	ChangeLogTailID = []byte("ChangeLogTailID")
+
+	// ChangeLogCheckpoints maps block heights to the change entries that moved
+	// the tip of the current path to that height. Subscribers that only care
+	// about the blockchain past a certain height can start from a checkpoint
+	// instead of from the beginning of the change log.
+	ChangeLogCheckpoints = []byte("ChangeLogCheckpoints")
+)
+
+var (
+	// checkpointInterval is the number of blocks between consensus change
+	// checkpoints.
+	checkpointInterval = build.Select(build.Var{
+		Dev:      types.BlockHeight(20),
+		Standard: types.BlockHeight(1000),
+		Testing:  types.BlockHeight(5),
+	}).(types.BlockHeight)
 )
 
 type (
@@ -128,6 +146,74 @@ func (ce *changeEntry) NextEntry(tx *bolt.Tx) (nextEntry changeEntry, exists boo
 	return getEntry(tx, cn.Next)
 }
 
+// checkpointKey returns the database key of the checkpoint at a height. Keys
+// are big endian so that bolt keeps the checkpoints sorted by height.
+func checkpointKey(height types.BlockHeight) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(height))
+	return key
+}
+
+// updateCheckpoints updates the checkpoints of the change log after the change
+// entry has been appended. Checkpoints beyond the height of the first reverted
+// block are no longer part of the current path and are removed. A checkpoint
+// is added if the entry applied a block at a multiple of checkpointInterval.
+func updateCheckpoints(tx *bolt.Tx, ce changeEntry) error {
+	bc := tx.Bucket(ChangeLogCheckpoints)
+	if len(ce.RevertedBlocks) > 0 {
+		// Blocks are reverted from the tip down, the last reverted block is
+		// the lowest one.
+		pb, err := getBlockMap(tx, ce.RevertedBlocks[len(ce.RevertedBlocks)-1])
+		if err != nil {
+			return err
+		}
+		var stale [][]byte
+		c := bc.Cursor()
+		for k, _ := c.Seek(checkpointKey(pb.Height)); k != nil; k, _ = c.Next() {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+		for _, k := range stale {
+			if err := bc.Delete(k); err != nil {
+				return err
+			}
+		}
+	}
+	if len(ce.AppliedBlocks) == 0 {
+		return nil
+	}
+
+	tip, err := getBlockMap(tx, ce.AppliedBlocks[len(ce.AppliedBlocks)-1])
+	if err != nil {
+		return err
+	}
+	lowest := tip.Height + 1 - types.BlockHeight(len(ce.AppliedBlocks))
+	if lowest%checkpointInterval != 0 && lowest/checkpointInterval == tip.Height/checkpointInterval {
+		return nil
+	}
+	ceid := ce.ID()
+	return bc.Put(checkpointKey(tip.Height), ceid[:])
+}
+
+// initCheckpoints creates the checkpoint bucket if it does not exist yet, and
+// fills it by walking through the change log. Databases created before
+// checkpoints existed have a change log but no checkpoints.
+func initCheckpoints(tx *bolt.Tx, genesis changeEntry) error {
+	if tx.Bucket(ChangeLogCheckpoints) != nil {
+		return nil
+	}
+	_, err := tx.CreateBucket(ChangeLogCheckpoints)
+	if err != nil {
+		return err
+	}
+	for entry, exists := genesis, true; exists; entry, exists = entry.NextEntry(tx) {
+		err = updateCheckpoints(tx, entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // createChangeLog assumes that no change log exists and creates a new one.
 func (cs *ConsensusSet) createChangeLog(tx *bolt.Tx) error {
 	// Create the changelog bucket.
@@ -5,6 +5,8 @@ import (
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
 )
 
 // TestIntegrationChangeLog does a general test of the changelog by creating a
@@ -57,3 +59,109 @@ func TestIntegrationChangeLog(t *testing.T) {
 		t.Error("subscribers have inconsistent update chains")
 	}
 }
+
+// TestChangeLogCheckpoints checks that change log checkpoints are created at
+// the checkpoint interval, that subscribing from a checkpoint skips the blocks
+// below it, and that checkpoints are replaced when a reorg crosses them.
+func TestChangeLogCheckpoints(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := blankConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+	alt, err := blankConsensusSetTester(t.Name() + " - alt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer alt.Close()
+
+	// checkCheckpoint verifies that the most recent checkpoint at or below
+	// the height brings a subscriber to the current block. Checkpoints are
+	// only guaranteed to be at most checkpointInterval blocks apart when no
+	// reorg has crossed them.
+	checkCheckpoint := func(height types.BlockHeight, dense bool) {
+		t.Helper()
+		id, cpHeight, exists := cst.cs.ConsensusCheckpoint(height)
+		if !exists {
+			t.Fatal("no checkpoint found for height", height)
+		}
+		if cpHeight > height || (dense && height-cpHeight >= checkpointInterval) {
+			t.Fatalf("wrong checkpoint height %v for height %v", cpHeight, height)
+		}
+		ms := newMockSubscriber()
+		err := cst.cs.ConsensusSetSubscribe(&ms, id, cst.cs.tg.StopChan())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cst.cs.Unsubscribe(&ms)
+
+		// Replay the updates on top of the checkpoint height, the subscriber
+		// should end up at the current block.
+		tip := cpHeight
+		for _, cc := range ms.updates {
+			tip -= types.BlockHeight(len(cc.RevertedBlocks))
+			tip += types.BlockHeight(len(cc.AppliedBlocks))
+		}
+		if tip != cst.cs.Height() {
+			t.Fatalf("subscriber from checkpoint %v ended at height %v, expected %v", cpHeight, tip, cst.cs.Height())
+		}
+		if len(ms.updates) > 0 {
+			last := ms.updates[len(ms.updates)-1].AppliedBlocks
+			if last[len(last)-1].ID() != cst.cs.CurrentBlock().ID() {
+				t.Fatal("subscriber from checkpoint did not end at the current block")
+			}
+		}
+	}
+
+	for i := types.BlockHeight(0); i < 3*checkpointInterval+2; i++ {
+		_, err = cst.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for h := types.BlockHeight(0); h <= cst.cs.Height(); h++ {
+		checkCheckpoint(h, true)
+	}
+
+	// Reorg the main chain onto a longer alternate chain, the checkpoints
+	// of the reverted blocks should be replaced.
+	mainHeight := cst.cs.Height()
+	for alt.cs.Height() <= mainHeight {
+		b, err := alt.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = cst.cs.AcceptBlock(b)
+	}
+	if cst.cs.CurrentBlock().ID() != alt.cs.CurrentBlock().ID() {
+		t.Fatal("reorg did not happen")
+	}
+	// The reorg reverted every block after genesis, so the only checkpoint
+	// left below the old height is the genesis checkpoint.
+	if _, cpHeight, _ := cst.cs.ConsensusCheckpoint(mainHeight); cpHeight != 0 {
+		t.Fatal("checkpoint of a reverted block was not removed:", cpHeight)
+	}
+	for h := types.BlockHeight(0); h <= cst.cs.Height(); h++ {
+		checkCheckpoint(h, false)
+	}
+
+	// Drop the checkpoints and check that they are rebuilt from the change
+	// log.
+	err = cst.cs.db.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket(ChangeLogCheckpoints)
+		if err != nil {
+			return err
+		}
+		return initCheckpoints(tx, cst.cs.genesisEntry())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for h := types.BlockHeight(0); h <= cst.cs.Height(); h++ {
+		checkCheckpoint(h, false)
+	}
+}
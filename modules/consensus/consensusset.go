@@ -372,3 +372,49 @@ func (cs *ConsensusSet) StorageProofSegment(fcid types.FileContractID) (index ui
 	})
 	return index, err
 }
+
+// SiacoinOutput returns the siacoin output with the provided id, along with a
+// bool indicating whether the output is in the unspent set at the current
+// block. The lock is held so that the lookup does not observe a partially
+// applied reorg.
+func (cs *ConsensusSet) SiacoinOutput(id types.SiacoinOutputID) (sco types.SiacoinOutput, exists bool) {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return types.SiacoinOutput{}, false
+	}
+	defer cs.tg.Done()
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		sco, err = getSiacoinOutput(tx, id)
+		exists = err == nil
+		return nil
+	})
+	return sco, exists
+}
+
+// SiafundOutput returns the siafund output with the provided id, along with a
+// bool indicating whether the output is in the unspent set at the current
+// block. The lock is held so that the lookup does not observe a partially
+// applied reorg.
+func (cs *ConsensusSet) SiafundOutput(id types.SiafundOutputID) (sfo types.SiafundOutput, exists bool) {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return types.SiafundOutput{}, false
+	}
+	defer cs.tg.Done()
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		sfo, err = getSiafundOutput(tx, id)
+		exists = err == nil
+		return nil
+	})
+	return sfo, exists
+}
@@ -199,3 +199,42 @@ func TestDatabaseClosing(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestOutputLookups checks that siacoin and siafund outputs can be looked up
+// while they are unspent, and not after they have been spent.
+func TestOutputLookups(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := blankConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// The anyone-can-spend siafund output of the genesis block is unspent
+	// until addSiafunds moves it to the wallet.
+	genesisID := cst.cs.blockRoot.Block.Transactions[0].SiafundOutputID(2)
+	if _, exists := cst.cs.SiafundOutput(genesisID); !exists {
+		t.Fatal("unspent genesis siafund output was not found")
+	}
+	cst.addSiafunds()
+	if _, exists := cst.cs.SiafundOutput(genesisID); exists {
+		t.Fatal("spent siafund output was found")
+	}
+
+	// Miner payouts are added to the unspent set once they mature.
+	cst.mineSiacoins()
+	b, _ := cst.cs.BlockAtHeight(1)
+	sco, exists := cst.cs.SiacoinOutput(b.MinerPayoutID(0))
+	if !exists {
+		t.Fatal("matured miner payout was not found")
+	}
+	if sco.Value.Cmp(b.MinerPayouts[0].Value) != 0 || sco.UnlockHash != b.MinerPayouts[0].UnlockHash {
+		t.Fatal("miner payout does not match the block")
+	}
+	if _, exists := cst.cs.SiacoinOutput(types.SiacoinOutputID{1}); exists {
+		t.Fatal("unknown siacoin output was found")
+	}
+}
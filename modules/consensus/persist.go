@@ -47,6 +47,13 @@ func (cs *ConsensusSet) loadDB() error {
 			return err
 		}
 
+		// Build the change log checkpoints for databases that were created
+		// before checkpoints were introduced.
+		err = initCheckpoints(tx, cs.genesisEntry())
+		if err != nil {
+			return err
+		}
+
 		// Check that the genesis block is correct - typically only incorrect
 		// in the event of developer binaries vs. release binaires.
 		genesisID, err := getPath(tx, 0)
@@ -1,8 +1,12 @@
 package consensus
 
 import (
+	"bytes"
+	"encoding/binary"
+
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
 
 	siasync "github.com/NebulousLabs/Sia/sync"
 	"github.com/coreos/bbolt"
@@ -231,6 +235,37 @@ func (cs *ConsensusSet) ConsensusSetSubscribe(subscriber modules.ConsensusSetSub
 	return nil
 }
 
+// ConsensusCheckpoint returns the most recent change log checkpoint at or below
+// the provided height, along with the height of the current path's tip after
+// the checkpoint's change was applied. Subscribing with the checkpoint's id
+// sends every change that happened after that point, skipping the blocks
+// below it. The bool is false if there is no such checkpoint.
+func (cs *ConsensusSet) ConsensusCheckpoint(height types.BlockHeight) (id modules.ConsensusChangeID, checkpointHeight types.BlockHeight, exists bool) {
+	if cs.tg.Add() != nil {
+		return modules.ConsensusChangeID{}, 0, false
+	}
+	defer cs.tg.Done()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(ChangeLogCheckpoints).Cursor()
+		key := checkpointKey(height)
+		k, v := c.Seek(key)
+		if k == nil {
+			k, v = c.Last()
+		} else if !bytes.Equal(k, key) {
+			k, v = c.Prev()
+		}
+		if k == nil {
+			return nil
+		}
+		checkpointHeight = types.BlockHeight(binary.BigEndian.Uint64(k))
+		copy(id[:], v)
+		exists = true
+		return nil
+	})
+	return id, checkpointHeight, exists
+}
+
 // Unsubscribe removes a subscriber from the list of subscribers, allowing for
 // garbage collection and rescanning. If the subscriber is not found in the
 // subscriber database, no action is taken.
@@ -420,12 +420,23 @@ func (cs *ConsensusSet) threadedRPCRelayHeader(conn modules.PeerConn) error {
 	}
 
 	// Start verification inside of a bolt View tx.
+	var height types.BlockHeight
 	cs.mu.RLock()
 	err = cs.db.View(func(tx *bolt.Tx) error {
+		// Look up the height of the header so that it can be recorded in the
+		// peer's statistics.
+		if parent, err := getBlockMap(tx, h.ParentID); err == nil {
+			height = parent.Height + 1
+		}
 		// Do some relatively inexpensive checks to validate the header
 		return cs.validateHeader(boltTxWrapper{tx}, h)
 	})
 	cs.mu.RUnlock()
+	// Record the header as the most recent block advertised by the peer if it
+	// is valid or already known.
+	if err == nil || err == modules.ErrBlockKnown {
+		cs.gateway.UpdatePeerBlock(conn.RPCAddr(), h.ID(), height)
+	}
 	// WARN: orphan multithreading logic (dangerous areas, see below)
 	//
 	// If the header is valid and extends the heaviest chain, fetch the
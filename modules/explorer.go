@@ -45,6 +45,22 @@ type (
 		TotalRevisionVolume types.Currency `json:"totalrevisionvolume"`
 	}
 
+	// BlockStats are aggregate statistics about the transactions of a single
+	// block. Unlike the BlockFacts, which accumulate over the whole
+	// blockchain, the totals only cover the block itself, with the exception
+	// of the active contract count.
+	BlockStats struct {
+		BlockID   types.BlockID     `json:"blockid"`
+		Height    types.BlockHeight `json:"height"`
+		Timestamp types.Timestamp   `json:"timestamp"`
+
+		TransactionCount    uint64         `json:"transactioncount"`
+		SiacoinsTransferred types.Currency `json:"siacoinstransferred"`
+		FileContractCount   uint64         `json:"filecontractcount"`
+		StorageProofCount   uint64         `json:"storageproofcount"`
+		ActiveContractCount uint64         `json:"activecontractcount"`
+	}
+
 	// Explorer tracks the blockchain and provides tools for gathering
 	// statistics and finding objects or patterns within the blockchain.
 	Explorer interface {
@@ -60,6 +76,10 @@ type (
 		// in the explorer's database.
 		LatestBlockFacts() BlockFacts
 
+		// BlockStats returns the statistics of the blocks between the start
+		// and end height, inclusive, in order of height.
+		BlockStats(start, end types.BlockHeight) []BlockStats
+
 		// Transaction returns the block that contains the input transaction
 		// id. The transaction itself is either the block (indicating the miner
 		// payouts are somehow involved), or it is a transaction inside of the
@@ -91,6 +111,10 @@ type (
 		// the provided file contract id.
 		FileContractID(types.FileContractID) []types.TransactionID
 
+		// UnlockHashFileContracts returns the ids of all the file contracts
+		// whose valid or missed proof outputs pay the provided unlock hash.
+		UnlockHashFileContracts(types.UnlockHash) []types.FileContractID
+
 		// SiafundOutput will return the siafund output associated with the
 		// input id.
 		SiafundOutput(types.SiafundOutputID) (types.SiafundOutput, bool)
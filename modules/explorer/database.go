@@ -13,6 +13,7 @@ var (
 	// database buckets
 	bucketBlockFacts            = []byte("BlockFacts")
 	bucketBlockIDs              = []byte("BlockIDs")
+	bucketBlockStats            = []byte("BlockStats")
 	bucketBlocksDifficulty      = []byte("BlocksDifficulty")
 	bucketBlockTargets          = []byte("BlockTargets")
 	bucketFileContractHistories = []byte("FileContractHistories")
@@ -25,6 +26,9 @@ var (
 	bucketSiafundOutputs   = []byte("SiafundOutputs")
 	bucketTransactionIDs   = []byte("TransactionIDs")
 	bucketUnlockHashes     = []byte("UnlockHashes")
+	// bucketUnlockHashContracts maps unlock hashes to the file contracts
+	// whose proof outputs pay them
+	bucketUnlockHashContracts = []byte("UnlockHashContracts")
 
 	errNotExist = errors.New("entry does not exist")
 
@@ -89,6 +93,33 @@ func dbGetTransactionIDSet(bucket []byte, key interface{}, ids *[]types.Transact
 	}
 }
 
+// dbGetFileContractIDSet returns a 'func(*bolt.Tx) error' that decodes the
+// file contract IDs paying the unlock hash into a slice. If the unlock hash is
+// not paid by any file contract, dbGetFileContractIDSet returns errNotExist.
+func dbGetFileContractIDSet(uh types.UnlockHash, ids *[]types.FileContractID) func(*bolt.Tx) error {
+	return func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketUnlockHashContracts).Bucket(encoding.Marshal(uh))
+		if b == nil {
+			return errNotExist
+		}
+		var fcids []types.FileContractID
+		err := b.ForEach(func(fcid, _ []byte) error {
+			var id types.FileContractID
+			err := encoding.Unmarshal(fcid, &id)
+			if err != nil {
+				return err
+			}
+			fcids = append(fcids, id)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		*ids = fcids
+		return nil
+	}
+}
+
 // dbGetBlockFacts returns a 'func(*bolt.Tx) error' that decodes
 // the block facts for `height` into blockfacts
 func (e *Explorer) dbGetBlockFacts(height types.BlockHeight, bf *blockFacts) func(*bolt.Tx) error {
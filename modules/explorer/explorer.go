@@ -41,6 +41,18 @@ type (
 		Timestamp types.Timestamp
 	}
 
+	// blockStats contains the statistics of a single block that are computed
+	// as the block is processed. The remaining fields of modules.BlockStats
+	// are filled in from the block and its block facts when the statistics
+	// are requested, since the active contract count is only known once the
+	// whole consensus change has been processed.
+	blockStats struct {
+		TransactionCount    uint64
+		SiacoinsTransferred types.Currency
+		FileContractCount   uint64
+		StorageProofCount   uint64
+	}
+
 	// An Explorer contains a more comprehensive view of the blockchain,
 	// including various statistics and metrics.
 	Explorer struct {
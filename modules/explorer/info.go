@@ -53,6 +53,60 @@ func (e *Explorer) LatestBlockFacts() modules.BlockFacts {
 	return bf.BlockFacts
 }
 
+// BlockStats returns the statistics of the blocks between the start and end
+// height, inclusive. Heights above the latest block in the explorer's
+// database are ignored.
+func (e *Explorer) BlockStats(start, end types.BlockHeight) []modules.BlockStats {
+	var stats []modules.BlockStats
+	err := e.db.View(func(tx *bolt.Tx) error {
+		var height types.BlockHeight
+		err := dbGetInternal(internalBlockHeight, &height)(tx)
+		if err != nil {
+			return err
+		}
+		if end > height {
+			end = height
+		}
+		for h := start; h <= end; h++ {
+			block, exists := e.cs.BlockAtHeight(h)
+			if !exists {
+				break
+			}
+			// The stats of a block that the explorer has not processed,
+			// which can happen while it catches up with a reorg, are
+			// computed on the spot.
+			var bs blockStats
+			err := dbGetAndDecode(bucketBlockStats, block.ID(), &bs)(tx)
+			if err == errNotExist {
+				bs = calculateBlockStats(block)
+			} else if err != nil {
+				return err
+			}
+			// The active contract count is only tracked by the block facts,
+			// which may be missing for some blocks.
+			var bf blockFacts
+			dbGetAndDecode(bucketBlockFacts, block.ID(), &bf)(tx)
+
+			stats = append(stats, modules.BlockStats{
+				BlockID:   block.ID(),
+				Height:    h,
+				Timestamp: block.Timestamp,
+
+				TransactionCount:    bs.TransactionCount,
+				SiacoinsTransferred: bs.SiacoinsTransferred,
+				FileContractCount:   bs.FileContractCount,
+				StorageProofCount:   bs.StorageProofCount,
+				ActiveContractCount: bf.ActiveContractCount,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		build.Critical(err)
+	}
+	return stats
+}
+
 // Transaction takes a transaction ID and finds the block containing the
 // transaction. Because of the miner payouts, the transaction ID might be a
 // block ID. To find the transaction, iterate through the block.
@@ -163,6 +217,19 @@ func (e *Explorer) FileContractPayouts(id types.FileContractID) ([]types.Siacoin
 	return outputs, nil
 }
 
+// UnlockHashFileContracts returns the IDs of all the file contracts whose valid
+// or missed proof outputs pay the unlock hash, including the outputs set by
+// revisions. An empty set indicates that no file contract pays the unlock
+// hash.
+func (e *Explorer) UnlockHashFileContracts(uh types.UnlockHash) []types.FileContractID {
+	var ids []types.FileContractID
+	err := e.db.View(dbGetFileContractIDSet(uh, &ids))
+	if err != nil {
+		ids = nil
+	}
+	return ids
+}
+
 // SiafundOutput returns the siafund output associated with the specified ID.
 func (e *Explorer) SiafundOutput(id types.SiafundOutputID) (types.SiafundOutput, bool) {
 	var sco types.SiafundOutput
@@ -1,11 +1,15 @@
 package explorer
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/fastrand"
+
+	"github.com/coreos/bbolt"
 )
 
 // TestImmediateBlockFacts grabs the block facts object from the block explorer
@@ -259,3 +263,182 @@ func TestFileContractsPayoutValidProof(t *testing.T) {
 		t.Errorf("expected %v, got %v ", fc.MissedProofOutputs, outputs)
 	}
 }
+
+// TestUnlockHashFileContracts checks that the explorer tracks which file
+// contracts pay an unlock hash, including after the index is rebuilt from the
+// stored contract histories.
+func TestUnlockHashFileContracts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	et, err := createExplorerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a contract that pays uhA twice when the proof is valid and uhB
+	// when the proof is missed.
+	var uhA, uhB types.UnlockHash
+	fastrand.Read(uhA[:])
+	fastrand.Read(uhB[:])
+	payout := types.NewCurrency64(1e9)
+	postTax := types.PostTax(et.cs.Height(), payout)
+	half := postTax.Div64(2)
+	fc := types.FileContract{
+		WindowStart: et.cs.Height() + 10,
+		WindowEnd:   et.cs.Height() + 20,
+		Payout:      payout,
+		ValidProofOutputs: []types.SiacoinOutput{
+			{Value: half, UnlockHash: uhA},
+			{Value: postTax.Sub(half), UnlockHash: uhA},
+		},
+		MissedProofOutputs: []types.SiacoinOutput{{Value: postTax, UnlockHash: uhB}},
+		UnlockHash:         types.UnlockConditions{}.UnlockHash(),
+	}
+	builder := et.wallet.StartTransaction()
+	err = builder.FundSiacoins(payout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcIndex := builder.AddFileContract(fc)
+	tSet, err := builder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = et.tpool.AcceptTransactionSet(tSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = et.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fcid := tSet[len(tSet)-1].FileContractID(fcIndex)
+
+	checkContracts := func(uh types.UnlockHash, expected []types.FileContractID) {
+		t.Helper()
+		fcids := et.explorer.UnlockHashFileContracts(uh)
+		if len(fcids) != len(expected) {
+			t.Fatalf("expected %v contracts, got %v", len(expected), len(fcids))
+		}
+		for i := range fcids {
+			if fcids[i] != expected[i] {
+				t.Fatal("wrong contract returned for unlock hash")
+			}
+		}
+	}
+	checkContracts(uhA, []types.FileContractID{fcid})
+	checkContracts(uhB, []types.FileContractID{fcid})
+
+	// Drop the index and reopen the explorer, the index should be rebuilt.
+	et.cs.Unsubscribe(et.explorer)
+	err = et.explorer.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(bucketUnlockHashContracts)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = et.explorer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	et.explorer, err = New(et.cs, filepath.Join(et.testdir, modules.ExplorerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContracts(uhA, []types.FileContractID{fcid})
+	checkContracts(uhB, []types.FileContractID{fcid})
+
+	// Removing the contract outputs one at a time, as done when reverting the
+	// contract, should only drop the contract once no output references the
+	// unlock hash.
+	err = et.explorer.db.Update(func(tx *bolt.Tx) error {
+		dbRemoveUnlockHashContract(tx, uhA, fcid)
+		dbRemoveUnlockHashContract(tx, uhB, fcid)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContracts(uhA, []types.FileContractID{fcid})
+	checkContracts(uhB, nil)
+	err = et.explorer.db.Update(func(tx *bolt.Tx) error {
+		dbRemoveUnlockHashContract(tx, uhA, fcid)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContracts(uhA, nil)
+}
+
+// TestBlockStats checks that the explorer records the statistics of each
+// block, and that they survive a rebuild of the block stats bucket.
+func TestBlockStats(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	et, err := createExplorerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Send some coins so that a block contains a transfer.
+	var uc types.UnlockConditions
+	_, err = et.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := et.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	height := et.cs.Height()
+	stats := et.explorer.BlockStats(0, height+10)
+	if types.BlockHeight(len(stats)) != height+1 {
+		t.Fatalf("expected stats for %v blocks, got %v", height+1, len(stats))
+	}
+	for i, bs := range stats {
+		if bs.Height != types.BlockHeight(i) {
+			t.Fatal("stats are out of order:", i, bs.Height)
+		}
+	}
+	if stats[0].BlockID != types.GenesisID || stats[0].TransactionCount != 1 {
+		t.Error("wrong stats for the genesis block:", stats[0])
+	}
+	last := stats[height]
+	if last.BlockID != block.ID() || last.TransactionCount != uint64(len(block.Transactions)) {
+		t.Error("wrong stats for the last block:", last)
+	}
+	if last.SiacoinsTransferred.Cmp(types.SiacoinPrecision.Mul64(100)) < 0 {
+		t.Error("transfer is missing from the stats:", last.SiacoinsTransferred)
+	}
+	if len(et.explorer.BlockStats(height+1, height+10)) != 0 {
+		t.Error("got stats for blocks above the current height")
+	}
+
+	// Explorers created before the stats existed have them computed from
+	// the blockchain.
+	err = et.explorer.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucketBlockStats); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(bucketBlockStats); err != nil {
+			return err
+		}
+		return et.explorer.dbBuildBlockStats(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuilt := et.explorer.BlockStats(0, height)
+	if len(rebuilt) != len(stats) {
+		t.Fatal("rebuilt stats have the wrong length:", len(rebuilt))
+	}
+	for i := range stats {
+		if rebuilt[i].TransactionCount != stats[i].TransactionCount || !rebuilt[i].SiacoinsTransferred.Equals(stats[i].SiacoinsTransferred) {
+			t.Fatal("rebuilt stats differ at height", i)
+		}
+	}
+}
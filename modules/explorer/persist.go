@@ -1,6 +1,7 @@
 package explorer
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -34,9 +35,17 @@ func (e *Explorer) initPersist() error {
 
 	// Initialize the database
 	err = e.db.Update(func(tx *bolt.Tx) error {
+		// Explorers created before the unlock hash contract index existed
+		// need to have it built from the stored contract histories.
+		buildContractIndex := tx.Bucket(bucketUnlockHashContracts) == nil
+		// Likewise, explorers created before the block statistics existed
+		// need to compute them for the blocks that were already processed.
+		buildBlockStats := tx.Bucket(bucketBlockStats) == nil
+
 		buckets := [][]byte{
 			bucketBlockFacts,
 			bucketBlockIDs,
+			bucketBlockStats,
 			bucketBlocksDifficulty,
 			bucketBlockTargets,
 			bucketFileContractHistories,
@@ -48,6 +57,7 @@ func (e *Explorer) initPersist() error {
 			bucketSiafundOutputs,
 			bucketTransactionIDs,
 			bucketUnlockHashes,
+			bucketUnlockHashContracts,
 		}
 		for _, b := range buckets {
 			_, err := tx.CreateBucketIfNotExists(b)
@@ -74,6 +84,14 @@ func (e *Explorer) initPersist() error {
 			}
 		}
 
+		if buildContractIndex {
+			if err := dbBuildUnlockHashContracts(tx); err != nil {
+				return err
+			}
+		}
+		if buildBlockStats {
+			return e.dbBuildBlockStats(tx)
+		}
 		return nil
 	})
 	if err != nil {
@@ -82,3 +100,60 @@ func (e *Explorer) initPersist() error {
 
 	return nil
 }
+
+// dbBuildBlockStats computes the block statistics of the blocks that are
+// already in the database.
+func (e *Explorer) dbBuildBlockStats(tx *bolt.Tx) (err error) {
+	// the db helpers panic on error
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	var height types.BlockHeight
+	if err := dbGetInternal(internalBlockHeight, &height)(tx); err != nil {
+		return err
+	}
+	if tx.Bucket(bucketBlockIDs).Get(encoding.Marshal(types.GenesisID)) == nil {
+		// The explorer has not processed any blocks yet.
+		return nil
+	}
+	for h := types.BlockHeight(0); h <= height; h++ {
+		block, exists := e.cs.BlockAtHeight(h)
+		if !exists {
+			return fmt.Errorf("consensus set is missing block at height %v", h)
+		}
+		dbAddBlockStats(tx, block.ID(), calculateBlockStats(block))
+	}
+	return nil
+}
+
+// dbBuildUnlockHashContracts fills the unlock hash contract index using the
+// file contract histories that are already in the database.
+func dbBuildUnlockHashContracts(tx *bolt.Tx) (err error) {
+	// the db helpers panic on error
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return tx.Bucket(bucketFileContractHistories).ForEach(func(k, v []byte) error {
+		var fcid types.FileContractID
+		var history fileContractHistory
+		if err := encoding.Unmarshal(k, &fcid); err != nil {
+			return err
+		}
+		if err := encoding.Unmarshal(v, &history); err != nil {
+			return err
+		}
+		outputs := append(history.Contract.ValidProofOutputs, history.Contract.MissedProofOutputs...)
+		for _, fcr := range history.Revisions {
+			outputs = append(outputs, fcr.NewValidProofOutputs...)
+			outputs = append(outputs, fcr.NewMissedProofOutputs...)
+		}
+		for _, sco := range outputs {
+			dbAddUnlockHashContract(tx, sco.UnlockHash, fcid)
+		}
+		return nil
+	})
+}
@@ -78,11 +78,13 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 						scoid := fcid.StorageProofOutputID(types.ProofValid, uint64(l))
 						dbRemoveSiacoinOutputID(tx, scoid, txid)
 						dbRemoveUnlockHash(tx, sco.UnlockHash, txid)
+						dbRemoveUnlockHashContract(tx, sco.UnlockHash, fcid)
 					}
 					for l, sco := range fc.MissedProofOutputs {
 						scoid := fcid.StorageProofOutputID(types.ProofMissed, uint64(l))
 						dbRemoveSiacoinOutputID(tx, scoid, txid)
 						dbRemoveUnlockHash(tx, sco.UnlockHash, txid)
+						dbRemoveUnlockHashContract(tx, sco.UnlockHash, fcid)
 					}
 					dbRemoveFileContract(tx, fcid)
 				}
@@ -94,11 +96,13 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 						scoid := fcr.ParentID.StorageProofOutputID(types.ProofValid, uint64(l))
 						dbRemoveSiacoinOutputID(tx, scoid, txid)
 						dbRemoveUnlockHash(tx, sco.UnlockHash, txid)
+						dbRemoveUnlockHashContract(tx, sco.UnlockHash, fcr.ParentID)
 					}
 					for l, sco := range fcr.NewMissedProofOutputs {
 						scoid := fcr.ParentID.StorageProofOutputID(types.ProofMissed, uint64(l))
 						dbRemoveSiacoinOutputID(tx, scoid, txid)
 						dbRemoveUnlockHash(tx, sco.UnlockHash, txid)
+						dbRemoveUnlockHashContract(tx, sco.UnlockHash, fcr.ParentID)
 					}
 					// Remove the file contract revision from the revision chain.
 					dbRemoveFileContractRevision(tx, fcr.ParentID)
@@ -118,8 +122,9 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 				}
 			}
 
-			// remove the associated block facts
+			// remove the associated block facts and stats
 			dbRemoveBlockFacts(tx, bid)
+			dbRemoveBlockStats(tx, bid)
 		}
 
 		// Update cumulative stats for applied blocks.
@@ -174,11 +179,13 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 						scoid := fcid.StorageProofOutputID(types.ProofValid, uint64(l))
 						dbAddSiacoinOutputID(tx, scoid, txid)
 						dbAddUnlockHash(tx, sco.UnlockHash, txid)
+						dbAddUnlockHashContract(tx, sco.UnlockHash, fcid)
 					}
 					for l, sco := range fc.MissedProofOutputs {
 						scoid := fcid.StorageProofOutputID(types.ProofMissed, uint64(l))
 						dbAddSiacoinOutputID(tx, scoid, txid)
 						dbAddUnlockHash(tx, sco.UnlockHash, txid)
+						dbAddUnlockHashContract(tx, sco.UnlockHash, fcid)
 					}
 				}
 				for _, fcr := range txn.FileContractRevisions {
@@ -189,11 +196,13 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 						scoid := fcr.ParentID.StorageProofOutputID(types.ProofValid, uint64(l))
 						dbAddSiacoinOutputID(tx, scoid, txid)
 						dbAddUnlockHash(tx, sco.UnlockHash, txid)
+						dbAddUnlockHashContract(tx, sco.UnlockHash, fcr.ParentID)
 					}
 					for l, sco := range fcr.NewMissedProofOutputs {
 						scoid := fcr.ParentID.StorageProofOutputID(types.ProofMissed, uint64(l))
 						dbAddSiacoinOutputID(tx, scoid, txid)
 						dbAddUnlockHash(tx, sco.UnlockHash, txid)
+						dbAddUnlockHashContract(tx, sco.UnlockHash, fcr.ParentID)
 					}
 					dbAddFileContractRevision(tx, fcr.ParentID, fcr)
 				}
@@ -218,6 +227,7 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 				facts := dbCalculateBlockFacts(tx, e.cs, block)
 				dbAddBlockFacts(tx, facts)
 			}
+			dbAddBlockStats(tx, bid, calculateBlockStats(block))
 		}
 
 		// Update stats according to SiacoinOutputDiffs
@@ -322,6 +332,14 @@ func dbRemoveBlockFacts(tx *bolt.Tx, id types.BlockID) {
 	mustDelete(tx.Bucket(bucketBlockFacts), id)
 }
 
+// Add/Remove block stats
+func dbAddBlockStats(tx *bolt.Tx, id types.BlockID, stats blockStats) {
+	mustPut(tx.Bucket(bucketBlockStats), id, stats)
+}
+func dbRemoveBlockStats(tx *bolt.Tx, id types.BlockID) {
+	mustDelete(tx.Bucket(bucketBlockStats), id)
+}
+
 // Add/Remove block target
 func dbAddBlockTarget(tx *bolt.Tx, id types.BlockID, target types.Target) {
 	mustPut(tx.Bucket(bucketBlockTargets), id, target)
@@ -444,6 +462,33 @@ func dbRemoveUnlockHash(tx *bolt.Tx, uh types.UnlockHash, txid types.Transaction
 	}
 }
 
+// Add/Remove file contract ID from unlock hash contract bucket. An unlock hash
+// can be paid by several outputs of the same contract and its revisions, so
+// each file contract ID is stored along with the number of outputs referencing
+// the unlock hash.
+func dbAddUnlockHashContract(tx *bolt.Tx, uh types.UnlockHash, fcid types.FileContractID) {
+	b, err := tx.Bucket(bucketUnlockHashContracts).CreateBucketIfNotExists(encoding.Marshal(uh))
+	assertNil(err)
+	var refs uint64
+	if v := b.Get(encoding.Marshal(fcid)); v != nil {
+		assertNil(encoding.Unmarshal(v, &refs))
+	}
+	mustPut(b, fcid, refs+1)
+}
+func dbRemoveUnlockHashContract(tx *bolt.Tx, uh types.UnlockHash, fcid types.FileContractID) {
+	bucket := tx.Bucket(bucketUnlockHashContracts).Bucket(encoding.Marshal(uh))
+	var refs uint64
+	assertNil(encoding.Unmarshal(bucket.Get(encoding.Marshal(fcid)), &refs))
+	if refs > 1 {
+		mustPut(bucket, fcid, refs-1)
+		return
+	}
+	mustDelete(bucket, fcid)
+	if bucketIsEmpty(bucket) {
+		tx.Bucket(bucketUnlockHashContracts).DeleteBucket(encoding.Marshal(uh))
+	}
+}
+
 func dbCalculateBlockFacts(tx *bolt.Tx, cs modules.ConsensusSet, block types.Block) blockFacts {
 	// get the parent block facts
 	var bf blockFacts
@@ -524,6 +569,22 @@ func dbCalculateBlockFacts(tx *bolt.Tx, cs modules.ConsensusSet, block types.Blo
 	return bf
 }
 
+// calculateBlockStats returns the statistics of the transactions in a block.
+// The siacoins transferred are the value of the siacoin outputs created by the
+// transactions, which excludes miner payouts, miner fees and contract payouts.
+func calculateBlockStats(block types.Block) blockStats {
+	var bs blockStats
+	bs.TransactionCount = uint64(len(block.Transactions))
+	for _, txn := range block.Transactions {
+		for _, sco := range txn.SiacoinOutputs {
+			bs.SiacoinsTransferred = bs.SiacoinsTransferred.Add(sco.Value)
+		}
+		bs.FileContractCount += uint64(len(txn.FileContracts))
+		bs.StorageProofCount += uint64(len(txn.StorageProofs))
+	}
+	return bs
+}
+
 // Special handling for the genesis block. No other functions are called on it.
 func dbAddGenesisBlock(tx *bolt.Tx) {
 	id := types.GenesisID
@@ -548,4 +609,5 @@ func dbAddGenesisBlock(tx *bolt.Tx) {
 		},
 		Timestamp: types.GenesisBlock.Timestamp,
 	})
+	dbAddBlockStats(tx, id, calculateBlockStats(types.GenesisBlock))
 }
@@ -2,8 +2,10 @@ package modules
 
 import (
 	"net"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 const (
@@ -137,6 +139,20 @@ type (
 		Version    string     `json:"version"`
 	}
 
+	// PeerStats contains the statistics of the connection to a peer.
+	PeerStats struct {
+		Peer
+		BytesSent     uint64        `json:"bytessent"`
+		BytesReceived uint64        `json:"bytesreceived"`
+		Uptime        time.Duration `json:"uptime"`
+
+		// LastBlockID and LastBlockHeight identify the most recent block
+		// that the peer advertised. They are empty if the peer has not
+		// advertised any blocks since connecting.
+		LastBlockID     types.BlockID     `json:"lastblockid"`
+		LastBlockHeight types.BlockHeight `json:"lastblockheight"`
+	}
+
 	// A PeerConn is the connection type used when communicating with peers during
 	// an RPC. It is identical to a net.Conn with the additional RPCAddr method.
 	// This method acts as an identifier for peers and is the address that the
@@ -171,6 +187,41 @@ type (
 		// Peers returns the addresses that the Gateway is currently connected to.
 		Peers() []Peer
 
+		// PeerStats returns the statistics of the peers that the Gateway is
+		// currently connected to.
+		PeerStats() []PeerStats
+
+		// UpdatePeerBlock records the most recent block that a peer
+		// advertised.
+		UpdatePeerBlock(addr NetAddress, id types.BlockID, height types.BlockHeight)
+
+		// PinPeer marks a peer as trusted. Pinned peers are reconnected on
+		// startup and are never evicted by the normal connection management.
+		PinPeer(NetAddress) error
+
+		// UnpinPeer removes a peer from the set of pinned peers.
+		UnpinPeer(NetAddress) error
+
+		// PinnedPeers returns the addresses of the pinned peers.
+		PinnedPeers() []NetAddress
+
+		// BlockHost permanently refuses connections to and from a host.
+		BlockHost(string) error
+
+		// UnblockHost removes a host from the blocklist.
+		UnblockHost(string) error
+
+		// Blocklist returns the hosts that the Gateway refuses to connect to.
+		Blocklist() []string
+
+		// RateLimits returns the maximum download and upload speeds of the
+		// Gateway in bytes per second. A value of 0 means unlimited.
+		RateLimits() (downloadSpeed, uploadSpeed int64)
+
+		// SetRateLimits limits the total bandwidth used by the Gateway's peer
+		// connections. A value of 0 means unlimited.
+		SetRateLimits(downloadSpeed, uploadSpeed int64) error
+
 		// RegisterRPC registers a function to handle incoming connections that
 		// supply the given RPC ID.
 		RegisterRPC(string, RPCFunc)
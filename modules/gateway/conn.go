@@ -2,9 +2,12 @@ package gateway
 
 import (
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/ratelimit"
 )
 
 // peerConn is a simple type that implements the modules.PeerConn interface.
@@ -19,9 +22,58 @@ func (pc peerConn) RPCAddr() modules.NetAddress {
 	return pc.dialbackAddr
 }
 
+// peerStats contains the statistics of a peer session. The byte counters are
+// updated atomically by the session's connection, the remaining fields are
+// protected by the gateway's mutex.
+type peerStats struct {
+	bytesSent     uint64
+	bytesReceived uint64
+
+	connectedAt     time.Time
+	lastBlockID     types.BlockID
+	lastBlockHeight types.BlockHeight
+}
+
+// newPeerStats returns the statistics of a new peer session.
+func newPeerStats() *peerStats {
+	return &peerStats{
+		connectedAt: time.Now(),
+	}
+}
+
+// statsConn is a net.Conn that counts the bytes that are sent and received
+// over it.
+type statsConn struct {
+	net.Conn
+	stats *peerStats
+}
+
+// newStatsConn wraps a connection so that its traffic is added to stats.
+func newStatsConn(conn net.Conn, stats *peerStats) net.Conn {
+	return &statsConn{
+		Conn:  conn,
+		stats: stats,
+	}
+}
+
+// Read implements the io.Reader interface.
+func (sc *statsConn) Read(b []byte) (int, error) {
+	n, err := sc.Conn.Read(b)
+	atomic.AddUint64(&sc.stats.bytesReceived, uint64(n))
+	return n, err
+}
+
+// Write implements the io.Writer interface.
+func (sc *statsConn) Write(b []byte) (int, error) {
+	n, err := sc.Conn.Write(b)
+	atomic.AddUint64(&sc.stats.bytesSent, uint64(n))
+	return n, err
+}
+
 // staticDial will staticDial the input address and return a connection. staticDial appropriately
 // handles things like clean shutdown, fast shutdown, and chooses the correct
-// communication protocol.
+// communication protocol. The connection is subject to the gateway's rate
+// limits.
 func (g *Gateway) staticDial(addr modules.NetAddress) (net.Conn, error) {
 	dialer := &net.Dialer{
 		Cancel:  g.threads.StopChan(),
@@ -32,5 +84,5 @@ func (g *Gateway) staticDial(addr modules.NetAddress) (net.Conn, error) {
 		return nil, err
 	}
 	conn.SetDeadline(time.Now().Add(connStdDeadline))
-	return conn, nil
+	return ratelimit.NewRLConn(conn, g.rl, g.threads.StopChan()), nil
 }
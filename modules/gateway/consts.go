@@ -25,6 +25,12 @@ const (
 	// connect to itself, this number can be reduced.
 	maxLocalOutboundPeers = 3
 
+	// rateLimitPacketSize is the size of the packets in which the bandwidth
+	// of rate limited peer connections is allotted. Connections take turns
+	// sending and receiving packets, so that a single peer cannot use up the
+	// entire bandwidth allowance.
+	rateLimitPacketSize = 4 * 4096
+
 	// minAcceptableVersion is the version below which the gateway will refuse to
 	// connect to peers and reject connection attempts.
 	//
@@ -107,6 +107,7 @@ import (
 	"github.com/NebulousLabs/Sia/persist"
 	siasync "github.com/NebulousLabs/Sia/sync"
 	"github.com/NebulousLabs/fastrand"
+	"github.com/NebulousLabs/ratelimit"
 )
 
 var (
@@ -145,6 +146,21 @@ type Gateway struct {
 	peers  map[modules.NetAddress]*peer
 	peerTG siasync.ThreadGroup
 
+	// pinnedPeers are the peers that the user trusts. They are reconnected on
+	// startup, are never kicked to make room for other peers, and are never
+	// pruned from the node list.
+	//
+	// blocklist is the set of hosts that the gateway refuses to connect to or
+	// accept connections from.
+	pinnedPeers map[modules.NetAddress]struct{}
+	blocklist   map[string]struct{}
+
+	// rl limits the bandwidth of all peer connections. The limits are shared
+	// by all connections. A limit of 0 means unlimited.
+	rl               *ratelimit.RateLimit
+	maxDownloadSpeed int64
+	maxUploadSpeed   int64
+
 	// Utilities.
 	log        *persist.Logger
 	mu         sync.RWMutex
@@ -176,6 +192,42 @@ func (g *Gateway) Address() modules.NetAddress {
 	return g.myAddr
 }
 
+// RateLimits returns the maximum download and upload speeds of the Gateway in
+// bytes per second. A value of 0 means unlimited.
+func (g *Gateway) RateLimits() (downloadSpeed, uploadSpeed int64) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.maxDownloadSpeed, g.maxUploadSpeed
+}
+
+// SetRateLimits limits the total bandwidth used by the connections to the
+// Gateway's peers. The limits are shared fairly by all connections. A value of
+// 0 means unlimited.
+func (g *Gateway) SetRateLimits(downloadSpeed, uploadSpeed int64) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+
+	if downloadSpeed < 0 || uploadSpeed < 0 {
+		return errors.New("download/upload rate limit can't be below 0")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.setRateLimits(downloadSpeed, uploadSpeed)
+	return g.saveSync()
+}
+
+// setRateLimits applies the bandwidth limits to the Gateway's rate limiter.
+func (g *Gateway) setRateLimits(downloadSpeed, uploadSpeed int64) {
+	g.maxDownloadSpeed, g.maxUploadSpeed = downloadSpeed, uploadSpeed
+	if downloadSpeed == 0 && uploadSpeed == 0 {
+		g.rl.SetLimits(0, 0, 0)
+	} else {
+		g.rl.SetLimits(downloadSpeed, uploadSpeed, rateLimitPacketSize)
+	}
+}
+
 // Close saves the state of the Gateway and stops its listener process.
 func (g *Gateway) Close() error {
 	if err := g.threads.Stop(); err != nil {
@@ -201,6 +253,11 @@ func New(addr string, bootstrap bool, persistDir string) (*Gateway, error) {
 		nodes: make(map[modules.NetAddress]*node),
 		peers: make(map[modules.NetAddress]*peer),
 
+		pinnedPeers: make(map[modules.NetAddress]struct{}),
+		blocklist:   make(map[string]struct{}),
+
+		rl: ratelimit.NewRateLimit(0, 0, 0),
+
 		persistDir: persistDir,
 	}
 
@@ -247,6 +304,17 @@ func New(addr string, bootstrap bool, persistDir string) (*Gateway, error) {
 	if loadErr := g.load(); loadErr != nil && !os.IsNotExist(loadErr) {
 		return nil, loadErr
 	}
+	// Load the pinned peers and the blocklist. The pinned peers are added to
+	// the node list so that they will be connected to.
+	if err := g.loadSettings(); err != nil {
+		return nil, err
+	}
+	for addr := range g.pinnedPeers {
+		if err := g.addNode(addr); err != nil && err != errNodeExists {
+			g.log.Printf("WARN: failed to add the pinned node '%v': %v", addr, err)
+		}
+	}
+	g.setRateLimits(g.maxDownloadSpeed, g.maxUploadSpeed)
 	// Spawn the thread to periodically save the gateway.
 	go g.threadedSaveLoop()
 	// Make sure that the gateway saves after shutdown.
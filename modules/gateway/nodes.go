@@ -32,6 +32,8 @@ func (g *Gateway) addNode(addr modules.NetAddress) error {
 		return errOurAddress
 	} else if _, exists := g.nodes[addr]; exists {
 		return errNodeExists
+	} else if g.isBlocked(addr) {
+		return errHostBlocked
 	} else if addr.IsStdValid() != nil {
 		return errors.New("address is not valid: " + string(addr))
 	} else if net.ParseIP(addr.Host()) == nil {
@@ -252,11 +254,12 @@ func (g *Gateway) permanentNodePurger(closeChan chan struct{}) {
 			continue
 		}
 		// Check whether this node is already a peer. If so, no need to dial
-		// them.
+		// them. Pinned nodes are never pruned.
 		g.mu.RLock()
 		_, exists := g.peers[node]
+		pinned := g.isPinned(node)
 		g.mu.RUnlock()
-		if exists {
+		if exists || pinned {
 			continue
 		}
 
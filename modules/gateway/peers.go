@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
@@ -11,6 +12,7 @@ import (
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/fastrand"
+	"github.com/NebulousLabs/ratelimit"
 )
 
 var (
@@ -37,6 +39,9 @@ func (s invalidVersionError) Error() string {
 type peer struct {
 	modules.Peer
 	sess streamSession
+
+	// stats accumulates the statistics of the peer's session.
+	stats *peerStats
 }
 
 // sessionHeader is sent after the initial version exchange. It prevents peers
@@ -102,7 +107,7 @@ func (g *Gateway) permanentListen(closeChan chan struct{}) {
 			return
 		}
 
-		go g.threadedAcceptConn(conn)
+		go g.threadedAcceptConn(ratelimit.NewRLConn(conn, g.rl, g.threads.StopChan()))
 
 		// Sleep after each accept. This limits the rate at which the Gateway
 		// will accept new connections. The intent here is to prevent new
@@ -128,6 +133,15 @@ func (g *Gateway) threadedAcceptConn(conn net.Conn) {
 	addr := modules.NetAddress(conn.RemoteAddr().String())
 	g.log.Debugf("INFO: %v wants to connect", addr)
 
+	g.mu.RLock()
+	blocked := g.isBlocked(addr)
+	g.mu.RUnlock()
+	if blocked {
+		g.log.Debugf("INFO: refused connection from blocked host %v", addr)
+		conn.Close()
+		return
+	}
+
 	remoteVersion, err := acceptVersionHandshake(conn, build.Version)
 	if err != nil {
 		g.log.Debugf("INFO: %v wanted to connect but version handshake failed: %v", addr, err)
@@ -195,6 +209,7 @@ func (g *Gateway) managedAcceptConnPeer(conn net.Conn, remoteVersion string) err
 	remoteAddr := modules.NetAddress(net.JoinHostPort(remoteIP, remotePort))
 
 	// Accept the peer.
+	stats := newPeerStats()
 	peer := &peer{
 		Peer: modules.Peer{
 			Inbound: true,
@@ -206,9 +221,14 @@ func (g *Gateway) managedAcceptConnPeer(conn net.Conn, remoteVersion string) err
 			NetAddress: remoteAddr,
 			Version:    remoteVersion,
 		},
-		sess: newServerStream(conn, remoteVersion),
+		sess:  newServerStream(newStatsConn(conn, stats), remoteVersion),
+		stats: stats,
 	}
 	g.mu.Lock()
+	if g.isBlocked(remoteAddr) {
+		g.mu.Unlock()
+		return errHostBlocked
+	}
 	g.acceptPeer(peer)
 	g.mu.Unlock()
 
@@ -237,12 +257,12 @@ func (g *Gateway) acceptPeer(p *peer) {
 		return
 	}
 
-	// Select a peer to kick. Outbound peers and local peers are not
-	// available to be kicked.
+	// Select a peer to kick. Outbound peers, local peers and pinned peers are
+	// not available to be kicked.
 	var addrs []modules.NetAddress
 	for addr, peer := range g.peers {
-		// Do not kick outbound peers or local peers.
-		if !peer.Inbound || peer.Local {
+		// Do not kick outbound peers, local peers or pinned peers.
+		if !peer.Inbound || peer.Local || g.isPinned(addr) {
 			continue
 		}
 
@@ -401,9 +421,12 @@ func (g *Gateway) managedConnect(addr modules.NetAddress) error {
 	}
 	g.mu.RLock()
 	_, exists := g.peers[addr]
+	blocked := g.isBlocked(addr)
 	g.mu.RUnlock()
 	if exists {
 		return errPeerExists
+	} else if blocked {
+		return errHostBlocked
 	}
 
 	// Dial the peer and perform peer initialization.
@@ -433,10 +456,16 @@ func (g *Gateway) managedConnect(addr modules.NetAddress) error {
 	// connection to this peer.
 	conn.SetDeadline(time.Time{})
 
-	// Add the peer.
+	// Add the peer. The host may have been blocked while the connection was
+	// being established.
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	if g.isBlocked(addr) {
+		conn.Close()
+		return errHostBlocked
+	}
 
+	stats := newPeerStats()
 	g.addPeer(&peer{
 		Peer: modules.Peer{
 			Inbound:    false,
@@ -444,7 +473,8 @@ func (g *Gateway) managedConnect(addr modules.NetAddress) error {
 			NetAddress: addr,
 			Version:    remoteVersion,
 		},
-		sess: newClientStream(conn, remoteVersion),
+		sess:  newClientStream(newStatsConn(conn, stats), remoteVersion),
+		stats: stats,
 	})
 	g.addNode(addr)
 	g.nodes[addr].WasOutboundPeer = true
@@ -521,6 +551,38 @@ func (g *Gateway) Peers() []modules.Peer {
 	return peers
 }
 
+// PeerStats returns the statistics of the peers that are currently connected to
+// the Gateway.
+func (g *Gateway) PeerStats() []modules.PeerStats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var stats []modules.PeerStats
+	for _, p := range g.peers {
+		ps := modules.PeerStats{Peer: p.Peer}
+		if p.stats != nil {
+			ps.BytesSent = atomic.LoadUint64(&p.stats.bytesSent)
+			ps.BytesReceived = atomic.LoadUint64(&p.stats.bytesReceived)
+			ps.Uptime = time.Since(p.stats.connectedAt)
+			ps.LastBlockID = p.stats.lastBlockID
+			ps.LastBlockHeight = p.stats.lastBlockHeight
+		}
+		stats = append(stats, ps)
+	}
+	return stats
+}
+
+// UpdatePeerBlock records the most recent block that a peer advertised.
+func (g *Gateway) UpdatePeerBlock(addr modules.NetAddress, id types.BlockID, height types.BlockHeight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	p, exists := g.peers[addr]
+	if !exists || p.stats == nil {
+		return
+	}
+	p.stats.lastBlockID = id
+	p.stats.lastBlockHeight = height
+}
+
 // Online returns true if the node is connected to the internet. During testing
 // we always assume that the node is online
 func (g *Gateway) Online() bool {
@@ -1162,3 +1162,62 @@ func TestBuildPeerManagerNodeList(t *testing.T) {
 		t.Fatal("bad nodelist:", nodelist)
 	}
 }
+
+// TestPeerStats checks that the gateway tracks the traffic, uptime and most
+// recently advertised block of its peers.
+func TestPeerStats(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	defer g1.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+
+	if err := g1.Connect(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Send some data to g2 in an RPC and wait for its response.
+	data := fastrand.Bytes(4096)
+	g2.RegisterRPC("Foo", func(conn modules.PeerConn) error {
+		var b []byte
+		if err := encoding.ReadObject(conn, &b, uint64(len(data))+8); err != nil {
+			return err
+		}
+		return encoding.WriteObject(conn, true)
+	})
+	err := g1.RPC(g2.Address(), "Foo", func(conn modules.PeerConn) error {
+		if err := encoding.WriteObject(conn, data); err != nil {
+			return err
+		}
+		var ok bool
+		return encoding.ReadObject(conn, &ok, 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := g1.PeerStats()
+	if len(stats) != 1 || stats[0].NetAddress != g2.Address() {
+		t.Fatal("unexpected peer stats:", stats)
+	}
+	if stats[0].BytesSent < uint64(len(data)) {
+		t.Fatalf("expected at least %v bytes to be sent, got %v", len(data), stats[0].BytesSent)
+	}
+	if stats[0].BytesReceived == 0 || stats[0].Uptime <= 0 {
+		t.Fatal("expected bytes to be received and the uptime to be positive:", stats[0])
+	}
+	if stats[0].LastBlockID != (types.BlockID{}) {
+		t.Fatal("peer should not have advertised a block yet")
+	}
+
+	// Record an advertised block.
+	id := types.BlockID{1}
+	g1.UpdatePeerBlock(g2.Address(), id, 5)
+	stats = g1.PeerStats()
+	if stats[0].LastBlockID != id || stats[0].LastBlockHeight != 5 {
+		t.Fatal("advertised block was not recorded:", stats[0])
+	}
+}
@@ -41,9 +41,10 @@ func (g *Gateway) managedPeerManagerConnect(addr modules.NetAddress) {
 	} else if err != nil {
 		g.log.Debugf("[PMC] [ERROR] [%v] WARN: removing peer because automatic connect failed: %v\n", addr, err)
 
-		// Remove the node, but only if there are enough nodes in the node list.
+		// Remove the node, but only if there are enough nodes in the node list
+		// and the node is not pinned.
 		g.mu.Lock()
-		if len(g.nodes) > pruneNodeListLen {
+		if len(g.nodes) > pruneNodeListLen && !g.isPinned(addr) {
 			g.removeNode(addr)
 		}
 		g.mu.Unlock()
@@ -78,6 +79,10 @@ func (g *Gateway) permanentPeerManager(closedChan chan struct{}) {
 	g.log.Debugln("INFO: [PPM] Permanent peer manager has started")
 
 	for {
+		// Make sure that the pinned peers are connected before any other
+		// nodes are considered.
+		g.managedConnectPinnedPeers()
+
 		// Fetch the set of nodes to try.
 		g.mu.RLock()
 		nodes := g.buildPeerManagerNodeList()
@@ -176,5 +181,14 @@ func (g *Gateway) buildPeerManagerNodeList() []modules.NetAddress {
 			numOutbound++
 		}
 	}
+
+	// swap the pinned nodes in front of the outbound nodes
+	numPinned := 0
+	for i, node := range nodes {
+		if g.isPinned(node) {
+			nodes[numPinned], nodes[i] = nodes[i], nodes[numPinned]
+			numPinned++
+		}
+	}
 	return nodes
 }
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"os"
 	"path/filepath"
 	"time"
 
@@ -14,6 +15,10 @@ const (
 
 	// nodesFile is the name of the file that contains all seen nodes.
 	nodesFile = "nodes.json"
+
+	// settingsFile is the name of the file that contains the pinned peers and
+	// the blocklist of the gateway.
+	settingsFile = "gateway.json"
 )
 
 // persistMetadata contains the header and version strings that identify the
@@ -23,6 +28,22 @@ var persistMetadata = persist.Metadata{
 	Version: "1.3.0",
 }
 
+// settingsMetadata contains the header and version strings that identify the
+// gateway settings file.
+var settingsMetadata = persist.Metadata{
+	Header:  "Gateway Settings",
+	Version: "1.3.2",
+}
+
+// gatewaySettings contains the user-specified settings of the gateway that
+// are saved to disk.
+type gatewaySettings struct {
+	PinnedPeers      []modules.NetAddress `json:"pinnedpeers"`
+	Blocklist        []string             `json:"blocklist"`
+	MaxDownloadSpeed int64                `json:"maxdownloadspeed"`
+	MaxUploadSpeed   int64                `json:"maxuploadspeed"`
+}
+
 // persistData returns the data in the Gateway that will be saved to disk.
 func (g *Gateway) persistData() (nodes []*node) {
 	for _, node := range g.nodes {
@@ -31,6 +52,40 @@ func (g *Gateway) persistData() (nodes []*node) {
 	return
 }
 
+// settingsData returns the settings of the Gateway that will be saved to disk.
+func (g *Gateway) settingsData() (gs gatewaySettings) {
+	gs.MaxDownloadSpeed = g.maxDownloadSpeed
+	gs.MaxUploadSpeed = g.maxUploadSpeed
+	for addr := range g.pinnedPeers {
+		gs.PinnedPeers = append(gs.PinnedPeers, addr)
+	}
+	for host := range g.blocklist {
+		gs.Blocklist = append(gs.Blocklist, host)
+	}
+	return
+}
+
+// loadSettings loads the Gateway's settings from disk. A missing settings file
+// is not an error.
+func (g *Gateway) loadSettings() error {
+	var gs gatewaySettings
+	err := persist.LoadJSON(settingsMetadata, &gs, filepath.Join(g.persistDir, settingsFile))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, addr := range gs.PinnedPeers {
+		g.pinnedPeers[addr] = struct{}{}
+	}
+	for _, host := range gs.Blocklist {
+		g.blocklist[host] = struct{}{}
+	}
+	g.maxDownloadSpeed = gs.MaxDownloadSpeed
+	g.maxUploadSpeed = gs.MaxUploadSpeed
+	return nil
+}
+
 // load loads the Gateway's persistent data from disk.
 func (g *Gateway) load() error {
 	var nodes []*node
@@ -48,6 +103,10 @@ func (g *Gateway) load() error {
 // saveSync stores the Gateway's persistent data on disk, and then syncs to
 // disk to minimize the possibility of data loss.
 func (g *Gateway) saveSync() error {
+	err := persist.SaveJSON(settingsMetadata, g.settingsData(), filepath.Join(g.persistDir, settingsFile))
+	if err != nil {
+		return err
+	}
 	return persist.SaveJSON(persistMetadata, g.persistData(), filepath.Join(g.persistDir, nodesFile))
 }
 
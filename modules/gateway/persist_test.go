@@ -61,3 +61,34 @@ func TestLoadv033(t *testing.T) {
 		t.Error("expected empty log, got", buf.String())
 	}
 }
+
+// TestSetRateLimits checks that the rate limits are validated and persist
+// across restarts.
+func TestSetRateLimits(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g := newTestingGateway(t)
+
+	// The gateway should be unlimited by default.
+	if down, up := g.RateLimits(); down != 0 || up != 0 {
+		t.Fatal("expected no rate limits by default, got", down, up)
+	}
+	if err := g.SetRateLimits(-1, 0); err == nil {
+		t.Fatal("expected negative rate limit to be rejected")
+	}
+	if err := g.SetRateLimits(1e6, 2e6); err != nil {
+		t.Fatal(err)
+	}
+	g.Close()
+
+	g2, err := New("localhost:0", false, g.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g2.Close()
+	if down, up := g2.RateLimits(); down != 1e6 || up != 2e6 {
+		t.Fatal("rate limits were not persisted, got", down, up)
+	}
+}
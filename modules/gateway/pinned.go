@@ -0,0 +1,189 @@
+package gateway
+
+import (
+	"errors"
+	"net"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	errHostBlocked = errors.New("host is on the gateway's blocklist")
+	errNotBlocked  = errors.New("host is not on the gateway's blocklist")
+	errNotPinned   = errors.New("peer is not pinned")
+)
+
+// isBlocked returns true if the host of the address is on the blocklist.
+func (g *Gateway) isBlocked(addr modules.NetAddress) bool {
+	_, blocked := g.blocklist[addr.Host()]
+	return blocked
+}
+
+// isPinned returns true if the address belongs to a pinned peer.
+func (g *Gateway) isPinned(addr modules.NetAddress) bool {
+	_, pinned := g.pinnedPeers[addr]
+	return pinned
+}
+
+// managedConnectPinnedPeers tries to connect to every pinned peer that the
+// gateway is not connected to as an outbound peer.
+func (g *Gateway) managedConnectPinnedPeers() {
+	g.mu.RLock()
+	var addrs []modules.NetAddress
+	for addr := range g.pinnedPeers {
+		if p, exists := g.peers[addr]; !exists || p.Inbound {
+			addrs = append(addrs, addr)
+		}
+	}
+	g.mu.RUnlock()
+
+	for _, addr := range addrs {
+		// peerManagerConnect will handle all of its own logging.
+		g.managedPeerManagerConnect(addr)
+	}
+}
+
+// PinPeer marks a peer as trusted. Pinned peers are connected to before any
+// other nodes, are never kicked to make room for new peers, and are never
+// removed from the node list. The set of pinned peers persists across
+// restarts.
+func (g *Gateway) PinPeer(addr modules.NetAddress) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+
+	if err := addr.IsStdValid(); err != nil {
+		return errors.New("can't pin invalid address")
+	}
+	if net.ParseIP(addr.Host()) == nil {
+		return errors.New("address must be an IP address")
+	}
+
+	g.mu.Lock()
+	if g.isBlocked(addr) {
+		g.mu.Unlock()
+		return errHostBlocked
+	}
+	if err := g.addNode(addr); err != nil && err != errNodeExists {
+		g.mu.Unlock()
+		return err
+	}
+	g.pinnedPeers[addr] = struct{}{}
+	err := g.saveSync()
+	g.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	g.log.Println("INFO: pinned peer", addr)
+
+	// Connect to the peer right away instead of waiting for the peer manager.
+	go func() {
+		if err := g.threads.Add(); err != nil {
+			return
+		}
+		defer g.threads.Done()
+		g.managedConnectPinnedPeers()
+	}()
+	return nil
+}
+
+// UnpinPeer removes a peer from the set of pinned peers. The gateway stays
+// connected to the peer, but the peer becomes subject to the normal connection
+// management again.
+func (g *Gateway) UnpinPeer(addr modules.NetAddress) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.isPinned(addr) {
+		return errNotPinned
+	}
+	delete(g.pinnedPeers, addr)
+	g.log.Println("INFO: unpinned peer", addr)
+	return g.saveSync()
+}
+
+// PinnedPeers returns the addresses of the pinned peers, sorted.
+func (g *Gateway) PinnedPeers() []modules.NetAddress {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	addrs := make([]modules.NetAddress, 0, len(g.pinnedPeers))
+	for addr := range g.pinnedPeers {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i] < addrs[j]
+	})
+	return addrs
+}
+
+// BlockHost adds a host to the blocklist. The gateway disconnects from any
+// peers on the host, removes the host from the node list and the pinned peers,
+// and refuses all future connections to and from it.
+func (g *Gateway) BlockHost(host string) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+
+	if net.ParseIP(host) == nil {
+		return errors.New("host must be an IP address")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.blocklist[host] = struct{}{}
+	for addr := range g.pinnedPeers {
+		if addr.Host() == host {
+			delete(g.pinnedPeers, addr)
+		}
+	}
+	for addr := range g.nodes {
+		if addr.Host() == host {
+			delete(g.nodes, addr)
+		}
+	}
+	for addr, p := range g.peers {
+		if addr.Host() == host {
+			p.sess.Close()
+			delete(g.peers, addr)
+			g.log.Println("INFO: disconnected from blocked peer", addr)
+		}
+	}
+	g.log.Println("INFO: blocked host", host)
+	return g.saveSync()
+}
+
+// UnblockHost removes a host from the blocklist.
+func (g *Gateway) UnblockHost(host string) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, exists := g.blocklist[host]; !exists {
+		return errNotBlocked
+	}
+	delete(g.blocklist, host)
+	g.log.Println("INFO: unblocked host", host)
+	return g.saveSync()
+}
+
+// Blocklist returns the hosts that the gateway refuses to connect to, sorted.
+func (g *Gateway) Blocklist() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	hosts := make([]string, 0, len(g.blocklist))
+	for host := range g.blocklist {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
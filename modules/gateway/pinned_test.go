@@ -0,0 +1,157 @@
+package gateway
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestPinPeer checks that pinned peers are connected to, persist across
+// restarts, are reconnected on startup, and are prioritized by the peer
+// manager.
+func TestPinPeer(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+
+	// Pinning an invalid address should fail.
+	if err := g1.PinPeer("foo"); err == nil {
+		t.Fatal("expected error when pinning an invalid address")
+	}
+
+	// Pinning a peer should connect to it as an outbound peer.
+	connected := func(g *Gateway, addr modules.NetAddress) error {
+		return build.Retry(50, 100*time.Millisecond, func() error {
+			g.mu.RLock()
+			p, ok := g.peers[addr]
+			g.mu.RUnlock()
+			if !ok || p.Inbound {
+				return errors.New("gateway is not connected to the pinned peer")
+			}
+			return nil
+		})
+	}
+	if err := g1.PinPeer(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if err := connected(g1, g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if pinned := g1.PinnedPeers(); len(pinned) != 1 || pinned[0] != g2.Address() {
+		t.Fatal("unexpected pinned peers:", pinned)
+	}
+
+	// The pinned node should be at the front of the peer manager's list.
+	g1.mu.Lock()
+	g1.addNode(dummyNode)
+	for i := 0; i < 10; i++ {
+		if nodes := g1.buildPeerManagerNodeList(); nodes[0] != g2.Address() {
+			g1.mu.Unlock()
+			t.Fatal("pinned node is not at the front of the node list:", nodes)
+		}
+	}
+	g1.mu.Unlock()
+
+	// Restart the gateway. The pinned peer should be loaded and reconnected.
+	if err := g1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	g1, err := New("localhost:0", false, g1.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g1.Close()
+	if pinned := g1.PinnedPeers(); len(pinned) != 1 || pinned[0] != g2.Address() {
+		t.Fatal("pinned peers were not persisted:", pinned)
+	}
+	if err := connected(g1, g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unpin the peer.
+	if err := g1.UnpinPeer(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if err := g1.UnpinPeer(g2.Address()); err != errNotPinned {
+		t.Fatal("expected errNotPinned, got", err)
+	}
+	if pinned := g1.PinnedPeers(); len(pinned) != 0 {
+		t.Fatal("peer was not unpinned:", pinned)
+	}
+}
+
+// TestBlockHost checks that blocking a host disconnects its peers and refuses
+// any further connections to and from it.
+func TestBlockHost(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	defer g1.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+
+	if err := g1.Connect(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if err := g1.PinPeer(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Block the host of g2. g1 should disconnect from it, forget it and unpin
+	// it.
+	host := g2.Address().Host()
+	if err := g1.BlockHost(host); err != nil {
+		t.Fatal(err)
+	}
+	g1.mu.RLock()
+	_, isPeer := g1.peers[g2.Address()]
+	_, isNode := g1.nodes[g2.Address()]
+	g1.mu.RUnlock()
+	if isPeer || isNode {
+		t.Fatal("blocked host should have been removed from the peers and nodes")
+	}
+	if len(g1.PinnedPeers()) != 0 {
+		t.Fatal("blocked host should have been unpinned")
+	}
+	if bl := g1.Blocklist(); len(bl) != 1 || bl[0] != host {
+		t.Fatal("unexpected blocklist:", bl)
+	}
+
+	// Connections in either direction should be refused.
+	if err := g1.Connect(g2.Address()); err != errHostBlocked {
+		t.Fatal("expected errHostBlocked, got", err)
+	}
+	if err := g1.PinPeer(g2.Address()); err != errHostBlocked {
+		t.Fatal("expected errHostBlocked, got", err)
+	}
+	if err := g2.Connect(g1.Address()); err == nil {
+		t.Fatal("blocked host should not be able to connect")
+	}
+
+	// Unblock the host; connecting should work again.
+	if err := g1.UnblockHost(host); err != nil {
+		t.Fatal(err)
+	}
+	if err := g1.UnblockHost(host); err != errNotBlocked {
+		t.Fatal("expected errNotBlocked, got", err)
+	}
+	err := build.Retry(50, 100*time.Millisecond, func() error {
+		err := g1.Connect(g2.Address())
+		if err == errPeerExists {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
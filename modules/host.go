@@ -7,6 +7,56 @@ import (
 const (
 	// HostDir names the directory that contains the host persistence.
 	HostDir = "host"
+
+	// ObligationStatusUnresolved is the ObligationStatus of a storage
+	// obligation that is still active, meaning that it has neither succeeded
+	// nor failed yet.
+	ObligationStatusUnresolved = "obligationUnresolved"
+
+	// ObligationStatusRejected is the ObligationStatus of a storage
+	// obligation whose file contract never made it onto the blockchain.
+	ObligationStatusRejected = "obligationRejected"
+
+	// ObligationStatusSucceeded is the ObligationStatus of a storage
+	// obligation for which the host submitted a storage proof in time.
+	ObligationStatusSucceeded = "obligationSucceeded"
+
+	// ObligationStatusFailed is the ObligationStatus of a storage obligation
+	// for which the host missed the storage proof.
+	ObligationStatusFailed = "obligationFailed"
+)
+
+const (
+	// StorageObligationAdded is sent when the host accepts a new storage
+	// obligation.
+	StorageObligationAdded StorageObligationEventType = "added"
+
+	// StorageObligationRevised is sent when a storage obligation is revised.
+	StorageObligationRevised StorageObligationEventType = "revised"
+
+	// StorageObligationOriginConfirmed is sent when the file contract of a
+	// storage obligation is confirmed on the blockchain.
+	StorageObligationOriginConfirmed StorageObligationEventType = "originconfirmed"
+
+	// StorageObligationRevisionConfirmed is sent when the latest revision of
+	// a storage obligation is confirmed on the blockchain.
+	StorageObligationRevisionConfirmed StorageObligationEventType = "revisionconfirmed"
+
+	// StorageObligationProofConfirmed is sent when the storage proof of a
+	// storage obligation is confirmed on the blockchain.
+	StorageObligationProofConfirmed StorageObligationEventType = "proofconfirmed"
+
+	// StorageObligationSucceeded is sent when a storage obligation is
+	// resolved successfully.
+	StorageObligationSucceeded StorageObligationEventType = "succeeded"
+
+	// StorageObligationFailed is sent when a storage obligation is resolved
+	// with a missed storage proof.
+	StorageObligationFailed StorageObligationEventType = "failed"
+
+	// StorageObligationRejected is sent when a storage obligation is dropped
+	// because its file contract never made it onto the blockchain.
+	StorageObligationRejected StorageObligationEventType = "rejected"
 )
 
 var (
@@ -47,6 +97,24 @@ var (
 )
 
 type (
+	// HostContractQuote is the price that the host would charge for a file
+	// contract, as returned by QuoteContract. The storage revenue, upload
+	// revenue and risked collateral cover the data being uploaded in full
+	// right after the contract is formed. Value is the value of the resulting
+	// storage obligation to the host.
+	HostContractQuote struct {
+		FileSize uint64            `json:"filesize"`
+		Duration types.BlockHeight `json:"duration"`
+
+		ContractPrice    types.Currency `json:"contractprice"`
+		StorageRevenue   types.Currency `json:"storagerevenue"`
+		UploadRevenue    types.Currency `json:"uploadrevenue"`
+		Collateral       types.Currency `json:"collateral"`
+		RiskedCollateral types.Currency `json:"riskedcollateral"`
+		RenterCost       types.Currency `json:"rentercost"`
+		Value            types.Currency `json:"value"`
+	}
+
 	// HostFinancialMetrics provides financial statistics for the host,
 	// including money that is locked in contracts. Though verbose, these
 	// statistics should provide a clear picture of where the host's money is
@@ -60,15 +128,34 @@ type (
 		ContractCompensation          types.Currency `json:"contractcompensation"`
 		PotentialContractCompensation types.Currency `json:"potentialcontractcompensation"`
 
+		// The number of storage obligations at which the host stops accepting
+		// new contracts, as a counterpart to the ContractCount.
+		ObligationLimit uint64 `json:"obligationlimit"`
+
 		// Metrics related to storage proofs, collateral, and submitting
 		// transactions to the blockchain.
-		LockedStorageCollateral types.Currency `json:"lockedstoragecollateral"`
-		LostRevenue             types.Currency `json:"lostrevenue"`
-		LostStorageCollateral   types.Currency `json:"loststoragecollateral"`
-		PotentialStorageRevenue types.Currency `json:"potentialstoragerevenue"`
-		RiskedStorageCollateral types.Currency `json:"riskedstoragecollateral"`
-		StorageRevenue          types.Currency `json:"storagerevenue"`
-		TransactionFeeExpenses  types.Currency `json:"transactionfeeexpenses"`
+		LockedStorageCollateral   types.Currency `json:"lockedstoragecollateral"`
+		LostRevenue               types.Currency `json:"lostrevenue"`
+		LostStorageCollateral     types.Currency `json:"loststoragecollateral"`
+		ObligationsAtRisk         uint64         `json:"obligationsatrisk"`
+		ObligationsUnconfirmed    uint64         `json:"obligationsunconfirmed"`
+		PendingSectorRemovals     uint64         `json:"pendingsectorremovals"`
+		PotentialStorageRevenue   types.Currency `json:"potentialstoragerevenue"`
+		RemainingCollateralBudget types.Currency `json:"remainingcollateralbudget"`
+		RiskedStorageCollateral   types.Currency `json:"riskedstoragecollateral"`
+		StorageRevenue            types.Currency `json:"storagerevenue"`
+		TransactionFeeExpenses    types.Currency `json:"transactionfeeexpenses"`
+
+		// The outcome of the most recently resolved storage obligations, as a
+		// measure of the host's recent reliability.
+		RecentProofOutcomes    uint64  `json:"recentproofoutcomes"`
+		RecentProofSuccessRate float64 `json:"recentproofsuccessrate"`
+
+		// The average latency of the recent disk probes in milliseconds, and
+		// whether the host declines new contracts because the latency exceeds
+		// its MaxDiskLatency.
+		DiskLatency  uint64 `json:"disklatency"`
+		DiskDegraded bool   `json:"diskdegraded"`
 
 		// Bandwidth financial metrics.
 		DownloadBandwidthRevenue          types.Currency `json:"downloadbandwidthrevenue"`
@@ -80,20 +167,41 @@ type (
 	// HostInternalSettings contains a list of settings that can be changed.
 	HostInternalSettings struct {
 		AcceptingContracts   bool              `json:"acceptingcontracts"`
+		ArchiveDir           string            `json:"archivedir"`
+		ArchiveRetention     types.BlockHeight `json:"archiveretention"`
+		EncryptSectors       bool              `json:"encryptsectors"`
+		MaintenanceEnd       types.BlockHeight `json:"maintenanceend"`
+		MaintenanceStart     types.BlockHeight `json:"maintenancestart"`
+		MaxConcurrentProofs  uint64            `json:"maxconcurrentproofs"`
+		MaxConfirmationDelay types.BlockHeight `json:"maxconfirmationdelay"`
+		MaxContractRevisions uint64            `json:"maxcontractrevisions"`
+		MaxDiskLatency       uint64            `json:"maxdisklatency"`
 		MaxDownloadBatchSize uint64            `json:"maxdownloadbatchsize"`
 		MaxDuration          types.BlockHeight `json:"maxduration"`
+		MaxObligations       uint64            `json:"maxobligations"`
+		MaxRenterRequestRate uint64            `json:"maxrenterrequestrate"`
+		MaxRenterSessions    uint64            `json:"maxrentersessions"`
 		MaxReviseBatchSize   uint64            `json:"maxrevisebatchsize"`
+		MinDuration          types.BlockHeight `json:"minduration"`
+		MinFileSize          uint64            `json:"minfilesize"`
 		NetAddress           NetAddress        `json:"netaddress"`
+		ReannounceInterval   types.BlockHeight `json:"reannounceinterval"`
+		ResubmissionJitter   types.BlockHeight `json:"resubmissionjitter"`
+		ShutdownTimeout      uint64            `json:"shutdowntimeout"`
 		WindowSize           types.BlockHeight `json:"windowsize"`
 
 		Collateral       types.Currency `json:"collateral"`
 		CollateralBudget types.Currency `json:"collateralbudget"`
 		MaxCollateral    types.Currency `json:"maxcollateral"`
 
+		TrustedRenterCollateral types.Currency `json:"trustedrentercollateral"`
+
 		MinContractPrice          types.Currency `json:"mincontractprice"`
 		MinDownloadBandwidthPrice types.Currency `json:"mindownloadbandwidthprice"`
 		MinStoragePrice           types.Currency `json:"minstorageprice"`
 		MinUploadBandwidthPrice   types.Currency `json:"minuploadbandwidthprice"`
+
+		StoragePriceTiers []HostStoragePriceTier `json:"storagepricetiers"`
 	}
 
 	// HostNetworkMetrics reports the quantity of each type of RPC call that
@@ -113,14 +221,21 @@ type (
 	StorageObligation struct {
 		ContractCost             types.Currency       `json:"contractcost"`
 		DataSize                 uint64               `json:"datasize"`
+		DownloadBytes            uint64               `json:"downloadbytes"`
 		LockedCollateral         types.Currency       `json:"lockedcollateral"`
 		ObligationId             types.FileContractID `json:"obligationid"`
 		PotentialDownloadRevenue types.Currency       `json:"potentialdownloadrevenue"`
 		PotentialStorageRevenue  types.Currency       `json:"potentialstoragerevenue"`
 		PotentialUploadRevenue   types.Currency       `json:"potentialuploadrevenue"`
+		RenterKey                types.SiaPublicKey   `json:"renterkey"`
 		RiskedCollateral         types.Currency       `json:"riskedcollateral"`
 		SectorRootsCount         uint64               `json:"sectorrootscount"`
 		TransactionFeesAdded     types.Currency       `json:"transactionfeesadded"`
+		UploadBytes              uint64               `json:"uploadbytes"`
+
+		// Tags are key-value pairs that the operator has attached to the
+		// storage obligation.
+		Tags map[string]string `json:"tags"`
 
 		// The negotiation height specifies the block height at which the file
 		// contract was negotiated. The expiration height and the proof deadline
@@ -138,6 +253,42 @@ type (
 		ProofConstructed    bool   `json:"proofconstructed"`
 		RevisionConfirmed   bool   `json:"revisionconfirmed"`
 		RevisionConstructed bool   `json:"revisionconstructed"`
+
+		// The revision submission height is the block height at which the host
+		// first submitted the final revision, and is zero if the revision has
+		// not been submitted yet. The confirmation delay is the number of
+		// blocks that the origin or revision transaction set has been waiting
+		// for confirmation since it was submitted. Unconfirmed is set if the
+		// delay exceeds the MaxConfirmationDelay of the host.
+		RevisionSubmissionHeight types.BlockHeight `json:"revisionsubmissionheight"`
+		ConfirmationDelay        types.BlockHeight `json:"confirmationdelay"`
+		Unconfirmed              bool              `json:"unconfirmed"`
+	}
+
+	// StorageObligationEventType identifies a transition in the lifecycle of a
+	// storage obligation.
+	StorageObligationEventType string
+
+	// StorageObligationEvent describes a transition in the lifecycle of a
+	// storage obligation, along with the state of the obligation after the
+	// transition.
+	StorageObligationEvent struct {
+		Type         StorageObligationEventType `json:"type"`
+		ObligationID types.FileContractID       `json:"obligationid"`
+
+		DataSize         uint64         `json:"datasize"`
+		LockedCollateral types.Currency `json:"lockedcollateral"`
+		PotentialRevenue types.Currency `json:"potentialrevenue"`
+		RiskedCollateral types.Currency `json:"riskedcollateral"`
+	}
+
+	// A StorageObligationSubscriber receives updates about changes to the
+	// storage obligations of the host.
+	StorageObligationSubscriber interface {
+		// ProcessStorageObligationEvent is called for every transition in the
+		// lifecycle of a storage obligation. The host is locked while the
+		// event is delivered, so subscribers must not call into the host.
+		ProcessStorageObligationEvent(StorageObligationEvent)
 	}
 
 	// HostWorkingStatus reports the working state of a host. Can be one of
@@ -158,6 +309,19 @@ type (
 		// AnnounceAddress submits an announcement using the given address.
 		AnnounceAddress(NetAddress) error
 
+		// BackupStorageObligations writes the storage obligations of the host
+		// to a backup file, along with a manifest of its storage folders.
+		BackupStorageObligations(dst string) error
+
+		// CheckReachable checks that the host can reach itself through the
+		// given address.
+		CheckReachable(NetAddress) error
+
+		// CheckObligationInvariants compares the aggregate metrics of the host
+		// against the values implied by its storage obligations, returning an
+		// error for every mismatch.
+		CheckObligationInvariants() []error
+
 		// ExternalSettings returns the settings of the host as seen by an
 		// untrusted node querying the host for settings.
 		ExternalSettings() HostExternalSettings
@@ -176,6 +340,25 @@ type (
 		// PublicKey returns the public key of the host.
 		PublicKey() types.SiaPublicKey
 
+		// QuoteContract returns the price that the host would charge for a
+		// file contract storing the given number of bytes for the given
+		// number of blocks, with the given collateral, without forming the
+		// contract.
+		QuoteContract(fileSize uint64, duration types.BlockHeight, collateral types.Currency) (HostContractQuote, error)
+
+		// RepairObligationInvariants re-derives the aggregate metrics of the
+		// host from its storage obligations.
+		RepairObligationInvariants() error
+
+		// RestoreStorageObligations loads the storage obligations from a
+		// backup file into the host, after verifying that the host holds the
+		// data they reference.
+		RestoreStorageObligations(src string) error
+
+		// RotateSectorKey generates a new key to encrypt sectors with on
+		// disk. Sectors which are already stored keep their key.
+		RotateSectorKey() error
+
 		// SetInternalSettings sets the hosting parameters of the host.
 		SetInternalSettings(HostInternalSettings) error
 
@@ -183,6 +366,22 @@ type (
 		// the host.
 		StorageObligations() []StorageObligation
 
+		// SubmitStorageProof rebuilds the storage proof of a storage
+		// obligation and submits it to the transaction pool right away.
+		SubmitStorageProof(types.FileContractID) error
+
+		// UpdateStorageObligationTags sets and removes tags on a storage
+		// obligation.
+		UpdateStorageObligationTags(id types.FileContractID, set map[string]string, remove []string) error
+
+		// StorageObligationSubscribe adds a subscriber which will be notified
+		// of every transition in the lifecycle of the host's storage
+		// obligations.
+		StorageObligationSubscribe(StorageObligationSubscriber)
+
+		// StorageObligationUnsubscribe removes a subscriber from the host.
+		StorageObligationUnsubscribe(StorageObligationSubscriber)
+
 		// ConnectabilityStatus returns the connectability status of the host, that
 		// is, if it can connect to itself on the configured NetAddress.
 		ConnectabilityStatus() HostConnectabilityStatus
@@ -2,8 +2,12 @@ package host
 
 import (
 	"errors"
+	"net"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 )
 
@@ -15,6 +19,10 @@ var (
 	// errUnknownAddress is returned if the host is unable to determine a
 	// public address for itself to use in the announcement.
 	errUnknownAddress = errors.New("host cannot announce, does not seem to have a valid address.")
+
+	// errUnreachableAddress is returned if the host is unable to reach itself
+	// through the address that it is going to announce.
+	errUnreachableAddress = errors.New("host is not reachable at the provided address")
 )
 
 // managedAnnounce creates an announcement transaction and submits it to the network.
@@ -68,6 +76,8 @@ func (h *Host) managedAnnounce(addr modules.NetAddress) error {
 
 	h.mu.Lock()
 	h.announced = true
+	h.lastAnnouncement = h.blockHeight
+	h.reannouncePending = false
 	h.mu.Unlock()
 	h.log.Printf("INFO: Successfully announced as %v", addr)
 	return nil
@@ -146,3 +156,113 @@ func (h *Host) AnnounceAddress(addr modules.NetAddress) error {
 	h.mu.Unlock()
 	return nil
 }
+
+// CheckReachable checks that the host can reach itself through the provided
+// address. The host dials the address and requests its settings, which have
+// to be signed by the host's own key. This confirms that renters dialing the
+// address end up at this host, and not at a different machine behind the same
+// NAT.
+func (h *Host) CheckReachable(addr modules.NetAddress) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	err = addr.IsValid()
+	if err != nil {
+		return build.ExtendErr("invalid net address", err)
+	}
+	h.mu.RLock()
+	var pubKey crypto.PublicKey
+	copy(pubKey[:], h.publicKey.Key)
+	h.mu.RUnlock()
+
+	dialer := &net.Dialer{
+		Cancel:  h.tg.StopChan(),
+		Timeout: connectabilityCheckTimeout,
+	}
+	conn, err := dialer.Dial("tcp", string(addr))
+	if err != nil {
+		return build.ComposeErrors(errUnreachableAddress, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(connectabilityCheckTimeout))
+
+	err = encoding.WriteObject(conn, modules.RPCSettings)
+	if err != nil {
+		return build.ComposeErrors(errUnreachableAddress, err)
+	}
+	var settings modules.HostExternalSettings
+	err = crypto.ReadSignedObject(conn, &settings, modules.NegotiateMaxHostExternalSettingsLen, pubKey)
+	if err != nil {
+		return build.ComposeErrors(errUnreachableAddress, err)
+	}
+	return nil
+}
+
+// announcedSettingsChanged returns true if any of the settings that renters
+// learn about after an announcement differ between the two sets of settings.
+func announcedSettingsChanged(old, new modules.HostInternalSettings) bool {
+	return old.AcceptingContracts != new.AcceptingContracts ||
+		old.NetAddress != new.NetAddress ||
+		!old.Collateral.Equals(new.Collateral) ||
+		!old.MaxCollateral.Equals(new.MaxCollateral) ||
+		!old.MinContractPrice.Equals(new.MinContractPrice) ||
+		!old.MinDownloadBandwidthPrice.Equals(new.MinDownloadBandwidthPrice) ||
+		!old.MinStoragePrice.Equals(new.MinStoragePrice) ||
+		!old.MinUploadBandwidthPrice.Equals(new.MinUploadBandwidthPrice) ||
+		!storagePriceTiersEqual(old.StoragePriceTiers, new.StoragePriceTiers)
+}
+
+// reannounceDue returns true if the host should automatically re-announce
+// itself at the current block height. Only hosts that have announced before
+// are re-announced, either once the re-announce interval has passed since the
+// previous announcement, or after a settings change as long as the previous
+// announcement is at least minReannounceInterval blocks old. Failed attempts
+// are retried after minReannounceInterval blocks.
+func (h *Host) reannounceDue() bool {
+	interval := h.settings.ReannounceInterval
+	if interval == 0 || h.reannouncing || (!h.announced && h.lastAnnouncement == 0) {
+		return false
+	}
+	if h.reannounceAttempt != 0 && h.blockHeight < h.reannounceAttempt+minReannounceInterval {
+		return false
+	}
+	age := h.blockHeight - h.lastAnnouncement
+	if h.blockHeight < h.lastAnnouncement {
+		// The announcement was reverted by a reorg.
+		age = 0
+	}
+	return age >= interval || (h.reannouncePending && age >= minReannounceInterval)
+}
+
+// threadedReannounce re-announces the host with its current address. The
+// announcement is skipped with a warning if the wallet can't pay for the
+// transaction fee.
+func (h *Host) threadedReannounce() {
+	defer func() {
+		h.mu.Lock()
+		h.reannouncing = false
+		h.mu.Unlock()
+	}()
+	if err := h.tg.Add(); err != nil {
+		return
+	}
+	defer h.tg.Done()
+
+	_, fee := h.tpool.FeeEstimation()
+	fee = fee.Mul64(600) // Estimated txn size (in bytes) of a host announcement.
+	if !h.wallet.Unlocked() {
+		h.log.Println("WARN: skipping automatic re-announcement, the wallet is locked")
+		return
+	}
+	balance, _, _ := h.wallet.ConfirmedBalance()
+	if balance.Cmp(fee) < 0 {
+		h.log.Printf("WARN: skipping automatic re-announcement, the wallet can't pay the fee of %v", fee.HumanString())
+		return
+	}
+	if err := h.Announce(); err != nil {
+		h.log.Println("WARN: automatic re-announcement failed:", err)
+	}
+}
@@ -2,7 +2,10 @@ package host
 
 import (
 	"bytes"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
@@ -193,3 +196,107 @@ func TestHostAnnounceCheckUnlockHash(t *testing.T) {
 		t.Fatal("host unlock has did not exist in wallet")
 	}
 }
+
+// TestHostCheckReachable checks that the host can verify that it is reachable
+// through an address.
+func TestHostCheckReachable(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// The host should be reachable through its listener.
+	addr := modules.NetAddress(ht.host.listener.Addr().String())
+	err = ht.host.CheckReachable(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid addresses should be rejected.
+	err = ht.host.CheckReachable("foo")
+	if err == nil || strings.Contains(err.Error(), errUnreachableAddress.Error()) {
+		t.Fatal("expected an invalid address error, got", err)
+	}
+
+	// The host should not be reachable through an address that nothing is
+	// listening on.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := modules.NetAddress(l.Addr().String())
+	l.Close()
+	err = ht.host.CheckReachable(closedAddr)
+	if err == nil || !strings.Contains(err.Error(), errUnreachableAddress.Error()) {
+		t.Fatal("expected errUnreachableAddress, got", err)
+	}
+}
+
+// TestHostReannounce checks that the host re-announces itself automatically
+// once its previous announcement is older than the re-announce interval.
+func TestHostReannounce(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+	af, err := newAnnouncementFinder(ht.cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer af.Close()
+
+	// Intervals below the minimum should be rejected.
+	settings := ht.host.InternalSettings()
+	settings.ReannounceInterval = minReannounceInterval - 1
+	if err := ht.host.SetInternalSettings(settings); err == nil {
+		t.Fatal("expected a reannounce interval below the minimum to be rejected")
+	}
+
+	// A host that never announced should not be re-announced.
+	settings.ReannounceInterval = minReannounceInterval
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	for i := types.BlockHeight(0); i <= minReannounceInterval; i++ {
+		if _, err := ht.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(af.publicKeys) != 0 {
+		t.Fatal("host that never announced was re-announced")
+	}
+
+	// Announce the host, then mine until the announcement is old enough to
+	// be repeated.
+	if err := ht.host.Announce(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ht.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if len(af.publicKeys) != 1 {
+		t.Fatal("could not find host announcement in blockchain")
+	}
+	for i := 0; i < 10 && len(af.publicKeys) < 2; i++ {
+		if _, err := ht.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(af.publicKeys) < 2 {
+		t.Fatal("host was not re-announced")
+	}
+	if af.netAddresses[1] != af.netAddresses[0] || !bytes.Equal(af.publicKeys[1].Key, ht.host.publicKey.Key) {
+		t.Error("re-announcement has the wrong address or key")
+	}
+}
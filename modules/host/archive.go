@@ -0,0 +1,179 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// archiveDirName returns the name of the directory that holds the archived
+// data of a storage obligation. The name is tagged with the height at which
+// the obligation was archived, the id of the obligation, and the outcome of
+// the obligation, so that archives can be pruned and found without opening
+// them.
+func archiveDirName(height types.BlockHeight, soid types.FileContractID, sos storageObligationStatus) string {
+	outcome := "failed"
+	if sos == obligationSucceeded {
+		outcome = "succeeded"
+	}
+	return fmt.Sprintf("%v-%v-%v", height, soid, outcome)
+}
+
+// checkArchiveDir checks that the archive directory is an absolute path that
+// the host can write to, creating the directory if it does not exist yet.
+func checkArchiveDir(archiveDir string) error {
+	if !filepath.IsAbs(archiveDir) {
+		return errors.New("archive directory must be an absolute path")
+	}
+	err := os.MkdirAll(archiveDir, 0700)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(archiveDir, "writecheck")
+	if err != nil {
+		return err
+	}
+	return composeErrors(f.Close(), os.Remove(f.Name()))
+}
+
+// archiveJob is a resolved storage obligation whose sectors are waiting to be
+// archived. The settings that the archival depends on are captured when the
+// obligation is resolved, so that the archival does not need the host lock.
+type archiveJob struct {
+	so        storageObligation
+	sos       storageObligationStatus
+	height    types.BlockHeight
+	dir       string
+	retention types.BlockHeight
+}
+
+// queueArchive captures the data needed to archive a resolved storage
+// obligation and starts the archival in the background. The sectors of the
+// obligation must not be removed by the caller, they are removed once they
+// have been copied. Obligations that are being archived are skipped by the
+// storage reconciliation until the archival is done.
+func (h *Host) queueArchive(so storageObligation, sos storageObligationStatus) {
+	h.archiving[so.id()] = struct{}{}
+	go h.threadedArchiveStorageObligation(archiveJob{
+		so:        so,
+		sos:       sos,
+		height:    h.blockHeight,
+		dir:       h.settings.ArchiveDir,
+		retention: h.settings.ArchiveRetention,
+	})
+}
+
+// threadedArchiveStorageObligation copies the sectors of a resolved storage
+// obligation into the archive directory, prunes old archives, and then removes
+// the sectors from the storage manager. Archival problems do not prevent the
+// host from reclaiming the space. If the host shuts down first, the sectors
+// are left to the storage reconciliation, which removes them without
+// archiving them.
+func (h *Host) threadedArchiveStorageObligation(job archiveJob) {
+	soid := job.so.id()
+	defer func() {
+		h.mu.Lock()
+		delete(h.archiving, soid)
+		h.mu.Unlock()
+	}()
+	err := h.tg.Add()
+	if err != nil {
+		return
+	}
+	defer h.tg.Done()
+
+	err = h.managedArchiveStorageObligation(job.dir, job.height, job.so, job.sos)
+	if err != nil {
+		h.logObligation(LogWarn, soid, err, "Unable to archive storage obligation")
+	}
+	err = pruneArchive(job.dir, job.retention, job.height)
+	if err != nil {
+		h.log.Println("WARN: unable to prune archived storage obligations:", err)
+	}
+
+	// Remove the sectors, keeping the roots of the sectors which could not be
+	// removed on the obligation so that the removal is retried later.
+	failed := h.managedRemoveSectors(soid, job.so.SectorRoots)
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		so, err := getStorageObligation(tx, soid)
+		if err != nil {
+			return err
+		}
+		so.SectorRoots = failed
+		return putStorageObligation(tx, so)
+	})
+	if err != nil {
+		h.logObligation(LogWarn, soid, err, "Unable to update archived storage obligation")
+	}
+}
+
+// managedArchiveStorageObligation copies every sector of a resolved storage
+// obligation into the archive directory. The sectors are still removed from
+// the storage manager afterwards, which means that the archive directory can
+// be placed on a separate volume without affecting the storage capacity of the
+// host.
+func (h *Host) managedArchiveStorageObligation(archiveDir string, height types.BlockHeight, so storageObligation, sos storageObligationStatus) error {
+	dir := filepath.Join(archiveDir, archiveDirName(height, so.id(), sos))
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	for _, root := range so.SectorRoots {
+		sectorData, err := h.ReadSector(root)
+		if err != nil {
+			return extendErr("could not read sector for archival: ", err)
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, root.String()), sectorData, 0600)
+		if err != nil {
+			return extendErr("could not write archived sector: ", err)
+		}
+	}
+	return nil
+}
+
+// pruneArchive removes the archived storage obligations which are older than
+// the archive retention at the given height. A retention of zero keeps
+// archives forever.
+func pruneArchive(archiveDir string, retention, height types.BlockHeight) error {
+	if archiveDir == "" || retention == 0 {
+		return nil
+	}
+	if height <= retention {
+		return nil
+	}
+	cutoff := height - retention
+
+	entries, err := ioutil.ReadDir(archiveDir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var errs []error
+	for _, entry := range entries {
+		// Skip anything that was not created by the archiver.
+		if !entry.IsDir() {
+			continue
+		}
+		heightStr := strings.SplitN(entry.Name(), "-", 2)[0]
+		height, err := strconv.ParseUint(heightStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		if types.BlockHeight(height) < cutoff {
+			err = os.RemoveAll(filepath.Join(archiveDir, entry.Name()))
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return composeErrors(errs...)
+}
@@ -0,0 +1,166 @@
+package host
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestArchiveStorageObligation checks that the sectors of a storage obligation
+// are copied into the archive directory, and that old archives are pruned.
+func TestArchiveStorageObligation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestArchiveStorageObligation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	archiveDir := build.TempDir(modules.HostDir, "TestArchiveStorageObligation", "archive")
+
+	// Create a storage obligation holding a single sector.
+	sectorRoot, sectorData := randSector()
+	err = ht.host.AddSector(sectorRoot, sectorData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+
+	// Archive the obligation and check that the sector data was copied.
+	ht.host.mu.RLock()
+	height := ht.host.blockHeight
+	ht.host.mu.RUnlock()
+	err = ht.host.managedArchiveStorageObligation(archiveDir, height, so, obligationSucceeded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(archiveDir, archiveDirName(height, so.id(), obligationSucceeded))
+	archivedData, err := ioutil.ReadFile(filepath.Join(dir, sectorRoot.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(archivedData, sectorData) {
+		t.Fatal("archived sector does not match the original sector")
+	}
+
+	// The archive should survive pruning until it is older than the
+	// retention.
+	err = pruneArchive(archiveDir, 10, height+10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatal("archive was pruned too early:", err)
+	}
+	err = pruneArchive(archiveDir, 10, height+11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatal("archive was not pruned:", err)
+	}
+}
+
+// TestArchiveResolvedObligation checks that the host archives the sectors of
+// a storage obligation when the obligation is resolved, and that only usable
+// archive directories are accepted.
+func TestArchiveResolvedObligation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestArchiveResolvedObligation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Relative archive directories are rejected.
+	settings := ht.host.InternalSettings()
+	settings.ArchiveDir = "archive"
+	if err := ht.host.SetInternalSettings(settings); err == nil {
+		t.Fatal("relative archive directory was accepted")
+	}
+	archiveDir := build.TempDir(modules.HostDir, "TestArchiveResolvedObligation", "archive")
+	settings.ArchiveDir = archiveDir
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a storage obligation holding a single sector and resolve it.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sectorRoot, sectorData := randSector()
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+	err = ht.host.modifyStorageObligation(so, sectorEncryption{}, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.mu.Lock()
+	height := ht.host.blockHeight
+	err = ht.host.removeStorageObligation(so, obligationSucceeded)
+	ht.host.mu.Unlock()
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The sector should be archived and then removed in the background.
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		ht.host.mu.RLock()
+		defer ht.host.mu.RUnlock()
+		if len(ht.host.archiving) != 0 {
+			return errors.New("obligation is still being archived")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(archiveDir, archiveDirName(height, so.id(), obligationSucceeded))
+	archivedData, err := ioutil.ReadFile(filepath.Join(dir, sectorRoot.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(archivedData, sectorData) {
+		t.Fatal("archived sector does not match the original sector")
+	}
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, so.id())
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(so.SectorRoots) != 0 {
+		t.Fatal("archived sectors were not removed:", len(so.SectorRoots))
+	}
+	if _, err := ht.host.ReadSector(sectorRoot); err == nil {
+		t.Fatal("archived sector is still held by the storage manager")
+	}
+}
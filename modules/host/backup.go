@@ -0,0 +1,246 @@
+package host
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/persist"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// backupMetadata is the header of a host backup file.
+	backupMetadata = persist.Metadata{
+		Header:  "Sia Host Backup",
+		Version: "1.2.0",
+	}
+
+	// errBackupVerification is returned if a backup is restored onto a host
+	// which is missing some of the data referenced by the backup.
+	errBackupVerification = errors.New("host does not hold the data referenced by the backup")
+)
+
+// hostBackup is a snapshot of the storage obligations of the host. The sector
+// roots of the storage obligations double as checksums of the sectors, the
+// sectors themselves live in the storage folders listed in the manifest and
+// are not part of the backup. The sector keys and the record of which sectors
+// are encrypted with which key are needed to read the sectors back.
+type hostBackup struct {
+	StorageFolders   []backupStorageFolder   `json:"storagefolders"`
+	Obligations      []storageObligation     `json:"obligations"`
+	SectorKeys       []sectorKey             `json:"sectorkeys"`
+	EncryptedSectors []backupEncryptedSector `json:"encryptedsectors"`
+}
+
+// backupStorageFolder is a storage folder that held sectors of the host when
+// the backup was made.
+type backupStorageFolder struct {
+	Path     string `json:"path"`
+	Capacity uint64 `json:"capacity"`
+}
+
+// backupEncryptedSector records the key that a sector is encrypted with.
+type backupEncryptedSector struct {
+	Root  crypto.Hash `json:"root"`
+	KeyID uint64      `json:"keyid"`
+}
+
+// BackupStorageObligations writes every storage obligation of the host to
+// the backup file at 'dst', along with a manifest of the storage folders that
+// hold their sectors.
+func (h *Host) BackupStorageObligations(dst string) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	var backup hostBackup
+	for _, sf := range h.StorageFolders() {
+		backup.StorageFolders = append(backup.StorageFolders, backupStorageFolder{
+			Path:     sf.Path,
+			Capacity: sf.Capacity,
+		})
+	}
+	h.mu.RLock()
+	backup.SectorKeys = h.sectorKeys
+	err = h.db.View(func(tx *bolt.Tx) error {
+		err := tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return err
+			}
+			backup.Obligations = append(backup.Obligations, so)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(bucketEncryptedSectors).ForEach(func(rootBytes, idBytes []byte) error {
+			var es backupEncryptedSector
+			copy(es.Root[:], rootBytes)
+			es.KeyID = encoding.DecUint64(idBytes)
+			backup.EncryptedSectors = append(backup.EncryptedSectors, es)
+			return nil
+		})
+	})
+	h.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return persist.SaveJSON(backupMetadata, backup, dst)
+}
+
+// managedVerifyBackup checks that the host holds all of the data that is
+// referenced by a backup. Every storage folder in the manifest has to be in use
+// by the host, and every sector of the unresolved storage obligations has to be
+// readable and match its sector root. A description of every problem that was
+// found is returned.
+func (h *Host) managedVerifyBackup(backup hostBackup) []error {
+	// Sectors which the host has no encryption record for are decrypted
+	// using the records of the backup.
+	encrypted := make(map[crypto.Hash]uint64)
+	for _, es := range backup.EncryptedSectors {
+		encrypted[es.Root] = es.KeyID
+	}
+	readSector := func(root crypto.Hash) ([]byte, error) {
+		id, exists := encrypted[root]
+		if exists {
+			err := h.db.View(func(tx *bolt.Tx) error {
+				_, known := getSectorKeyID(tx, root)
+				exists = !known
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !exists {
+			return h.ReadSector(root)
+		}
+		key, exists := findSectorKey(backup.SectorKeys, id)
+		if !exists {
+			return nil, errMissingSectorKey
+		}
+		data, err := h.StorageManager.ReadSector(root)
+		if err != nil {
+			return nil, err
+		}
+		return cryptSector(key, root, data), nil
+	}
+
+	folders := make(map[string]struct{})
+	for _, sf := range h.StorageFolders() {
+		folders[sf.Path] = struct{}{}
+	}
+	var errs []error
+	for _, sf := range backup.StorageFolders {
+		if _, exists := folders[sf.Path]; !exists {
+			errs = append(errs, fmt.Errorf("storage folder %v is missing", sf.Path))
+		}
+	}
+	for _, so := range backup.Obligations {
+		if so.ObligationStatus != obligationUnresolved {
+			continue
+		}
+		if err := verifyIntegrity(so, readSector); err != nil {
+			errs = append(errs, fmt.Errorf("storage obligation %v: %v", so.id(), err))
+		}
+	}
+	return errs
+}
+
+// RestoreStorageObligations loads the storage obligations from the backup
+// file at 'src' into the host. The storage folders of the backup are expected
+// to have been moved to the host already, and the restore is aborted without
+// changes if any of the storage folders are missing or any sector of an
+// unresolved storage obligation is missing or corrupt. Storage obligations
+// which the host is already tracking are left untouched, as are the
+// encryption records of sectors that the host already knows. Afterwards, the
+// aggregate metrics of the host are re-derived from the storage obligations
+// rather than being carried over from the old host.
+func (h *Host) RestoreStorageObligations(src string) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	var backup hostBackup
+	err = persist.LoadJSON(backupMetadata, &backup, src)
+	if err != nil {
+		return err
+	}
+	if errs := h.managedVerifyBackup(backup); len(errs) > 0 {
+		for _, err := range errs {
+			h.log.Println("Backup verification failed:", err)
+		}
+		return fmt.Errorf("%v: %v", errBackupVerification, composeErrors(errs...))
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var restored []storageObligation
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		for _, es := range backup.EncryptedSectors {
+			if _, known := getSectorKeyID(tx, es.Root); known {
+				continue
+			}
+			err := putSectorKeyID(tx, es.Root, es.KeyID)
+			if err != nil {
+				return err
+			}
+		}
+		for _, so := range backup.Obligations {
+			soid := so.id()
+			if tx.Bucket(bucketStorageObligations).Get(soid[:]) != nil {
+				continue
+			}
+			err := putStorageObligation(tx, so)
+			if err != nil {
+				return err
+			}
+			if so.ObligationStatus == obligationUnresolved {
+				err = indexStorageObligationWindow(tx, so.expiration(), soid)
+				if err != nil {
+					return err
+				}
+				restored = append(restored, so)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// The keys of the backup are placed before the keys of the host, so that
+	// the current key of the host stays in use for new sectors.
+	var keys []sectorKey
+	for _, sk := range backup.SectorKeys {
+		if _, exists := findSectorKey(h.sectorKeys, sk.ID); !exists {
+			keys = append(keys, sk)
+		}
+	}
+	h.sectorKeys = append(keys, h.sectorKeys...)
+	for _, so := range restored {
+		if !so.OriginConfirmed {
+			h.watchOriginInputs(so)
+		}
+		err = h.queueObligationActionItems(so)
+		if err != nil {
+			return err
+		}
+	}
+	h.log.Printf("Restored %v unresolved storage obligations from %v\n", len(restored), src)
+
+	err = h.repairObligationMetrics()
+	if err != nil {
+		return err
+	}
+	return h.saveSync()
+}
@@ -0,0 +1,109 @@
+package host
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/persist"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestBackupRestoreStorageObligations checks that storage obligations can be
+// restored from a backup, that the aggregate metrics are re-derived from the
+// restored obligations, and that backups referencing missing data are
+// rejected.
+func TestBackupRestoreStorageObligations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestBackupRestoreStorageObligations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+
+	backupPath := filepath.Join(ht.persistDir, "host.backup")
+	err = ht.host.BackupStorageObligations(backupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Forget about the storage obligation and leave stale metrics behind.
+	soid := so.id()
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).Delete(soid[:])
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.mu.Lock()
+	ht.host.financialMetrics.ContractCount = 5
+	ht.host.mu.Unlock()
+
+	// Restore the backup. The obligation should be back and the metrics
+	// should match it.
+	err = ht.host.RestoreStorageObligations(backupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ht.host.StorageObligations()) != 1 {
+		t.Fatal("storage obligation was not restored")
+	}
+	if errs := ht.host.CheckObligationInvariants(); len(errs) != 0 {
+		t.Fatal("invariant violations after restore:", errs)
+	}
+	if ht.host.FinancialMetrics().ContractCount != 1 {
+		t.Fatal("contract count was not re-derived")
+	}
+	// Restoring again should not count the obligation twice.
+	err = ht.host.RestoreStorageObligations(backupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ht.host.FinancialMetrics().ContractCount != 1 {
+		t.Fatal("restoring twice changed the contract count")
+	}
+
+	// A backup which references a missing sector and a missing storage
+	// folder should be rejected without restoring anything.
+	bad, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad.SectorRoots = []crypto.Hash{{1}}
+	bad.OriginTransactionSet[len(bad.OriginTransactionSet)-1].FileContracts[0].FileMerkleRoot = cachedMerkleRoot(bad.SectorRoots)
+	badBackup := hostBackup{
+		StorageFolders: []backupStorageFolder{{Path: filepath.Join(ht.persistDir, "missing")}},
+		Obligations:    []storageObligation{bad},
+	}
+	badPath := filepath.Join(ht.persistDir, "bad.backup")
+	err = persist.SaveJSON(backupMetadata, badBackup, badPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.RestoreStorageObligations(badPath)
+	if err == nil || !strings.Contains(err.Error(), errBackupVerification.Error()) {
+		t.Fatal("expected errBackupVerification, got", err)
+	}
+	if !strings.Contains(err.Error(), "missing") {
+		t.Error("missing storage folder was not reported:", err)
+	}
+	if len(ht.host.StorageObligations()) != 1 {
+		t.Fatal("invalid backup was partially restored")
+	}
+}
@@ -31,9 +31,56 @@ const (
 	// Typically, this transaction will contain either a file contract, a file
 	// contract revision, or a storage proof.
 	resubmissionTimeout = 3
+
+	// maxSectorRemovalThreads is the maximum number of threads that the host
+	// will use to remove the sectors of a storage obligation.
+	maxSectorRemovalThreads = 100
+
+	// maxResubmissionTimeout caps the number of blocks that a host will wait
+	// before resubmitting a transaction. Each failed resubmission doubles the
+	// wait, starting from resubmissionTimeout, until this limit is reached.
+	maxResubmissionTimeout = resubmissionTimeout * 8
+
+	// diskLatencyWindow is the number of most recent disk probes whose
+	// average latency determines whether the host accepts new contracts.
+	diskLatencyWindow = 5
+
+	// diskProbeSize is the number of bytes that each disk probe writes and
+	// reads back.
+	diskProbeSize = 1 << 16
+
+	// maxProofBatchSize is the maximum combined size of the storage proofs
+	// that the host submits in a single transaction. Some room is left for
+	// the inputs and signatures that pay the transaction fee.
+	maxProofBatchSize = modules.TransactionSizeLimit - 2e3
+
+	// proofBatchDeadlineBuffer is the number of blocks before the end of its
+	// proof window within which a storage proof is no longer batched. An
+	// invalid proof invalidates the whole batch, so proofs that may not make
+	// it into a block in time are submitted on their own.
+	proofBatchDeadlineBuffer = 2
+
+	// renterLimiterPruneSize is the number of renters tracked by the renter
+	// limiter at which it starts to forget about idle renters.
+	renterLimiterPruneSize = 1000
+
+	// trustedRenterLimitMultiplier is the factor by which the session and
+	// request rate limits are raised for renters that have locked up at least
+	// TrustedRenterCollateral with the host.
+	trustedRenterLimitMultiplier = 4
 )
 
 var (
+	// minReannounceInterval is the minimum number of blocks between two
+	// automatic re-announcements of the host. Re-announcements triggered by a
+	// settings change wait until the previous announcement is at least this
+	// old, and the re-announce interval can't be set below it.
+	minReannounceInterval = build.Select(build.Var{
+		Standard: types.BlockHeight(144),
+		Dev:      types.BlockHeight(10),
+		Testing:  types.BlockHeight(3),
+	}).(types.BlockHeight)
+
 	// connectablityCheckFirstWait defines how often the host's connectability
 	// check is run.
 	connectabilityCheckFirstWait = build.Select(build.Var{
@@ -102,6 +149,28 @@ var (
 	// MiB.
 	defaultMaxDownloadBatchSize = 17 * (1 << 20)
 
+	// defaultMaxConcurrentProofs is the number of storage proofs that the host
+	// will build at the same time by default. Building a storage proof reads
+	// a full sector from disk, so a burst of proof windows closing in the same
+	// block would otherwise hit the disk all at once.
+	defaultMaxConcurrentProofs = uint64(4)
+
+	// defaultMaxContractRevisions is the number of revisions that the host
+	// accepts for a single storage obligation within revisionLimitWindow
+	// blocks by default. Every upload of a batch of sectors and every download
+	// is a revision, so the limit is high enough to upload several TiB to a
+	// single contract per window, while keeping a renter from making the host
+	// save an obligation thousands of times per second.
+	defaultMaxContractRevisions = uint64(250e3)
+
+	// defaultMaxRenterRequestRate is the number of RPCs per minute that a
+	// single renter can make with the host by default.
+	defaultMaxRenterRequestRate = uint64(600)
+
+	// defaultMaxRenterSessions is the number of connections that a single
+	// renter can have open with the host at the same time by default.
+	defaultMaxRenterSessions = uint64(16)
+
 	// defaultMaxReviseBatchSize defines the maximum number of bytes that the
 	// host will allow to be sent during a single batch update in a revision
 	// RPC. 17 MiB has been chosen because it's four full sectors, plus some
@@ -141,6 +210,78 @@ var (
 		Testing:  types.BlockHeight(5),   // 5 seconds.
 	}).(types.BlockHeight)
 
+	// defaultShutdownTimeout is the number of seconds that the host waits by
+	// default for the storage proofs that are in progress when it shuts down.
+	defaultShutdownTimeout = build.Select(build.Var{
+		Dev:      uint64(60),
+		Standard: uint64(300),
+		Testing:  uint64(10),
+	}).(uint64)
+
+	// defaultMaxConfirmationDelay is the default number of blocks that the
+	// origin or revision transaction set of a storage obligation may wait for
+	// confirmation after being submitted before the obligation is reported as
+	// unconfirmed.
+	defaultMaxConfirmationDelay = build.Select(build.Var{
+		Dev:      types.BlockHeight(10),
+		Standard: types.BlockHeight(36), // 6 hours.
+		Testing:  types.BlockHeight(5),
+	}).(types.BlockHeight)
+
+	// defaultResubmissionJitter is the default maximum number of blocks of
+	// random jitter that is added to the height of the action items which
+	// resubmit the transactions of a storage obligation, so that the
+	// resubmissions of many obligations are spread over several blocks.
+	defaultResubmissionJitter = build.Select(build.Var{
+		Dev:      types.BlockHeight(2),
+		Standard: types.BlockHeight(3),
+		Testing:  types.BlockHeight(0),
+	}).(types.BlockHeight)
+
+	// defaultMaxDiskLatency is the default number of milliseconds that the
+	// recent disk probes of the host may take on average before the host
+	// stops accepting new contracts.
+	defaultMaxDiskLatency = build.Select(build.Var{
+		Dev:      uint64(2000),
+		Standard: uint64(2000),
+		Testing:  uint64(1000),
+	}).(uint64)
+
+	// defaultMaxObligations is the default number of storage obligations
+	// that the host holds before it stops accepting new contracts. Every
+	// obligation takes up memory and is visited when the host processes
+	// consensus changes.
+	defaultMaxObligations = build.Select(build.Var{
+		Dev:      uint64(10e3),
+		Standard: uint64(100e3),
+		Testing:  uint64(1e3),
+	}).(uint64)
+
+	// diskProbeFrequency is how often the host measures the latency of its
+	// disks.
+	diskProbeFrequency = build.Select(build.Var{
+		Dev:      30 * time.Second,
+		Standard: 2 * time.Minute,
+		Testing:  3 * time.Second,
+	}).(time.Duration)
+
+	// revisionLimitWindow is the number of blocks over which the revisions of
+	// a storage obligation are counted against the MaxContractRevisions of
+	// the host.
+	revisionLimitWindow = build.Select(build.Var{
+		Dev:      types.BlockHeight(36),
+		Standard: types.BlockHeight(144), // 1 day.
+		Testing:  types.BlockHeight(10),
+	}).(types.BlockHeight)
+
+	// proofBatchDelay is the amount of time that the host waits for more
+	// storage proofs to be built before submitting a batch of storage proofs.
+	proofBatchDelay = build.Select(build.Var{
+		Dev:      5 * time.Second,
+		Standard: 30 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
 	// logAllLimit is the number of errors of each type that the host will log
 	// before switching to probabilistic logging. If there are not many errors,
 	// it is reasonable that all errors get logged. If there are lots of
@@ -179,6 +320,25 @@ var (
 		Testing:  time.Second * 3,
 	}).(time.Duration)
 
+	// proofDeadlineSafetyMargin is the number of blocks of slack before the
+	// close of the proof window below which a storage obligation is considered
+	// to be at risk. Action items for obligations at risk are handled ahead of
+	// all other action items.
+	proofDeadlineSafetyMargin = build.Select(build.Var{
+		Dev:      types.BlockHeight(12),
+		Standard: types.BlockHeight(36), // 6 hours.
+		Testing:  types.BlockHeight(2),
+	}).(types.BlockHeight)
+
+	// proofOutcomeWindow is the number of most recently resolved storage
+	// obligations that are used to compute the recent storage proof success
+	// rate of the host.
+	proofOutcomeWindow = build.Select(build.Var{
+		Dev:      100,
+		Standard: 1000,
+		Testing:  10,
+	}).(int)
+
 	// revisionSubmissionBuffer describes the number of blocks ahead of time
 	// that the host will submit a file contract revision. The host will not
 	// accept any more revisions once inside the submission buffer.
@@ -196,6 +356,24 @@ var (
 		Testing:  time.Millisecond,
 	}).(time.Duration)
 
+	// storageReconciliationFrequency defines how often the host retries the
+	// removal of sectors which could not be removed when their storage
+	// obligation was resolved, and checks that the storage manager agrees
+	// with the storage obligations.
+	storageReconciliationFrequency = build.Select(build.Var{
+		Standard: time.Hour * 6,
+		Dev:      time.Minute * 10,
+		Testing:  time.Second * 5,
+	}).(time.Duration)
+
+	// storageScrubFrequency defines how often the host re-reads all of the
+	// data belonging to its storage obligations to check for corruption.
+	storageScrubFrequency = build.Select(build.Var{
+		Standard: time.Hour * 24 * 7,
+		Dev:      time.Hour,
+		Testing:  time.Second * 10,
+	}).(time.Duration)
+
 	// workingStatusFirstCheck defines how frequently the Host's working status
 	// check runs
 	workingStatusFirstCheck = build.Select(build.Var{
@@ -234,9 +412,22 @@ var (
 	// using the id.
 	bucketActionItems = []byte("BucketActionItems")
 
+	// bucketEncryptedSectors maps the root of each sector that is encrypted on
+	// disk to the id of the key that it is encrypted with. Sectors which are
+	// not in the bucket are stored in plaintext.
+	bucketEncryptedSectors = []byte("BucketEncryptedSectors")
+
 	// bucketStorageObligations contains a set of serialized
 	// 'storageObligations' sorted by their file contract id.
 	bucketStorageObligations = []byte("BucketStorageObligations")
+
+	// bucketStorageObligationWindows maps a blockchain height to the list of
+	// unresolved storage obligations whose proof window starts at that
+	// height. Like the action items, the height is stored as a big endian
+	// uint64 and the list is an array of file contract ids. The index allows
+	// the host to find the obligations in a range of proof windows without
+	// scanning every storage obligation.
+	bucketStorageObligationWindows = []byte("BucketStorageObligationWindows")
 )
 
 // init runs a series of sanity checks to verify that the constants have sane
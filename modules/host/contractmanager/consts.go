@@ -128,4 +128,22 @@ var (
 		Standard: time.Second * 60 * 5,
 		Testing:  time.Second * 8,
 	}).(time.Duration)
+
+	// defragSectorInterval is the amount of time that the contract manager
+	// waits between sector moves while defragmenting the storage folders, so
+	// that the defrag does not compete with uploads and storage proofs for
+	// disk bandwidth.
+	defragSectorInterval = build.Select(build.Var{
+		Dev:      time.Millisecond * 10,
+		Standard: time.Millisecond * 50,
+		Testing:  time.Duration(0),
+	}).(time.Duration)
+)
+
+const (
+	// defragThreshold is the fraction of its capacity that a storage folder
+	// may at most be using to be emptied during a defrag. Folders that are
+	// fuller than this are left alone, as emptying them would move a lot of
+	// data for little gain.
+	defragThreshold = 0.25
 )
@@ -41,6 +41,11 @@ import (
 // renters, including storing the data, submitting storage proofs, and deleting
 // the data when a contract is complete.
 type ContractManager struct {
+	// atomicDefragging is set to 1 while the storage folders are being
+	// defragmented, ensuring that only one defrag runs at a time. It is the
+	// first field so that it is 64-bit aligned.
+	atomicDefragging uint64
+
 	// The contract manager controls many resources which are spread across
 	// multiple files yet must all be consistent and durable. ACID properties
 	// have been achieved by using a write-ahead-logger (WAL). The in-memory
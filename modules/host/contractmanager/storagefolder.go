@@ -209,35 +209,41 @@ func usageSectors(usage []uint64) (usageSectors []uint32) {
 // folder with vacancy for a sector along with its index. 'nil' and '-1' are
 // returned if none of the storage folders are available to accept a sector.
 // The returned storage folder will be holding an RLock on its mutex.
+//
+// Storage folders are selected at random, weighted by the number of free
+// sectors that they have. This spreads new data across all of the storage
+// folders while filling emptier folders faster, balancing usage over time.
 func vacancyStorageFolder(sfs []*storageFolder) (*storageFolder, int) {
-	enoughRoom := false
-	var winningIndex int
-
-	// Go through the folders in random order.
-	for _, index := range fastrand.Perm(len(sfs)) {
-		sf := sfs[index]
-
-		// Skip past this storage folder if there is not enough room for at
-		// least one sector.
-		if sf.sectors >= uint64(len(sf.usage))*storageFolderGranularity {
-			continue
+	// Count the free sectors in each storage folder.
+	var totalFree uint64
+	free := make([]uint64, len(sfs))
+	for i, sf := range sfs {
+		capacity := uint64(len(sf.usage)) * storageFolderGranularity
+		if sf.sectors < capacity {
+			free[i] = capacity - sf.sectors
+			totalFree += free[i]
 		}
+	}
 
-		// Skip past this storage folder if it's not available to receive new
-		// data.
-		if !sf.mu.TryRLock() {
-			continue
+	for totalFree > 0 {
+		// Select a storage folder with probability proportional to its free
+		// space.
+		target := fastrand.Uint64n(totalFree)
+		index := 0
+		for target >= free[index] {
+			target -= free[index]
+			index++
 		}
 
-		// Select this storage folder.
-		enoughRoom = true
-		winningIndex = index
-		break
-	}
-	if !enoughRoom {
-		return nil, -1
+		// Return this storage folder if it's available to receive new data.
+		// Otherwise, remove it from consideration and try again.
+		if sfs[index].mu.TryRLock() {
+			return sfs[index], index
+		}
+		totalFree -= free[index]
+		free[index] = 0
 	}
-	return sfs[winningIndex], winningIndex
+	return nil, -1
 }
 
 // clearUsage will unset the usage bit at the provided sector index for this
@@ -0,0 +1,53 @@
+package contractmanager
+
+import (
+	"testing"
+)
+
+// TestVacancyStorageFolder checks that vacancyStorageFolder only returns
+// storage folders with room, and that emptier storage folders are preferred.
+func TestVacancyStorageFolder(t *testing.T) {
+	// A full storage folder should never be selected.
+	full := &storageFolder{
+		usage:   make([]uint64, 1),
+		sectors: storageFolderGranularity,
+	}
+	sf, index := vacancyStorageFolder([]*storageFolder{full})
+	if sf != nil || index != -1 {
+		t.Fatal("full storage folder was selected")
+	}
+
+	// Create one nearly full storage folder and one empty storage folder.
+	nearlyFull := &storageFolder{
+		usage:   make([]uint64, 16),
+		sectors: 16*storageFolderGranularity - 1,
+	}
+	empty := &storageFolder{
+		usage: make([]uint64, 16),
+	}
+	sfs := []*storageFolder{full, nearlyFull, empty}
+	var selected [3]int
+	for i := 0; i < 1000; i++ {
+		sf, index := vacancyStorageFolder(sfs)
+		if sf == nil || sf != sfs[index] {
+			t.Fatal("bad storage folder returned")
+		}
+		sf.mu.RUnlock()
+		selected[index]++
+	}
+	if selected[0] != 0 {
+		t.Error("full storage folder was selected")
+	}
+	if selected[1] >= selected[2] {
+		t.Error("nearly full storage folder was preferred over the empty storage folder:", selected)
+	}
+
+	// A storage folder which is locked should be skipped.
+	empty.mu.Lock()
+	sf, index = vacancyStorageFolder(sfs)
+	if sf != nearlyFull || index != 1 {
+		t.Error("expected the nearly full storage folder to be selected")
+	}
+	sf.mu.RUnlock()
+	empty.mu.Unlock()
+}
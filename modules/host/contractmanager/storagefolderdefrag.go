@@ -0,0 +1,218 @@
+package contractmanager
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errDefragInProgress is returned if a defrag is requested while another
+	// defrag is still running.
+	errDefragInProgress = errors.New("storage folders are already being defragmented")
+)
+
+// defragCandidate returns the emptiest storage folder that is using less than
+// defragThreshold of its capacity and whose sectors fit into the free space of
+// the other storage folders. Folders in 'skip' are neither considered nor
+// counted as free space. nil is returned if there is no such storage folder.
+func (cm *ContractManager) defragCandidate(skip map[uint16]struct{}) *storageFolder {
+	sfs := cm.availableStorageFolders()
+	var candidate *storageFolder
+	var candidateUsage float64
+	for _, sf := range sfs {
+		if _, exists := skip[sf.index]; exists {
+			continue
+		}
+		capacity := uint64(len(sf.usage)) * storageFolderGranularity
+		if sf.sectors == 0 || capacity == 0 {
+			continue
+		}
+		usage := float64(sf.sectors) / float64(capacity)
+		if usage >= defragThreshold || (candidate != nil && usage >= candidateUsage) {
+			continue
+		}
+
+		// Check that the other folders have room for every sector in this
+		// folder.
+		var free uint64
+		for _, osf := range sfs {
+			if _, exists := skip[osf.index]; exists || osf == sf {
+				continue
+			}
+			ocapacity := uint64(len(osf.usage)) * storageFolderGranularity
+			if osf.sectors < ocapacity {
+				free += ocapacity - osf.sectors
+			}
+		}
+		if free < sf.sectors {
+			continue
+		}
+		candidate = sf
+		candidateUsage = usage
+	}
+	return candidate
+}
+
+// managedDefragStorageFolder moves the sectors out of a storage folder one at
+// a time, pausing between moves. Each move is recorded in the WAL as a single
+// update, so an interrupted defrag leaves every sector either in its old or
+// its new location. The number of sectors that could not be moved is
+// returned.
+//
+// The caller is expected to hold a writelock on the storage folder, which
+// makes the storage folder invisible to AddSector and managedMoveSector.
+func (wal *writeAheadLog) managedDefragStorageFolder(sf *storageFolder) (uint64, error) {
+	// Read the sector lookup bytes into memory to figure out which sectors
+	// are in which locations.
+	sectorLookupBytes, err := readFullMetadata(sf.metadataFile, len(sf.usage)*storageFolderGranularity)
+	if err != nil {
+		atomic.AddUint64(&sf.atomicFailedReads, 1)
+		return 0, build.ExtendErr("unable to read sector metadata", err)
+	}
+	atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+
+	// Report the progress of the defrag through the storage folder.
+	wal.mu.Lock()
+	atomic.StoreUint64(&sf.atomicProgressNumerator, 0)
+	atomic.StoreUint64(&sf.atomicProgressDenominator, sf.sectors*modules.SectorSize)
+	wal.mu.Unlock()
+	defer func() {
+		atomic.StoreUint64(&sf.atomicProgressNumerator, 0)
+		atomic.StoreUint64(&sf.atomicProgressDenominator, 0)
+	}()
+
+	var failed uint64
+	for i, usage := range sf.usage {
+		// The usage is a bitfield indicating where sectors exist. Iterate
+		// through each bit to check for a sector.
+		for j := 0; j < storageFolderGranularity; j++ {
+			if usage&(1<<uint(j)) == 0 {
+				continue
+			}
+			readHead := (i*storageFolderGranularity + j) * sectorMetadataDiskSize
+			var id sectorID
+			copy(id[:], sectorLookupBytes[readHead:readHead+12])
+			wal.mu.Lock()
+			_, exists := wal.cm.sectorLocations[id]
+			wal.mu.Unlock()
+			if !exists {
+				// The sector has been deleted, but the usage has not been
+				// updated yet. Safe to ignore.
+				continue
+			}
+
+			// Stop if the contract manager is shutting down, otherwise wait
+			// a moment so that the defrag runs at low priority.
+			select {
+			case <-wal.cm.tg.StopChan():
+				return failed, errors.New("defrag interrupted by shutdown")
+			case <-time.After(defragSectorInterval):
+			}
+
+			err := wal.managedMoveSector(id)
+			if err == errInsufficientStorageForSector {
+				return failed, err
+			} else if err != nil {
+				failed++
+				wal.cm.log.Println("Unable to move sector during defrag:", err)
+				continue
+			}
+			atomic.AddUint64(&sf.atomicProgressNumerator, modules.SectorSize)
+		}
+	}
+	return failed, nil
+}
+
+// threadedDefragStorageFolders repeatedly empties the least used storage
+// folder into the other storage folders, until no storage folder is left that
+// is worth emptying.
+func (cm *ContractManager) threadedDefragStorageFolders() {
+	defer atomic.StoreUint64(&cm.atomicDefragging, 0)
+	if err := cm.tg.Add(); err != nil {
+		return
+	}
+	defer cm.tg.Done()
+
+	// Storage folders stay locked until the defrag is complete, so that the
+	// sectors moved out of one storage folder are not moved back into it
+	// when the next storage folder is emptied. Storage folders that are
+	// already empty are locked up front, as moving sectors into them would
+	// not consolidate any free space. Before the storage folders are
+	// unlocked, wait for the moves to be synchronized.
+	skip := make(map[uint16]struct{})
+	var locked []*storageFolder
+	cm.wal.mu.Lock()
+	for _, sf := range cm.availableStorageFolders() {
+		if sf.sectors == 0 {
+			skip[sf.index] = struct{}{}
+			locked = append(locked, sf)
+		}
+	}
+	cm.wal.mu.Unlock()
+	for _, sf := range locked {
+		sf.mu.Lock()
+	}
+	defer func() {
+		cm.wal.mu.Lock()
+		syncChan := cm.wal.syncChan
+		cm.wal.mu.Unlock()
+		<-syncChan
+		for _, sf := range locked {
+			sf.mu.Unlock()
+		}
+	}()
+
+	for {
+		cm.wal.mu.Lock()
+		sf := cm.defragCandidate(skip)
+		cm.wal.mu.Unlock()
+		if sf == nil {
+			cm.log.Println("Storage folder defrag complete")
+			return
+		}
+		skip[sf.index] = struct{}{}
+
+		// Lock the storage folder for the duration of the defrag. The storage
+		// folder may have been removed or become unavailable while waiting
+		// for the lock.
+		sf.mu.Lock()
+		locked = append(locked, sf)
+		cm.wal.mu.Lock()
+		_, exists := cm.storageFolders[sf.index]
+		cm.wal.mu.Unlock()
+		if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+			continue
+		}
+
+		failed, err := cm.wal.managedDefragStorageFolder(sf)
+		if err != nil {
+			cm.log.Printf("Defrag of storage folder %v stopped: %v\n", sf.path, err)
+			return
+		} else if failed > 0 {
+			cm.log.Printf("Defrag was unable to move %v sectors out of storage folder %v\n", failed, sf.path)
+		}
+	}
+}
+
+// DefragStorageFolders starts consolidating the free space of the contract
+// manager in the background. Storage folders that are nearly unused are
+// emptied into the other storage folders, emptiest first, freeing them up to
+// be shrunk or removed. Progress is reported through the progress fields of
+// the storage folder that is being emptied. The defrag stops when the
+// contract manager is closed.
+func (cm *ContractManager) DefragStorageFolders() error {
+	if err := cm.tg.Add(); err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+
+	if !atomic.CompareAndSwapUint64(&cm.atomicDefragging, 0, 1) {
+		return errDefragInProgress
+	}
+	go cm.threadedDefragStorageFolders()
+	return nil
+}
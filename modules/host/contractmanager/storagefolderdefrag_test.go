@@ -0,0 +1,139 @@
+package contractmanager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestDefragStorageFolders checks that a defrag empties a nearly unused
+// storage folder into the other storage folders without losing data.
+func TestDefragStorageFolders(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester("TestDefragStorageFolders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder and give it a few sectors.
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderOne, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roots []crypto.Hash
+	var datas [][]byte
+	for i := 0; i < 3; i++ {
+		root, data := randSector()
+		err = cmt.cm.AddSector(root, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, root)
+		datas = append(datas, data)
+	}
+
+	// Add a second storage folder and fill it to beyond the defrag threshold,
+	// keeping the first storage folder locked so that it receives none of the
+	// new sectors.
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	err = os.MkdirAll(storageFolderTwo, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sfOne *storageFolder
+	cmt.cm.wal.mu.Lock()
+	for _, sf := range cmt.cm.storageFolders {
+		if sf.path == storageFolderOne {
+			sfOne = sf
+		}
+	}
+	cmt.cm.wal.mu.Unlock()
+	sfOne.mu.Lock()
+	for i := 0; i < 40; i++ {
+		root, data := randSector()
+		err = cmt.cm.AddSector(root, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, root)
+		datas = append(datas, data)
+	}
+	sfOne.mu.Unlock()
+
+	// Defrag. The sectors in the first storage folder should all be moved
+	// into the second storage folder.
+	err = cmt.cm.DefragStorageFolders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100 && atomic.LoadUint64(&cmt.cm.atomicDefragging) == 1; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if atomic.LoadUint64(&cmt.cm.atomicDefragging) == 1 {
+		t.Fatal("defrag did not complete")
+	}
+
+	checkFolders := func() {
+		for _, sf := range cmt.cm.StorageFolders() {
+			used := sf.Capacity - sf.CapacityRemaining
+			if sf.Path == storageFolderOne && used != 0 {
+				t.Error("first storage folder should be empty, has", used, "bytes")
+			} else if sf.Path == storageFolderTwo && used != modules.SectorSize*43 {
+				t.Error("second storage folder should hold every sector, has", used, "bytes")
+			}
+			if sf.ProgressDenominator != 0 {
+				t.Error("progress should be reset after the defrag")
+			}
+		}
+		for i, root := range roots {
+			data, err := cmt.cm.ReadSector(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, datas[i]) {
+				t.Fatal("sector data changed during defrag")
+			}
+		}
+	}
+	checkFolders()
+
+	// Running the defrag again should not move the sectors back.
+	err = cmt.cm.DefragStorageFolders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100 && atomic.LoadUint64(&cmt.cm.atomicDefragging) == 1; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	checkFolders()
+
+	// Restart the contract manager and check that the moves persisted.
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFolders()
+}
@@ -64,6 +64,29 @@ func (cm *ContractManager) RemoveStorageFolder(index uint16, force bool) error {
 	sf.mu.Lock()
 	defer sf.mu.Unlock()
 
+	// Unless the removal is being forced, check that the other storage folders
+	// have enough room to hold all of the sectors in this storage folder. This
+	// check happens before any sectors are moved, so that a removal which
+	// cannot complete will fail without moving anything.
+	if !force {
+		cm.wal.mu.Lock()
+		sectors := sf.sectors
+		var free uint64
+		for _, osf := range cm.availableStorageFolders() {
+			if osf == sf {
+				continue
+			}
+			capacity := uint64(len(osf.usage)) * storageFolderGranularity
+			if osf.sectors < capacity {
+				free += capacity - osf.sectors
+			}
+		}
+		cm.wal.mu.Unlock()
+		if sectors > free {
+			return errInsufficientStorageForSector
+		}
+	}
+
 	// Clear out the sectors in the storage folder.
 	_, err := cm.wal.managedEmptyStorageFolder(index, 0)
 	if err != nil && !force {
@@ -385,3 +385,81 @@ func TestRemoveStorageFolderConcurrentAddSector(t *testing.T) {
 		}
 	}
 }
+
+// TestRemoveStorageFolderInsufficientRoom checks that removing a storage
+// folder fails without moving any sectors when the remaining storage folders
+// do not have room for all of its sectors.
+func TestRemoveStorageFolderInsufficientRoom(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester("TestRemoveStorageFolderInsufficientRoom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder and fill it with more sectors than a second
+	// storage folder could hold.
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderOne, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roots []crypto.Hash
+	for i := 0; i < storageFolderGranularity+1; i++ {
+		root, data := randSector()
+		err = cmt.cm.AddSector(root, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, root)
+	}
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 {
+		t.Fatal("there should be one storage folder in the contract manager")
+	}
+	sfOneIndex := sfs[0].Index
+
+	// Add a second storage folder which is too small to hold all of the
+	// sectors of the first storage folder.
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	err = os.MkdirAll(storageFolderTwo, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing the first storage folder should fail, and no sectors should
+	// have been moved into the second storage folder.
+	err = cmt.cm.RemoveStorageFolder(sfOneIndex, false)
+	if err != errInsufficientStorageForSector {
+		t.Fatal("expected errInsufficientStorageForSector, got", err)
+	}
+	sfs = cmt.cm.StorageFolders()
+	if len(sfs) != 2 {
+		t.Fatal("there should be two storage folders in the contract manager")
+	}
+	for _, sf := range sfs {
+		if sf.Index == sfOneIndex && sf.Capacity != sf.CapacityRemaining+modules.SectorSize*uint64(len(roots)) {
+			t.Error("sectors were moved out of the first storage folder")
+		}
+		if sf.Index != sfOneIndex && sf.Capacity != sf.CapacityRemaining {
+			t.Error("sectors were moved into the second storage folder")
+		}
+	}
+	for _, root := range roots {
+		_, err = cmt.cm.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
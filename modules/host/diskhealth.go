@@ -0,0 +1,148 @@
+package host
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NebulousLabs/fastrand"
+)
+
+// diskProbeFilename is the name of the file that is written to every storage
+// folder to probe the latency of the disk.
+const diskProbeFilename = "diskprobe.tmp"
+
+// errDiskProbeMismatch is returned when the data read back by a disk probe
+// does not match the data that was written.
+var errDiskProbeMismatch = errors.New("disk probe read back different data than it wrote")
+
+// threadedProbeDiskLatency periodically measures the latency of the disks of
+// the host, so that the host stops accepting new contracts while its disks
+// are too slow to reliably serve storage proofs.
+func (h *Host) threadedProbeDiskLatency(closeChan chan struct{}) {
+	defer close(closeChan)
+	for {
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(diskProbeFrequency):
+		}
+		latency, err := h.managedProbeDiskLatency()
+		if err != nil {
+			// A failed probe is recorded as a probe that took as long as the
+			// probe interval, since a disk that fails writes can't be trusted
+			// with new data either.
+			h.log.Println("WARN: disk latency probe failed:", err)
+			latency = diskProbeFrequency
+		}
+		h.mu.Lock()
+		h.recordDiskLatency(latency)
+		h.mu.Unlock()
+	}
+}
+
+// managedProbeDiskLatency writes a small file to every storage folder of the
+// host, syncs it to disk and reads it back, returning the time taken by the
+// slowest folder. If the host has no storage folders, its persist directory is
+// probed instead.
+func (h *Host) managedProbeDiskLatency() (time.Duration, error) {
+	var dirs []string
+	for _, sf := range h.StorageFolders() {
+		dirs = append(dirs, sf.Path)
+	}
+	if len(dirs) == 0 {
+		dirs = append(dirs, h.persistDir)
+	}
+
+	var slowest time.Duration
+	for _, dir := range dirs {
+		latency, err := probeDiskLatency(filepath.Join(dir, diskProbeFilename))
+		if err != nil {
+			return 0, err
+		}
+		if latency > slowest {
+			slowest = latency
+		}
+	}
+	return slowest, nil
+}
+
+// probeDiskLatency returns the time it takes to write diskProbeSize bytes to
+// the file at path, sync them to disk and read them back. The file is removed
+// afterwards.
+func probeDiskLatency(path string) (time.Duration, error) {
+	data := fastrand.Bytes(diskProbeSize)
+	defer os.Remove(path)
+
+	start := time.Now()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return 0, err
+	}
+	readData, err := ioutil.ReadAll(f)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	if !bytes.Equal(data, readData) {
+		return 0, errDiskProbeMismatch
+	}
+	return latency, nil
+}
+
+// maxDiskLatency returns the average disk latency above which the host
+// declines new contracts.
+func (h *Host) maxDiskLatency() time.Duration {
+	if h.settings.MaxDiskLatency == 0 {
+		return time.Duration(defaultMaxDiskLatency) * time.Millisecond
+	}
+	return time.Duration(h.settings.MaxDiskLatency) * time.Millisecond
+}
+
+// recordDiskLatency adds the result of a disk probe to the window of recent
+// probes and updates the disk posture of the host.
+func (h *Host) recordDiskLatency(latency time.Duration) {
+	h.recentDiskLatencies = append(h.recentDiskLatencies, latency)
+	if len(h.recentDiskLatencies) > diskLatencyWindow {
+		h.recentDiskLatencies = h.recentDiskLatencies[len(h.recentDiskLatencies)-diskLatencyWindow:]
+	}
+	h.updateDiskPosture()
+}
+
+// updateDiskPosture marks the disk as degraded while the average latency of
+// the recent probes exceeds the MaxDiskLatency of the host, and as recovered
+// as soon as the average drops back below it.
+func (h *Host) updateDiskPosture() {
+	degraded := h.averageDiskLatency() > h.maxDiskLatency()
+	if degraded && !h.diskDegraded {
+		h.log.Printf("WARN: average disk latency of %v exceeds %v, declining new contracts until it recovers\n", h.averageDiskLatency(), h.maxDiskLatency())
+	} else if !degraded && h.diskDegraded {
+		h.log.Printf("Average disk latency recovered to %v, accepting new contracts again\n", h.averageDiskLatency())
+	}
+	h.diskDegraded = degraded
+}
+
+// averageDiskLatency returns the average latency of the recent disk probes.
+func (h *Host) averageDiskLatency() time.Duration {
+	if len(h.recentDiskLatencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, latency := range h.recentDiskLatencies {
+		total += latency
+	}
+	return total / time.Duration(len(h.recentDiskLatencies))
+}
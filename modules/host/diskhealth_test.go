@@ -0,0 +1,101 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestDiskPosture checks that the host declines new contracts while the
+// average latency of its recent disk probes exceeds MaxDiskLatency, and
+// accepts them again once the latency recovers.
+func TestDiskPosture(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	settings := ht.host.InternalSettings()
+	settings.AcceptingContracts = true
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if !ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should be accepting contracts")
+	}
+
+	// Fill the window with slow probes.
+	ht.host.mu.Lock()
+	for i := 0; i < diskLatencyWindow; i++ {
+		ht.host.recordDiskLatency(10 * time.Second)
+	}
+	ht.host.mu.Unlock()
+	if ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should decline contracts while the disk is degraded")
+	}
+	if !ht.host.InternalSettings().AcceptingContracts {
+		t.Fatal("degraded disk should not change the internal settings")
+	}
+	fm := ht.host.FinancialMetrics()
+	if !fm.DiskDegraded || fm.DiskLatency < 9000 {
+		t.Fatal("metrics don't report the degraded disk:", fm.DiskDegraded, fm.DiskLatency)
+	}
+
+	// Raising the limit clears the posture right away.
+	settings.MaxDiskLatency = 20e3
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if !ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should accept contracts below the raised limit")
+	}
+	settings.MaxDiskLatency = 0
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should decline contracts above the default limit")
+	}
+
+	// Fast probes clear the posture.
+	ht.host.mu.Lock()
+	for i := 0; i < diskLatencyWindow; i++ {
+		ht.host.recordDiskLatency(time.Millisecond)
+	}
+	ht.host.mu.Unlock()
+	if !ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should accept contracts once the disk recovers")
+	}
+	if fm := ht.host.FinancialMetrics(); fm.DiskDegraded {
+		t.Fatal("metrics report a degraded disk after it recovered")
+	}
+}
+
+// TestProbeDiskLatency checks that a disk probe succeeds and cleans up after
+// itself.
+func TestProbeDiskLatency(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	if _, err := ht.host.managedProbeDiskLatency(); err != nil {
+		t.Fatal(err)
+	}
+	for _, sf := range ht.host.StorageFolders() {
+		if _, err := os.Stat(filepath.Join(sf.Path, diskProbeFilename)); !os.IsNotExist(err) {
+			t.Fatal("probe file was not removed:", err)
+		}
+	}
+}
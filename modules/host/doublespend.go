@@ -0,0 +1,117 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// originInput is an output spent by the origin transaction set of a storage
+// obligation whose file contract has not been confirmed yet.
+type originInput struct {
+	soid types.FileContractID
+	txid types.TransactionID
+}
+
+// watchOriginInputs starts watching the outputs spent by the origin
+// transaction set of a storage obligation. If any of them is spent by a
+// different transaction, the file contract can never be confirmed.
+func (h *Host) watchOriginInputs(so storageObligation) {
+	soid := so.id()
+	for _, txn := range so.OriginTransactionSet {
+		txid := txn.ID()
+		for _, sci := range txn.SiacoinInputs {
+			h.originInputs[sci.ParentID] = originInput{
+				soid: soid,
+				txid: txid,
+			}
+		}
+	}
+}
+
+// unwatchOriginInputs stops watching the outputs spent by the origin
+// transaction set of a storage obligation.
+func (h *Host) unwatchOriginInputs(so storageObligation) {
+	soid := so.id()
+	for _, txn := range so.OriginTransactionSet {
+		for _, sci := range txn.SiacoinInputs {
+			if oi, exists := h.originInputs[sci.ParentID]; exists && oi.soid == soid {
+				delete(h.originInputs, sci.ParentID)
+			}
+		}
+	}
+}
+
+// doubleSpend is an output spent by the origin transaction set of a storage
+// obligation which was spent by a conflicting transaction instead.
+type doubleSpend struct {
+	output   types.SiacoinOutputID
+	conflict types.TransactionID
+}
+
+// findDoubleSpends returns the storage obligations whose origin transaction
+// set conflicts with a transaction of the block.
+func (h *Host) findDoubleSpends(block types.Block) map[types.FileContractID]doubleSpend {
+	var doubleSpends map[types.FileContractID]doubleSpend
+	for _, txn := range block.Transactions {
+		txid := txn.ID()
+		for _, sci := range txn.SiacoinInputs {
+			oi, exists := h.originInputs[sci.ParentID]
+			if !exists || oi.txid == txid {
+				continue
+			}
+			if doubleSpends == nil {
+				doubleSpends = make(map[types.FileContractID]doubleSpend)
+			}
+			doubleSpends[oi.soid] = doubleSpend{
+				output:   sci.ParentID,
+				conflict: txid,
+			}
+		}
+	}
+	return doubleSpends
+}
+
+// threadedRejectDoubleSpentObligation drops a storage obligation whose origin
+// transaction set was invalidated by a conflicting transaction, reclaiming the
+// storage and collateral right away instead of resubmitting the origin
+// transaction set until it times out.
+func (h *Host) threadedRejectDoubleSpentObligation(soid types.FileContractID, ds doubleSpend) {
+	err := h.tg.Add()
+	if err != nil {
+		return
+	}
+	defer h.tg.Done()
+
+	h.managedLockStorageObligation(soid)
+	defer h.managedUnlockStorageObligation(soid)
+
+	// The conflicting transaction may have been reverted by a reorg in the
+	// meantime, in which case the origin transaction set can still be
+	// confirmed. The consensus set must not be called under the host lock.
+	if _, unspent := h.cs.SiacoinOutput(ds.output); unspent {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var so storageObligation
+	err = h.db.View(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, soid)
+		return err
+	})
+	if err != nil {
+		h.logObligation(LogWarn, soid, err, "Could not get double-spent storage obligation")
+		return
+	}
+	if so.ObligationStatus != obligationUnresolved || so.OriginConfirmed {
+		return
+	}
+	h.logObligation(LogWarn, soid, nil, fmt.Sprintf("Origin transaction set was double-spent by transaction %v, renter %v, dropping the obligation", ds.conflict, so.RenterKey))
+	err = h.removeStorageObligation(so, obligationRejected)
+	if err != nil {
+		h.logObligation(LogWarn, soid, err, "Error removing storage obligation")
+	}
+}
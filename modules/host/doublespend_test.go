@@ -0,0 +1,123 @@
+package host
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestDoubleSpentObligation checks that the host drops a storage obligation
+// as soon as a transaction conflicting with its origin transaction set is
+// confirmed.
+func TestDoubleSpentObligation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestDoubleSpentObligation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Create a transaction that sends money back to the wallet, and an
+	// origin transaction set which spends the same outputs to form a file
+	// contract instead.
+	payout := types.SiacoinPrecision.Mul64(1e3)
+	builder := ht.wallet.StartTransaction()
+	err = builder.FundSiacoins(payout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc, err := ht.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.AddSiacoinOutput(types.SiacoinOutput{
+		Value:      payout,
+		UnlockHash: uc.UnlockHash(),
+	})
+	conflictSet, err := builder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	originSet := append([]types.Transaction(nil), conflictSet...)
+	origin := originSet[len(originSet)-1]
+	origin.SiacoinOutputs = nil
+	origin.FileContracts = []types.FileContract{{
+		WindowStart: ht.host.blockHeight + revisionSubmissionBuffer + 2,
+		WindowEnd:   ht.host.blockHeight + revisionSubmissionBuffer + defaultWindowSize + 2,
+		Payout:      payout,
+		UnlockHash:  (types.UnlockConditions{}).UnlockHash(),
+	}}
+	originSet[len(originSet)-1] = origin
+	so := storageObligation{
+		OriginTransactionSet: originSet,
+		LockedCollateral:     types.SiacoinPrecision,
+	}
+	soid := so.id()
+
+	// Add the storage obligation directly, as the transaction pool would not
+	// accept the unsigned origin transaction.
+	ht.host.mu.Lock()
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		err := putStorageObligation(tx, so)
+		if err != nil {
+			return err
+		}
+		return indexStorageObligationWindow(tx, so.expiration(), soid)
+	})
+	ht.host.watchOriginInputs(so)
+	ht.host.financialMetrics.ContractCount++
+	ht.host.financialMetrics.LockedStorageCollateral = ht.host.financialMetrics.LockedStorageCollateral.Add(so.LockedCollateral)
+	ht.host.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Confirm the conflicting transaction.
+	err = ht.tpool.AcceptTransactionSet(conflictSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ht.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The storage obligation should be rejected without waiting for the
+	// resubmission timeout.
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		return ht.host.db.View(func(tx *bolt.Tx) error {
+			so, err := getStorageObligation(tx, soid)
+			if err != nil {
+				return err
+			}
+			if so.ObligationStatus != obligationRejected {
+				return errors.New("double-spent storage obligation was not rejected")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm := ht.host.FinancialMetrics()
+	if fm.ContractCount != 0 {
+		t.Error("contract count was not reduced:", fm.ContractCount)
+	}
+	if !fm.LockedStorageCollateral.IsZero() {
+		t.Error("locked collateral was not released:", fm.LockedStorageCollateral)
+	}
+	ht.host.mu.RLock()
+	watched := len(ht.host.originInputs)
+	ht.host.mu.RUnlock()
+	if watched != 0 {
+		t.Error("inputs of the rejected storage obligation are still watched:", watched)
+	}
+}
@@ -69,6 +69,7 @@ import (
 	"net"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -134,6 +135,10 @@ type Host struct {
 	atomicInternalErrors      uint64
 	atomicNormalErrors        uint64
 
+	// atomicDraining is set when the host starts shutting down, from which
+	// point on new RPCs and storage obligations are refused.
+	atomicDraining uint64
+
 	// Dependencies.
 	cs           modules.ConsensusSet
 	tpool        modules.TransactionPool
@@ -145,6 +150,7 @@ type Host struct {
 	// transactions.
 	announced         bool
 	announceConfirmed bool
+	lastAnnouncement  types.BlockHeight // Height of the most recent announcement.
 	blockHeight       types.BlockHeight
 	publicKey         types.SiaPublicKey
 	secretKey         crypto.SecretKey
@@ -158,17 +164,70 @@ type Host struct {
 	settings             modules.HostInternalSettings
 	revisionNumber       uint64
 	workingStatus        modules.HostWorkingStatus
+	recentProofOutcomes  []bool          // Oldest first, true for a successful proof.
+	recentDiskLatencies  []time.Duration // Oldest first.
+	diskDegraded         bool            // Set while new contracts are declined due to disk latency.
+	sectorKeys           []sectorKey     // The last key is used for new sectors.
 	connectabilityStatus modules.HostConnectabilityStatus
 
+	// Automatic re-announcement. reannouncePending is set when settings that
+	// renters care about change, and reannounceAttempt is the height of the
+	// most recent automatic re-announcement attempt.
+	reannouncePending bool
+	reannounceAttempt types.BlockHeight
+	reannouncing      bool
+
 	// A map of storage obligations that are currently being modified. Locks on
 	// storage obligations can be long-running, and each storage obligation can
 	// be locked separately.
 	lockedStorageObligations map[types.FileContractID]*siasync.TryMutex
 
+	// Resolved storage obligations whose sectors are being copied into the
+	// archive directory.
+	archiving map[types.FileContractID]struct{}
+
+	// Resolved storage obligations whose leftover sectors are being removed
+	// by the storage reconciliation.
+	reconciling map[types.FileContractID]struct{}
+
+	// Transactions that the host has submitted to the transaction pool on
+	// behalf of storage obligations and that have not been confirmed yet,
+	// mapped to the transaction set and storage obligation that they belong
+	// to. The map has its own lock because it is updated by the transaction
+	// pool, which must not wait on the host lock.
+	pendingTransactions map[types.TransactionID]pendingTransactionSet
+	pendingMu           sync.Mutex
+
+	// The outputs spent by the origin transaction sets of storage obligations
+	// whose file contracts have not been confirmed yet. A confirmed
+	// transaction spending one of them in place of the origin transaction set
+	// means that the renter double-spent the funding of the file contract.
+	originInputs map[types.SiacoinOutputID]originInput
+
+	// Subscribers which are notified about changes to storage obligations.
+	obligationSubscribers []modules.StorageObligationSubscriber
+
+	// The proof queue limits the number of storage proofs that are built at
+	// the same time.
+	proofQueue proofQueue
+
+	// The proof batcher submits storage proofs that are built at about the
+	// same time in a single transaction.
+	proofBatcher proofBatcher
+
+	// The renter limiter limits the number of sessions and the rate of RPCs
+	// of each renter.
+	renterLimiter renterLimiter
+
+	// sectorMu serializes the bookkeeping of which key each sector is
+	// encrypted with.
+	sectorMu sync.Mutex
+
 	// Utilities.
 	db         *persist.BoltDatabase
 	listener   net.Listener
 	log        *persist.Logger
+	logSink    LogSink
 	mu         sync.RWMutex
 	persistDir string
 	port       string
@@ -212,6 +271,13 @@ func (h *Host) checkUnlockHash() error {
 // behaviors during testing, enabling easier testing of the failure modes of
 // the Host.
 func newHost(dependencies modules.Dependencies, cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.Wallet, listenerAddress string, persistDir string) (*Host, error) {
+	return newHostWithLogSink(dependencies, cs, tpool, wallet, listenerAddress, persistDir, nil)
+}
+
+// newHostWithLogSink returns an initialized Host which sends its structured
+// log entries to 'sink'. If 'sink' is nil, the entries are written to the host
+// log.
+func newHostWithLogSink(dependencies modules.Dependencies, cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.Wallet, listenerAddress string, persistDir string, sink LogSink) (*Host, error) {
 	// Check that all the dependencies were provided.
 	if cs == nil {
 		return nil, errNilCS
@@ -229,8 +295,13 @@ func newHost(dependencies modules.Dependencies, cs modules.ConsensusSet, tpool m
 		tpool:        tpool,
 		wallet:       wallet,
 		dependencies: dependencies,
+		logSink:      sink,
 
 		lockedStorageObligations: make(map[types.FileContractID]*siasync.TryMutex),
+		archiving:                make(map[types.FileContractID]struct{}),
+		reconciling:              make(map[types.FileContractID]struct{}),
+		pendingTransactions:      make(map[types.TransactionID]pendingTransactionSet),
+		originInputs:             make(map[types.SiacoinOutputID]originInput),
 
 		persistDir: persistDir,
 	}
@@ -255,6 +326,9 @@ func newHost(dependencies modules.Dependencies, cs modules.ConsensusSet, tpool m
 	if err != nil {
 		return nil, err
 	}
+	if h.logSink == nil {
+		h.logSink = fileLogSink{log: h.log}
+	}
 	h.tg.AfterStop(func() {
 		err = h.log.Close()
 		if err != nil {
@@ -291,12 +365,51 @@ func newHost(dependencies modules.Dependencies, cs modules.ConsensusSet, tpool m
 		}
 	})
 
+	// Report how much of the storage is committed to storage obligations, and
+	// check that the storage manager agrees with the storage obligations.
+	totalStorage, remainingStorage := h.capacity()
+	h.log.Printf("Host has %v bytes committed to storage obligations, %v of %v bytes remaining\n", h.committedStorage(), remainingStorage, totalStorage)
+	h.mu.RLock()
+	err = h.checkStorageConsistency()
+	h.mu.RUnlock()
+	if err != nil {
+		h.log.Println("WARN: storage is inconsistent:", err)
+	}
+
 	// Initialize the networking.
 	err = h.initNetworking(listenerAddress)
 	if err != nil {
 		h.log.Println("Could not initialize host networking:", err)
 		return nil, err
 	}
+
+	// Subscribe to the transaction pool, so that transactions which are
+	// dropped from the pool can be resubmitted.
+	h.tpool.TransactionPoolSubscribe(h)
+	h.tg.OnStop(func() {
+		h.tpool.Unsubscribe(h)
+	})
+
+	// Periodically reconcile the storage manager with the storage obligations.
+	threadedReconcileStorageClosedChan := make(chan struct{})
+	go h.threadedReconcileStorage(threadedReconcileStorageClosedChan)
+	h.tg.OnStop(func() {
+		<-threadedReconcileStorageClosedChan
+	})
+
+	// Periodically check the stored data for corruption.
+	threadedScrubStorageObligationsClosedChan := make(chan struct{})
+	go h.threadedScrubStorageObligations(threadedScrubStorageObligationsClosedChan)
+	h.tg.OnStop(func() {
+		<-threadedScrubStorageObligationsClosedChan
+	})
+
+	// Periodically measure the latency of the disks.
+	threadedProbeDiskLatencyClosedChan := make(chan struct{})
+	go h.threadedProbeDiskLatency(threadedProbeDiskLatencyClosedChan)
+	h.tg.OnStop(func() {
+		<-threadedProbeDiskLatencyClosedChan
+	})
 	return h, nil
 }
 
@@ -305,8 +418,19 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.
 	return newHost(modules.ProdDependencies, cs, tpool, wallet, address, persistDir)
 }
 
-// Close shuts down the host.
+// NewWithLogSink returns an initialized Host which sends its structured log
+// entries, such as the warnings about storage obligations, to 'sink' instead
+// of the host log.
+func NewWithLogSink(cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.Wallet, address string, persistDir string, sink LogSink) (*Host, error) {
+	return newHostWithLogSink(modules.ProdDependencies, cs, tpool, wallet, address, persistDir, sink)
+}
+
+// Close shuts down the host. New RPCs and storage obligations are refused right
+// away, while the storage proofs that are being built or are waiting to be
+// built are given up to the shutdown timeout to be submitted. Storage
+// obligations whose proof windows are further out are not waited for.
 func (h *Host) Close() error {
+	h.managedDrain()
 	return h.tg.Stop()
 }
 
@@ -344,14 +468,42 @@ func (h *Host) ConnectabilityStatus() modules.HostConnectabilityStatus {
 // FinancialMetrics returns information about the financial commitments,
 // rewards, and activities of the host.
 func (h *Host) FinancialMetrics() modules.HostFinancialMetrics {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
 	err := h.tg.Add()
 	if err != nil {
 		build.Critical("Call to FinancialMetrics after close")
 	}
 	defer h.tg.Done()
-	return h.financialMetrics
+
+	h.mu.RLock()
+	fm := h.financialMetrics
+	blockHeight := h.blockHeight
+	maxDelay := h.maxConfirmationDelay()
+	// The remaining collateral budget is derived from the settings, so that
+	// changes to the budget are reflected immediately.
+	if fm.LockedStorageCollateral.Cmp(h.settings.CollateralBudget) < 0 {
+		fm.RemainingCollateralBudget = h.settings.CollateralBudget.Sub(fm.LockedStorageCollateral)
+	} else {
+		fm.RemainingCollateralBudget = types.ZeroCurrency
+	}
+	fm.RecentProofOutcomes, fm.RecentProofSuccessRate = h.recentProofSuccessRate()
+	fm.DiskLatency = uint64(h.averageDiskLatency() / time.Millisecond)
+	fm.DiskDegraded = h.diskDegraded
+	fm.ObligationLimit = h.obligationLimit()
+	h.mu.RUnlock()
+
+	// The obligations at risk, the unconfirmed obligations and the sectors
+	// which could not be removed from resolved obligations are not tracked
+	// persistently, they are derived from the current block height each time
+	// metrics are requested. Every obligation has to be decoded to count them,
+	// so this is done outside of the host lock.
+	counts, err := h.managedCountObligations(blockHeight, maxDelay)
+	if err != nil {
+		h.log.Println("Unable to count the storage obligations:", err)
+	}
+	fm.ObligationsAtRisk = counts.atRisk
+	fm.ObligationsUnconfirmed = counts.unconfirmed
+	fm.PendingSectorRemovals = counts.pendingSectorRemovals
+	return fm
 }
 
 // PublicKey returns the public key of the host that is used to facilitate
@@ -388,15 +540,48 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 		}
 	}
 
+	if settings.ReannounceInterval != 0 && settings.ReannounceInterval < minReannounceInterval {
+		return fmt.Errorf("internal settings not updated, reannounce interval must be at least %v blocks", minReannounceInterval)
+	}
+
+	settings.StoragePriceTiers, err = sortStoragePriceTiers(settings.StoragePriceTiers)
+	if err != nil {
+		return errors.New("internal settings not updated, invalid storage price tiers: " + err.Error())
+	}
+
+	if settings.ArchiveDir != "" && settings.ArchiveDir != h.settings.ArchiveDir {
+		err := checkArchiveDir(settings.ArchiveDir)
+		if err != nil {
+			return errors.New("internal settings not updated, invalid archive directory: " + err.Error())
+		}
+	}
+
+	if settings.MaintenanceEnd < settings.MaintenanceStart {
+		return errors.New("internal settings not updated, maintenance window ends before it starts")
+	}
+	// Warn about existing storage obligations that are due during a new
+	// maintenance window. They remain valid, but the host must be online to
+	// submit their storage proofs.
+	if settings.MaintenanceStart != h.settings.MaintenanceStart || settings.MaintenanceEnd != h.settings.MaintenanceEnd {
+		h.warnMaintenanceConflicts(settings)
+	}
+
 	// Check if the net address for the host has changed. If it has, and it's
 	// not equal to the auto address, then the host is going to need to make
 	// another blockchain announcement.
 	if h.settings.NetAddress != settings.NetAddress && settings.NetAddress != h.autoAddress {
 		h.announced = false
 	}
+	// If settings that renters see have changed, queue an automatic
+	// re-announcement so that renters rescan the host sooner.
+	if settings.ReannounceInterval != 0 && announcedSettingsChanged(h.settings, settings) {
+		h.reannouncePending = true
+	}
 
 	h.settings = settings
 	h.revisionNumber++
+	// A new MaxDiskLatency takes effect without waiting for the next probe.
+	h.updateDiskPosture()
 
 	err = h.saveSync()
 	if err != nil {
@@ -422,6 +422,113 @@ func TestSetAndGetInternalSettings(t *testing.T) {
 	ht.host = rebootHost
 }
 
+// TestMinDuration checks that the host rejects new file contracts that end
+// sooner than its minimum duration.
+func TestMinDuration(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	ht, err := newHostTester("TestMinDuration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	eSettings := ht.host.ExternalSettings()
+	windowStart := ht.host.blockHeight + revisionSubmissionBuffer + 1
+	txnSet := []types.Transaction{{
+		FileContracts: []types.FileContract{{
+			WindowStart: windowStart,
+			WindowEnd:   windowStart + eSettings.WindowSize,
+		}},
+	}}
+
+	// With a minimum duration that the contract does not meet, the contract
+	// should be rejected for its duration.
+	settings := ht.host.InternalSettings()
+	settings.MinDuration = revisionSubmissionBuffer + eSettings.WindowSize + 10
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.managedVerifyNewContract(txnSet, crypto.PublicKey{}, eSettings)
+	if err != errShortDuration {
+		t.Fatal("expected errShortDuration, got", err)
+	}
+
+	// Without a minimum duration, the contract should make it past the
+	// duration checks.
+	settings.MinDuration = 0
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.managedVerifyNewContract(txnSet, crypto.PublicKey{}, eSettings)
+	if err != errBadContractOutputCounts {
+		t.Fatal("expected errBadContractOutputCounts, got", err)
+	}
+}
+
+// TestMaintenanceWindow checks that the host rejects contracts with a proof
+// window that overlaps its maintenance window.
+func TestMaintenanceWindow(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	ht, err := newHostTester("TestMaintenanceWindow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	eSettings := ht.host.ExternalSettings()
+	windowStart := ht.host.blockHeight + revisionSubmissionBuffer + 1
+	windowEnd := windowStart + eSettings.WindowSize
+	txnSet := []types.Transaction{{
+		FileContracts: []types.FileContract{{
+			WindowStart: windowStart,
+			WindowEnd:   windowEnd,
+		}},
+	}}
+
+	// A maintenance window that ends before it starts should be refused.
+	settings := ht.host.InternalSettings()
+	settings.MaintenanceStart = windowEnd
+	settings.MaintenanceEnd = windowStart
+	if ht.host.SetInternalSettings(settings) == nil {
+		t.Fatal("expected an error for an inverted maintenance window")
+	}
+
+	// A maintenance window that overlaps the end of the proof window should
+	// cause the contract to be rejected.
+	settings.MaintenanceStart = windowEnd
+	settings.MaintenanceEnd = windowEnd + 10
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.managedVerifyNewContract(txnSet, crypto.PublicKey{}, eSettings)
+	if err != errMaintenanceWindow {
+		t.Fatal("expected errMaintenanceWindow, got", err)
+	}
+
+	// A maintenance window after the proof window should not affect the
+	// contract.
+	settings.MaintenanceStart = windowEnd + 1
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.managedVerifyNewContract(txnSet, crypto.PublicKey{}, eSettings)
+	if err != errBadContractOutputCounts {
+		t.Fatal("expected errBadContractOutputCounts, got", err)
+	}
+}
+
 /*
 // TestSetAndGetSettings checks that the functions for interacting with the
 // hosts settings object are working as expected.
@@ -0,0 +1,120 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// deriveObligationMetrics computes the financial metrics that are implied by
+// the set of unresolved storage obligations in the database. Only the metrics
+// which describe open contracts are filled in.
+func (h *Host) deriveObligationMetrics() (fm modules.HostFinancialMetrics, err error) {
+	err = h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return err
+			}
+			if so.ObligationStatus != obligationUnresolved {
+				return nil
+			}
+			fm.ContractCount++
+			fm.PotentialContractCompensation = fm.PotentialContractCompensation.Add(so.ContractCost)
+			fm.LockedStorageCollateral = fm.LockedStorageCollateral.Add(so.LockedCollateral)
+			fm.PotentialStorageRevenue = fm.PotentialStorageRevenue.Add(so.PotentialStorageRevenue)
+			fm.PotentialDownloadBandwidthRevenue = fm.PotentialDownloadBandwidthRevenue.Add(so.PotentialDownloadRevenue)
+			fm.PotentialUploadBandwidthRevenue = fm.PotentialUploadBandwidthRevenue.Add(so.PotentialUploadRevenue)
+			fm.RiskedStorageCollateral = fm.RiskedStorageCollateral.Add(so.RiskedCollateral)
+			return nil
+		})
+	})
+	return fm, err
+}
+
+// CheckObligationInvariants recomputes the aggregate metrics of the host from
+// its storage obligations and returns an error for every aggregate that does
+// not match the value tracked by the host. Storage used by the storage manager
+// is also checked against the sectors referenced by the storage obligations.
+func (h *Host) CheckObligationInvariants() []error {
+	err := h.tg.Add()
+	if err != nil {
+		return []error{err}
+	}
+	defer h.tg.Done()
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	expected, err := h.deriveObligationMetrics()
+	if err != nil {
+		return []error{err}
+	}
+	tracked := h.financialMetrics
+
+	var errs []error
+	if tracked.ContractCount != expected.ContractCount {
+		errs = append(errs, fmt.Errorf("contract count is %v, but the storage obligations imply %v", tracked.ContractCount, expected.ContractCount))
+	}
+	currencies := []struct {
+		name              string
+		tracked, expected types.Currency
+	}{
+		{"potential contract compensation", tracked.PotentialContractCompensation, expected.PotentialContractCompensation},
+		{"locked storage collateral", tracked.LockedStorageCollateral, expected.LockedStorageCollateral},
+		{"potential storage revenue", tracked.PotentialStorageRevenue, expected.PotentialStorageRevenue},
+		{"potential download bandwidth revenue", tracked.PotentialDownloadBandwidthRevenue, expected.PotentialDownloadBandwidthRevenue},
+		{"potential upload bandwidth revenue", tracked.PotentialUploadBandwidthRevenue, expected.PotentialUploadBandwidthRevenue},
+		{"risked storage collateral", tracked.RiskedStorageCollateral, expected.RiskedStorageCollateral},
+	}
+	for _, c := range currencies {
+		if !c.tracked.Equals(c.expected) {
+			errs = append(errs, fmt.Errorf("%v is %v, but the storage obligations imply %v", c.name, c.tracked, c.expected))
+		}
+	}
+	if err := h.checkStorageConsistency(); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// repairObligationMetrics replaces the aggregate metrics that describe open
+// contracts with the values derived from the host's storage obligations.
+func (h *Host) repairObligationMetrics() error {
+	expected, err := h.deriveObligationMetrics()
+	if err != nil {
+		return err
+	}
+	h.financialMetrics.ContractCount = expected.ContractCount
+	h.financialMetrics.PotentialContractCompensation = expected.PotentialContractCompensation
+	h.financialMetrics.LockedStorageCollateral = expected.LockedStorageCollateral
+	h.financialMetrics.PotentialStorageRevenue = expected.PotentialStorageRevenue
+	h.financialMetrics.PotentialDownloadBandwidthRevenue = expected.PotentialDownloadBandwidthRevenue
+	h.financialMetrics.PotentialUploadBandwidthRevenue = expected.PotentialUploadBandwidthRevenue
+	h.financialMetrics.RiskedStorageCollateral = expected.RiskedStorageCollateral
+	return nil
+}
+
+// RepairObligationInvariants re-derives the aggregate metrics that describe
+// open contracts from the host's storage obligations, replacing the values
+// tracked by the host. Lifetime metrics such as earned and lost revenue cannot
+// be derived from the open obligations and are left untouched.
+func (h *Host) RepairObligationInvariants() error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	err = h.repairObligationMetrics()
+	if err != nil {
+		return err
+	}
+	return h.saveSync()
+}
@@ -0,0 +1,56 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestCheckObligationInvariants checks that drift in the aggregate metrics of
+// the host is detected, and that repairing the invariants removes the drift.
+func TestCheckObligationInvariants(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestCheckObligationInvariants")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+	if errs := ht.host.CheckObligationInvariants(); len(errs) != 0 {
+		t.Fatal("unexpected invariant violations:", errs)
+	}
+
+	// Introduce drift in two of the aggregates.
+	ht.host.mu.Lock()
+	ht.host.financialMetrics.ContractCount += 2
+	ht.host.financialMetrics.LockedStorageCollateral = ht.host.financialMetrics.LockedStorageCollateral.Add(types.SiacoinPrecision)
+	ht.host.mu.Unlock()
+	if errs := ht.host.CheckObligationInvariants(); len(errs) != 2 {
+		t.Fatal("expected two invariant violations, got", errs)
+	}
+
+	// Repair the aggregates.
+	err = ht.host.RepairObligationInvariants()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := ht.host.CheckObligationInvariants(); len(errs) != 0 {
+		t.Fatal("invariant violations remain after repair:", errs)
+	}
+	if ht.host.FinancialMetrics().ContractCount != 1 {
+		t.Fatal("contract count was not repaired")
+	}
+}
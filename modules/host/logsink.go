@@ -0,0 +1,91 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// LogLevel is the severity of a structured log entry.
+type LogLevel int
+
+const (
+	// LogInfo is the level of entries that report normal operation.
+	LogInfo LogLevel = iota
+
+	// LogWarn is the level of entries that report a problem which the host
+	// recovered from, or which only affects a single storage obligation.
+	LogWarn
+
+	// LogError is the level of entries that report a problem which the host
+	// could not recover from, and which may need attention from the user.
+	LogError
+)
+
+// String returns the prefix that the level is logged with.
+func (l LogLevel) String() string {
+	switch l {
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// LogEntry is a structured log entry of the host. FileContractID is the id of
+// the storage obligation that the entry is about, and is empty for entries
+// that are not about a storage obligation. Err is the error that caused the
+// entry, if any.
+type LogEntry struct {
+	Level          LogLevel
+	Message        string
+	FileContractID types.FileContractID
+	Err            error
+}
+
+// String formats the entry the way it is written to the host log.
+func (e LogEntry) String() string {
+	s := e.Level.String() + ": " + e.Message
+	if e.FileContractID != (types.FileContractID{}) {
+		s += ", id " + e.FileContractID.String()
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// A LogSink receives the structured log entries of the host, such as the
+// warnings about storage obligations which could not be saved or removed.
+// Log may be called from many goroutines at once, and while the host is under
+// lock, so it must not block or call back into the host.
+type LogSink interface {
+	Log(LogEntry)
+}
+
+// fileLogSink is the default LogSink of the host, which writes the entries to
+// the host log file along with the unstructured messages.
+type fileLogSink struct {
+	log *persist.Logger
+}
+
+// Log implements LogSink.
+func (s fileLogSink) Log(e LogEntry) {
+	s.log.Output(3, e.String())
+}
+
+// logObligation sends a log entry about a storage obligation to the log sink
+// of the host.
+func (h *Host) logObligation(level LogLevel, soid types.FileContractID, err error, msg string) {
+	h.logSink.Log(LogEntry{
+		Level:          level,
+		Message:        msg,
+		FileContractID: soid,
+		Err:            err,
+	})
+}
@@ -0,0 +1,102 @@
+package host
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// recordingLogSink is a LogSink that keeps every entry it receives.
+type recordingLogSink struct {
+	entries []LogEntry
+	mu      sync.Mutex
+}
+
+// Log implements LogSink.
+func (s *recordingLogSink) Log(e LogEntry) {
+	s.mu.Lock()
+	s.entries = append(s.entries, e)
+	s.mu.Unlock()
+}
+
+// TestLogEntryString checks the format that log entries are written to the
+// host log in.
+func TestLogEntryString(t *testing.T) {
+	soid := types.FileContractID{1}
+	tests := []struct {
+		entry LogEntry
+		want  string
+	}{
+		{LogEntry{Level: LogInfo, Message: "foo"}, "INFO: foo"},
+		{LogEntry{Level: LogWarn, Message: "foo", FileContractID: soid}, "WARN: foo, id " + soid.String()},
+		{LogEntry{Level: LogError, Message: "foo", FileContractID: soid, Err: errors.New("bar")}, "ERROR: foo, id " + soid.String() + ": bar"},
+	}
+	for _, test := range tests {
+		if s := test.entry.String(); s != test.want {
+			t.Errorf("expected %q, got %q", test.want, s)
+		}
+	}
+}
+
+// TestHostLogSink checks that the host sends warnings about storage
+// obligations to its log sink.
+func TestHostLogSink(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester("TestHostLogSink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	sink := new(recordingLogSink)
+	ht.host.mu.Lock()
+	ht.host.logSink = sink
+	ht.host.mu.Unlock()
+
+	// Handling an action item for an unknown storage obligation should log a
+	// warning carrying the id of the obligation and the error.
+	soid := types.FileContractID{1}
+	ht.host.threadedHandleActionItem(soid)
+	sink.mu.Lock()
+	if len(sink.entries) != 1 {
+		t.Fatal("expected 1 log entry, got", len(sink.entries))
+	}
+	e := sink.entries[0]
+	sink.mu.Unlock()
+	if e.Level != LogWarn || e.FileContractID != soid || e.Err != errNoStorageObligation {
+		t.Fatal("wrong log entry:", e)
+	}
+
+	// A storage obligation whose sector is missing fails the integrity check
+	// of the scrubber, which should also be logged to the sink.
+	so := storageObligation{
+		SectorRoots: []crypto.Hash{{1}},
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{}},
+		}},
+	}
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		return putStorageObligation(tx, so)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedScrubStorageObligations()
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.entries) != 2 {
+		t.Fatal("expected 2 log entries, got", len(sink.entries))
+	}
+	e = sink.entries[1]
+	if e.Level != LogWarn || e.FileContractID != so.id() || e.Err == nil {
+		t.Fatal("wrong log entry:", e)
+	}
+}
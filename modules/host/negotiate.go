@@ -89,6 +89,10 @@ var (
 	// length.
 	errIllegalOffsetAndLength = ErrorCommunication("renter is trying to do a modify with an illegal offset and length")
 
+	// errInsufficientStorage is returned if the renter is trying to upload
+	// more data than the host has room for.
+	errInsufficientStorage = ErrorCommunication("rejected because the host does not have enough free storage for the uploaded data")
+
 	// errLargeSector is returned if the renter sends a RevisionAction that has
 	// data which creates a sector that is larger than what the host uses.
 	errLargeSector = ErrorCommunication("renter has sent a sector that exceeds the host's sector size")
@@ -120,15 +124,32 @@ var (
 	// funds to the void output.
 	errLowVoidOutput = ErrorCommunication("rejected for low value void output")
 
+	// errMaintenanceWindow is returned if the renter proposes a file contract
+	// with a storage proof window that overlaps the host's planned
+	// maintenance.
+	errMaintenanceWindow = ErrorCommunication("rejected for a proof window that overlaps the host's maintenance window")
+
 	// errMismatchedHostPayouts is returned if the renter incorrectly sets the
 	// host valid and missed payouts to different values during contract
 	// formation.
 	errMismatchedHostPayouts = ErrorCommunication("rejected because host valid and missed payouts are not the same value")
 
+	// errShortDuration is returned if the renter proposes a file contract
+	// with an expiration that is too close according to the host's settings.
+	errShortDuration = ErrorCommunication("renter proposed a file contract with a too-short duration")
+
+	// errSmallFileSize is returned if the renter tries to renew a file
+	// contract that holds less data than the host's settings allow.
+	errSmallFileSize = ErrorCommunication("rejected for holding too little data")
+
 	// errSmallWindow is returned if the renter suggests a storage proof window
 	// that is too small.
 	errSmallWindow = ErrorCommunication("rejected for small window size")
 
+	// errTooManyRevisions is returned if the renter tries to revise a storage
+	// obligation more often than the host's MaxContractRevisions allows.
+	errTooManyRevisions = ErrorCommunication("rejected because the contract has exceeded the host's revision limit, try again later")
+
 	// errUnknownModification is returned if the host receives a modification
 	// action from the renter that it does not understand.
 	errUnknownModification = ErrorCommunication("renter is attempting an action that the host does not understand")
@@ -218,6 +239,8 @@ func (h *Host) managedFinalizeContract(builder modules.TransactionBuilder, rente
 		PotentialStorageRevenue: hostInitialRevenue,
 		RiskedCollateral:        hostInitialRisk,
 
+		RenterKey: types.Ed25519PublicKey(renterPK),
+
 		OriginTransactionSet:   fullTxnSet,
 		RevisionTransactionSet: []types.Transaction{revisionTransaction},
 	}
@@ -247,13 +270,19 @@ func (h *Host) managedFinalizeContract(builder modules.TransactionBuilder, rente
 		//
 		// The storage obligation locks should occur at the highest level, not
 		// just when the actual modification is happening.
+		//
+		// A failed attempt may have already added the storage obligation to
+		// the database, so retries go through the upsert path to avoid
+		// counting the obligation twice.
 		i := 0
 		for {
-			err = h.managedAddStorageObligation(so)
+			err = h.managedUpsertStorageObligation(so)
 			if err == nil {
 				return nil
 			}
-			if err != nil && i > 4 {
+			// Retrying will not make room in the collateral budget or below
+			// the obligation limit.
+			if err == errCollateralBudgetExceeded || err == errMaxObligationsReached || (err != nil && i > 4) {
 				h.log.Println(err)
 				builder.Drop()
 				return err
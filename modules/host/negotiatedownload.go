@@ -47,6 +47,7 @@ func (h *Host) managedDownloadIteration(conn net.Conn, so *storageObligation) er
 	blockHeight := h.blockHeight
 	secretKey := h.secretKey
 	settings := h.externalSettings()
+	maxRevisions := h.maxContractRevisions()
 	h.mu.Unlock()
 
 	// Read the download requests, followed by the file contract revision that
@@ -66,10 +67,15 @@ func (h *Host) managedDownloadIteration(conn net.Conn, so *storageObligation) er
 	// for the renter.
 	existingRevision := so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].FileContractRevisions[0]
 	var payload [][]byte
+	var totalSize uint64
 	err = func() error {
+		// Check that the renter has not used up the revisions of the contract.
+		if so.revisionLimitReached(blockHeight, maxRevisions) {
+			return errTooManyRevisions
+		}
+
 		// Check that the length of each file is in-bounds, and that the total
 		// size being requested is acceptable.
-		var totalSize uint64
 		for _, request := range requests {
 			if request.Length > modules.SectorSize || request.Offset+request.Length > modules.SectorSize {
 				return extendErr("download iteration request failed: ", errRequestOutOfBounds)
@@ -119,12 +125,15 @@ func (h *Host) managedDownloadIteration(conn net.Conn, so *storageObligation) er
 	// Update the storage obligation.
 	paymentTransfer := existingRevision.NewValidProofOutputs[0].Value.Sub(paymentRevision.NewValidProofOutputs[0].Value)
 	so.PotentialDownloadRevenue = so.PotentialDownloadRevenue.Add(paymentTransfer)
+	so.DownloadBytes += totalSize
 	so.RevisionTransactionSet = []types.Transaction{{
 		FileContractRevisions: []types.FileContractRevision{paymentRevision},
 		TransactionSignatures: []types.TransactionSignature{renterSignature, txn.TransactionSignatures[1]},
 	}}
+	so.recordRevision(blockHeight)
+	// Downloads do not add sectors, so no encryption keys are needed.
 	h.mu.Lock()
-	err = h.modifyStorageObligation(*so, nil, nil, nil)
+	err = h.modifyStorageObligation(*so, sectorEncryption{}, nil, nil, nil)
 	h.mu.Unlock()
 	if err != nil {
 		return extendErr("failed to modify storage obligation: ", ErrorInternal(modules.WriteNegotiationRejection(conn, err).Error()))
@@ -105,6 +105,11 @@ func (h *Host) managedRPCFormContract(conn net.Conn) error {
 	if err != nil {
 		return extendErr("could not read renter public key: ", ErrorConnection(err.Error()))
 	}
+	renterKey := types.Ed25519PublicKey(renterPK)
+	err = h.managedLimitRenter(conn, renterKey, types.ZeroCurrency)
+	if err != nil {
+		return extendErr("renter limit reached: ", err)
+	}
 
 	// The host verifies that the file contract coming over the wire is
 	// acceptable.
@@ -240,6 +245,14 @@ func (h *Host) managedVerifyNewContract(txnSet []types.Transaction, renterPK cry
 	if fc.WindowEnd > blockHeight+eSettings.MaxDuration {
 		return errLongDuration
 	}
+	// WindowEnd must be at least settings.MinDuration blocks into the future.
+	if fc.WindowEnd < blockHeight+iSettings.MinDuration {
+		return errShortDuration
+	}
+	// The proof window must not overlap the host's maintenance window.
+	if overlapsMaintenance(iSettings, fc.WindowStart, fc.WindowEnd) {
+		return errMaintenanceWindow
+	}
 
 	// ValidProofOutputs shoud have 2 outputs (renter + host) and missed
 	// outputs should have 3 (renter + host + void)
@@ -139,6 +139,13 @@ func (h *Host) managedRPCRecentRevision(conn net.Conn) (types.FileContractID, st
 		}
 	}()
 
+	// Reject the renter if it is making too many requests.
+	renterKey := recentRevision.UnlockConditions.PublicKeys[0]
+	err = h.managedLimitRenter(conn, renterKey, so.LockedCollateral)
+	if err != nil {
+		return types.FileContractID{}, storageObligation{}, extendErr("renter limit reached: ", err)
+	}
+
 	// Send the file contract revision and the corresponding signatures to the
 	// renter.
 	err = modules.WriteNegotiationAcceptance(conn)
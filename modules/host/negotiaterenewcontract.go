@@ -28,29 +28,32 @@ func renewBaseCollateral(so storageObligation, settings modules.HostExternalSett
 }
 
 // renewBasePrice returns the base cost of the storage in the file contract,
-// using the host external settings and the starting file contract.
-func renewBasePrice(so storageObligation, settings modules.HostExternalSettings, fc types.FileContract) types.Currency {
+// using the host external settings and the starting file contract. The
+// storage price is the price for the duration of the renewed contract, which
+// is formed at 'blockHeight'.
+func renewBasePrice(so storageObligation, settings modules.HostExternalSettings, fc types.FileContract, blockHeight types.BlockHeight) types.Currency {
 	if fc.WindowEnd <= so.proofDeadline() {
 		return types.NewCurrency64(0)
 	}
 	timeExtension := fc.WindowEnd - so.proofDeadline()
-	return settings.StoragePrice.Mul64(fc.FileSize).Mul64(uint64(timeExtension))
+	storagePrice := settings.StoragePriceForDuration(modules.StorageTierDuration(blockHeight, fc.WindowEnd))
+	return storagePrice.Mul64(fc.FileSize).Mul64(uint64(timeExtension))
 }
 
 // renewContractCollateral returns the amount of collateral that the host is
 // expected to add to the file contract based on the file contract and host
 // settings.
-func renewContractCollateral(so storageObligation, settings modules.HostExternalSettings, fc types.FileContract) types.Currency {
-	return fc.ValidProofOutputs[1].Value.Sub(settings.ContractPrice).Sub(renewBasePrice(so, settings, fc))
+func renewContractCollateral(so storageObligation, settings modules.HostExternalSettings, fc types.FileContract, blockHeight types.BlockHeight) types.Currency {
+	return fc.ValidProofOutputs[1].Value.Sub(settings.ContractPrice).Sub(renewBasePrice(so, settings, fc, blockHeight))
 }
 
 // managedAddRenewCollateral adds the host's collateral to the renewed file
 // contract.
-func (h *Host) managedAddRenewCollateral(so storageObligation, settings modules.HostExternalSettings, txnSet []types.Transaction) (builder modules.TransactionBuilder, newParents []types.Transaction, newInputs []types.SiacoinInput, newOutputs []types.SiacoinOutput, err error) {
+func (h *Host) managedAddRenewCollateral(so storageObligation, settings modules.HostExternalSettings, blockHeight types.BlockHeight, txnSet []types.Transaction) (builder modules.TransactionBuilder, newParents []types.Transaction, newInputs []types.SiacoinInput, newOutputs []types.SiacoinOutput, err error) {
 	txn := txnSet[len(txnSet)-1]
 	parents := txnSet[:len(txnSet)-1]
 	fc := txn.FileContracts[0]
-	hostPortion := renewContractCollateral(so, settings, fc)
+	hostPortion := renewContractCollateral(so, settings, fc, blockHeight)
 	builder = h.wallet.RegisterTransaction(txn, parents)
 	err = builder.FundSiacoins(hostPortion)
 	if err != nil {
@@ -92,6 +95,15 @@ func (h *Host) managedRPCRenewContract(conn net.Conn) error {
 	if err != nil {
 		return extendErr("RPCSettings failed: ", err)
 	}
+	// A renewal creates a new storage obligation, so a host at its obligation
+	// limit turns the renewal down before negotiating it. The renter has
+	// learned from the host settings that the host is not accepting contracts.
+	h.mu.RLock()
+	limitReached := h.obligationLimitReached()
+	h.mu.RUnlock()
+	if limitReached {
+		return extendErr("turning down renewal: ", ErrorCommunication(errMaxObligationsReached.Error()))
+	}
 
 	// Set the renewal deadline.
 	conn.SetDeadline(time.Now().Add(modules.NegotiateRenewContractTime))
@@ -120,6 +132,7 @@ func (h *Host) managedRPCRenewContract(conn net.Conn) error {
 
 	h.mu.Lock()
 	settings := h.externalSettings()
+	blockHeight := h.blockHeight
 	h.mu.Unlock()
 
 	// Verify that the transaction coming over the wire is a proper renewal.
@@ -128,7 +141,7 @@ func (h *Host) managedRPCRenewContract(conn net.Conn) error {
 		modules.WriteNegotiationRejection(conn, err) // Error is ignored to preserve type for extendErr
 		return extendErr("verification of renewal failed: ", err)
 	}
-	txnBuilder, newParents, newInputs, newOutputs, err := h.managedAddRenewCollateral(so, settings, txnSet)
+	txnBuilder, newParents, newInputs, newOutputs, err := h.managedAddRenewCollateral(so, settings, blockHeight, txnSet)
 	if err != nil {
 		modules.WriteNegotiationRejection(conn, err) // Error is ignored to preserve type for extendErr
 		return extendErr("failed to add collateral: ", err)
@@ -182,8 +195,8 @@ func (h *Host) managedRPCRenewContract(conn net.Conn) error {
 	// During finalization the signatures sent by the renter are all checked.
 	h.mu.RLock()
 	fc := txnSet[len(txnSet)-1].FileContracts[0]
-	renewCollateral := renewContractCollateral(so, settings, fc)
-	renewRevenue := renewBasePrice(so, settings, fc)
+	renewCollateral := renewContractCollateral(so, settings, fc, blockHeight)
+	renewRevenue := renewBasePrice(so, settings, fc, blockHeight)
 	renewRisk := renewBaseCollateral(so, settings, fc)
 	h.mu.RUnlock()
 	hostTxnSignatures, hostRevisionSignature, newSOID, err := h.managedFinalizeContract(txnBuilder, renterPK, renterTxnSignatures, renterRevisionSignature, so.SectorRoots, renewCollateral, renewRevenue, renewRisk, settings)
@@ -248,6 +261,19 @@ func (h *Host) managedVerifyRenewedContract(so storageObligation, txnSet []types
 	if fc.WindowEnd < fc.WindowStart+externalSettings.WindowSize {
 		return errSmallWindow
 	}
+	// WindowEnd must be at least settings.MinDuration blocks into the future.
+	if fc.WindowEnd < blockHeight+internalSettings.MinDuration {
+		return errShortDuration
+	}
+	// The proof window must not overlap the host's maintenance window.
+	if overlapsMaintenance(internalSettings, fc.WindowStart, fc.WindowEnd) {
+		return errMaintenanceWindow
+	}
+	// The renewed contract must hold at least settings.MinFileSize bytes.
+	// New contracts start out empty, so this is only checked for renewals.
+	if fc.FileSize < internalSettings.MinFileSize {
+		return errSmallFileSize
+	}
 
 	// ValidProofOutputs shoud have 2 outputs (renter + host) and missed
 	// outputs should have 3 (renter + host + void)
@@ -263,7 +289,7 @@ func (h *Host) managedVerifyRenewedContract(so storageObligation, txnSet []types
 
 	// Check that the collateral does not exceed the maximum amount of
 	// collateral allowed.
-	expectedCollateral := renewContractCollateral(so, externalSettings, fc)
+	expectedCollateral := renewContractCollateral(so, externalSettings, fc, blockHeight)
 	if expectedCollateral.Cmp(externalSettings.MaxCollateral) > 0 {
 		return errMaxCollateralReached
 	}
@@ -274,7 +300,7 @@ func (h *Host) managedVerifyRenewedContract(so storageObligation, txnSet []types
 	}
 	// Check that the missed proof outputs contain enough money, and that the
 	// void output contains enough money.
-	basePrice := renewBasePrice(so, externalSettings, fc)
+	basePrice := renewBasePrice(so, externalSettings, fc, blockHeight)
 	baseCollateral := renewBaseCollateral(so, externalSettings, fc)
 	if fc.ValidProofOutputs[1].Value.Cmp(basePrice.Add(baseCollateral)) < 0 {
 		return errLowHostValidOutput
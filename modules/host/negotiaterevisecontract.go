@@ -42,7 +42,12 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 	settings := h.externalSettings()
 	secretKey := h.secretKey
 	blockHeight := h.blockHeight
+	maxRevisions := h.maxContractRevisions()
 	h.mu.Unlock()
+	se, err := h.managedSectorEncryption()
+	if err != nil {
+		return extendErr("unable to get the sector encryption keys: ", ErrorInternal(err.Error()))
+	}
 
 	// The renter is going to send its intended modifications, followed by the
 	// file contract revision that pays for them.
@@ -61,12 +66,17 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 	// with the ability to reverse them. Then verify the file contract revision
 	// correctly accounts for the changes.
 	var bandwidthRevenue types.Currency // Upload bandwidth.
+	var uploadBytes uint64
 	var storageRevenue types.Currency
 	var newCollateral types.Currency
 	var sectorsRemoved []crypto.Hash
 	var sectorsGained []crypto.Hash
 	var gainedSectorData [][]byte
 	err = func() error {
+		// Check that the renter has not used up the revisions of the contract.
+		if so.revisionLimitReached(blockHeight, maxRevisions) {
+			return errTooManyRevisions
+		}
 		for _, modification := range modifications {
 			// Check that the index points to an existing sector root. If the type
 			// is ActionInsert, we permit inserting at the end.
@@ -95,11 +105,11 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 				}
 
 				// Update finances.
-				blocksRemaining := so.proofDeadline() - blockHeight
-				blockBytesCurrency := types.NewCurrency64(uint64(blocksRemaining)).Mul64(modules.SectorSize)
-				bandwidthRevenue = bandwidthRevenue.Add(settings.UploadBandwidthPrice.Mul64(modules.SectorSize))
-				storageRevenue = storageRevenue.Add(settings.StoragePrice.Mul(blockBytesCurrency))
-				newCollateral = newCollateral.Add(settings.Collateral.Mul(blockBytesCurrency))
+				uploadBytes += uint64(len(modification.Data))
+				sectorStorage, sectorBandwidth, sectorCollateral := sectorUploadPrices(settings, so.duration(), so.proofDeadline()-blockHeight)
+				bandwidthRevenue = bandwidthRevenue.Add(sectorBandwidth)
+				storageRevenue = storageRevenue.Add(sectorStorage)
+				newCollateral = newCollateral.Add(sectorCollateral)
 
 				// Insert the sector into the root list.
 				newRoot := crypto.MerkleRoot(modification.Data)
@@ -116,13 +126,14 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 				}
 
 				// Get the data for the new sector.
-				sector, err := h.ReadSector(so.SectorRoots[modification.SectorIndex])
+				sector, err := h.readSector(se.keys, so.SectorRoots[modification.SectorIndex])
 				if err != nil {
 					return extendErr("could not read sector: ", ErrorInternal(err.Error()))
 				}
 				copy(sector[modification.Offset:], modification.Data)
 
 				// Update finances.
+				uploadBytes += uint64(len(modification.Data))
 				bandwidthRevenue = bandwidthRevenue.Add(settings.UploadBandwidthPrice.Mul64(uint64(len(modification.Data))))
 
 				// Update the sectors removed and gained to indicate that the old
@@ -136,6 +147,14 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 				return errUnknownModification
 			}
 		}
+		// Refuse the modifications if the host does not have room for the new
+		// sectors. Sectors are added before old sectors are removed, so the
+		// removed sectors do not free up room.
+		_, remaining := h.capacity()
+		if uint64(len(sectorsGained))*modules.SectorSize > remaining {
+			return errInsufficientStorage
+		}
+
 		newRevenue := storageRevenue.Add(bandwidthRevenue)
 		return extendErr("unable to verify updated contract: ", verifyRevision(*so, revision, blockHeight, newRevenue, newCollateral))
 	}()
@@ -165,9 +184,11 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 	so.PotentialStorageRevenue = so.PotentialStorageRevenue.Add(storageRevenue)
 	so.RiskedCollateral = so.RiskedCollateral.Add(newCollateral)
 	so.PotentialUploadRevenue = so.PotentialUploadRevenue.Add(bandwidthRevenue)
+	so.UploadBytes += uploadBytes
 	so.RevisionTransactionSet = []types.Transaction{txn}
+	so.recordRevision(blockHeight)
 	h.mu.Lock()
-	err = h.modifyStorageObligation(*so, sectorsRemoved, sectorsGained, gainedSectorData)
+	err = h.modifyStorageObligation(*so, se, sectorsRemoved, sectorsGained, gainedSectorData)
 	h.mu.Unlock()
 	if err != nil {
 		modules.WriteNegotiationRejection(conn, err) // Error is ignored so that the error type can be preserved in extendErr.
@@ -17,8 +17,16 @@ func (h *Host) capacity() (total, remaining uint64) {
 	// folders.
 	sfs := h.StorageFolders()
 	for _, sf := range sfs {
+		// A storage folder should never have more remaining capacity than
+		// total capacity. If the accounting has drifted, clamp the remaining
+		// capacity so that the used storage does not wrap around.
+		sfRemaining := sf.CapacityRemaining
+		if sfRemaining > sf.Capacity {
+			h.log.Printf("WARN: storage folder %v reports %v bytes remaining out of a capacity of %v bytes, clamping", sf.Path, sfRemaining, sf.Capacity)
+			sfRemaining = sf.Capacity
+		}
 		total += sf.Capacity
-		remaining += sf.CapacityRemaining
+		remaining += sfRemaining
 	}
 	return total, remaining
 }
@@ -44,7 +52,7 @@ func (h *Host) externalSettings() modules.HostExternalSettings {
 	}
 
 	return modules.HostExternalSettings{
-		AcceptingContracts:   h.settings.AcceptingContracts,
+		AcceptingContracts:   h.settings.AcceptingContracts && !h.diskDegraded && !h.obligationLimitReached(),
 		MaxDownloadBatchSize: h.settings.MaxDownloadBatchSize,
 		MaxDuration:          h.settings.MaxDuration,
 		MaxReviseBatchSize:   h.settings.MaxReviseBatchSize,
@@ -65,6 +73,8 @@ func (h *Host) externalSettings() modules.HostExternalSettings {
 
 		RevisionNumber: h.revisionNumber,
 		Version:        build.Version,
+
+		StoragePriceTiers: append([]modules.HostStoragePriceTier(nil), h.settings.StoragePriceTiers...),
 	}
 }
 
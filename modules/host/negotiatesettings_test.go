@@ -0,0 +1,39 @@
+package host
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+)
+
+// folderStorageManager is a storage manager that only reports a fixed set of
+// storage folders.
+type folderStorageManager struct {
+	modules.StorageManager
+	folders []modules.StorageFolderMetadata
+}
+
+// StorageFolders returns the fixed set of storage folders.
+func (fsm folderStorageManager) StorageFolders() []modules.StorageFolderMetadata {
+	return fsm.folders
+}
+
+// TestCapacityClamp checks that a storage folder reporting more remaining
+// capacity than total capacity does not make the used storage wrap around.
+func TestCapacityClamp(t *testing.T) {
+	h := &Host{
+		StorageManager: folderStorageManager{
+			folders: []modules.StorageFolderMetadata{
+				{Capacity: 100, CapacityRemaining: 40},
+				{Capacity: 100, CapacityRemaining: 150},
+			},
+		},
+		log: persist.NewLogger(ioutil.Discard),
+	}
+	total, remaining := h.capacity()
+	if total != 200 || remaining != 140 {
+		t.Fatalf("expected 200 total and 140 remaining, got %v and %v", total, remaining)
+	}
+}
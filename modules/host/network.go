@@ -249,6 +249,12 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 	}
 	defer h.tg.Done()
 
+	// Refuse new RPCs while the host is shutting down.
+	if atomic.LoadUint64(&h.atomicDraining) == 1 {
+		conn.Close()
+		return
+	}
+
 	// Close the conn on host.Close or when the method terminates, whichever comes
 	// first.
 	connCloseChan := make(chan struct{})
@@ -277,6 +283,16 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 		return
 	}
 
+	// The renter limits are applied once the RPC has learned which renter is
+	// on the other end of the connection.
+	rc := &renterConn{Conn: conn}
+	defer func() {
+		if rc.release != nil {
+			rc.release()
+		}
+	}()
+	conn = rc
+
 	switch id {
 	case modules.RPCDownload:
 		atomic.AddUint64(&h.atomicDownloadCalls, 1)
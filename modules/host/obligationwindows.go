@@ -0,0 +1,133 @@
+package host
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// indexStorageObligationWindow adds a storage obligation to the proof window
+// index at the height where its proof window starts.
+func indexStorageObligationWindow(tx *bolt.Tx, windowStart types.BlockHeight, soid types.FileContractID) error {
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, uint64(windowStart))
+
+	bsow := tx.Bucket(bucketStorageObligationWindows)
+	existingIDs := bsow.Get(heightBytes)
+	for i := 0; i+crypto.HashSize <= len(existingIDs); i += crypto.HashSize {
+		if bytes.Equal(existingIDs[i:i+crypto.HashSize], soid[:]) {
+			return nil
+		}
+	}
+	extendedIDs := make([]byte, len(existingIDs), len(existingIDs)+len(soid[:]))
+	copy(extendedIDs, existingIDs)
+	extendedIDs = append(extendedIDs, soid[:]...)
+	return bsow.Put(heightBytes, extendedIDs)
+}
+
+// unindexStorageObligationWindow removes a storage obligation from the proof
+// window index. Removing an obligation that is not in the index is a no-op.
+func unindexStorageObligationWindow(tx *bolt.Tx, windowStart types.BlockHeight, soid types.FileContractID) error {
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, uint64(windowStart))
+
+	bsow := tx.Bucket(bucketStorageObligationWindows)
+	existingIDs := bsow.Get(heightBytes)
+	remainingIDs := make([]byte, 0, len(existingIDs))
+	for i := 0; i+crypto.HashSize <= len(existingIDs); i += crypto.HashSize {
+		if !bytes.Equal(existingIDs[i:i+crypto.HashSize], soid[:]) {
+			remainingIDs = append(remainingIDs, existingIDs[i:i+crypto.HashSize]...)
+		}
+	}
+	if len(remainingIDs) == len(existingIDs) {
+		return nil
+	}
+	if len(remainingIDs) == 0 {
+		return bsow.Delete(heightBytes)
+	}
+	return bsow.Put(heightBytes, remainingIDs)
+}
+
+// initStorageObligationWindows builds the proof window index from the
+// unresolved storage obligations in the database.
+func initStorageObligationWindows(tx *bolt.Tx) error {
+	return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+		var so storageObligation
+		err := json.Unmarshal(soBytes, &so)
+		if err != nil {
+			return err
+		}
+		if so.ObligationStatus != obligationUnresolved {
+			return nil
+		}
+		return indexStorageObligationWindow(tx, so.expiration(), so.id())
+	})
+}
+
+// storageObligationsInWindow returns every unresolved storage obligation whose
+// proof window overlaps the range of blocks [start, end]. The proof window
+// index is used so that only obligations with a window starting at or before
+// 'end' are examined.
+func (h *Host) storageObligationsInWindow(start, end types.BlockHeight) (sos []storageObligation, err error) {
+	if end < start {
+		return nil, nil
+	}
+	endBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(endBytes, uint64(end))
+
+	err = h.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketStorageObligationWindows).Cursor()
+		for k, ids := c.First(); k != nil && bytes.Compare(k, endBytes) <= 0; k, ids = c.Next() {
+			for i := 0; i+crypto.HashSize <= len(ids); i += crypto.HashSize {
+				var soid types.FileContractID
+				copy(soid[:], ids[i:i+crypto.HashSize])
+				so, err := getStorageObligation(tx, soid)
+				if err != nil {
+					return err
+				}
+				// The window starts before the end of the range, check that
+				// it does not end before the start of the range.
+				if so.proofDeadline() >= start {
+					sos = append(sos, so)
+				}
+			}
+		}
+		return nil
+	})
+	return sos, err
+}
+
+// overlapsMaintenance returns true if the proof window [windowStart,
+// windowEnd] overlaps the maintenance window in the provided settings. A
+// maintenance window that ends at height zero is not set.
+func overlapsMaintenance(settings modules.HostInternalSettings, windowStart, windowEnd types.BlockHeight) bool {
+	if settings.MaintenanceEnd == 0 {
+		return false
+	}
+	return windowStart <= settings.MaintenanceEnd && settings.MaintenanceStart <= windowEnd
+}
+
+// warnMaintenanceConflicts logs a warning for every unresolved storage
+// obligation that has its proof window inside the maintenance window of the
+// provided settings, as the host risks losing the collateral of those
+// obligations if it is offline during the maintenance.
+func (h *Host) warnMaintenanceConflicts(settings modules.HostInternalSettings) {
+	if settings.MaintenanceEnd == 0 {
+		return
+	}
+	sos, err := h.storageObligationsInWindow(settings.MaintenanceStart, settings.MaintenanceEnd)
+	if err != nil {
+		h.log.Println("Unable to check storage obligations against the maintenance window:", err)
+		return
+	}
+	for _, so := range sos {
+		h.logObligation(LogWarn, so.id(), nil, fmt.Sprintf("Storage obligation has a proof window from %v to %v, which overlaps the maintenance window from %v to %v", so.expiration(), so.proofDeadline(), settings.MaintenanceStart, settings.MaintenanceEnd))
+	}
+}
@@ -0,0 +1,118 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestStorageObligationsInWindow checks that storage obligations can be looked
+// up by the range of their proof windows, and that the index is kept up to date
+// when obligations are removed.
+func TestStorageObligationsInWindow(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestStorageObligationsInWindow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Add two storage obligations with different proof windows.
+	so1, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so1.id())
+	err = ht.host.managedAddStorageObligation(so1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so1.id())
+	for i := 0; i < 3; i++ {
+		_, err = ht.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	so2, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so2.id())
+	err = ht.host.managedAddStorageObligation(so2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so2.id())
+	if so1.expiration() == so2.expiration() {
+		t.Fatal("test obligations have the same proof window")
+	}
+
+	// A range covering both windows should return both obligations.
+	sos, err := ht.host.storageObligationsInWindow(0, so2.proofDeadline())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sos) != 2 {
+		t.Fatal("expected two obligations, got", len(sos))
+	}
+	// A range ending before the second window starts should only return the
+	// first obligation.
+	sos, err = ht.host.storageObligationsInWindow(0, so2.expiration()-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sos) != 1 || sos[0].id() != so1.id() {
+		t.Fatal("expected only the first obligation")
+	}
+	// A range starting after the first window ends should only return the
+	// second obligation.
+	sos, err = ht.host.storageObligationsInWindow(so1.proofDeadline()+1, so2.proofDeadline()+100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sos) != 1 || sos[0].id() != so2.id() {
+		t.Fatal("expected only the second obligation")
+	}
+
+	// Removed obligations should be dropped from the index.
+	ht.host.mu.Lock()
+	err = ht.host.removeStorageObligation(so1, obligationRejected)
+	ht.host.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sos, err = ht.host.storageObligationsInWindow(0, so2.proofDeadline())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sos) != 1 || sos[0].id() != so2.id() {
+		t.Fatal("removed obligation is still in the index")
+	}
+
+	// Rebuilding the index from scratch should give the same result.
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket(bucketStorageObligationWindows)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucket(bucketStorageObligationWindows)
+		if err != nil {
+			return err
+		}
+		return initStorageObligationWindows(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sos, err = ht.host.storageObligationsInWindow(0, so2.proofDeadline())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sos) != 1 || sos[0].id() != so2.id() {
+		t.Fatal("rebuilt index does not match")
+	}
+}
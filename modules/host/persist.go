@@ -23,12 +23,19 @@ type persistence struct {
 	// Host Identity.
 	Announced        bool                         `json:"announced"`
 	AutoAddress      modules.NetAddress           `json:"autoaddress"`
+	LastAnnouncement types.BlockHeight            `json:"lastannouncement"`
 	FinancialMetrics modules.HostFinancialMetrics `json:"financialmetrics"`
 	PublicKey        types.SiaPublicKey           `json:"publickey"`
 	RevisionNumber   uint64                       `json:"revisionnumber"`
 	SecretKey        crypto.SecretKey             `json:"secretkey"`
 	Settings         modules.HostInternalSettings `json:"settings"`
 	UnlockHash       types.UnlockHash             `json:"unlockhash"`
+
+	// Reliability Tracking.
+	RecentProofOutcomes []bool `json:"recentproofoutcomes"`
+
+	// Sector Encryption.
+	SectorKeys []sectorKey `json:"sectorkeys"`
 }
 
 // persistData returns the data in the Host that will be saved to disk.
@@ -41,12 +48,19 @@ func (h *Host) persistData() persistence {
 		// Host Identity.
 		Announced:        h.announced,
 		AutoAddress:      h.autoAddress,
+		LastAnnouncement: h.lastAnnouncement,
 		FinancialMetrics: h.financialMetrics,
 		PublicKey:        h.publicKey,
 		RevisionNumber:   h.revisionNumber,
 		SecretKey:        h.secretKey,
 		Settings:         h.settings,
 		UnlockHash:       h.unlockHash,
+
+		// Reliability Tracking.
+		RecentProofOutcomes: h.recentProofOutcomes,
+
+		// Sector Encryption.
+		SectorKeys: h.sectorKeys,
 	}
 }
 
@@ -55,9 +69,18 @@ func (h *Host) persistData() persistence {
 func (h *Host) establishDefaults() error {
 	// Configure the settings object.
 	h.settings = modules.HostInternalSettings{
+		MaxConcurrentProofs:  defaultMaxConcurrentProofs,
+		MaxConfirmationDelay: defaultMaxConfirmationDelay,
+		MaxContractRevisions: defaultMaxContractRevisions,
 		MaxDownloadBatchSize: uint64(defaultMaxDownloadBatchSize),
 		MaxDuration:          defaultMaxDuration,
+		MaxDiskLatency:       defaultMaxDiskLatency,
+		MaxObligations:       defaultMaxObligations,
+		MaxRenterRequestRate: defaultMaxRenterRequestRate,
+		MaxRenterSessions:    defaultMaxRenterSessions,
 		MaxReviseBatchSize:   uint64(defaultMaxReviseBatchSize),
+		ResubmissionJitter:   defaultResubmissionJitter,
+		ShutdownTimeout:      defaultShutdownTimeout,
 		WindowSize:           defaultWindowSize,
 
 		Collateral:       defaultCollateral,
@@ -92,6 +115,7 @@ func (h *Host) loadPersistObject(p *persistence) {
 
 	// Copy over host identity.
 	h.announced = p.Announced
+	h.lastAnnouncement = p.LastAnnouncement
 	h.autoAddress = p.AutoAddress
 	if err := p.AutoAddress.IsValid(); err != nil {
 		h.log.Printf("WARN: AutoAddress '%v' loaded from persist is invalid: %v", p.AutoAddress, err)
@@ -107,6 +131,12 @@ func (h *Host) loadPersistObject(p *persistence) {
 		h.settings.NetAddress = ""
 	}
 	h.unlockHash = p.UnlockHash
+
+	// Copy over reliability tracking.
+	h.recentProofOutcomes = p.RecentProofOutcomes
+
+	// Copy over sector encryption.
+	h.sectorKeys = p.SectorKeys
 }
 
 // initDB will check that the database has been initialized and if not, will
@@ -127,9 +157,12 @@ func (h *Host) initDB() (err error) {
 	return h.db.Update(func(tx *bolt.Tx) error {
 		// The storage obligation bucket does not exist, which means the
 		// database needs to be initialized. Create the database buckets.
+		buildWindowIndex := tx.Bucket(bucketStorageObligationWindows) == nil
 		buckets := [][]byte{
 			bucketActionItems,
+			bucketEncryptedSectors,
 			bucketStorageObligations,
+			bucketStorageObligationWindows,
 		}
 		for _, bucket := range buckets {
 			_, err := tx.CreateBucketIfNotExists(bucket)
@@ -137,6 +170,12 @@ func (h *Host) initDB() (err error) {
 				return err
 			}
 		}
+
+		// Databases created before the proof window index existed need to
+		// have the index built from the existing storage obligations.
+		if buildWindowIndex {
+			return initStorageObligationWindows(tx)
+		}
 		return nil
 	})
 }
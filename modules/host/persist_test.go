@@ -85,3 +85,41 @@ func TestHostAddressPersistence(t *testing.T) {
 		t.Error("User-set address does not seem to be persisting.")
 	}
 }
+
+// TestHostProofOutcomePersistence checks that the recent storage proof
+// outcomes of the host survive a restart.
+func TestHostProofOutcomePersistence(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record some outcomes and save the host.
+	ht.host.mu.Lock()
+	ht.host.recordProofOutcome(true)
+	ht.host.recordProofOutcome(false)
+	err = ht.host.saveSync()
+	ht.host.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reboot the host.
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify that the outcomes persisted.
+	fm := ht.host.FinancialMetrics()
+	if fm.RecentProofOutcomes != 2 || fm.RecentProofSuccessRate != 50 {
+		t.Error("recent proof outcomes did not persist:", fm.RecentProofOutcomes, fm.RecentProofSuccessRate)
+	}
+}
@@ -0,0 +1,57 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errDuplicateStoragePriceTier is returned if two storage price tiers
+	// apply to the same contract duration.
+	errDuplicateStoragePriceTier = errors.New("multiple storage price tiers have the same minimum duration")
+
+	// errZeroStoragePriceTier is returned if a storage price tier applies to
+	// contracts of any duration, which is what MinStoragePrice is for.
+	errZeroStoragePriceTier = errors.New("storage price tiers must have a nonzero minimum duration")
+)
+
+// sortStoragePriceTiers checks that the storage price tiers are valid and
+// returns a copy of them sorted by minimum duration.
+func sortStoragePriceTiers(tiers []modules.HostStoragePriceTier) ([]modules.HostStoragePriceTier, error) {
+	if len(tiers) > modules.MaxStoragePriceTiers {
+		return nil, fmt.Errorf("%v, at most %v are allowed", modules.ErrTooManyStoragePriceTiers, modules.MaxStoragePriceTiers)
+	}
+	if len(tiers) == 0 {
+		return nil, nil
+	}
+	sorted := append([]modules.HostStoragePriceTier(nil), tiers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].MinDuration < sorted[j].MinDuration
+	})
+	for i, tier := range sorted {
+		if tier.MinDuration == 0 {
+			return nil, errZeroStoragePriceTier
+		}
+		if i > 0 && tier.MinDuration == sorted[i-1].MinDuration {
+			return nil, errDuplicateStoragePriceTier
+		}
+	}
+	return sorted, nil
+}
+
+// storagePriceTiersEqual returns true if both sets of storage price tiers
+// charge the same prices.
+func storagePriceTiersEqual(a, b []modules.HostStoragePriceTier) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].MinDuration != b[i].MinDuration || !a[i].StoragePrice.Equals(b[i].StoragePrice) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestStoragePriceTiers checks that the host validates its storage price
+// tiers, advertises them to renters, and charges the tiered price when a
+// contract is renewed.
+func TestStoragePriceTiers(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester("TestStoragePriceTiers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Tiers without a duration or with the same duration are rejected.
+	settings := ht.host.InternalSettings()
+	for _, tiers := range [][]modules.HostStoragePriceTier{
+		{{MinDuration: 0, StoragePrice: types.NewCurrency64(2)}},
+		{{MinDuration: 10, StoragePrice: types.NewCurrency64(2)}, {MinDuration: 10, StoragePrice: types.NewCurrency64(3)}},
+	} {
+		settings.StoragePriceTiers = tiers
+		if err := ht.host.SetInternalSettings(settings); err == nil {
+			t.Fatal("invalid storage price tiers were accepted:", tiers)
+		}
+	}
+
+	// Valid tiers are sorted and advertised.
+	settings.MinStoragePrice = types.NewCurrency64(1)
+	settings.StoragePriceTiers = []modules.HostStoragePriceTier{
+		{MinDuration: 200, StoragePrice: types.NewCurrency64(3)},
+		{MinDuration: 100, StoragePrice: types.NewCurrency64(2)},
+	}
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tiers := ht.host.ExternalSettings().StoragePriceTiers
+	if len(tiers) != 2 || tiers[0].MinDuration != 100 || tiers[1].MinDuration != 200 {
+		t.Fatal("storage price tiers were not advertised in order:", tiers)
+	}
+
+	// Renewing a contract for 100 blocks charges the price of the first tier
+	// for the time extension.
+	es := ht.host.ExternalSettings()
+	so := storageObligation{
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{WindowEnd: 50}},
+		}},
+	}
+	fc := types.FileContract{FileSize: 10, WindowEnd: 110}
+	if price := renewBasePrice(so, es, fc, 10); !price.Equals64(2 * 10 * 60) {
+		t.Fatal("wrong renewal price:", price)
+	}
+	if price := renewBasePrice(so, es, fc, 11); !price.Equals64(1 * 10 * 60) {
+		t.Fatal("wrong renewal price:", price)
+	}
+	so.NegotiationHeight = 10
+	if so.duration() != 40 {
+		t.Fatal("wrong storage obligation duration:", so.duration())
+	}
+}
@@ -0,0 +1,206 @@
+package host
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// proofBatcher collects the storage proofs that the host builds at about the
+// same time, so that they can be submitted in a single transaction that pays a
+// single transaction fee. A transaction is only valid if every storage proof
+// in it is valid, so the proofs of a batch are submitted individually if the
+// batch is rejected.
+//
+// Proofs are added to a batch after their slot in the proof queue has been
+// released, so the batcher keeps track of the proofs that have been built but
+// not yet submitted, allowing the host to wait for them when shutting down.
+type proofBatcher struct {
+	current  *proofBatch
+	inFlight uint64
+	idle     []chan struct{}
+	flush    chan struct{}
+	flushed  bool
+	mu       sync.Mutex
+}
+
+// proofBatch is a set of storage proofs that are submitted together. The
+// first proof added to a batch makes its caller the leader of the batch, which
+// submits the batch once proofBatchDelay has passed. The results are available
+// once done is closed.
+type proofBatch struct {
+	proofs []types.StorageProof
+	size   uint64
+
+	fees []types.Currency
+	errs []error
+	done chan struct{}
+}
+
+// managedAdd adds a storage proof to the current batch, starting a new batch if
+// there is none or if the proof does not fit into the current batch. The index
+// of the proof within the batch is returned, along with whether the caller is
+// the leader of the batch.
+func (pb *proofBatcher) managedAdd(sp types.StorageProof) (batch *proofBatch, index int, leader bool) {
+	size := uint64(len(encoding.Marshal(sp)))
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if pb.current == nil || pb.current.size+size > maxProofBatchSize {
+		pb.current = &proofBatch{
+			done: make(chan struct{}),
+		}
+		leader = true
+	}
+	batch = pb.current
+	batch.proofs = append(batch.proofs, sp)
+	batch.size += size
+	return batch, len(batch.proofs) - 1, leader
+}
+
+// flushChan returns the channel that is closed once the batches should be
+// submitted without waiting for proofBatchDelay.
+func (pb *proofBatcher) flushChan() chan struct{} {
+	if pb.flush == nil {
+		pb.flush = make(chan struct{})
+	}
+	return pb.flush
+}
+
+// notifyIdle wakes up the threads waiting for the batcher to become idle if
+// no built storage proofs are waiting to be submitted.
+func (pb *proofBatcher) notifyIdle() {
+	if pb.inFlight != 0 {
+		return
+	}
+	for _, c := range pb.idle {
+		close(c)
+	}
+	pb.idle = nil
+}
+
+// managedStart marks a built storage proof as waiting to be submitted. It
+// should be called before the slot of the proof in the proof queue is
+// released, so that the proof is never untracked while it is in progress.
+func (pb *proofBatcher) managedStart() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.inFlight++
+}
+
+// managedFinish marks a storage proof started with managedStart as submitted.
+func (pb *proofBatcher) managedFinish() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.inFlight--
+	pb.notifyIdle()
+}
+
+// managedFlush makes the leaders of the open batches, as well as the leaders
+// of any batches started later on, submit their batch right away.
+func (pb *proofBatcher) managedFlush() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if !pb.flushed {
+		close(pb.flushChan())
+		pb.flushed = true
+	}
+}
+
+// managedFlushChan returns the channel that is closed by managedFlush.
+func (pb *proofBatcher) managedFlushChan() <-chan struct{} {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.flushChan()
+}
+
+// managedIdle returns a channel that is closed once no built storage proofs
+// are waiting to be submitted.
+func (pb *proofBatcher) managedIdle() <-chan struct{} {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	c := make(chan struct{})
+	pb.idle = append(pb.idle, c)
+	pb.notifyIdle()
+	return c
+}
+
+// managedClose stops proofs from being added to the batch.
+func (pb *proofBatcher) managedClose(batch *proofBatch) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if pb.current == batch {
+		pb.current = nil
+	}
+}
+
+// splitProofFee splits the transaction fee of a batch of storage proofs
+// evenly between the proofs. The remainder of the split is added to the last
+// proof, so that the shares add up to the fee.
+func splitProofFee(fee types.Currency, n int) []types.Currency {
+	share := fee.Div64(uint64(n))
+	fees := make([]types.Currency, n)
+	for i := range fees {
+		fees[i] = share
+	}
+	fees[n-1] = fee.Sub(share.Mul64(uint64(n - 1)))
+	return fees
+}
+
+// managedSubmitProofBatch submits the storage proofs of a batch in a single
+// transaction. If the transaction is rejected, every proof is submitted in a
+// transaction of its own, so that a single invalid proof does not hold back
+// the others.
+func (h *Host) managedSubmitProofBatch(batch *proofBatch) {
+	n := len(batch.proofs)
+	batch.fees = make([]types.Currency, n)
+	batch.errs = make([]error, n)
+	defer close(batch.done)
+
+	if n > 1 {
+		fee, err := h.managedSubmitStorageProofs(batch.proofs)
+		if err == nil {
+			h.log.Debugf("Submitted %v storage proofs in a single transaction\n", n)
+			batch.fees = splitProofFee(fee, n)
+			return
+		}
+		h.log.Debugf("Unable to submit a batch of %v storage proofs, submitting them individually: %v\n", n, err)
+	}
+	for i, sp := range batch.proofs {
+		batch.fees[i], batch.errs[i] = h.managedSubmitStorageProofs([]types.StorageProof{sp})
+	}
+}
+
+// managedSubmitBatchedStorageProof submits the storage proof of a storage
+// obligation together with the other storage proofs that are built within
+// proofBatchDelay, returning the share of the transaction fee that was paid
+// for the proof. Proofs whose window closes within proofBatchDeadlineBuffer
+// blocks, and proofs built while the host is shutting down, are submitted
+// right away in a transaction of their own.
+//
+// The proof must have been registered with managedStart, the registration is
+// finished once the proof has been submitted.
+func (h *Host) managedSubmitBatchedStorageProof(so storageObligation, sp types.StorageProof) (types.Currency, error) {
+	defer h.proofBatcher.managedFinish()
+	h.mu.RLock()
+	blockHeight := h.blockHeight
+	h.mu.RUnlock()
+	if so.proofDeadline() <= blockHeight+proofBatchDeadlineBuffer || atomic.LoadUint64(&h.atomicDraining) == 1 {
+		return h.managedSubmitStorageProofs([]types.StorageProof{sp})
+	}
+
+	batch, index, leader := h.proofBatcher.managedAdd(sp)
+	if leader {
+		select {
+		case <-time.After(proofBatchDelay):
+		case <-h.proofBatcher.managedFlushChan():
+		case <-h.tg.StopChan():
+		}
+		h.proofBatcher.managedClose(batch)
+		h.managedSubmitProofBatch(batch)
+	}
+	<-batch.done
+	return batch.fees[index], batch.errs[index]
+}
@@ -0,0 +1,161 @@
+package host
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestProofBatcherAdd checks that storage proofs are added to the current
+// batch until the batch is closed or full.
+func TestProofBatcherAdd(t *testing.T) {
+	var pb proofBatcher
+	sp := types.StorageProof{HashSet: make([]crypto.Hash, 8)}
+
+	first, index, leader := pb.managedAdd(sp)
+	if !leader || index != 0 {
+		t.Fatal("first proof should lead a new batch:", index, leader)
+	}
+	batch, index, leader := pb.managedAdd(sp)
+	if leader || batch != first || index != 1 {
+		t.Fatal("second proof should join the current batch:", index, leader)
+	}
+
+	// Once the leader closes the batch, the next proof starts a new one.
+	pb.managedClose(first)
+	batch, index, leader = pb.managedAdd(sp)
+	if !leader || batch == first || index != 0 {
+		t.Fatal("proof should lead a new batch after the batch was closed:", index, leader)
+	}
+
+	// A proof that doesn't fit into the current batch starts a new one.
+	size := uint64(len(encoding.Marshal(sp)))
+	for batch.size+size <= maxProofBatchSize {
+		pb.managedAdd(sp)
+	}
+	full := batch
+	batch, _, leader = pb.managedAdd(sp)
+	if !leader || batch == full {
+		t.Fatal("proof should lead a new batch once the batch is full")
+	}
+	if len(full.proofs) < 2 || full.size > maxProofBatchSize {
+		t.Fatal("batch has the wrong size:", len(full.proofs), full.size)
+	}
+}
+
+// TestSplitProofFee checks that the fee of a batch of storage proofs is split
+// between the proofs without losing any hastings.
+func TestSplitProofFee(t *testing.T) {
+	fees := splitProofFee(types.NewCurrency64(10), 3)
+	if len(fees) != 3 || !fees[0].Equals64(3) || !fees[1].Equals64(3) || !fees[2].Equals64(4) {
+		t.Fatal("wrong fee split:", fees)
+	}
+	fees = splitProofFee(types.NewCurrency64(7), 1)
+	if len(fees) != 1 || !fees[0].Equals64(7) {
+		t.Fatal("wrong fee split:", fees)
+	}
+}
+
+// TestProofBatchConcurrencyLimit checks that storage proofs which are due at
+// the same time end up in a single batch even if the host builds fewer proofs
+// at once than there are proofs due.
+func TestProofBatchConcurrencyLimit(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestProofBatchConcurrencyLimit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+	ht.host.mu.Lock()
+	ht.host.settings.MaxConcurrentProofs = 1
+	ht.host.mu.Unlock()
+
+	// Add storage obligations with a sector each, all of which are due for a
+	// storage proof at the same height. The proof windows are long enough for
+	// the proofs to be batched.
+	const numObligations = 3
+	var expiration types.BlockHeight
+	for i := 0; i < numObligations; i++ {
+		so, err := ht.newTesterStorageObligation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ht.host.managedLockStorageObligation(so.id())
+		err = ht.host.managedAddStorageObligation(so)
+		ht.host.managedUnlockStorageObligation(so.id())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sectorRoot, sectorData := randSector()
+		so.SectorRoots = []crypto.Hash{sectorRoot}
+		sectorCost := types.SiacoinPrecision.Mul64(550)
+		so.PotentialStorageRevenue = so.PotentialStorageRevenue.Add(sectorCost)
+		validPayouts, missedPayouts := so.payouts()
+		validPayouts[0].Value = validPayouts[0].Value.Sub(sectorCost)
+		validPayouts[1].Value = validPayouts[1].Value.Add(sectorCost)
+		missedPayouts[0].Value = missedPayouts[0].Value.Sub(sectorCost)
+		missedPayouts[1].Value = missedPayouts[1].Value.Add(sectorCost)
+		revisionSet := []types.Transaction{{
+			FileContractRevisions: []types.FileContractRevision{{
+				ParentID:          so.id(),
+				UnlockConditions:  types.UnlockConditions{},
+				NewRevisionNumber: 1,
+
+				NewFileSize:           uint64(len(sectorData)),
+				NewFileMerkleRoot:     sectorRoot,
+				NewWindowStart:        so.expiration(),
+				NewWindowEnd:          so.proofDeadline() + 2*proofBatchDeadlineBuffer,
+				NewValidProofOutputs:  validPayouts,
+				NewMissedProofOutputs: missedPayouts,
+				NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
+			}},
+		}}
+		so.RevisionTransactionSet = revisionSet
+		ht.host.managedLockStorageObligation(so.id())
+		err = ht.host.modifyStorageObligation(so, sectorEncryption{}, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+		ht.host.managedUnlockStorageObligation(so.id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ht.tpool.AcceptTransactionSet(revisionSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expiration = so.expiration()
+	}
+
+	// Mine until the host submits the storage proofs.
+	for ht.host.blockHeight <= expiration+resubmissionTimeout {
+		_, err := ht.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// All of the proofs should be submitted in a single transaction.
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		var proofTxns, proofs int
+		for _, txn := range ht.tpool.TransactionList() {
+			if len(txn.StorageProofs) > 0 {
+				proofTxns++
+				proofs += len(txn.StorageProofs)
+			}
+		}
+		if proofTxns != 1 || proofs != numObligations {
+			return fmt.Errorf("expected %v storage proofs in 1 transaction, got %v in %v", numObligations, proofs, proofTxns)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,160 @@
+package host
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// proofQueue limits the number of storage proofs that the host builds at the
+// same time. Storage proofs which have to wait for a slot are admitted in
+// order of their proof deadline, so that the obligations with the least slack
+// left are served first.
+type proofQueue struct {
+	active  uint64
+	waiting []proofQueueEntry
+	idle    []chan struct{}
+	mu      sync.Mutex
+}
+
+// proofQueueEntry is a storage proof that is waiting for a slot in the proof
+// queue.
+type proofQueueEntry struct {
+	deadline types.BlockHeight
+	ready    chan struct{}
+}
+
+// admit hands out free slots to the waiting storage proofs with the earliest
+// deadlines.
+func (pq *proofQueue) admit(limit uint64) {
+	for pq.active < limit && len(pq.waiting) > 0 {
+		close(pq.waiting[0].ready)
+		pq.waiting = pq.waiting[1:]
+		pq.active++
+	}
+}
+
+// notifyIdle wakes up the threads waiting for the queue to become idle if no
+// storage proofs are being built or waiting to be built.
+func (pq *proofQueue) notifyIdle() {
+	if pq.active != 0 || len(pq.waiting) != 0 {
+		return
+	}
+	for _, c := range pq.idle {
+		close(c)
+	}
+	pq.idle = nil
+}
+
+// managedAcquire blocks until the caller may build a storage proof for an
+// obligation with the given proof deadline. At most 'limit' storage proofs are
+// built at once. False is returned if 'stop' is closed before a slot became
+// available, in which case managedRelease should not be called.
+func (pq *proofQueue) managedAcquire(deadline types.BlockHeight, limit uint64, stop <-chan struct{}) bool {
+	pq.mu.Lock()
+	if pq.active < limit && len(pq.waiting) == 0 {
+		pq.active++
+		pq.mu.Unlock()
+		return true
+	}
+	entry := proofQueueEntry{
+		deadline: deadline,
+		ready:    make(chan struct{}),
+	}
+	i := sort.Search(len(pq.waiting), func(i int) bool {
+		return pq.waiting[i].deadline > deadline
+	})
+	pq.waiting = append(pq.waiting, proofQueueEntry{})
+	copy(pq.waiting[i+1:], pq.waiting[i:])
+	pq.waiting[i] = entry
+	pq.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return true
+	case <-stop:
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	for j := range pq.waiting {
+		if pq.waiting[j].ready == entry.ready {
+			pq.waiting = append(pq.waiting[:j], pq.waiting[j+1:]...)
+			pq.notifyIdle()
+			return false
+		}
+	}
+	// The slot was handed out at the same time as the stop signal arrived,
+	// give it back.
+	pq.active--
+	pq.admit(limit)
+	pq.notifyIdle()
+	return false
+}
+
+// managedRelease returns a slot acquired through managedAcquire to the queue.
+func (pq *proofQueue) managedRelease(limit uint64) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	pq.active--
+	pq.admit(limit)
+	pq.notifyIdle()
+}
+
+// managedIdle returns a channel that is closed once no storage proofs are
+// being built or waiting to be built.
+func (pq *proofQueue) managedIdle() <-chan struct{} {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	c := make(chan struct{})
+	pq.idle = append(pq.idle, c)
+	pq.notifyIdle()
+	return c
+}
+
+// managedMaxConcurrentProofs returns the number of storage proofs that the
+// host is allowed to build at the same time.
+func (h *Host) managedMaxConcurrentProofs() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.settings.MaxConcurrentProofs == 0 {
+		return defaultMaxConcurrentProofs
+	}
+	return h.settings.MaxConcurrentProofs
+}
+
+// managedDrain stops the host from accepting new RPCs and storage
+// obligations, and then waits for the storage proofs that are being built or
+// waiting to be built to be submitted. Proofs are only built once the proof
+// window of their obligation has opened, so obligations with proof windows
+// further out do not hold up the shutdown. The wait is bounded by the shutdown
+// timeout of the host.
+func (h *Host) managedDrain() {
+	if !atomic.CompareAndSwapUint64(&h.atomicDraining, 0, 1) {
+		return
+	}
+	h.mu.RLock()
+	timeout := h.settings.ShutdownTimeout
+	h.mu.RUnlock()
+	if timeout == 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	// Proofs that have been built are submitted without waiting for the rest
+	// of their batch.
+	h.proofBatcher.managedFlush()
+	deadline := time.After(time.Duration(timeout) * time.Second)
+	for _, idle := range []func() <-chan struct{}{h.proofQueue.managedIdle, h.proofBatcher.managedIdle} {
+		select {
+		case <-idle():
+		case <-h.tg.StopChan():
+			return
+		case <-deadline:
+			h.log.Println("WARN: shutdown timeout reached with storage proofs still in progress")
+			return
+		}
+	}
+}
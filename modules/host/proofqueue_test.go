@@ -0,0 +1,121 @@
+package host
+
+import (
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestProofQueue checks that the proof queue respects its limit and admits
+// waiting storage proofs in order of their deadline.
+func TestProofQueue(t *testing.T) {
+	var pq proofQueue
+	stop := make(chan struct{})
+	if !pq.managedAcquire(100, 1, stop) {
+		t.Fatal("could not acquire a slot from an empty queue")
+	}
+
+	// Queue two proofs, the later deadline first.
+	order := make(chan types.BlockHeight, 2)
+	for i, deadline := range []types.BlockHeight{20, 10} {
+		go func(deadline types.BlockHeight) {
+			if pq.managedAcquire(deadline, 1, stop) {
+				order <- deadline
+				pq.managedRelease(1)
+			}
+		}(deadline)
+		for {
+			pq.mu.Lock()
+			queued := len(pq.waiting)
+			pq.mu.Unlock()
+			if queued == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Releasing the slot should admit the earliest deadline first.
+	pq.managedRelease(1)
+	if first, second := <-order, <-order; first != 10 || second != 20 {
+		t.Fatal("proofs were admitted in the wrong order:", first, second)
+	}
+
+	// A waiting proof should give up when stop is closed.
+	if !pq.managedAcquire(100, 1, stop) {
+		t.Fatal("could not acquire a slot from an empty queue")
+	}
+	close(stop)
+	if pq.managedAcquire(10, 1, stop) {
+		t.Error("proof was admitted past the limit")
+	}
+	pq.managedRelease(1)
+	if pq.active != 0 || len(pq.waiting) != 0 {
+		t.Error("queue was not emptied:", pq.active, len(pq.waiting))
+	}
+}
+
+// TestHostDrain checks that a host which is shutting down refuses new storage
+// obligations and waits for the storage proofs in progress before stopping.
+func TestHostDrain(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestHostDrain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Take a slot in the proof queue to emulate a storage proof in progress.
+	limit := ht.host.managedMaxConcurrentProofs()
+	if !ht.host.proofQueue.managedAcquire(100, limit, ht.host.tg.StopChan()) {
+		t.Fatal("could not acquire a slot from an empty queue")
+	}
+	closed := make(chan error)
+	go func() {
+		closed <- ht.host.Close()
+	}()
+	for atomic.LoadUint64(&ht.host.atomicDraining) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// New storage obligations should be refused.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != errHostShuttingDown {
+		t.Fatal("expected errHostShuttingDown, got", err)
+	}
+
+	// The host should not stop until the proof is done.
+	select {
+	case <-closed:
+		t.Fatal("host stopped while a storage proof was in progress")
+	case <-time.After(100 * time.Millisecond):
+	}
+	ht.host.proofQueue.managedRelease(limit)
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("host did not stop after the storage proof was done")
+	}
+
+	// Reopen the host so that ht.Close() succeeds.
+	ht.host, err = newHost(modules.ProdDependencies, ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+package host
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// errZeroDuration is returned when a quote is requested for a file contract
+// with a duration of zero blocks.
+var errZeroDuration = errors.New("contract duration must be nonzero")
+
+// sectorUploadPrices returns the storage revenue, upload bandwidth revenue and
+// collateral of uploading a full sector to a storage obligation. The contract
+// lasts 'duration' blocks in total, and its proof window ends in
+// 'blocksRemaining' blocks.
+func sectorUploadPrices(settings modules.HostExternalSettings, duration, blocksRemaining types.BlockHeight) (storageRevenue, bandwidthRevenue, collateral types.Currency) {
+	blockBytesCurrency := types.NewCurrency64(uint64(blocksRemaining)).Mul64(modules.SectorSize)
+	storageRevenue = settings.StoragePriceForDuration(duration).Mul(blockBytesCurrency)
+	bandwidthRevenue = settings.UploadBandwidthPrice.Mul64(modules.SectorSize)
+	collateral = settings.Collateral.Mul(blockBytesCurrency)
+	return
+}
+
+// QuoteContract returns the price that the host would charge for a file
+// contract that stores 'fileSize' bytes until the end of a proof window
+// 'duration' blocks from now, with 'collateral' locked by the host. The data
+// is priced as if it were uploaded right after the contract is formed, using
+// the same prices as contract formation and revision, and rounded up to full
+// sectors. If collateral is zero, the collateral that the host would risk on
+// the data is used. An error is returned if the host would reject the
+// contract. No storage obligation is created.
+func (h *Host) QuoteContract(fileSize uint64, duration types.BlockHeight, collateral types.Currency) (modules.HostContractQuote, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostContractQuote{}, err
+	}
+	defer h.tg.Done()
+
+	h.mu.Lock()
+	blockHeight := h.blockHeight
+	eSettings := h.externalSettings()
+	iSettings := h.settings
+	lockedStorageCollateral := h.financialMetrics.LockedStorageCollateral
+	h.mu.Unlock()
+	_, remaining := h.capacity()
+
+	// Check the contract against the same limits as contract formation.
+	windowEnd := blockHeight + duration
+	windowStart := windowEnd - eSettings.WindowSize
+	switch {
+	case duration == 0:
+		return modules.HostContractQuote{}, errZeroDuration
+	case duration > eSettings.MaxDuration:
+		return modules.HostContractQuote{}, errLongDuration
+	case duration < iSettings.MinDuration:
+		return modules.HostContractQuote{}, errShortDuration
+	case duration < eSettings.WindowSize || windowStart <= blockHeight+revisionSubmissionBuffer:
+		return modules.HostContractQuote{}, errEarlyWindow
+	case overlapsMaintenance(iSettings, windowStart, windowEnd):
+		return modules.HostContractQuote{}, errMaintenanceWindow
+	}
+	sectors := fileSize / modules.SectorSize
+	if fileSize%modules.SectorSize != 0 {
+		sectors++
+	}
+	if sectors*modules.SectorSize > remaining {
+		return modules.HostContractQuote{}, errInsufficientStorage
+	}
+
+	storageRevenue, bandwidthRevenue, riskedCollateral := sectorUploadPrices(eSettings, duration, duration)
+	storageRevenue = storageRevenue.Mul64(sectors)
+	bandwidthRevenue = bandwidthRevenue.Mul64(sectors)
+	riskedCollateral = riskedCollateral.Mul64(sectors)
+	if collateral.IsZero() {
+		collateral = riskedCollateral
+	} else if riskedCollateral.Cmp(collateral) > 0 {
+		// The host never risks more collateral than is in the contract.
+		riskedCollateral = collateral
+	}
+	if collateral.Cmp(eSettings.MaxCollateral) > 0 {
+		return modules.HostContractQuote{}, errMaxCollateralReached
+	}
+	if lockedStorageCollateral.Add(collateral).Cmp(iSettings.CollateralBudget) > 0 {
+		return modules.HostContractQuote{}, errCollateralBudgetExceeded
+	}
+
+	so := storageObligation{
+		ContractCost:            eSettings.ContractPrice,
+		PotentialStorageRevenue: storageRevenue,
+		PotentialUploadRevenue:  bandwidthRevenue,
+		RiskedCollateral:        riskedCollateral,
+	}
+	return modules.HostContractQuote{
+		FileSize: fileSize,
+		Duration: duration,
+
+		ContractPrice:    eSettings.ContractPrice,
+		StorageRevenue:   storageRevenue,
+		UploadRevenue:    bandwidthRevenue,
+		Collateral:       collateral,
+		RiskedCollateral: riskedCollateral,
+		RenterCost:       eSettings.ContractPrice.Add(storageRevenue).Add(bandwidthRevenue),
+		Value:            so.value(),
+	}, nil
+}
@@ -0,0 +1,70 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestQuoteContract checks that contract quotes use the prices of the host
+// and respect the limits of contract formation.
+func TestQuoteContract(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestQuoteContract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	es := ht.host.ExternalSettings()
+	duration := es.WindowSize + revisionSubmissionBuffer + 10
+	q, err := ht.host.QuoteContract(modules.SectorSize+1, duration, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file is rounded up to two sectors.
+	storage, bandwidth, collateral := sectorUploadPrices(es, duration, duration)
+	if !q.StorageRevenue.Equals(storage.Mul64(2)) || !q.UploadRevenue.Equals(bandwidth.Mul64(2)) || !q.RiskedCollateral.Equals(collateral.Mul64(2)) {
+		t.Fatal("wrong quote:", q)
+	}
+	if !q.Collateral.Equals(q.RiskedCollateral) {
+		t.Fatal("collateral should default to the risked collateral:", q.Collateral, q.RiskedCollateral)
+	}
+	if !q.RenterCost.Equals(es.ContractPrice.Add(q.StorageRevenue).Add(q.UploadRevenue)) {
+		t.Fatal("wrong renter cost:", q.RenterCost)
+	}
+	if !q.Value.Equals(q.RenterCost.Add(q.RiskedCollateral)) {
+		t.Fatal("wrong value:", q.Value)
+	}
+
+	// The host never risks more than the collateral in the contract.
+	q, err = ht.host.QuoteContract(modules.SectorSize, duration, types.NewCurrency64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Collateral.Equals64(1) || !q.RiskedCollateral.Equals64(1) {
+		t.Fatal("risked collateral exceeds the collateral:", q.Collateral, q.RiskedCollateral)
+	}
+
+	// Contracts that the host would reject are not quoted.
+	if _, err := ht.host.QuoteContract(0, 0, types.ZeroCurrency); err != errZeroDuration {
+		t.Fatal("expected errZeroDuration, got", err)
+	}
+	if _, err := ht.host.QuoteContract(0, es.MaxDuration+1, types.ZeroCurrency); err != errLongDuration {
+		t.Fatal("expected errLongDuration, got", err)
+	}
+	if _, err := ht.host.QuoteContract(0, es.WindowSize, types.ZeroCurrency); err != errEarlyWindow {
+		t.Fatal("expected errEarlyWindow, got", err)
+	}
+	if _, err := ht.host.QuoteContract(0, duration, es.MaxCollateral.Add(types.NewCurrency64(1))); err != errMaxCollateralReached {
+		t.Fatal("expected errMaxCollateralReached, got", err)
+	}
+	if _, err := ht.host.QuoteContract(1<<62, duration, types.ZeroCurrency); err != errInsufficientStorage {
+		t.Fatal("expected errInsufficientStorage, got", err)
+	}
+}
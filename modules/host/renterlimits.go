@@ -0,0 +1,146 @@
+package host
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errRenterBusy is returned to a renter that has too many open sessions
+	// with the host, or that has been sending RPCs faster than the host
+	// allows.
+	errRenterBusy = ErrorCommunication("host is busy, too many requests from this renter")
+)
+
+// renterLimiter tracks the number of open sessions and the recent RPCs of each
+// renter that the host is talking to. The request rate is limited using a
+// token bucket per renter which holds up to a minute worth of requests, so
+// that short bursts are allowed as long as the average rate stays below the
+// limit.
+type renterLimiter struct {
+	renters map[string]*renterUsage
+	mu      sync.Mutex
+}
+
+// renterUsage is the usage of the host by a single renter.
+type renterUsage struct {
+	sessions   uint64
+	tokens     float64
+	lastRefill time.Time
+}
+
+// refill adds the tokens that have been earned since the last refill to the
+// bucket of the renter.
+func (ru *renterUsage) refill(rate float64, now time.Time) {
+	ru.tokens += rate * now.Sub(ru.lastRefill).Minutes()
+	if ru.tokens > rate {
+		ru.tokens = rate
+	}
+	ru.lastRefill = now
+}
+
+// prune removes the renters that have no open sessions and a full bucket, as
+// they are indistinguishable from renters that the host has never seen.
+func (rl *renterLimiter) prune(rate float64, now time.Time) {
+	for key, ru := range rl.renters {
+		ru.refill(rate, now)
+		if ru.sessions == 0 && ru.tokens >= rate {
+			delete(rl.renters, key)
+		}
+	}
+}
+
+// managedAdmit opens a session for a renter and charges it for a single RPC.
+// If the renter is at its session limit or has run out of requests, no
+// session is opened and errRenterBusy is returned. Otherwise the session must
+// be closed with managedRelease.
+func (rl *renterLimiter) managedAdmit(renter types.SiaPublicKey, maxSessions, maxRate uint64) error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rate := float64(maxRate)
+	if len(rl.renters) >= renterLimiterPruneSize {
+		rl.prune(rate, now)
+	}
+	if rl.renters == nil {
+		rl.renters = make(map[string]*renterUsage)
+	}
+	key := renter.String()
+	ru, exists := rl.renters[key]
+	if !exists {
+		ru = &renterUsage{
+			tokens:     rate,
+			lastRefill: now,
+		}
+		rl.renters[key] = ru
+	}
+	ru.refill(rate, now)
+	if ru.sessions >= maxSessions || ru.tokens < 1 {
+		return errRenterBusy
+	}
+	ru.sessions++
+	ru.tokens--
+	return nil
+}
+
+// managedRelease closes a session opened with managedAdmit.
+func (rl *renterLimiter) managedRelease(renter types.SiaPublicKey) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if ru, exists := rl.renters[renter.String()]; exists && ru.sessions > 0 {
+		ru.sessions--
+	}
+}
+
+// renterConn is a connection to a renter. The session that the renter holds
+// with the host is released when the connection has been handled.
+type renterConn struct {
+	net.Conn
+	release func()
+}
+
+// managedRenterLimits returns the maximum number of sessions and the maximum
+// number of RPCs per minute allowed for a single renter. Renters that have
+// locked up at least TrustedRenterCollateral in a contract with the host are
+// given a larger allowance.
+func (h *Host) managedRenterLimits(lockedCollateral types.Currency) (maxSessions, maxRate uint64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	maxSessions, maxRate = h.settings.MaxRenterSessions, h.settings.MaxRenterRequestRate
+	if maxSessions == 0 {
+		maxSessions = defaultMaxRenterSessions
+	}
+	if maxRate == 0 {
+		maxRate = defaultMaxRenterRequestRate
+	}
+	trusted := h.settings.TrustedRenterCollateral
+	if !trusted.IsZero() && lockedCollateral.Cmp(trusted) >= 0 {
+		maxSessions *= trustedRenterLimitMultiplier
+		maxRate *= trustedRenterLimitMultiplier
+	}
+	return maxSessions, maxRate
+}
+
+// managedLimitRenter opens a session for the renter on the connection. If the
+// renter is over its limits, the RPC is rejected with errRenterBusy. The
+// session stays open until the connection has been handled.
+func (h *Host) managedLimitRenter(conn net.Conn, renter types.SiaPublicKey, lockedCollateral types.Currency) error {
+	rc, ok := conn.(*renterConn)
+	if !ok || rc.release != nil {
+		// The connection is either not subject to the renter limits, or the
+		// renter already holds a session on it.
+		return nil
+	}
+	maxSessions, maxRate := h.managedRenterLimits(lockedCollateral)
+	if err := h.renterLimiter.managedAdmit(renter, maxSessions, maxRate); err != nil {
+		modules.WriteNegotiationRejection(conn, err) // Error ignored to preserve type in extendErr
+		return err
+	}
+	rc.release = func() { h.renterLimiter.managedRelease(renter) }
+	return nil
+}
@@ -0,0 +1,126 @@
+package host
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// randRenterKey returns a random renter public key.
+func randRenterKey() types.SiaPublicKey {
+	return types.SiaPublicKey{
+		Algorithm: types.SignatureEd25519,
+		Key:       fastrand.Bytes(32),
+	}
+}
+
+// TestRenterLimiter checks that the renter limiter enforces the session and
+// request rate limits of each renter independently.
+func TestRenterLimiter(t *testing.T) {
+	var rl renterLimiter
+	renter, other := randRenterKey(), randRenterKey()
+
+	// The renter can open two sessions, but not a third.
+	for i := 0; i < 2; i++ {
+		if err := rl.managedAdmit(renter, 2, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rl.managedAdmit(renter, 2, 10); err != errRenterBusy {
+		t.Fatal("expected errRenterBusy, got", err)
+	}
+	// Another renter is not affected.
+	if err := rl.managedAdmit(other, 2, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once a session is closed, the renter can open another until it runs
+	// out of requests.
+	for i := 0; i < 8; i++ {
+		rl.managedRelease(renter)
+		if err := rl.managedAdmit(renter, 2, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rl.managedRelease(renter)
+	if err := rl.managedAdmit(renter, 2, 10); err != errRenterBusy {
+		t.Fatal("expected errRenterBusy, got", err)
+	}
+
+	// Renters without sessions and with a full bucket are pruned.
+	rl.managedRelease(other)
+	rl.prune(10, time.Now().Add(time.Minute))
+	if _, exists := rl.renters[other.String()]; exists {
+		t.Fatal("idle renter was not pruned")
+	}
+	if _, exists := rl.renters[renter.String()]; !exists {
+		t.Fatal("renter with open sessions was pruned")
+	}
+}
+
+// TestHostRenterLimits checks that the host rejects renters that are over
+// their session limit, and that trusted renters get a higher limit.
+func TestHostRenterLimits(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester("TestHostRenterLimits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	settings := ht.host.InternalSettings()
+	settings.MaxRenterSessions = 1
+	settings.TrustedRenterCollateral = types.SiacoinPrecision
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// limit opens a session for the renter on a new connection, returning the
+	// connection.
+	renter := randRenterKey()
+	limit := func(collateral types.Currency) (*renterConn, error) {
+		hostConn, rConn := net.Pipe()
+		rc := &renterConn{Conn: hostConn}
+		go func() {
+			// Drain the rejection, if any.
+			modules.ReadNegotiationAcceptance(rConn)
+			rConn.Close()
+		}()
+		err := ht.host.managedLimitRenter(rc, renter, collateral)
+		if err == nil {
+			rConn.Close()
+		}
+		return rc, err
+	}
+
+	rc, err := limit(types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limit(types.ZeroCurrency); err != errRenterBusy {
+		t.Fatal("expected errRenterBusy, got", err)
+	}
+	// A renter with enough collateral locked up is allowed more sessions.
+	for i := 0; i < trustedRenterLimitMultiplier-1; i++ {
+		if _, err := limit(types.SiacoinPrecision); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := limit(types.SiacoinPrecision); err != errRenterBusy {
+		t.Fatal("expected errRenterBusy, got", err)
+	}
+
+	// Releasing the session makes room for another.
+	rc.release()
+	if _, err := limit(types.SiacoinPrecision); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,276 @@
+package host
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/fastrand"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errMissingSectorKey is returned when a sector is encrypted with a key
+	// that the host no longer has.
+	errMissingSectorKey = errors.New("sector is encrypted with an unknown key")
+)
+
+// sectorKey is a key that the host uses to encrypt sectors on disk. Keys are
+// identified by a random id, so that keys imported from a backup do not clash
+// with the keys of the host.
+type sectorKey struct {
+	ID  uint64            `json:"id"`
+	Key crypto.TwofishKey `json:"key"`
+}
+
+// cryptSector encrypts or decrypts the data of a sector. Each sector is
+// encrypted with a stream cipher keyed from the host key and the sector root,
+// which keeps the ciphertext the same size as the sector and makes it safe to
+// use a zero IV. The sector root is always computed over the plaintext, so
+// the roots known to renters and used for storage proofs are unaffected.
+func cryptSector(key crypto.TwofishKey, root crypto.Hash, data []byte) []byte {
+	sectorKey := crypto.TwofishKey(crypto.HashAll(key, root))
+	out := make([]byte, len(data))
+	// Reading from a bytes.Reader into a buffer of the same size cannot fail.
+	io.ReadFull(sectorKey.NewReader(bytes.NewReader(data)), out)
+	return out
+}
+
+// getSectorKeyID returns the id of the key that a sector is encrypted with,
+// and false if the sector is stored in plaintext.
+func getSectorKeyID(tx *bolt.Tx, root crypto.Hash) (uint64, bool) {
+	idBytes := tx.Bucket(bucketEncryptedSectors).Get(root[:])
+	if idBytes == nil {
+		return 0, false
+	}
+	return encoding.DecUint64(idBytes), true
+}
+
+// putSectorKeyID records the id of the key that a sector is encrypted with.
+func putSectorKeyID(tx *bolt.Tx, root crypto.Hash, id uint64) error {
+	return tx.Bucket(bucketEncryptedSectors).Put(root[:], encoding.EncUint64(id))
+}
+
+// findSectorKey returns the sector key with the provided id.
+func findSectorKey(keys []sectorKey, id uint64) (crypto.TwofishKey, bool) {
+	for _, sk := range keys {
+		if sk.ID == id {
+			return sk.Key, true
+		}
+	}
+	return crypto.TwofishKey{}, false
+}
+
+// newSectorKey generates a new sector key with a random id.
+func newSectorKey() sectorKey {
+	return sectorKey{
+		ID:  fastrand.Uint64n(math.MaxUint64),
+		Key: crypto.GenerateTwofishKey(),
+	}
+}
+
+// sectorEncryption is a snapshot of the sector encryption state of the host.
+// It is taken before h.mu is acquired, so that sectors can be added and read
+// by callers that hold h.mu.
+type sectorEncryption struct {
+	enabled bool
+	keys    []sectorKey // The last key is used for new sectors.
+}
+
+// managedSectorEncryption returns a snapshot of the sector encryption state of
+// the host, generating the first key if encryption is enabled and the host
+// does not have a key yet.
+func (h *Host) managedSectorEncryption() (sectorEncryption, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.settings.EncryptSectors && len(h.sectorKeys) == 0 {
+		h.sectorKeys = append(h.sectorKeys, newSectorKey())
+		err := h.saveSync()
+		if err != nil {
+			h.sectorKeys = nil
+			return sectorEncryption{}, err
+		}
+	}
+	// The slice is never modified in place, a rotation replaces it.
+	return sectorEncryption{
+		enabled: h.settings.EncryptSectors,
+		keys:    h.sectorKeys,
+	}, nil
+}
+
+// AddSector adds a sector to the storage manager. If sector encryption is
+// enabled, the sector is encrypted with the current sector key before it is
+// written to disk. Sectors that are already stored keep the key, or lack of
+// encryption, that they were first stored with, as the storage manager only
+// keeps a single copy of each sector.
+func (h *Host) AddSector(root crypto.Hash, data []byte) error {
+	se, err := h.managedSectorEncryption()
+	if err != nil {
+		return err
+	}
+	return h.addSector(se, root, data)
+}
+
+// addSector adds a sector to the storage manager, encrypting it according to
+// the provided snapshot. addSector does not acquire h.mu.
+func (h *Host) addSector(se sectorEncryption, root crypto.Hash, data []byte) error {
+	h.sectorMu.Lock()
+	defer h.sectorMu.Unlock()
+
+	var id uint64
+	var encrypted bool
+	err := h.db.View(func(tx *bolt.Tx) error {
+		id, encrypted = getSectorKeyID(tx, root)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	newlyEncrypted := false
+	if !encrypted && se.enabled && len(se.keys) > 0 {
+		// A sector that the storage manager can already read was stored
+		// before encryption was enabled, and has to stay in plaintext.
+		if _, err := h.StorageManager.ReadSector(root); err != nil {
+			id, encrypted, newlyEncrypted = se.keys[len(se.keys)-1].ID, true, true
+		}
+	}
+	if encrypted {
+		key, exists := findSectorKey(se.keys, id)
+		if !exists {
+			return errMissingSectorKey
+		}
+		data = cryptSector(key, root, data)
+	}
+
+	err = h.StorageManager.AddSector(root, data)
+	if err != nil || !newlyEncrypted {
+		return err
+	}
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		return putSectorKeyID(tx, root, id)
+	})
+	if err != nil {
+		// Without the record the sector cannot be decrypted, so it is removed
+		// again.
+		h.StorageManager.RemoveSector(root)
+	}
+	return err
+}
+
+// ReadSector reads a sector from the storage manager, decrypting it if it is
+// encrypted on disk.
+func (h *Host) ReadSector(root crypto.Hash) ([]byte, error) {
+	h.mu.RLock()
+	keys := h.sectorKeys
+	h.mu.RUnlock()
+	return h.readSector(keys, root)
+}
+
+// readSector reads a sector from the storage manager, decrypting it with one
+// of the provided keys if it is encrypted on disk. readSector does not acquire
+// h.mu.
+func (h *Host) readSector(keys []sectorKey, root crypto.Hash) ([]byte, error) {
+	data, err := h.StorageManager.ReadSector(root)
+	if err != nil {
+		return nil, err
+	}
+	var id uint64
+	var encrypted bool
+	err = h.db.View(func(tx *bolt.Tx) error {
+		id, encrypted = getSectorKeyID(tx, root)
+		return nil
+	})
+	if err != nil || !encrypted {
+		return data, err
+	}
+	key, exists := findSectorKey(keys, id)
+	if !exists {
+		return nil, errMissingSectorKey
+	}
+	return cryptSector(key, root, data), nil
+}
+
+// RotateSectorKey generates a new key to encrypt sectors with. Sectors that
+// are already on disk are not rewritten, they stay encrypted with the key
+// they were stored with until they are removed. Keys which no longer protect
+// any sector of a storage obligation are discarded.
+func (h *Host) RotateSectorKey() error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sectorMu.Lock()
+	defer h.sectorMu.Unlock()
+
+	// Records of sectors which are not part of any storage obligation are
+	// dropped, unless they use the current key, which may belong to a sector
+	// that is still being uploaded.
+	var current uint64
+	if len(h.sectorKeys) > 0 {
+		current = h.sectorKeys[len(h.sectorKeys)-1].ID
+	}
+	used := make(map[uint64]struct{})
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		referenced := make(map[crypto.Hash]struct{})
+		err := tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return err
+			}
+			for _, root := range so.SectorRoots {
+				referenced[root] = struct{}{}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		var stale [][]byte
+		err = tx.Bucket(bucketEncryptedSectors).ForEach(func(rootBytes, idBytes []byte) error {
+			var root crypto.Hash
+			copy(root[:], rootBytes)
+			id := encoding.DecUint64(idBytes)
+			if _, exists := referenced[root]; !exists && id != current {
+				stale = append(stale, rootBytes)
+				return nil
+			}
+			used[id] = struct{}{}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, rootBytes := range stale {
+			err := tx.Bucket(bucketEncryptedSectors).Delete(rootBytes)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The current key is kept even if no sector uses it yet, as an upload
+	// that took its snapshot of the keys before the rotation may still store
+	// sectors with it.
+	var keys []sectorKey
+	for _, sk := range h.sectorKeys {
+		if _, exists := used[sk.ID]; exists || sk.ID == current {
+			keys = append(keys, sk)
+		}
+	}
+	h.log.Printf("Rotating the sector key, %v of %v old keys are still in use\n", len(keys), len(h.sectorKeys))
+	h.sectorKeys = append(keys, newSectorKey())
+	return h.saveSync()
+}
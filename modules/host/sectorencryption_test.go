@@ -0,0 +1,270 @@
+package host
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestSectorEncryption checks that sectors are encrypted on disk once
+// encryption is enabled, that they are decrypted transparently, and that
+// rotating the key keeps the old sectors readable.
+func TestSectorEncryption(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestSectorEncryption")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// addSector adds a random sector to the host, returning its root and
+	// data.
+	addSector := func() (crypto.Hash, []byte) {
+		root, data := randSector()
+		if err := ht.host.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+		return root, data
+	}
+	// checkSector checks that the sector can be read back, and whether it is
+	// encrypted on disk.
+	checkSector := func(root crypto.Hash, data []byte, encrypted bool) {
+		read, err := ht.host.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(read, data) {
+			t.Fatal("sector was not read back correctly")
+		}
+		raw, err := ht.host.StorageManager.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(raw, data) == encrypted {
+			t.Fatal("sector encryption on disk does not match, expected", encrypted)
+		}
+	}
+
+	plainRoot, plainData := addSector()
+	checkSector(plainRoot, plainData, false)
+
+	settings := ht.host.InternalSettings()
+	settings.EncryptSectors = true
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldRoot, oldData := addSector()
+	checkSector(oldRoot, oldData, true)
+	// A sector that was stored in plaintext stays in plaintext when it is
+	// added again.
+	if err := ht.host.AddSector(plainRoot, plainData); err != nil {
+		t.Fatal(err)
+	}
+	checkSector(plainRoot, plainData, false)
+
+	// After a rotation, new sectors use the new key while the old sectors
+	// can still be read.
+	err = ht.host.RotateSectorKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newRoot, newData := addSector()
+	checkSector(newRoot, newData, true)
+	checkSector(oldRoot, oldData, true)
+	var oldID, newID uint64
+	ht.host.db.View(func(tx *bolt.Tx) error {
+		oldID, _ = getSectorKeyID(tx, oldRoot)
+		newID, _ = getSectorKeyID(tx, newRoot)
+		return nil
+	})
+	if oldID == newID {
+		t.Fatal("new sector was encrypted with the old key")
+	}
+
+	// The sectors are not part of any storage obligation, so another
+	// rotation discards the first key, but keeps the key that the newest
+	// sector may still be uploaded with.
+	err = ht.host.RotateSectorKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.mu.RLock()
+	_, oldKept := findSectorKey(ht.host.sectorKeys, oldID)
+	_, newKept := findSectorKey(ht.host.sectorKeys, newID)
+	numKeys := len(ht.host.sectorKeys)
+	ht.host.mu.RUnlock()
+	if oldKept || !newKept || numKeys != 2 {
+		t.Fatal("unused sector keys were not discarded correctly", oldKept, newKept, numKeys)
+	}
+	checkSector(newRoot, newData, true)
+}
+
+// TestSectorEncryptionRevision uploads a sector through the revision protocol
+// with sector encryption enabled. The sector is added while the host holds
+// h.mu, so the encryption keys must be read before the lock is taken.
+func TestSectorEncryptionRevision(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestSectorEncryptionRevision")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	settings := ht.host.InternalSettings()
+	settings.EncryptSectors = true
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a storage obligation with a revision that the renter can sign.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	defer ht.host.managedUnlockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renterSK, renterPK := crypto.GenerateKeyPair()
+	ht.host.mu.RLock()
+	hostPK := ht.host.publicKey
+	ht.host.mu.RUnlock()
+	validPayouts, missedPayouts := so.payouts()
+	revision := types.FileContractRevision{
+		ParentID: so.id(),
+		UnlockConditions: types.UnlockConditions{
+			PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(renterPK), hostPK},
+			SignaturesRequired: 2,
+		},
+		NewRevisionNumber:     1,
+		NewWindowStart:        so.expiration(),
+		NewWindowEnd:          so.proofDeadline(),
+		NewValidProofOutputs:  validPayouts,
+		NewMissedProofOutputs: append(missedPayouts, types.SiacoinOutput{}),
+		NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
+	}
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{revision},
+	}}
+	err = ht.host.modifyStorageObligation(so, sectorEncryption{}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pay for a new sector in the next revision.
+	root, data := randSector()
+	cost := types.SiacoinPrecision.Mul64(100)
+	rev := revision
+	rev.NewRevisionNumber++
+	rev.NewFileSize = modules.SectorSize
+	rev.NewFileMerkleRoot = cachedMerkleRoot([]crypto.Hash{root})
+	rev.NewValidProofOutputs = []types.SiacoinOutput{
+		{Value: validPayouts[0].Value.Sub(cost), UnlockHash: validPayouts[0].UnlockHash},
+		{Value: validPayouts[1].Value.Add(cost), UnlockHash: validPayouts[1].UnlockHash},
+	}
+	rev.NewMissedProofOutputs = []types.SiacoinOutput{
+		{Value: missedPayouts[0].Value.Sub(cost), UnlockHash: missedPayouts[0].UnlockHash},
+		{Value: missedPayouts[1].Value.Add(cost), UnlockHash: missedPayouts[1].UnlockHash},
+		{},
+	}
+
+	// Run the host side of a revision iteration against a renter that
+	// uploads the sector.
+	renterConn, hostConn := net.Pipe()
+	defer renterConn.Close()
+	defer hostConn.Close()
+	renterConn.SetDeadline(time.Now().Add(time.Minute))
+	done := make(chan error, 1)
+	go func() {
+		done <- ht.host.managedRevisionIteration(hostConn, &so, true)
+	}()
+	var hes modules.HostExternalSettings
+	var hostKey crypto.PublicKey
+	copy(hostKey[:], hostPK.Key)
+	err = crypto.ReadSignedObject(renterConn, &hes, modules.NegotiateMaxHostExternalSettingsLen, hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = modules.WriteNegotiationAcceptance(renterConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := []modules.RevisionAction{{
+		Type:        modules.ActionInsert,
+		SectorIndex: 0,
+		Data:        data,
+	}}
+	err = encoding.WriteObject(renterConn, actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = encoding.WriteObject(renterConn, rev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = modules.ReadNegotiationAcceptance(renterConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{rev},
+		TransactionSignatures: []types.TransactionSignature{{
+			ParentID:       crypto.Hash(rev.ParentID),
+			CoveredFields:  types.CoveredFields{FileContractRevisions: []uint64{0}},
+			PublicKeyIndex: 0,
+		}},
+	}
+	renterSig := crypto.SignHash(txn.SigHash(0), renterSK)
+	txn.TransactionSignatures[0].Signature = renterSig[:]
+	err = encoding.WriteObject(renterConn, txn.TransactionSignatures[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The iteration is the final one, so the host responds with a stop.
+	err = modules.ReadNegotiationAcceptance(renterConn)
+	if err != modules.ErrStopResponse {
+		t.Fatal("expected the host to stop the revision loop, got", err)
+	}
+	var hostSig types.TransactionSignature
+	err = encoding.ReadObject(renterConn, &hostSig, 16e3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// The uploaded sector is encrypted on disk.
+	read, err := ht.host.ReadSector(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Fatal("sector was not read back correctly")
+	}
+	raw, err := ht.host.StorageManager.ReadSector(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(raw, data) {
+		t.Fatal("uploaded sector was not encrypted")
+	}
+}
@@ -31,17 +31,24 @@ package host
 // are not set or used.
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/host/contractmanager"
 	"github.com/NebulousLabs/Sia/types"
 
+	"github.com/NebulousLabs/fastrand"
 	"github.com/coreos/bbolt"
 )
 
@@ -53,12 +60,20 @@ const (
 )
 
 var (
+	// errCorruptSector is returned if a sector read from disk no longer
+	// matches the Merkle root that it was stored under.
+	errCorruptSector = errors.New("sector data does not match its Merkle root")
+
 	// errDuplicateStorageObligation is returned when the storage obligation
 	// database already has a storage obligation with the provided file
 	// contract. This error should only happen in the event of a developer
 	// mistake.
 	errDuplicateStorageObligation = errors.New("storage obligation has a file contract which conflicts with an existing storage obligation")
 
+	// errMaxObligationsReached is returned when a storage obligation is added
+	// while the host already holds its MaxObligations.
+	errMaxObligationsReached = ErrorInternal("host has reached its maximum number of storage obligations and cannot accept the file contract")
+
 	// errInsaneFileContractOutputCounts is returned when a file contract has
 	// the wrong number of outputs for either the valid or missed payouts.
 	errInsaneFileContractOutputCounts = errors.New("file contract has incorrect number of outputs for the valid or missed payouts")
@@ -93,6 +108,10 @@ var (
 	// inputs.
 	errInsaneStorageObligationRevisionData = errors.New("revision to storage obligation has insane data")
 
+	// errHostShuttingDown is returned if a storage obligation is added while
+	// the host is shutting down.
+	errHostShuttingDown = errors.New("host is shutting down")
+
 	// errNoBuffer is returned if there is an attempted storage obligation that
 	// needs to have the storage proof submitted in less than
 	// revisionSubmissionBuffer blocks.
@@ -131,6 +150,22 @@ type storageObligation struct {
 	RiskedCollateral         types.Currency
 	TransactionFeesAdded     types.Currency
 
+	// The amount of data that the renter has transferred to and from the host
+	// under this obligation. These are used to check the bandwidth revenue of
+	// the obligation against the traffic that it generated.
+	DownloadBytes uint64
+	UploadBytes   uint64
+
+	// RenterKey is the public key that the renter used to form the file
+	// contract. It identifies the renter for support and abuse handling.
+	// Storage obligations formed before the key was recorded have an empty
+	// key.
+	RenterKey types.SiaPublicKey
+
+	// Tags are key-value pairs that the operator has attached to the storage
+	// obligation to group and filter obligations. They are purely metadata.
+	Tags map[string]string
+
 	// The negotiation height specifies the block height at which the file
 	// contract was negotiated. If the origin transaction set is not accepted
 	// onto the blockchain quickly enough, the contract is pruned from the
@@ -149,6 +184,27 @@ type storageObligation struct {
 	ProofConstructed    bool
 	RevisionConfirmed   bool
 	RevisionConstructed bool
+
+	// ResubmissionAttempts counts the number of times that the host has
+	// resubmitted the origin or revision transaction set without seeing it
+	// confirmed. It is used to back off between resubmissions, and is reset
+	// whenever one of the transaction sets is confirmed.
+	ResubmissionAttempts uint64
+
+	// RevisionSubmissionHeight is the block height at which the host first
+	// submitted the final revision transaction set to the transaction pool.
+	// It is zero if the revision has not been submitted yet. The origin
+	// transaction set is submitted during negotiation, so the negotiation
+	// height doubles as its submission height.
+	RevisionSubmissionHeight types.BlockHeight
+
+	// RevisionCount is the number of revisions that the host has accepted for
+	// the obligation since RevisionWindowStart. It is checked against the
+	// host's MaxContractRevisions to limit how often a renter can make the
+	// host save the obligation. A renewed contract is a new obligation, so
+	// renewing starts a new count.
+	RevisionCount       uint64
+	RevisionWindowStart types.BlockHeight
 }
 
 func (i storageObligationStatus) String() string {
@@ -191,6 +247,51 @@ func putStorageObligation(tx *bolt.Tx, so storageObligation) error {
 	return tx.Bucket(bucketStorageObligations).Put(soid[:], soBytes)
 }
 
+// resubmissionBackoff returns the number of blocks that the host should wait
+// before resubmitting a transaction set that has already been resubmitted
+// 'attempts' times. The timeout doubles with each attempt, and is capped at
+// maxResubmissionTimeout.
+func resubmissionBackoff(attempts uint64) types.BlockHeight {
+	timeout := types.BlockHeight(resubmissionTimeout)
+	for i := uint64(0); i < attempts && timeout < maxResubmissionTimeout; i++ {
+		timeout *= 2
+	}
+	if timeout > maxResubmissionTimeout {
+		timeout = maxResubmissionTimeout
+	}
+	return timeout
+}
+
+// resubmissionJitter returns the maximum number of blocks of random jitter
+// that is added to the height of resubmission action items. Unlike most
+// settings, zero is not replaced by the default, it disables the jitter.
+func (h *Host) resubmissionJitter() types.BlockHeight {
+	return h.settings.ResubmissionJitter
+}
+
+// jitterHeight adds a random jitter of up to maxJitter blocks to the height of
+// an action item. The jitter never moves the action item past limit, so
+// action items at or close to the limit get less jitter or none at all.
+func jitterHeight(height, limit, maxJitter types.BlockHeight) types.BlockHeight {
+	if height >= limit {
+		return height
+	}
+	if maxJitter > limit-height {
+		maxJitter = limit - height
+	}
+	return height + types.BlockHeight(fastrand.Uint64n(uint64(maxJitter)+1))
+}
+
+// proofJitterLimit returns the latest height that the storage proof action
+// item of the obligation may be jittered to. Enough of the proof window is
+// left to resubmit the storage proof at least once.
+func (so storageObligation) proofJitterLimit() types.BlockHeight {
+	if so.proofDeadline() < resubmissionTimeout {
+		return 0
+	}
+	return so.proofDeadline() - resubmissionTimeout
+}
+
 // expiration returns the height at which the storage obligation expires.
 func (so storageObligation) expiration() types.BlockHeight {
 	if len(so.RevisionTransactionSet) > 0 {
@@ -295,6 +396,93 @@ func (so storageObligation) proofDeadline() types.BlockHeight {
 	return so.OriginTransactionSet[len(so.OriginTransactionSet)-1].FileContracts[0].WindowEnd
 }
 
+// duration returns the number of blocks between the negotiation of the
+// storage obligation and the end of its proof window, which determines the
+// storage price tier of the storage obligation.
+func (so storageObligation) duration() types.BlockHeight {
+	return modules.StorageTierDuration(so.NegotiationHeight, so.proofDeadline())
+}
+
+// proofDeadlineRisk returns the number of blocks of slack that remain before
+// the proof window of the storage obligation closes. The result is negative if
+// the proof window has already closed.
+func (so storageObligation) proofDeadlineRisk(currentHeight types.BlockHeight) int {
+	return int(so.proofDeadline()) - int(currentHeight)
+}
+
+// atRisk returns whether the storage obligation is still waiting on a storage
+// proof and has less than proofDeadlineSafetyMargin blocks of slack left.
+func (so storageObligation) atRisk(currentHeight types.BlockHeight) bool {
+	if so.ObligationStatus != obligationUnresolved || so.ProofConfirmed {
+		return false
+	}
+	return so.proofDeadlineRisk(currentHeight) < int(proofDeadlineSafetyMargin)
+}
+
+// confirmationDelay returns the number of blocks that the storage obligation
+// has been waiting for its origin or revision transaction set to be confirmed
+// since the transaction set was submitted. Zero is returned if no submitted
+// transaction set is waiting for confirmation.
+func (so storageObligation) confirmationDelay(currentHeight types.BlockHeight) types.BlockHeight {
+	if so.ObligationStatus != obligationUnresolved {
+		return 0
+	}
+	submissionHeight := so.NegotiationHeight
+	if so.OriginConfirmed {
+		if so.RevisionConfirmed || so.RevisionSubmissionHeight == 0 {
+			return 0
+		}
+		submissionHeight = so.RevisionSubmissionHeight
+	}
+	if currentHeight < submissionHeight {
+		return 0
+	}
+	return currentHeight - submissionHeight
+}
+
+// unconfirmed returns whether the storage obligation has been waiting for its
+// origin or revision transaction set to be confirmed for more than
+// maxDelay blocks.
+func (so storageObligation) unconfirmed(currentHeight, maxDelay types.BlockHeight) bool {
+	return so.confirmationDelay(currentHeight) > maxDelay
+}
+
+// revisionNumber returns the revision number of the latest revision of the
+// file contract that governs the storage obligation.
+func (so storageObligation) revisionNumber() uint64 {
+	if len(so.RevisionTransactionSet) > 0 {
+		return so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].FileContractRevisions[0].NewRevisionNumber
+	}
+	return so.OriginTransactionSet[len(so.OriginTransactionSet)-1].FileContracts[0].RevisionNumber
+}
+
+// validateRevision checks that a revision transaction is a sensible successor
+// to the latest revision of the storage obligation, without modifying the
+// storage obligation. The revision must have a higher revision number, must
+// not lower the host's valid payout, and must have a file size that matches
+// the sectors which will be stored once the revision is applied.
+func (so storageObligation) validateRevision(revisionTxn types.Transaction, sectorRoots []crypto.Hash) error {
+	if len(revisionTxn.FileContractRevisions) != 1 {
+		return errInsaneRevisionSetRevisionCount
+	}
+	revision := revisionTxn.FileContractRevisions[0]
+	if len(revision.NewValidProofOutputs) != 2 {
+		return errInsaneFileContractRevisionOutputCounts
+	}
+
+	if revision.NewRevisionNumber <= so.revisionNumber() {
+		return errBadRevisionNumber
+	}
+	validPayouts, _ := so.payouts()
+	if revision.NewValidProofOutputs[1].Value.Cmp(validPayouts[1].Value) < 0 {
+		return errLowHostValidOutput
+	}
+	if revision.NewFileSize != uint64(len(sectorRoots))*modules.SectorSize {
+		return errBadFileSize
+	}
+	return nil
+}
+
 // value returns the value of fulfilling the storage obligation to the host.
 func (so storageObligation) value() types.Currency {
 	return so.ContractCost.Add(so.PotentialDownloadRevenue).Add(so.PotentialStorageRevenue).Add(so.PotentialUploadRevenue).Add(so.RiskedCollateral)
@@ -315,8 +503,14 @@ func (h *Host) queueActionItem(height types.BlockHeight, id types.FileContractID
 		binary.BigEndian.PutUint64(heightBytes, uint64(height))
 
 		// Get the list of action items already at this height and extend it.
+		// An obligation only needs to be queued once per height.
 		bai := tx.Bucket(bucketActionItems)
 		existingItems := bai.Get(heightBytes)
+		for i := 0; i+crypto.HashSize <= len(existingItems); i += crypto.HashSize {
+			if bytes.Equal(existingItems[i:i+crypto.HashSize], id[:]) {
+				return nil
+			}
+		}
 		var extendedItems = make([]byte, len(existingItems), len(existingItems)+len(id[:]))
 		copy(extendedItems, existingItems)
 		extendedItems = append(extendedItems, id[:]...)
@@ -324,6 +518,51 @@ func (h *Host) queueActionItem(height types.BlockHeight, id types.FileContractID
 	})
 }
 
+// queueObligationActionItems queues the action items that are needed to carry
+// a storage obligation through to completion, based on the confirmation flags
+// of the obligation and the current block height. Action items which would
+// fall at or below the current height are queued for the next block instead.
+func (h *Host) queueObligationActionItems(so storageObligation) error {
+	soid := so.id()
+	nextHeight := h.blockHeight + 1
+	var errs []error
+	if !so.OriginConfirmed {
+		height := jitterHeight(h.blockHeight+resubmissionTimeout, so.expiration(), h.resubmissionJitter())
+		errs = append(errs, h.queueActionItem(height, soid))
+	}
+	if !so.RevisionConfirmed && len(so.RevisionTransactionSet) > 0 {
+		height := nextHeight
+		if so.expiration() > h.blockHeight+revisionSubmissionBuffer {
+			height = so.expiration() - revisionSubmissionBuffer
+		}
+		errs = append(errs, h.queueActionItem(height, soid))
+	}
+	if !so.ProofConfirmed {
+		height := so.expiration() + resubmissionTimeout
+		if height < nextHeight {
+			height = nextHeight
+		}
+		height = jitterHeight(height, so.proofJitterLimit(), h.resubmissionJitter())
+		errs = append(errs, h.queueActionItem(height, soid))
+	}
+	return composeErrors(errs...)
+}
+
+// obligationLimit returns the number of storage obligations at which the host
+// stops accepting new contracts.
+func (h *Host) obligationLimit() uint64 {
+	if h.settings.MaxObligations == 0 {
+		return defaultMaxObligations
+	}
+	return h.settings.MaxObligations
+}
+
+// obligationLimitReached returns true if the host holds as many storage
+// obligations as its obligation limit allows.
+func (h *Host) obligationLimitReached() bool {
+	return h.financialMetrics.ContractCount >= h.obligationLimit()
+}
+
 // managedAddStorageObligation adds a storage obligation to the host. Because
 // this operation can return errors, the transactions should not be submitted to
 // the blockchain until after this function has indicated success. All of the
@@ -332,86 +571,121 @@ func (h *Host) queueActionItem(height types.BlockHeight, id types.FileContractID
 // creating a new, empty file contract or when renewing an existing file
 // contract.
 func (h *Host) managedAddStorageObligation(so storageObligation) error {
-	var soid types.FileContractID
-	err := func() error {
-		h.mu.Lock()
-		defer h.mu.Unlock()
+	h.mu.Lock()
+	err := h.addStorageObligation(so)
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return h.managedSubmitStorageObligation(so)
+}
 
-		// Sanity check - obligation should be under lock while being added.
-		soid = so.id()
-		_, exists := h.lockedStorageObligations[soid]
-		if !exists {
-			h.log.Critical("addStorageObligation called with an obligation that is not locked")
-		}
-		// Sanity check - There needs to be enough time left on the file contract
-		// for the host to safely submit the file contract revision.
-		if h.blockHeight+revisionSubmissionBuffer >= so.expiration() {
-			h.log.Critical("submission window was not verified before trying to submit a storage obligation")
-			return errNoBuffer
-		}
-		// Sanity check - the resubmission timeout needs to be smaller than storage
-		// proof window.
-		if so.expiration()+resubmissionTimeout >= so.proofDeadline() {
-			h.log.Critical("host is misconfigured - the storage proof window needs to be long enough to resubmit if needed")
-			return errors.New("fill me in")
-		}
-
-		// Add the storage obligation information to the database.
-		err := h.db.Update(func(tx *bolt.Tx) error {
-			// Sanity check - a storage obligation using the same file contract id
-			// should not already exist. This situation can happen if the
-			// transaction pool ejects a file contract and then a new one is
-			// created. Though the file contract will have the same terms, some
-			// other conditions might cause problems. The check for duplicate file
-			// contract ids should happen during the negotiation phase, and not
-			// during the 'addStorageObligation' phase.
-			bso := tx.Bucket(bucketStorageObligations)
-
-			// If the storage obligation already has sectors, it means that the
-			// file contract is being renewed, and that the sector should be
-			// re-added with a new expiration height. If there is an error at any
-			// point, all of the sectors should be removed.
-			if len(so.SectorRoots) != 0 {
-				err := h.AddSectorBatch(so.SectorRoots)
-				if err != nil {
-					return err
-				}
-			}
+// addStorageObligation adds a new storage obligation to the database and to
+// the host's financial metrics. The caller must hold h.mu, and must submit the
+// obligation with managedSubmitStorageObligation once h.mu is released.
+func (h *Host) addStorageObligation(so storageObligation) error {
+	if atomic.LoadUint64(&h.atomicDraining) == 1 {
+		return errHostShuttingDown
+	}
+	// Sanity check - obligation should be under lock while being added.
+	soid := so.id()
+	_, exists := h.lockedStorageObligations[soid]
+	if !exists {
+		h.log.Critical("addStorageObligation called with an obligation that is not locked")
+	}
+	// Sanity check - There needs to be enough time left on the file contract
+	// for the host to safely submit the file contract revision.
+	if h.blockHeight+revisionSubmissionBuffer >= so.expiration() {
+		h.log.Critical("submission window was not verified before trying to submit a storage obligation")
+		return errNoBuffer
+	}
+	// Sanity check - the resubmission timeout needs to be smaller than storage
+	// proof window.
+	if so.expiration()+resubmissionTimeout >= so.proofDeadline() {
+		h.log.Critical("host is misconfigured - the storage proof window needs to be long enough to resubmit if needed")
+		return errors.New("fill me in")
+	}
+	// The collateral budget was checked during negotiation, but checking
+	// it again under the same lock that adds the collateral to the
+	// financial metrics prevents concurrent contract formations from all
+	// fitting under the budget individually while exceeding it together.
+	if h.financialMetrics.LockedStorageCollateral.Add(so.LockedCollateral).Cmp(h.settings.CollateralBudget) > 0 {
+		return errCollateralBudgetExceeded
+	}
+	// Likewise, the obligation limit is checked under the lock that
+	// increments the contract count. Existing obligations are unaffected
+	// by the limit, but renewals create new obligations and count
+	// against it.
+	if h.obligationLimitReached() {
+		return errMaxObligationsReached
+	}
 
-			// Add the storage obligation to the database.
-			soBytes, err := json.Marshal(so)
+	// Add the storage obligation information to the database.
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		// Sanity check - a storage obligation using the same file contract id
+		// should not already exist. This situation can happen if the
+		// transaction pool ejects a file contract and then a new one is
+		// created. Though the file contract will have the same terms, some
+		// other conditions might cause problems. The check for duplicate file
+		// contract ids should happen during the negotiation phase, and not
+		// during the 'addStorageObligation' phase. Overwriting the existing
+		// obligation would count its financial metrics twice, callers that
+		// are replaying a known obligation should use
+		// managedUpsertStorageObligation instead.
+		if tx.Bucket(bucketStorageObligations).Get(soid[:]) != nil {
+			return errDuplicateStorageObligation
+		}
+
+		// If the storage obligation already has sectors, it means that the
+		// file contract is being renewed, and that the sector should be
+		// re-added with a new expiration height. If there is an error at any
+		// point, all of the sectors should be removed.
+		if len(so.SectorRoots) != 0 {
+			err := h.AddSectorBatch(so.SectorRoots)
 			if err != nil {
 				return err
 			}
-			return bso.Put(soid[:], soBytes)
-		})
+		}
+
+		// Add the storage obligation to the database.
+		err := putStorageObligation(tx, so)
 		if err != nil {
 			return err
 		}
-
-		// Update the host financial metrics with regards to this storage
-		// obligation.
-		h.financialMetrics.ContractCount++
-		h.financialMetrics.PotentialContractCompensation = h.financialMetrics.PotentialContractCompensation.Add(so.ContractCost)
-		h.financialMetrics.LockedStorageCollateral = h.financialMetrics.LockedStorageCollateral.Add(so.LockedCollateral)
-		h.financialMetrics.PotentialStorageRevenue = h.financialMetrics.PotentialStorageRevenue.Add(so.PotentialStorageRevenue)
-		h.financialMetrics.PotentialDownloadBandwidthRevenue = h.financialMetrics.PotentialDownloadBandwidthRevenue.Add(so.PotentialDownloadRevenue)
-		h.financialMetrics.PotentialUploadBandwidthRevenue = h.financialMetrics.PotentialUploadBandwidthRevenue.Add(so.PotentialUploadRevenue)
-		h.financialMetrics.RiskedStorageCollateral = h.financialMetrics.RiskedStorageCollateral.Add(so.RiskedCollateral)
-		h.financialMetrics.TransactionFeeExpenses = h.financialMetrics.TransactionFeeExpenses.Add(so.TransactionFeesAdded)
-		return nil
-	}()
+		return indexStorageObligationWindow(tx, so.expiration(), soid)
+	})
 	if err != nil {
 		return err
 	}
+	h.watchOriginInputs(so)
+
+	// Update the host financial metrics with regards to this storage
+	// obligation.
+	h.financialMetrics.ContractCount++
+	h.financialMetrics.PotentialContractCompensation = h.financialMetrics.PotentialContractCompensation.Add(so.ContractCost)
+	h.financialMetrics.LockedStorageCollateral = h.financialMetrics.LockedStorageCollateral.Add(so.LockedCollateral)
+	h.financialMetrics.PotentialStorageRevenue = h.financialMetrics.PotentialStorageRevenue.Add(so.PotentialStorageRevenue)
+	h.financialMetrics.PotentialDownloadBandwidthRevenue = h.financialMetrics.PotentialDownloadBandwidthRevenue.Add(so.PotentialDownloadRevenue)
+	h.financialMetrics.PotentialUploadBandwidthRevenue = h.financialMetrics.PotentialUploadBandwidthRevenue.Add(so.PotentialUploadRevenue)
+	h.financialMetrics.RiskedStorageCollateral = h.financialMetrics.RiskedStorageCollateral.Add(so.RiskedCollateral)
+	h.financialMetrics.TransactionFeeExpenses = h.financialMetrics.TransactionFeeExpenses.Add(so.TransactionFeesAdded)
+	return nil
+}
+
+// managedSubmitStorageObligation submits the origin transaction set of a
+// storage obligation that was just added to the host, and queues the action
+// items that see the obligation through to its storage proof.
+func (h *Host) managedSubmitStorageObligation(so storageObligation) error {
+	soid := so.id()
 
 	// Check that the transaction is fully valid and submit it to the
 	// transaction pool.
-	err = h.tpool.AcceptTransactionSet(so.OriginTransactionSet)
+	err := h.tpool.AcceptTransactionSet(so.OriginTransactionSet)
 	if err != nil {
 		h.log.Println("Failed to add storage obligation, transaction set was not accepted:", err)
 		return err
 	}
+	h.trackTransactionSet(soid, so.OriginTransactionSet)
 
 	// Queue the action items.
 	h.mu.Lock()
@@ -419,7 +693,13 @@ func (h *Host) managedAddStorageObligation(so storageObligation) error {
 
 	// The file contract was already submitted to the blockchain, need to check
 	// after the resubmission timeout that it was submitted successfully.
-	err1 := h.queueActionItem(h.blockHeight+resubmissionTimeout, soid)
+	//
+	// The first action item of the origin transaction set and of the storage
+	// proof is jittered to spread the resubmissions of many obligations over
+	// several blocks. The paranoia action items are not, so they still catch
+	// the obligation at the usual height.
+	maxJitter := h.resubmissionJitter()
+	err1 := h.queueActionItem(jitterHeight(h.blockHeight+resubmissionTimeout, so.expiration(), maxJitter), soid)
 	err2 := h.queueActionItem(h.blockHeight+resubmissionTimeout*2, soid) // Paranoia
 	// Queue an action item to submit the file contract revision - if there is
 	// never a file contract revision, the handling of this action item will be
@@ -427,13 +707,127 @@ func (h *Host) managedAddStorageObligation(so storageObligation) error {
 	err3 := h.queueActionItem(so.expiration()-revisionSubmissionBuffer, soid)
 	err4 := h.queueActionItem(so.expiration()-revisionSubmissionBuffer+resubmissionTimeout, soid) // Paranoia
 	// The storage proof should be submitted
-	err5 := h.queueActionItem(so.expiration()+resubmissionTimeout, soid)
+	err5 := h.queueActionItem(jitterHeight(so.expiration()+resubmissionTimeout, so.proofJitterLimit(), maxJitter), soid)
 	err6 := h.queueActionItem(so.expiration()+resubmissionTimeout*2, soid) // Paranoia
 	err = composeErrors(err1, err2, err3, err4, err5, err6)
 	if err != nil {
-		h.log.Println("Error with transaction set, redacting obligation, id", so.id())
+		h.logObligation(LogWarn, soid, err, "Error with transaction set, redacting obligation")
 		return composeErrors(err, h.removeStorageObligation(so, obligationRejected))
 	}
+	h.notifyStorageObligationSubscribers(modules.StorageObligationAdded, so)
+	return nil
+}
+
+// managedUpsertStorageObligation adds a storage obligation to the host, or
+// replaces the storage obligation if the host is already tracking an
+// unresolved obligation with the same file contract id. This is the idempotent
+// counterpart of managedAddStorageObligation for the case where a known file
+// contract is being replayed, such as after a reorg or when retrying the
+// submission of a new file contract. When replacing, the financial metrics of
+// the existing obligation are swapped for the metrics of the new obligation
+// rather than being counted twice, and the sectors are expected to be in the
+// storage manager already. If the file contract has not been confirmed yet,
+// the origin transaction set is submitted to the transaction pool again.
+func (h *Host) managedUpsertStorageObligation(so storageObligation) error {
+	soid := so.id()
+	var found bool
+	err := func() error {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		// The lookup and the add happen in the same critical section, so that
+		// concurrent upserts of the same obligation cannot both add it.
+		var oldSO storageObligation
+		err := h.db.View(func(tx *bolt.Tx) error {
+			var err error
+			oldSO, err = getStorageObligation(tx, soid)
+			return err
+		})
+		if err == errNoStorageObligation {
+			return h.addStorageObligation(so)
+		} else if err != nil {
+			return err
+		}
+		found = true
+
+		// Sanity check - obligation should be under lock while being replaced.
+		_, exists := h.lockedStorageObligations[soid]
+		if !exists {
+			h.log.Critical("upsertStorageObligation called with an obligation that is not locked")
+		}
+		// A resolved obligation has already been accounted for in full, and
+		// cannot be brought back.
+		if oldSO.ObligationStatus != obligationUnresolved {
+			return errDuplicateStorageObligation
+		}
+
+		// Keep the sector roots, confirmation state, resubmission state and
+		// revision count that the host has already built up for the
+		// obligation.
+		so.SectorRoots = oldSO.SectorRoots
+		so.ObligationStatus = oldSO.ObligationStatus
+		so.OriginConfirmed = oldSO.OriginConfirmed
+		so.RevisionConfirmed = oldSO.RevisionConfirmed
+		so.ProofConfirmed = oldSO.ProofConfirmed
+		so.ResubmissionAttempts = oldSO.ResubmissionAttempts
+		so.RevisionSubmissionHeight = oldSO.RevisionSubmissionHeight
+		so.Tags = oldSO.Tags
+		so.RevisionCount = oldSO.RevisionCount
+		so.RevisionWindowStart = oldSO.RevisionWindowStart
+		err = h.db.Update(func(tx *bolt.Tx) error {
+			err := putStorageObligation(tx, so)
+			if err != nil {
+				return err
+			}
+			if oldSO.expiration() != so.expiration() {
+				err = unindexStorageObligationWindow(tx, oldSO.expiration(), soid)
+				if err != nil {
+					return err
+				}
+			}
+			return indexStorageObligationWindow(tx, so.expiration(), soid)
+		})
+		if err != nil {
+			return err
+		}
+		if !so.OriginConfirmed {
+			h.unwatchOriginInputs(oldSO)
+			h.watchOriginInputs(so)
+		}
+
+		// Reconcile the financial metrics - the contract count is unchanged,
+		// and the values of the old obligation are replaced by the values of
+		// the new obligation.
+		h.financialMetrics.PotentialContractCompensation = h.subMetric(h.financialMetrics.PotentialContractCompensation.Add(so.ContractCost), oldSO.ContractCost)
+		h.financialMetrics.LockedStorageCollateral = h.subMetric(h.financialMetrics.LockedStorageCollateral.Add(so.LockedCollateral), oldSO.LockedCollateral)
+		h.financialMetrics.PotentialStorageRevenue = h.subMetric(h.financialMetrics.PotentialStorageRevenue.Add(so.PotentialStorageRevenue), oldSO.PotentialStorageRevenue)
+		h.financialMetrics.PotentialDownloadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialDownloadBandwidthRevenue.Add(so.PotentialDownloadRevenue), oldSO.PotentialDownloadRevenue)
+		h.financialMetrics.PotentialUploadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialUploadBandwidthRevenue.Add(so.PotentialUploadRevenue), oldSO.PotentialUploadRevenue)
+		h.financialMetrics.RiskedStorageCollateral = h.subMetric(h.financialMetrics.RiskedStorageCollateral.Add(so.RiskedCollateral), oldSO.RiskedCollateral)
+		h.financialMetrics.TransactionFeeExpenses = h.subMetric(h.financialMetrics.TransactionFeeExpenses.Add(so.TransactionFeesAdded), oldSO.TransactionFeesAdded)
+
+		// Make sure that the action items for the obligation are in place.
+		// Action items are deduplicated, so obligations which are already
+		// queued are not queued twice.
+		return h.queueObligationActionItems(so)
+	}()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return h.managedSubmitStorageObligation(so)
+	}
+
+	// Resubmit the file contract if it has not made it onto the blockchain
+	// yet. A transaction pool which already has the file contract is fine.
+	if !so.OriginConfirmed {
+		err = h.tpool.AcceptTransactionSet(so.OriginTransactionSet)
+		if err != nil && err != modules.ErrDuplicateTransactionSet {
+			h.log.Println("Failed to resubmit storage obligation, transaction set was not accepted:", err)
+			return err
+		}
+		h.trackTransactionSet(soid, so.OriginTransactionSet)
+	}
 	return nil
 }
 
@@ -445,7 +839,9 @@ func (h *Host) managedAddStorageObligation(so storageObligation) error {
 // sectors will be removed the number of times that they are listed, to remove
 // multiple instances of the same virtual sector, the virtural sector will need
 // to appear in 'sectorsRemoved' multiple times. Same with 'sectorsGained'.
-func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []crypto.Hash, sectorsGained []crypto.Hash, gainedSectorData [][]byte) error {
+// The gained sectors are encrypted according to 'se', which the caller takes
+// before acquiring h.mu.
+func (h *Host) modifyStorageObligation(so storageObligation, se sectorEncryption, sectorsRemoved []crypto.Hash, sectorsGained []crypto.Hash, gainedSectorData [][]byte) error {
 	// Sanity check - obligation should be under lock while being modified.
 	soid := so.id()
 	_, exists := h.lockedStorageObligations[soid]
@@ -470,6 +866,29 @@ func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []cr
 		}
 	}
 
+	// Validate the new revision against the storage obligation that is
+	// currently in the database. Nothing has been changed yet, so a bad
+	// revision is rejected before it can corrupt the financial metrics.
+	var oldSO storageObligation
+	err := h.db.View(func(tx *bolt.Tx) error {
+		var err error
+		oldSO, err = getStorageObligation(tx, soid)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(so.RevisionTransactionSet) > 0 {
+		err = oldSO.validateRevision(so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1], so.SectorRoots)
+		if err != nil {
+			return err
+		}
+	} else if len(oldSO.RevisionTransactionSet) > 0 {
+		// Dropping the revision set would roll the obligation back to the
+		// original file contract.
+		return errBadRevisionNumber
+	}
+
 	// Note, for safe error handling, the operation order should be: add
 	// sectors, update database, remove sectors. If the adding or update fails,
 	// the added sectors should be removed and the storage obligation shoud be
@@ -478,9 +897,8 @@ func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []cr
 	// capacity, but will not inhibit the host's ability to submit storage
 	// proofs)
 	var i int
-	var err error
 	for i = range sectorsGained {
-		err = h.AddSector(sectorsGained[i], gainedSectorData[i])
+		err = h.addSector(se, sectorsGained[i], gainedSectorData[i])
 		if err != nil {
 			break
 		}
@@ -496,7 +914,6 @@ func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []cr
 		return err
 	}
 	// Update the database to contain the new storage obligation.
-	var oldSO storageObligation
 	err = h.db.Update(func(tx *bolt.Tx) error {
 		// Get the old storage obligation as a reference to know how to upate
 		// the host financial stats.
@@ -506,7 +923,20 @@ func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []cr
 		}
 
 		// Store the new storage obligation to replace the old one.
-		return putStorageObligation(tx, so)
+		err = putStorageObligation(tx, so)
+		if err != nil {
+			return err
+		}
+
+		// The revision may have moved the proof window.
+		if oldSO.expiration() != so.expiration() {
+			err = unindexStorageObligationWindow(tx, oldSO.expiration(), soid)
+			if err != nil {
+				return err
+			}
+			return indexStorageObligationWindow(tx, so.expiration(), soid)
+		}
+		return nil
 	})
 	if err != nil {
 		// Because there was an error, all of the sectors that got added need
@@ -538,22 +968,78 @@ func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []cr
 
 	// Update the financial information for the storage obligation - remove the
 	// old values.
-	h.financialMetrics.PotentialContractCompensation = h.financialMetrics.PotentialContractCompensation.Sub(oldSO.ContractCost)
-	h.financialMetrics.LockedStorageCollateral = h.financialMetrics.LockedStorageCollateral.Sub(oldSO.LockedCollateral)
-	h.financialMetrics.PotentialStorageRevenue = h.financialMetrics.PotentialStorageRevenue.Sub(oldSO.PotentialStorageRevenue)
-	h.financialMetrics.PotentialDownloadBandwidthRevenue = h.financialMetrics.PotentialDownloadBandwidthRevenue.Sub(oldSO.PotentialDownloadRevenue)
-	h.financialMetrics.PotentialUploadBandwidthRevenue = h.financialMetrics.PotentialUploadBandwidthRevenue.Sub(oldSO.PotentialUploadRevenue)
-	h.financialMetrics.RiskedStorageCollateral = h.financialMetrics.RiskedStorageCollateral.Sub(oldSO.RiskedCollateral)
-	h.financialMetrics.TransactionFeeExpenses = h.financialMetrics.TransactionFeeExpenses.Sub(oldSO.TransactionFeesAdded)
+	h.financialMetrics.PotentialContractCompensation = h.subMetric(h.financialMetrics.PotentialContractCompensation, oldSO.ContractCost)
+	h.financialMetrics.LockedStorageCollateral = h.subMetric(h.financialMetrics.LockedStorageCollateral, oldSO.LockedCollateral)
+	h.financialMetrics.PotentialStorageRevenue = h.subMetric(h.financialMetrics.PotentialStorageRevenue, oldSO.PotentialStorageRevenue)
+	h.financialMetrics.PotentialDownloadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialDownloadBandwidthRevenue, oldSO.PotentialDownloadRevenue)
+	h.financialMetrics.PotentialUploadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialUploadBandwidthRevenue, oldSO.PotentialUploadRevenue)
+	h.financialMetrics.RiskedStorageCollateral = h.subMetric(h.financialMetrics.RiskedStorageCollateral, oldSO.RiskedCollateral)
+	h.financialMetrics.TransactionFeeExpenses = h.subMetric(h.financialMetrics.TransactionFeeExpenses, oldSO.TransactionFeesAdded)
+
+	h.notifyStorageObligationSubscribers(modules.StorageObligationRevised, so)
 	return nil
 }
 
+// managedRemoveSectors removes a set of sectors from the storage manager and
+// returns the roots of the sectors that could not be removed. A sector which
+// the storage manager cannot find has already been removed, any other error
+// means that the sector is still taking up space.
+func (h *Host) managedRemoveSectors(soid types.FileContractID, roots []crypto.Hash) (failed []crypto.Hash) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxSectorRemovalThreads)
+	for _, root := range roots {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(root crypto.Hash) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			err := h.RemoveSector(root)
+			if err == nil || err == contractmanager.ErrSectorNotFound {
+				return
+			}
+			h.logObligation(LogWarn, soid, err, fmt.Sprintf("Unable to remove sector %v", root))
+			mu.Lock()
+			failed = append(failed, root)
+			mu.Unlock()
+		}(root)
+	}
+	wg.Wait()
+	return failed
+}
+
+// subMetric returns x - y for the host's financial metrics. The metrics are
+// bookkeeping only, so if they have drifted and y is larger than x, a warning
+// is logged and the metric is reset to zero instead of crashing the host.
+func (h *Host) subMetric(x, y types.Currency) types.Currency {
+	c, err := x.SubErr(y)
+	if err != nil {
+		h.log.Printf("WARN: host financial metrics have drifted, unable to subtract %v from %v\n", y, x)
+		return types.ZeroCurrency
+	}
+	return c
+}
+
 // removeStorageObligation will remove a storage obligation from the host,
 // either due to failure or success.
 func (h *Host) removeStorageObligation(so storageObligation, sos storageObligationStatus) error {
-	// Error is not checked, we want to call remove on every sector even if
-	// there are problems - disk health information will be updated.
-	_ = h.RemoveSectorBatch(so.SectorRoots)
+	// If an archive directory is configured, keep a copy of the data of
+	// resolved obligations for debugging and dispute resolution. The sectors
+	// are copied and then removed in the background, until then they are
+	// kept on the obligation.
+	//
+	// Otherwise, remove every sector, even if there are problems - disk health
+	// information will be updated. Sectors which could not be removed are kept
+	// on the obligation so that the removal can be retried later.
+	var failedRoots []crypto.Hash
+	if h.settings.ArchiveDir != "" && (sos == obligationSucceeded || sos == obligationFailed) && len(so.SectorRoots) > 0 {
+		h.queueArchive(so, sos)
+		failedRoots = so.SectorRoots
+	} else {
+		failedRoots = h.managedRemoveSectors(so.id(), so.SectorRoots)
+	}
 
 	// Update the host revenue metrics based on the status of the obligation.
 	if sos == obligationUnresolved {
@@ -561,27 +1047,27 @@ func (h *Host) removeStorageObligation(so storageObligation, sos storageObligati
 	}
 	if sos == obligationRejected {
 		if h.financialMetrics.TransactionFeeExpenses.Cmp(so.TransactionFeesAdded) >= 0 {
-			h.financialMetrics.TransactionFeeExpenses = h.financialMetrics.TransactionFeeExpenses.Sub(so.TransactionFeesAdded)
+			h.financialMetrics.TransactionFeeExpenses = h.subMetric(h.financialMetrics.TransactionFeeExpenses, so.TransactionFeesAdded)
 
 			// Remove the obligation statistics as potential risk and income.
 			h.log.Printf("Rejecting storage obligation expiring at block %v, current height is %v. Potential revenue is %v.\n", so.expiration(), h.blockHeight, h.financialMetrics.PotentialContractCompensation.Add(h.financialMetrics.PotentialStorageRevenue).Add(h.financialMetrics.PotentialDownloadBandwidthRevenue).Add(h.financialMetrics.PotentialUploadBandwidthRevenue))
-			h.financialMetrics.PotentialContractCompensation = h.financialMetrics.PotentialContractCompensation.Sub(so.ContractCost)
-			h.financialMetrics.LockedStorageCollateral = h.financialMetrics.LockedStorageCollateral.Sub(so.LockedCollateral)
-			h.financialMetrics.PotentialStorageRevenue = h.financialMetrics.PotentialStorageRevenue.Sub(so.PotentialStorageRevenue)
-			h.financialMetrics.PotentialDownloadBandwidthRevenue = h.financialMetrics.PotentialDownloadBandwidthRevenue.Sub(so.PotentialDownloadRevenue)
-			h.financialMetrics.PotentialUploadBandwidthRevenue = h.financialMetrics.PotentialUploadBandwidthRevenue.Sub(so.PotentialUploadRevenue)
-			h.financialMetrics.RiskedStorageCollateral = h.financialMetrics.RiskedStorageCollateral.Sub(so.RiskedCollateral)
+			h.financialMetrics.PotentialContractCompensation = h.subMetric(h.financialMetrics.PotentialContractCompensation, so.ContractCost)
+			h.financialMetrics.LockedStorageCollateral = h.subMetric(h.financialMetrics.LockedStorageCollateral, so.LockedCollateral)
+			h.financialMetrics.PotentialStorageRevenue = h.subMetric(h.financialMetrics.PotentialStorageRevenue, so.PotentialStorageRevenue)
+			h.financialMetrics.PotentialDownloadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialDownloadBandwidthRevenue, so.PotentialDownloadRevenue)
+			h.financialMetrics.PotentialUploadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialUploadBandwidthRevenue, so.PotentialUploadRevenue)
+			h.financialMetrics.RiskedStorageCollateral = h.subMetric(h.financialMetrics.RiskedStorageCollateral, so.RiskedCollateral)
 		}
 	}
 	if sos == obligationSucceeded {
 		// Remove the obligation statistics as potential risk and income.
 		h.log.Printf("Successfully submitted a storage proof. Revenue is %v.\n", so.ContractCost.Add(so.PotentialStorageRevenue).Add(so.PotentialDownloadRevenue).Add(so.PotentialUploadRevenue))
-		h.financialMetrics.PotentialContractCompensation = h.financialMetrics.PotentialContractCompensation.Sub(so.ContractCost)
-		h.financialMetrics.LockedStorageCollateral = h.financialMetrics.LockedStorageCollateral.Sub(so.LockedCollateral)
-		h.financialMetrics.PotentialStorageRevenue = h.financialMetrics.PotentialStorageRevenue.Sub(so.PotentialStorageRevenue)
-		h.financialMetrics.PotentialDownloadBandwidthRevenue = h.financialMetrics.PotentialDownloadBandwidthRevenue.Sub(so.PotentialDownloadRevenue)
-		h.financialMetrics.PotentialUploadBandwidthRevenue = h.financialMetrics.PotentialUploadBandwidthRevenue.Sub(so.PotentialUploadRevenue)
-		h.financialMetrics.RiskedStorageCollateral = h.financialMetrics.RiskedStorageCollateral.Sub(so.RiskedCollateral)
+		h.financialMetrics.PotentialContractCompensation = h.subMetric(h.financialMetrics.PotentialContractCompensation, so.ContractCost)
+		h.financialMetrics.LockedStorageCollateral = h.subMetric(h.financialMetrics.LockedStorageCollateral, so.LockedCollateral)
+		h.financialMetrics.PotentialStorageRevenue = h.subMetric(h.financialMetrics.PotentialStorageRevenue, so.PotentialStorageRevenue)
+		h.financialMetrics.PotentialDownloadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialDownloadBandwidthRevenue, so.PotentialDownloadRevenue)
+		h.financialMetrics.PotentialUploadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialUploadBandwidthRevenue, so.PotentialUploadRevenue)
+		h.financialMetrics.RiskedStorageCollateral = h.subMetric(h.financialMetrics.RiskedStorageCollateral, so.RiskedCollateral)
 
 		// Add the obligation statistics as actual income.
 		h.financialMetrics.ContractCompensation = h.financialMetrics.ContractCompensation.Add(so.ContractCost)
@@ -592,28 +1078,50 @@ func (h *Host) removeStorageObligation(so storageObligation, sos storageObligati
 	if sos == obligationFailed {
 		// Remove the obligation statistics as potential risk and income.
 		h.log.Printf("Missed storage proof. Revenue would have been %v.\n", so.ContractCost.Add(so.PotentialStorageRevenue).Add(so.PotentialDownloadRevenue).Add(so.PotentialUploadRevenue))
-		h.financialMetrics.PotentialContractCompensation = h.financialMetrics.PotentialContractCompensation.Sub(so.ContractCost)
-		h.financialMetrics.LockedStorageCollateral = h.financialMetrics.LockedStorageCollateral.Sub(so.LockedCollateral)
-		h.financialMetrics.PotentialStorageRevenue = h.financialMetrics.PotentialStorageRevenue.Sub(so.PotentialStorageRevenue)
-		h.financialMetrics.PotentialDownloadBandwidthRevenue = h.financialMetrics.PotentialDownloadBandwidthRevenue.Sub(so.PotentialDownloadRevenue)
-		h.financialMetrics.PotentialUploadBandwidthRevenue = h.financialMetrics.PotentialUploadBandwidthRevenue.Sub(so.PotentialUploadRevenue)
-		h.financialMetrics.RiskedStorageCollateral = h.financialMetrics.RiskedStorageCollateral.Sub(so.RiskedCollateral)
+		h.financialMetrics.PotentialContractCompensation = h.subMetric(h.financialMetrics.PotentialContractCompensation, so.ContractCost)
+		h.financialMetrics.LockedStorageCollateral = h.subMetric(h.financialMetrics.LockedStorageCollateral, so.LockedCollateral)
+		h.financialMetrics.PotentialStorageRevenue = h.subMetric(h.financialMetrics.PotentialStorageRevenue, so.PotentialStorageRevenue)
+		h.financialMetrics.PotentialDownloadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialDownloadBandwidthRevenue, so.PotentialDownloadRevenue)
+		h.financialMetrics.PotentialUploadBandwidthRevenue = h.subMetric(h.financialMetrics.PotentialUploadBandwidthRevenue, so.PotentialUploadRevenue)
+		h.financialMetrics.RiskedStorageCollateral = h.subMetric(h.financialMetrics.RiskedStorageCollateral, so.RiskedCollateral)
 
 		// Add the obligation statistics as loss.
 		h.financialMetrics.LostStorageCollateral = h.financialMetrics.LostStorageCollateral.Add(so.RiskedCollateral)
 		h.financialMetrics.LostRevenue = h.financialMetrics.LostRevenue.Add(so.ContractCost).Add(so.PotentialStorageRevenue).Add(so.PotentialDownloadRevenue).Add(so.PotentialUploadRevenue)
 	}
 
+	if sos == obligationSucceeded || sos == obligationFailed {
+		h.recordProofOutcome(sos == obligationSucceeded)
+	}
+
 	// Update the storage obligation to be finalized but still in-database. The
 	// obligation status is updated so that the user can see how the obligation
 	// ended up, and the sector roots are removed because they are large
 	// objects with little purpose once storage proofs are no longer needed.
+	// Only the roots of sectors which failed to be removed are kept.
 	h.financialMetrics.ContractCount--
+	h.unwatchOriginInputs(so)
 	so.ObligationStatus = sos
-	so.SectorRoots = nil
-	return h.db.Update(func(tx *bolt.Tx) error {
-		return putStorageObligation(tx, so)
+	so.SectorRoots = failedRoots
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		err := putStorageObligation(tx, so)
+		if err != nil {
+			return err
+		}
+		return unindexStorageObligationWindow(tx, so.expiration(), so.id())
 	})
+	if err != nil {
+		return err
+	}
+	switch sos {
+	case obligationRejected:
+		h.notifyStorageObligationSubscribers(modules.StorageObligationRejected, so)
+	case obligationSucceeded:
+		h.notifyStorageObligationSubscribers(modules.StorageObligationSucceeded, so)
+	case obligationFailed:
+		h.notifyStorageObligationSubscribers(modules.StorageObligationFailed, so)
+	}
+	return nil
 }
 
 // threadedHandleActionItem will look at a storage obligation and determine
@@ -641,7 +1149,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 	})
 	h.mu.RUnlock()
 	if err != nil {
-		h.log.Println("Could not get storage obligation:", err)
+		h.logObligation(LogWarn, soid, err, "Could not get storage obligation")
 		return
 	}
 
@@ -651,6 +1159,16 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		return
 	}
 
+	// Save the fields that the action item changes once it has been handled,
+	// on every path. The consensus set may have updated the obligation in the
+	// meantime, so the rest of the obligation is not overwritten.
+	defer func() {
+		err := h.saveHandledObligation(so)
+		if err != nil {
+			h.logObligation(LogWarn, soid, err, "Error updating the storage obligation")
+		}
+	}()
+
 	// Check whether the file contract has been seen. If not, resubmit and
 	// queue another action item. Check for death. (signature should have a
 	// kill height)
@@ -658,6 +1176,9 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		// Submit the transaction set again, try to get the transaction
 		// confirmed.
 		err := h.tpool.AcceptTransactionSet(so.OriginTransactionSet)
+		if err == nil || err == modules.ErrDuplicateTransactionSet {
+			h.trackTransactionSet(soid, so.OriginTransactionSet)
+		}
 		if err != nil {
 			h.log.Debugln("Could not get origin transaction set accepted", err)
 
@@ -676,16 +1197,19 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 				err = h.removeStorageObligation(so, obligationRejected)
 				h.mu.Unlock()
 				if err != nil {
-					h.log.Println("Error removing storage obligation:", err)
+					h.logObligation(LogWarn, soid, err, "Error removing storage obligation")
 				}
 				return
 			}
 		}
 
-		// Queue another action item to check the status of the transaction.
+		// Queue another action item to check the status of the transaction,
+		// backing off further with each failed attempt.
 		h.mu.Lock()
-		err = h.queueActionItem(h.blockHeight+resubmissionTimeout, so.id())
+		height := jitterHeight(h.blockHeight+resubmissionBackoff(so.ResubmissionAttempts), so.expiration(), h.resubmissionJitter())
+		err = h.queueActionItem(height, so.id())
 		h.mu.Unlock()
+		so.ResubmissionAttempts++
 		if err != nil {
 			h.log.Println("Error queuing action item:", err)
 		}
@@ -710,18 +1234,33 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			// due to the dynamic fee pool.
 			h.log.Println("Full time has elapsed, but the revision transaction could not be submitted to consensus, id", so.id())
 			h.mu.Lock()
-			h.removeStorageObligation(so, obligationRejected)
+			err := h.removeStorageObligation(so, obligationRejected)
 			h.mu.Unlock()
+			if err != nil {
+				h.logObligation(LogWarn, soid, err, "Error removing storage obligation")
+			}
 			return
 		}
 
 		// Queue another action item to check the status of the transaction.
+		// The backoff is not allowed to push the next check past the
+		// expiration of the contract, as the revision must be confirmed
+		// before then.
+		timeout := resubmissionBackoff(so.ResubmissionAttempts)
+		if blockHeight+timeout > so.expiration()+1 {
+			timeout = so.expiration() + 1 - blockHeight
+		}
 		h.mu.Lock()
-		err := h.queueActionItem(blockHeight+resubmissionTimeout, so.id())
+		height := jitterHeight(blockHeight+timeout, so.expiration()+1, h.resubmissionJitter())
+		err := h.queueActionItem(height, so.id())
 		h.mu.Unlock()
+		so.ResubmissionAttempts++
 		if err != nil {
 			h.log.Println("Error queuing action item:", err)
 		}
+		if so.RevisionSubmissionHeight == 0 {
+			so.RevisionSubmissionHeight = blockHeight
+		}
 
 		// Add a miner fee to the transaction and submit it to the blockchain.
 		revisionTxnIndex := len(so.RevisionTransactionSet) - 1
@@ -733,7 +1272,8 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			// There's no sense submitting the revision if the fee is more than
 			// half of the anticipated revenue - fee market went up
 			// unexpectedly, and the money that the renter paid to cover the
-			// fees is no longer enough.
+			// fees is no longer enough. The resubmission attempt is still
+			// saved.
 			return
 		}
 		txnSize := uint64(len(encoding.MarshalAll(so.RevisionTransactionSet)) + 300)
@@ -753,6 +1293,8 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		err = h.tpool.AcceptTransactionSet(feeAddedRevisionTransactionSet)
 		if err != nil {
 			h.log.Println("Error submitting transaction to transaction pool", err)
+		} else {
+			h.trackTransactionSet(soid, feeAddedRevisionTransactionSet)
 		}
 		so.TransactionFeesAdded = so.TransactionFeesAdded.Add(requiredFee)
 		// return
@@ -771,74 +1313,38 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			err := h.removeStorageObligation(so, obligationFailed)
 			h.mu.Unlock()
 			if err != nil {
-				h.log.Println("Error removing storage obligation:", err)
+				h.logObligation(LogWarn, soid, err, "Error removing storage obligation")
 			}
 			return
 		}
 
-		// Get the index of the segment, and the index of the sector containing
-		// the segment.
-		segmentIndex, err := h.cs.StorageProofSegment(so.id())
-		if err != nil {
-			h.log.Debugln("Host got an error when fetching a storage proof segment:", err)
-			return
-		}
-		sectorIndex := segmentIndex / (modules.SectorSize / crypto.SegmentSize)
-		// Pull the corresponding sector into memory.
-		sectorRoot := so.SectorRoots[sectorIndex]
-		sectorBytes, err := h.ReadSector(sectorRoot)
-		if err != nil {
-			h.log.Debugln(err)
-			return
-		}
-
-		// Build the storage proof for just the sector.
-		sectorSegment := segmentIndex % (modules.SectorSize / crypto.SegmentSize)
-		base, cachedHashSet := crypto.MerkleProof(sectorBytes, sectorSegment)
-
-		// Using the sector, build a cached root.
-		log2SectorSize := uint64(0)
-		for 1<<log2SectorSize < (modules.SectorSize / crypto.SegmentSize) {
-			log2SectorSize++
-		}
-		ct := crypto.NewCachedTree(log2SectorSize)
-		ct.SetIndex(segmentIndex)
-		for _, root := range so.SectorRoots {
-			ct.Push(root)
-		}
-		hashSet := ct.Prove(base, cachedHashSet)
-		sp := types.StorageProof{
-			ParentID: so.id(),
-			HashSet:  hashSet,
-		}
-		copy(sp.Segment[:], base)
-
-		// Create and build the transaction with the storage proof.
-		builder := h.wallet.StartTransaction()
+		// There's no sense submitting the storage proof if the fee is more
+		// than the anticipated revenue.
 		_, feeRecommendation := h.tpool.FeeEstimation()
 		if so.value().Cmp(feeRecommendation) < 0 {
-			// There's no sense submitting the storage proof if the fee is more
-			// than the anticipated revenue.
 			h.log.Debugln("Host not submitting storage proof due to a value that does not sufficiently exceed the fee cost")
 			return
 		}
-		txnSize := uint64(len(encoding.Marshal(sp)) + 300)
-		requiredFee := feeRecommendation.Mul64(txnSize)
-		err = builder.FundSiacoins(requiredFee)
-		if err != nil {
-			h.log.Println("Host error when funding a storage proof transaction fee:", err)
+
+		// Wait for a free slot before building the storage proof. When many
+		// proofs are due at once, the earliest deadlines are served first.
+		// The slot only covers building the proof, it is released before
+		// the proof waits for the rest of its batch.
+		if !h.proofQueue.managedAcquire(so.proofDeadline(), h.managedMaxConcurrentProofs(), h.tg.StopChan()) {
 			return
 		}
-		builder.AddMinerFee(requiredFee)
-		builder.AddStorageProof(sp)
-		storageProofSet, err := builder.Sign(true)
+		sp, err := h.managedBuildStorageProof(so)
+		if err == nil {
+			h.proofBatcher.managedStart()
+		}
+		h.proofQueue.managedRelease(h.managedMaxConcurrentProofs())
 		if err != nil {
-			h.log.Println("Host error when signing the storage proof transaction:", err)
+			h.logObligation(LogWarn, soid, err, "Unable to build storage proof")
 			return
 		}
-		err = h.tpool.AcceptTransactionSet(storageProofSet)
+		requiredFee, err := h.managedSubmitBatchedStorageProof(so, sp)
 		if err != nil {
-			h.log.Println("Host unable to submit storage proof transaction to transaction pool:", err)
+			h.logObligation(LogWarn, soid, err, "Unable to submit storage proof")
 			return
 		}
 		so.TransactionFeesAdded = so.TransactionFeesAdded.Add(requiredFee)
@@ -853,25 +1359,402 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		}
 	}
 
-	// Save the storage obligation to account for any fee changes.
-	err = h.db.Update(func(tx *bolt.Tx) error {
-		soBytes, err := json.Marshal(so)
+	// Check if all items have succeeded with the required confirmations. Report
+	// success, delete the obligation.
+	if so.ProofConfirmed && blockHeight >= so.proofDeadline() {
+		h.log.Println("file contract complete, id", so.id())
+		h.mu.Lock()
+		err = h.removeStorageObligation(so, obligationSucceeded)
+		h.mu.Unlock()
+		if err != nil {
+			h.logObligation(LogWarn, soid, err, "Error removing storage obligation")
+		}
+	}
+}
+
+// saveHandledObligation writes the fields of a storage obligation that
+// threadedHandleActionItem changes - the transaction fees, the resubmission
+// attempts and the revision submission height - into the database copy of the
+// obligation. The confirmation flags are set by the consensus set while the
+// action item is handled, so the database copy is read again instead of being
+// overwritten. The resubmission attempts are not written if the consensus set
+// confirmed a transaction of the obligation in the meantime, as it resets the
+// attempts on confirmation. Obligations that have been resolved are left alone.
+func (h *Host) saveHandledObligation(so storageObligation) error {
+	return h.db.Update(func(tx *bolt.Tx) error {
+		stored, err := getStorageObligation(tx, so.id())
 		if err != nil {
 			return err
 		}
-		return tx.Bucket(bucketStorageObligations).Put(soid[:], soBytes)
+		if stored.ObligationStatus != obligationUnresolved {
+			return nil
+		}
+		stored.TransactionFeesAdded = so.TransactionFeesAdded
+		confirmed := (stored.OriginConfirmed && !so.OriginConfirmed) || (stored.RevisionConfirmed && !so.RevisionConfirmed)
+		if !confirmed {
+			stored.ResubmissionAttempts = so.ResubmissionAttempts
+		}
+		stored.RevisionSubmissionHeight = so.RevisionSubmissionHeight
+		return putStorageObligation(tx, stored)
+	})
+}
+
+// recordProofOutcome adds the outcome of a resolved storage obligation to the
+// window of recent outcomes, dropping the oldest outcome once the window is
+// full.
+func (h *Host) recordProofOutcome(success bool) {
+	h.recentProofOutcomes = append(h.recentProofOutcomes, success)
+	if len(h.recentProofOutcomes) > proofOutcomeWindow {
+		h.recentProofOutcomes = h.recentProofOutcomes[len(h.recentProofOutcomes)-proofOutcomeWindow:]
+	}
+}
+
+// recentProofSuccessRate returns the number of recent outcomes that the host
+// has on record, and the percentage of those outcomes that were successful.
+// If there are no outcomes on record, the success rate is reported as 100%.
+func (h *Host) recentProofSuccessRate() (outcomes uint64, rate float64) {
+	if len(h.recentProofOutcomes) == 0 {
+		return 0, 100
+	}
+	var succeeded int
+	for _, success := range h.recentProofOutcomes {
+		if success {
+			succeeded++
+		}
+	}
+	return uint64(len(h.recentProofOutcomes)), 100 * float64(succeeded) / float64(len(h.recentProofOutcomes))
+}
+
+// obligationCounts holds the number of storage obligations in each of the
+// states that are reported by the host's financial metrics.
+type obligationCounts struct {
+	atRisk                uint64
+	unconfirmed           uint64
+	pendingSectorRemovals uint64
+}
+
+// managedCountObligations counts, in a single pass over the storage
+// obligations, the obligations that are at risk of missing their proof window,
+// the obligations whose origin or revision transaction set has been waiting
+// for confirmation for more than 'maxDelay' blocks, and the sectors of
+// resolved obligations which could not be removed yet. The caller must not
+// hold h.mu.
+func (h *Host) managedCountObligations(blockHeight, maxDelay types.BlockHeight) (counts obligationCounts, err error) {
+	err = h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return err
+			}
+			if so.atRisk(blockHeight) {
+				counts.atRisk++
+			}
+			if so.unconfirmed(blockHeight, maxDelay) {
+				counts.unconfirmed++
+			}
+			if so.ObligationStatus != obligationUnresolved {
+				counts.pendingSectorRemovals += uint64(len(so.SectorRoots))
+			}
+			return nil
+		})
+	})
+	return counts, err
+}
+
+// maxConfirmationDelay returns the number of blocks that a submitted
+// transaction set of a storage obligation may wait for confirmation before the
+// obligation is reported as unconfirmed.
+func (h *Host) maxConfirmationDelay() types.BlockHeight {
+	if h.settings.MaxConfirmationDelay == 0 {
+		return defaultMaxConfirmationDelay
+	}
+	return h.settings.MaxConfirmationDelay
+}
+
+// maxContractRevisions returns the number of revisions that the host accepts
+// for a single storage obligation within revisionLimitWindow blocks.
+func (h *Host) maxContractRevisions() uint64 {
+	if h.settings.MaxContractRevisions == 0 {
+		return defaultMaxContractRevisions
+	}
+	return h.settings.MaxContractRevisions
+}
+
+// revisionLimitReached returns true if the storage obligation has used up the
+// revisions that the host accepts within the current revision window.
+func (so *storageObligation) revisionLimitReached(blockHeight types.BlockHeight, limit uint64) bool {
+	if blockHeight >= so.RevisionWindowStart+revisionLimitWindow {
+		return false
+	}
+	return so.RevisionCount >= limit
+}
+
+// recordRevision counts a revision accepted at the given block height,
+// starting a new revision window if the current one has passed. The first
+// window starts at the first revision of the obligation.
+func (so *storageObligation) recordRevision(blockHeight types.BlockHeight) {
+	if so.RevisionCount == 0 || blockHeight >= so.RevisionWindowStart+revisionLimitWindow {
+		so.RevisionWindowStart = blockHeight
+		so.RevisionCount = 0
+	}
+	so.RevisionCount++
+}
+
+// committedStorage returns the number of bytes that the host has committed to
+// storing across all of the unresolved storage obligations.
+func (h *Host) committedStorage() (committed uint64) {
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return err
+			}
+			if so.ObligationStatus == obligationUnresolved {
+				committed += so.fileSize()
+			}
+			return nil
+		})
 	})
 	if err != nil {
-		h.log.Println("Error updating the storage obligations", err)
+		h.log.Println("Unable to compute the committed storage:", err)
 	}
+	return committed
+}
 
-	// Check if all items have succeeded with the required confirmations. Report
-	// success, delete the obligation.
-	if so.ProofConfirmed && blockHeight >= so.proofDeadline() {
-		h.log.Println("file contract complete, id", so.id())
+// checkStorageConsistency compares the amount of storage used in the storage
+// manager against the sectors referenced by the storage obligations. Resolved
+// storage obligations only reference sectors that failed to be removed. A
+// discrepancy means that either sectors were not removed when
+// their obligation was removed, or that sectors which are needed for storage
+// proofs have gone missing.
+//
+// Sectors are only added and removed under h.mu, except by the obligations
+// that are being archived or reconciled, whose sectors are removed in the
+// background. Those obligations are skipped: their sectors may or may not
+// still be stored, and the report says how many were skipped. The caller must
+// hold h.mu.
+func (h *Host) checkStorageConsistency() error {
+	sectors := make(map[crypto.Hash]struct{})
+	removing := make(map[crypto.Hash]struct{})
+	var skipped int
+	err := h.db.View(func(tx *bolt.Tx) error {
+		err := tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return err
+			}
+			_, archiving := h.archiving[so.id()]
+			_, reconciling := h.reconciling[so.id()]
+			if archiving || reconciling {
+				skipped++
+				for _, root := range so.SectorRoots {
+					removing[root] = struct{}{}
+				}
+				return nil
+			}
+			for _, root := range so.SectorRoots {
+				sectors[root] = struct{}{}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for root := range removing {
+			if _, exists := sectors[root]; exists {
+				delete(removing, root)
+			}
+		}
+
+		// The capacity is read within the same transaction so that it
+		// describes the same state as the storage obligations.
+		total, remaining := h.capacity()
+		used := total - remaining
+		referenced := uint64(len(sectors)) * modules.SectorSize
+		maxUsed := referenced + uint64(len(removing))*modules.SectorSize
+		if used >= referenced && used <= maxUsed {
+			return nil
+		}
+		if skipped > 0 {
+			return fmt.Errorf("storage manager is using %v bytes, but the storage obligations reference %v bytes of sectors, and up to %v more bytes of %v skipped obligations whose sectors are being removed", used, referenced, maxUsed-referenced, skipped)
+		}
+		return fmt.Errorf("storage manager is using %v bytes, but the storage obligations reference %v bytes of sectors", used, referenced)
+	})
+	return err
+}
+
+// managedReconcileStorage retries the removal of sectors that belong to
+// resolved storage obligations, and then checks that the storage manager is
+// consistent with the storage obligations.
+func (h *Host) managedReconcileStorage() error {
+	// Collect the stale obligations and mark them as being reconciled in the
+	// same critical section, so that the consistency check knows that their
+	// sectors are being removed.
+	var stale []storageObligation
+	h.mu.Lock()
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return err
+			}
+			// Obligations that are being archived still need their sectors.
+			if _, ok := h.archiving[so.id()]; ok {
+				return nil
+			}
+			if _, ok := h.reconciling[so.id()]; ok {
+				return nil
+			}
+			if so.ObligationStatus != obligationUnresolved && len(so.SectorRoots) > 0 {
+				stale = append(stale, so)
+			}
+			return nil
+		})
+	})
+	for _, so := range stale {
+		h.reconciling[so.id()] = struct{}{}
+	}
+	h.mu.Unlock()
+	defer func() {
 		h.mu.Lock()
-		h.removeStorageObligation(so, obligationSucceeded)
+		for _, so := range stale {
+			delete(h.reconciling, so.id())
+		}
 		h.mu.Unlock()
+	}()
+	if err != nil {
+		return err
+	}
+
+	var reclaimed, pending int
+	for _, so := range stale {
+		failed := h.managedRemoveSectors(so.id(), so.SectorRoots)
+		reclaimed += len(so.SectorRoots) - len(failed)
+		pending += len(failed)
+		so.SectorRoots = failed
+		err = h.db.Update(func(tx *bolt.Tx) error {
+			return putStorageObligation(tx, so)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if reclaimed > 0 {
+		h.log.Printf("Reclaimed %v sectors of resolved storage obligations\n", reclaimed)
+	}
+	if pending > 0 {
+		h.log.Printf("WARN: %v sectors of resolved storage obligations still could not be removed\n", pending)
+	}
+	// The sectors have been removed, so the obligations are checked in full.
+	h.mu.Lock()
+	for _, so := range stale {
+		delete(h.reconciling, so.id())
+	}
+	err = h.checkStorageConsistency()
+	h.mu.Unlock()
+	return err
+}
+
+// threadedReconcileStorage periodically reconciles the storage manager with
+// the storage obligations, so that sectors which could not be removed earlier
+// are eventually cleaned up.
+func (h *Host) threadedReconcileStorage(closeChan chan struct{}) {
+	defer close(closeChan)
+	for {
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(storageReconciliationFrequency):
+		}
+		err := h.managedReconcileStorage()
+		if err != nil {
+			h.log.Println("WARN: storage reconciliation failed:", err)
+		}
+	}
+}
+
+// managedVerifyIntegrity re-reads every sector of a storage obligation from
+// disk, checking that the data still matches the sector roots. The sector
+// roots are not compared against the Merkle root of the file contract; that is
+// up to the renter when revising the contract, and a mismatch says nothing
+// about the health of the disk.
+func (h *Host) managedVerifyIntegrity(so storageObligation) error {
+	return verifyIntegrity(so, h.ReadSector)
+}
+
+// verifyIntegrity checks the sectors of a storage obligation using the
+// provided function to read them.
+func verifyIntegrity(so storageObligation, readSector func(crypto.Hash) ([]byte, error)) error {
+	for _, root := range so.SectorRoots {
+		sectorData, err := readSector(root)
+		if err != nil {
+			return fmt.Errorf("unable to read sector %v: %v", root, err)
+		}
+		if crypto.MerkleRoot(sectorData) != root {
+			return fmt.Errorf("sector %v: %v", root, errCorruptSector)
+		}
+	}
+	return nil
+}
+
+// managedScrubStorageObligations verifies the integrity of every unresolved
+// storage obligation, logging each obligation that fails the check.
+// Obligations which are in use are skipped until the next scrub.
+func (h *Host) managedScrubStorageObligations() {
+	var soids []types.FileContractID
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(idBytes, _ []byte) error {
+			var soid types.FileContractID
+			copy(soid[:], idBytes)
+			soids = append(soids, soid)
+			return nil
+		})
+	})
+	if err != nil {
+		h.log.Println("Unable to list storage obligations for scrubbing:", err)
+		return
+	}
+
+	for _, soid := range soids {
+		select {
+		case <-h.tg.StopChan():
+			return
+		default:
+		}
+		if h.managedTryLockStorageObligation(soid) != nil {
+			continue
+		}
+		var so storageObligation
+		err = h.db.View(func(tx *bolt.Tx) error {
+			so, err = getStorageObligation(tx, soid)
+			return err
+		})
+		if err == nil && so.ObligationStatus == obligationUnresolved {
+			err = h.managedVerifyIntegrity(so)
+			if err != nil {
+				h.logObligation(LogWarn, soid, err, "Storage obligation failed its integrity check")
+			}
+		}
+		h.managedUnlockStorageObligation(soid)
+	}
+}
+
+// threadedScrubStorageObligations periodically verifies the integrity of the
+// data held for the storage obligations, so that disk corruption is noticed
+// before a storage proof is due.
+func (h *Host) threadedScrubStorageObligations(closeChan chan struct{}) {
+	defer close(closeChan)
+	for {
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(storageScrubFrequency):
+		}
+		h.managedScrubStorageObligations()
 	}
 }
 
@@ -880,6 +1763,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
+	maxDelay := h.maxConfirmationDelay()
 
 	err := h.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucketStorageObligations)
@@ -892,14 +1776,18 @@ func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
 			mso := modules.StorageObligation{
 				ContractCost:             so.ContractCost,
 				DataSize:                 so.fileSize(),
+				DownloadBytes:            so.DownloadBytes,
 				LockedCollateral:         so.LockedCollateral,
 				ObligationId:             so.id(),
 				PotentialDownloadRevenue: so.PotentialDownloadRevenue,
 				PotentialStorageRevenue:  so.PotentialStorageRevenue,
 				PotentialUploadRevenue:   so.PotentialUploadRevenue,
+				RenterKey:                so.RenterKey,
 				RiskedCollateral:         so.RiskedCollateral,
 				SectorRootsCount:         uint64(len(so.SectorRoots)),
+				Tags:                     so.Tags,
 				TransactionFeesAdded:     so.TransactionFeesAdded,
+				UploadBytes:              so.UploadBytes,
 
 				ExpirationHeight:  so.expiration(),
 				NegotiationHeight: so.NegotiationHeight,
@@ -911,6 +1799,10 @@ func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
 				ProofConstructed:    so.ProofConstructed,
 				RevisionConfirmed:   so.RevisionConfirmed,
 				RevisionConstructed: so.RevisionConstructed,
+
+				RevisionSubmissionHeight: so.RevisionSubmissionHeight,
+				ConfirmationDelay:        so.confirmationDelay(h.blockHeight),
+				Unconfirmed:              so.unconfirmed(h.blockHeight, maxDelay),
 			}
 			sos = append(sos, mso)
 			return nil
@@ -242,7 +242,7 @@ func TestSingleSectorStorageObligationStack(t *testing.T) {
 		}},
 	}}
 	ht.host.managedLockStorageObligation(so.id())
-	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	err = ht.host.modifyStorageObligation(so, sectorEncryption{}, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -433,7 +433,7 @@ func TestMultiSectorStorageObligationStack(t *testing.T) {
 		}},
 	}}
 	ht.host.managedLockStorageObligation(so.id())
-	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	err = ht.host.modifyStorageObligation(so, sectorEncryption{}, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -479,7 +479,7 @@ func TestMultiSectorStorageObligationStack(t *testing.T) {
 		}},
 	}}
 	ht.host.managedLockStorageObligation(so.id())
-	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot2}, [][]byte{sectorData2})
+	err = ht.host.modifyStorageObligation(so, sectorEncryption{}, nil, []crypto.Hash{sectorRoot2}, [][]byte{sectorData2})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -645,7 +645,7 @@ func TestAutoRevisionSubmission(t *testing.T) {
 	}}
 	so.RevisionTransactionSet = revisionSet
 	ht.host.managedLockStorageObligation(so.id())
-	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	err = ht.host.modifyStorageObligation(so, sectorEncryption{}, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
 	if err != nil {
 		t.Fatal(err)
 	}
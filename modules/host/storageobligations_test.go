@@ -1,11 +1,32 @@
 package host
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+
+	"github.com/coreos/bbolt"
 )
 
+// cachedMerkleRoot returns the Merkle root of a file that is made up of the
+// sectors with the provided roots.
+func cachedMerkleRoot(roots []crypto.Hash) crypto.Hash {
+	log2SectorSize := uint64(0)
+	for 1<<log2SectorSize < (modules.SectorSize / crypto.SegmentSize) {
+		log2SectorSize++
+	}
+	ct := crypto.NewCachedTree(log2SectorSize)
+	for _, root := range roots {
+		ct.Push(root)
+	}
+	return ct.Root()
+}
+
 // TestStorageObligationID checks that the return function of the storage
 // obligation returns the correct value for the obligaiton id.
 func TestStorageObligationID(t *testing.T) {
@@ -80,3 +101,997 @@ func TestStorageObligationID(t *testing.T) {
 		t.Error("id function of storage obligation incorrect for file contracts with dependencies")
 	}
 }
+
+// TestResubmissionBackoff checks that the resubmission timeout doubles with
+// each attempt and is capped at maxResubmissionTimeout.
+func TestResubmissionBackoff(t *testing.T) {
+	if resubmissionBackoff(0) != resubmissionTimeout {
+		t.Error("first resubmission should use the base timeout")
+	}
+	if resubmissionBackoff(1) != resubmissionTimeout*2 {
+		t.Error("second resubmission should use double the base timeout")
+	}
+	if resubmissionBackoff(2) != resubmissionTimeout*4 {
+		t.Error("third resubmission should use four times the base timeout")
+	}
+	for _, attempts := range []uint64{10, 100, 1 << 62} {
+		if resubmissionBackoff(attempts) != maxResubmissionTimeout {
+			t.Error("resubmission timeout was not capped:", attempts, resubmissionBackoff(attempts))
+		}
+	}
+}
+
+// TestSaveHandledObligation checks that saving a handled action item only
+// updates the fields owned by the action item handler, keeping the
+// confirmation flags that were set in the meantime.
+func TestSaveHandledObligation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestSaveHandledObligation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	load := func() storageObligation {
+		var stored storageObligation
+		err := ht.host.db.View(func(tx *bolt.Tx) error {
+			var err error
+			stored, err = getStorageObligation(tx, so.id())
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stored
+	}
+
+	// Saving a copy while nothing got confirmed stores the attempts.
+	so.ResubmissionAttempts = 1
+	err = ht.host.saveHandledObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored := load(); stored.ResubmissionAttempts != 1 {
+		t.Error("resubmission attempts were not saved:", stored.ResubmissionAttempts)
+	}
+
+	// Confirm the origin transaction behind the back of a stale copy, which
+	// resets the attempts, then save the stale copy with new fees and
+	// attempts.
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		stored, err := getStorageObligation(tx, so.id())
+		if err != nil {
+			return err
+		}
+		stored.OriginConfirmed = true
+		stored.ResubmissionAttempts = 0
+		return putStorageObligation(tx, stored)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	so.TransactionFeesAdded = types.NewCurrency64(5)
+	so.ResubmissionAttempts = 2
+	so.RevisionSubmissionHeight = 7
+	err = ht.host.saveHandledObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := load()
+	if !stored.OriginConfirmed {
+		t.Error("confirmation flag was overwritten by the stale copy")
+	}
+	if stored.ResubmissionAttempts != 0 {
+		t.Error("reset of the resubmission attempts was overwritten by the stale copy:", stored.ResubmissionAttempts)
+	}
+	if !stored.TransactionFeesAdded.Equals64(5) || stored.RevisionSubmissionHeight != 7 {
+		t.Error("handler fields were not saved:", stored.TransactionFeesAdded, stored.RevisionSubmissionHeight)
+	}
+}
+
+// TestStorageObligationAtRisk checks that storage obligations are only marked
+// at risk when they are unresolved and close to the end of their proof window.
+func TestStorageObligationAtRisk(t *testing.T) {
+	so := storageObligation{
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{
+				WindowStart: 100,
+				WindowEnd:   100 + proofDeadlineSafetyMargin*2,
+			}},
+		}},
+	}
+	if so.proofDeadlineRisk(100) != int(proofDeadlineSafetyMargin*2) {
+		t.Error("wrong proof deadline risk:", so.proofDeadlineRisk(100))
+	}
+	if so.proofDeadlineRisk(so.proofDeadline()+1) != -1 {
+		t.Error("proof deadline risk should be negative after the window closes")
+	}
+	if so.atRisk(100) {
+		t.Error("obligation with plenty of slack marked at risk")
+	}
+	if !so.atRisk(so.proofDeadline() - proofDeadlineSafetyMargin + 1) {
+		t.Error("obligation inside the safety margin not marked at risk")
+	}
+	so.ProofConfirmed = true
+	if so.atRisk(so.proofDeadline()) {
+		t.Error("obligation with a confirmed storage proof marked at risk")
+	}
+}
+
+// TestPrioritizeAtRiskObligations checks that the action items of storage
+// obligations at risk of missing their proof window are handled first.
+func TestPrioritizeAtRiskObligations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestPrioritizeAtRiskObligations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	ht.host.mu.RLock()
+	height := ht.host.blockHeight
+	ht.host.mu.RUnlock()
+	newObligation := func(windowEnd types.BlockHeight) storageObligation {
+		return storageObligation{
+			OriginTransactionSet: []types.Transaction{{
+				FileContracts: []types.FileContract{{WindowEnd: windowEnd}},
+			}},
+		}
+	}
+	// The obligation with a confirmed storage proof has the earliest
+	// deadline, but is not at risk.
+	confirmed := newObligation(height)
+	confirmed.ProofConfirmed = true
+	atRisk := newObligation(height + proofDeadlineSafetyMargin - 1)
+	safe := newObligation(height + proofDeadlineSafetyMargin*10)
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for _, so := range []storageObligation{confirmed, atRisk, safe} {
+			if err := putStorageObligation(tx, so); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknown := types.FileContractID{1}
+	actionItems := []types.FileContractID{confirmed.id(), atRisk.id(), safe.id(), unknown}
+	expected := []types.FileContractID{atRisk.id(), confirmed.id(), safe.id(), unknown}
+	ht.host.mu.RLock()
+	defer ht.host.mu.RUnlock()
+	ht.host.db.View(func(tx *bolt.Tx) error {
+		actionItems = ht.host.prioritizeAtRiskObligations(tx, actionItems)
+		return nil
+	})
+	for i := range expected {
+		if actionItems[i] != expected[i] {
+			t.Fatal("wrong order:", actionItems)
+		}
+	}
+}
+
+// TestCountObligations checks that the obligations reported in the financial
+// metrics are counted in a single pass over the storage obligations.
+func TestCountObligations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestCountObligations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	newObligation := func(windowStart, windowEnd types.BlockHeight) storageObligation {
+		return storageObligation{
+			OriginTransactionSet: []types.Transaction{{
+				FileContracts: []types.FileContract{{
+					WindowStart: windowStart,
+					WindowEnd:   windowEnd,
+				}},
+			}},
+		}
+	}
+	atRisk := newObligation(100, 101)
+	atRisk.NegotiationHeight = 100
+	atRisk.OriginConfirmed = true
+	unconfirmed := newObligation(1000, 2000)
+	unconfirmed.NegotiationHeight = 50
+	resolved := newObligation(50, 60)
+	resolved.ObligationStatus = obligationSucceeded
+	resolved.SectorRoots = []crypto.Hash{{1}, {2}}
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for _, so := range []storageObligation{atRisk, unconfirmed, resolved} {
+			if err := putStorageObligation(tx, so); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := ht.host.managedCountObligations(100, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.atRisk != 1 || counts.unconfirmed != 1 || counts.pendingSectorRemovals != 2 {
+		t.Errorf("wrong obligation counts: %+v", counts)
+	}
+}
+
+// TestStorageObligationUnconfirmed checks that the confirmation delay of a
+// storage obligation is measured from the submission of the transaction set
+// that is waiting for confirmation.
+func TestStorageObligationUnconfirmed(t *testing.T) {
+	so := storageObligation{NegotiationHeight: 100}
+	if so.confirmationDelay(110) != 10 {
+		t.Error("wrong origin confirmation delay:", so.confirmationDelay(110))
+	}
+	if so.unconfirmed(110, 10) || !so.unconfirmed(111, 10) {
+		t.Error("origin confirmation delay compared incorrectly against the limit")
+	}
+
+	// Once the origin is confirmed, nothing is waiting until the revision
+	// is submitted.
+	so.OriginConfirmed = true
+	if so.confirmationDelay(500) != 0 {
+		t.Error("obligation without a submitted revision has a confirmation delay")
+	}
+	so.RevisionSubmissionHeight = 400
+	if so.confirmationDelay(500) != 100 {
+		t.Error("wrong revision confirmation delay:", so.confirmationDelay(500))
+	}
+	so.RevisionConfirmed = true
+	if so.unconfirmed(500, 10) {
+		t.Error("obligation with a confirmed revision marked unconfirmed")
+	}
+
+	// Resolved obligations are never reported.
+	so.RevisionConfirmed = false
+	so.ObligationStatus = obligationRejected
+	if so.unconfirmed(500, 10) {
+		t.Error("resolved obligation marked unconfirmed")
+	}
+}
+
+// TestSubMetric checks that drifted financial metrics are reset to zero
+// instead of panicking when an obligation's values are removed.
+func TestSubMetric(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestObligationsByProofDeadline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	if c := ht.host.subMetric(types.NewCurrency64(5), types.NewCurrency64(3)); !c.Equals64(2) {
+		t.Error("expected 2, got", c)
+	}
+	if c := ht.host.subMetric(types.NewCurrency64(3), types.NewCurrency64(5)); !c.IsZero() {
+		t.Error("drifted metric should be reset to zero, got", c)
+	}
+}
+
+// TestCollateralBudget checks that the host refuses to add a storage
+// obligation that would exceed its collateral budget, and that the remaining
+// budget is reported in the financial metrics.
+func TestCollateralBudget(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestObligationsByProofDeadline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	settings := ht.host.InternalSettings()
+	settings.CollateralBudget = types.NewCurrency64(100)
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm := ht.host.FinancialMetrics(); !fm.RemainingCollateralBudget.Equals64(100) {
+		t.Fatal("expected a remaining budget of 100, got", fm.RemainingCollateralBudget)
+	}
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	so.LockedCollateral = types.NewCurrency64(101)
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != errCollateralBudgetExceeded {
+		t.Fatal("expected errCollateralBudgetExceeded, got", err)
+	}
+	if fm := ht.host.FinancialMetrics(); fm.ContractCount != 0 || !fm.RemainingCollateralBudget.Equals64(100) {
+		t.Fatal("rejected obligation should not change the financial metrics")
+	}
+
+	so.LockedCollateral = types.NewCurrency64(60)
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm := ht.host.FinancialMetrics(); !fm.RemainingCollateralBudget.Equals64(40) {
+		t.Fatal("expected a remaining budget of 40, got", fm.RemainingCollateralBudget)
+	}
+}
+
+// TestStorageConsistency checks that sectors which are not referenced by any
+// storage obligation are flagged by checkStorageConsistency.
+func TestStorageConsistency(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestStorageConsistency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	h := ht.host
+	if h.committedStorage() != 0 {
+		t.Error("host without storage obligations has committed storage")
+	}
+	h.mu.RLock()
+	err = h.checkStorageConsistency()
+	h.mu.RUnlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a sector which does not belong to any storage obligation.
+	sectorData := fastrand.Bytes(int(modules.SectorSize))
+	sectorRoot := crypto.MerkleRoot(sectorData)
+	err = h.AddSector(sectorRoot, sectorData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.mu.RLock()
+	err = h.checkStorageConsistency()
+	h.mu.RUnlock()
+	if err == nil {
+		t.Error("orphaned sector was not flagged")
+	}
+
+	// A resolved obligation whose sectors are being removed in the background
+	// may or may not still have its sectors stored.
+	so := storageObligation{
+		ObligationStatus: obligationSucceeded,
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{}},
+		}},
+		SectorRoots: []crypto.Hash{sectorRoot},
+	}
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		return putStorageObligation(tx, so)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.mu.Lock()
+	h.reconciling[so.id()] = struct{}{}
+	err = h.checkStorageConsistency()
+	h.mu.Unlock()
+	if err != nil {
+		t.Fatal("sector of an obligation being reconciled was flagged:", err)
+	}
+
+	// An orphaned sector is still flagged, and the report mentions the
+	// skipped obligation.
+	orphanData := fastrand.Bytes(int(modules.SectorSize))
+	orphanRoot := crypto.MerkleRoot(orphanData)
+	err = h.AddSector(orphanRoot, orphanData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.mu.RLock()
+	err = h.checkStorageConsistency()
+	h.mu.RUnlock()
+	if err == nil || !strings.Contains(err.Error(), "1 skipped obligations") {
+		t.Error("orphaned sector was not flagged with the skipped obligations:", err)
+	}
+	if err := h.RemoveSector(orphanRoot); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the sector has been removed, the storage is consistent as well.
+	if err := h.RemoveSector(sectorRoot); err != nil {
+		t.Fatal(err)
+	}
+	h.mu.RLock()
+	err = h.checkStorageConsistency()
+	h.mu.RUnlock()
+	if err != nil {
+		t.Fatal("removed sector of an obligation being reconciled was flagged:", err)
+	}
+}
+
+// TestReconcileStorage checks that sectors which are still held by resolved
+// storage obligations get removed by managedReconcileStorage.
+func TestReconcileStorage(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestReconcileStorage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Sectors which do not exist are considered removed.
+	if len(ht.host.managedRemoveSectors(types.FileContractID{}, []crypto.Hash{{1}, {2}})) != 0 {
+		t.Error("missing sectors were reported as failed removals")
+	}
+
+	// Add a sector and a resolved storage obligation that still references
+	// it, as if the earlier removal had failed.
+	sectorData := fastrand.Bytes(int(modules.SectorSize))
+	sectorRoot := crypto.MerkleRoot(sectorData)
+	err = ht.host.AddSector(sectorRoot, sectorData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	so := storageObligation{
+		ObligationStatus: obligationSucceeded,
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{}},
+		}},
+		SectorRoots: []crypto.Hash{sectorRoot},
+	}
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		return putStorageObligation(tx, so)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := ht.host.FinancialMetrics().PendingSectorRemovals; pending != 1 {
+		t.Error("expected 1 pending sector removal, got", pending)
+	}
+
+	// Reconciling should remove the sector and clear the sector roots.
+	err = ht.host.managedReconcileStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ht.host.ReadSector(sectorRoot); err == nil {
+		t.Error("sector was not removed")
+	}
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, so.id())
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(so.SectorRoots) != 0 {
+		t.Error("sector roots were not cleared from the storage obligation")
+	}
+	if pending := ht.host.FinancialMetrics().PendingSectorRemovals; pending != 0 {
+		t.Error("pending sector removals were not cleared:", pending)
+	}
+}
+
+// TestVerifyIntegrity checks that managedVerifyIntegrity detects sectors which
+// are missing, and does not compare the sector roots against the Merkle root
+// of the file contract.
+func TestVerifyIntegrity(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestVerifyIntegrity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	sectorData := fastrand.Bytes(int(modules.SectorSize))
+	sectorRoot := crypto.MerkleRoot(sectorData)
+	err = ht.host.AddSector(sectorRoot, sectorData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	so := storageObligation{
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{
+				FileMerkleRoot: cachedMerkleRoot([]crypto.Hash{sectorRoot}),
+			}},
+		}},
+		SectorRoots: []crypto.Hash{sectorRoot},
+	}
+	err = ht.host.managedVerifyIntegrity(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A Merkle root which differs from the tree of sector roots is not an
+	// integrity problem.
+	so.OriginTransactionSet[0].FileContracts[0].FileMerkleRoot = crypto.Hash{}
+	err = ht.host.managedVerifyIntegrity(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A sector which is missing from disk should be caught.
+	missingRoot := crypto.Hash{1}
+	so.SectorRoots = append(so.SectorRoots, missingRoot)
+	if ht.host.managedVerifyIntegrity(so) == nil {
+		t.Error("missing sector was not detected")
+	}
+}
+
+// TestValidateRevision checks that validateRevision rejects revisions with a
+// stale revision number, a lower host payout, or a mismatched file size.
+func TestValidateRevision(t *testing.T) {
+	so := storageObligation{
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{
+				RevisionNumber: 3,
+				ValidProofOutputs: []types.SiacoinOutput{
+					{Value: types.NewCurrency64(100)},
+					{Value: types.NewCurrency64(50)},
+				},
+			}},
+		}},
+	}
+	roots := []crypto.Hash{{1}}
+	revision := func(number uint64, hostPayout uint64, fileSize uint64) types.Transaction {
+		return types.Transaction{
+			FileContractRevisions: []types.FileContractRevision{{
+				NewRevisionNumber: number,
+				NewFileSize:       fileSize,
+				NewValidProofOutputs: []types.SiacoinOutput{
+					{Value: types.NewCurrency64(90)},
+					{Value: types.NewCurrency64(hostPayout)},
+				},
+			}},
+		}
+	}
+
+	if err := so.validateRevision(revision(4, 60, modules.SectorSize), roots); err != nil {
+		t.Error("valid revision was rejected:", err)
+	}
+	if err := so.validateRevision(revision(3, 60, modules.SectorSize), roots); err != errBadRevisionNumber {
+		t.Error("expected errBadRevisionNumber, got", err)
+	}
+	if err := so.validateRevision(revision(4, 40, modules.SectorSize), roots); err != errLowHostValidOutput {
+		t.Error("expected errLowHostValidOutput, got", err)
+	}
+	if err := so.validateRevision(revision(4, 60, 2*modules.SectorSize), roots); err != errBadFileSize {
+		t.Error("expected errBadFileSize, got", err)
+	}
+	if err := so.validateRevision(types.Transaction{}, roots); err != errInsaneRevisionSetRevisionCount {
+		t.Error("expected errInsaneRevisionSetRevisionCount, got", err)
+	}
+}
+
+// TestStorageObligationRevisionNumber checks that revisionNumber reports the
+// revision number of the latest revision, falling back to the file contract.
+func TestStorageObligationRevisionNumber(t *testing.T) {
+	so := storageObligation{
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{RevisionNumber: 2}},
+		}},
+	}
+	if so.revisionNumber() != 2 {
+		t.Error("wrong revision number for obligation without revisions:", so.revisionNumber())
+	}
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{NewRevisionNumber: 7}},
+	}}
+	if so.revisionNumber() != 7 {
+		t.Error("wrong revision number for revised obligation:", so.revisionNumber())
+	}
+}
+
+// TestDuplicateStorageObligation checks that adding a storage obligation twice
+// is rejected, and that upserting a known storage obligation does not count its
+// financial metrics twice.
+func TestDuplicateStorageObligation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestDuplicateStorageObligation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	defer ht.host.managedUnlockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm := ht.host.FinancialMetrics()
+
+	// Adding the obligation a second time should fail.
+	err = ht.host.managedAddStorageObligation(so)
+	if err != errDuplicateStorageObligation {
+		t.Fatal("expected errDuplicateStorageObligation, got", err)
+	}
+
+	// Upserting the obligation should succeed without changing the metrics.
+	err = ht.host.managedUpsertStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm2 := ht.host.FinancialMetrics()
+	if fm2.ContractCount != fm.ContractCount {
+		t.Error("contract count changed:", fm.ContractCount, fm2.ContractCount)
+	}
+	if !fm2.LockedStorageCollateral.Equals(fm.LockedStorageCollateral) {
+		t.Error("locked collateral changed")
+	}
+	if !fm2.PotentialContractCompensation.Equals(fm.PotentialContractCompensation) {
+		t.Error("potential contract compensation changed")
+	}
+
+	// Resolved obligations cannot be upserted.
+	ht.host.mu.Lock()
+	err = ht.host.removeStorageObligation(so, obligationRejected)
+	ht.host.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.managedUpsertStorageObligation(so)
+	if err != errDuplicateStorageObligation {
+		t.Fatal("expected errDuplicateStorageObligation, got", err)
+	}
+}
+
+// TestStorageObligationBandwidth checks that the bandwidth counters of a
+// storage obligation are reported by StorageObligations.
+func TestStorageObligationBandwidth(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestStorageObligationBandwidth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	so.DownloadBytes = 1000
+	so.UploadBytes = 2000
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+
+	sos := ht.host.StorageObligations()
+	if len(sos) != 1 {
+		t.Fatal("expected one storage obligation, got", len(sos))
+	}
+	if sos[0].DownloadBytes != 1000 || sos[0].UploadBytes != 2000 {
+		t.Fatal("bandwidth counters were not reported:", sos[0].DownloadBytes, sos[0].UploadBytes)
+	}
+}
+
+// TestRecentProofSuccessRate checks that the recent proof success rate only
+// considers the most recent outcomes.
+func TestRecentProofSuccessRate(t *testing.T) {
+	h := new(Host)
+	if outcomes, rate := h.recentProofSuccessRate(); outcomes != 0 || rate != 100 {
+		t.Fatal("unexpected success rate for a new host:", outcomes, rate)
+	}
+
+	// Fill the window with failures, then replace half of them with
+	// successes.
+	for i := 0; i < proofOutcomeWindow; i++ {
+		h.recordProofOutcome(false)
+	}
+	if outcomes, rate := h.recentProofSuccessRate(); outcomes != uint64(proofOutcomeWindow) || rate != 0 {
+		t.Fatal("unexpected success rate:", outcomes, rate)
+	}
+	for i := 0; i < proofOutcomeWindow/2; i++ {
+		h.recordProofOutcome(true)
+	}
+	if outcomes, rate := h.recentProofSuccessRate(); outcomes != uint64(proofOutcomeWindow) || rate != 50 {
+		t.Fatal("unexpected success rate:", outcomes, rate)
+	}
+}
+
+// TestStorageObligationRenterKey checks that the renter key of a storage
+// obligation survives encoding, and that storage obligations persisted without
+// a renter key can still be decoded.
+func TestStorageObligationRenterKey(t *testing.T) {
+	so := storageObligation{
+		RenterKey: types.SiaPublicKey{
+			Algorithm: types.SignatureEd25519,
+			Key:       fastrand.Bytes(32),
+		},
+	}
+	soBytes, err := json.Marshal(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded storageObligation
+	err = json.Unmarshal(soBytes, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.RenterKey.String() != so.RenterKey.String() {
+		t.Fatal("renter key did not survive encoding")
+	}
+
+	// Remove the renter key from the encoded obligation, as though it had been
+	// persisted by an older version of the host.
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(soBytes, &fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(fields, "RenterKey")
+	soBytes, err = json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded = storageObligation{}
+	err = json.Unmarshal(soBytes, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.RenterKey.Key) != 0 {
+		t.Fatal("expected an empty renter key")
+	}
+}
+
+// TestStorageObligationRevisionLimit checks that the revisions of a storage
+// obligation are counted per revision window.
+func TestStorageObligationRevisionLimit(t *testing.T) {
+	// The first window starts at the first revision, wherever that falls
+	// relative to the length of the window.
+	for _, start := range []types.BlockHeight{1, revisionLimitWindow / 2, revisionLimitWindow*3 + 1} {
+		var so storageObligation
+		for i := 0; i < 3; i++ {
+			if so.revisionLimitReached(start, 3) {
+				t.Fatal("limit reached after", i, "revisions")
+			}
+			so.recordRevision(start)
+		}
+		if so.RevisionWindowStart != start {
+			t.Fatal("window does not start at the first revision:", so.RevisionWindowStart, start)
+		}
+		if !so.revisionLimitReached(start, 3) || !so.revisionLimitReached(start+revisionLimitWindow-1, 3) {
+			t.Fatal("limit not reached within the window")
+		}
+		if !so.revisionLimitReached(start, 3) || so.revisionLimitReached(start, 4) {
+			t.Fatal("limit does not depend on the host setting")
+		}
+
+		// A revision in the next window starts a new count.
+		if so.revisionLimitReached(start+revisionLimitWindow, 3) {
+			t.Fatal("limit reached in a new window")
+		}
+		so.recordRevision(start + revisionLimitWindow)
+		if so.RevisionCount != 1 || so.RevisionWindowStart != start+revisionLimitWindow {
+			t.Fatal("revision count was not reset:", so.RevisionCount, so.RevisionWindowStart)
+		}
+	}
+}
+
+// TestSortByProofDeadline checks that storage obligations are sorted by proof
+// deadline, with a deterministic order for ties and unknown deadlines.
+func TestSortByProofDeadline(t *testing.T) {
+	deadlines := map[types.FileContractID]types.BlockHeight{
+		{1}: 30,
+		{2}: 10,
+		{3}: 20,
+		{4}: 10,
+	}
+	expected := []types.FileContractID{{2}, {4}, {3}, {1}, {5}, {6}}
+	for i := 0; i < 10; i++ {
+		// Build the input from the map so that it starts in a random order.
+		soids := []types.FileContractID{{6}, {5}}
+		for soid := range deadlines {
+			soids = append(soids, soid)
+		}
+		sortByProofDeadline(soids, deadlines)
+		for j := range expected {
+			if soids[j] != expected[j] {
+				t.Fatal("wrong order:", soids)
+			}
+		}
+	}
+}
+
+// TestObligationsByProofDeadline checks that the storage obligations in the
+// database are sorted by the proof deadline that they are stored with.
+func TestObligationsByProofDeadline(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestObligationsByProofDeadline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Store obligations with the proof deadlines 30, 10 and 20.
+	var soids []types.FileContractID
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for _, windowEnd := range []types.BlockHeight{30, 10, 20} {
+			so := storageObligation{
+				OriginTransactionSet: []types.Transaction{{
+					FileContracts: []types.FileContract{{WindowEnd: windowEnd}},
+				}},
+			}
+			soids = append(soids, so.id())
+			if err := putStorageObligation(tx, so); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := types.FileContractID{1}
+	expected := []types.FileContractID{soids[1], soids[2], soids[0], unknown}
+	ht.host.db.View(func(tx *bolt.Tx) error {
+		sorted := obligationsByProofDeadline(tx, append([]types.FileContractID{unknown}, soids...))
+		for i := range expected {
+			if sorted[i] != expected[i] {
+				t.Fatal("wrong order:", sorted)
+			}
+		}
+		return nil
+	})
+}
+
+// TestJitterHeight checks that the jitter of action items stays within its
+// range and never moves an action item past its limit.
+func TestJitterHeight(t *testing.T) {
+	seen := make(map[types.BlockHeight]bool)
+	for i := 0; i < 1000; i++ {
+		height := jitterHeight(100, 200, 3)
+		if height < 100 || height > 103 {
+			t.Fatal("jitter out of range:", height)
+		}
+		seen[height] = true
+
+		// Action items close to the limit get less jitter.
+		if height := jitterHeight(100, 101, 3); height > 101 {
+			t.Fatal("jitter moved the action item past the limit:", height)
+		}
+	}
+	if len(seen) != 4 {
+		t.Fatal("jitter does not cover its range:", seen)
+	}
+
+	// Action items at or past the limit, or without jitter, stay in place.
+	if height := jitterHeight(100, 100, 3); height != 100 {
+		t.Fatal("action item at the limit was jittered:", height)
+	}
+	if height := jitterHeight(100, 50, 3); height != 100 {
+		t.Fatal("action item past the limit was jittered:", height)
+	}
+	if height := jitterHeight(100, 200, 0); height != 100 {
+		t.Fatal("action item was jittered without jitter:", height)
+	}
+}
+
+// TestResubmissionJitterSetting checks that a ResubmissionJitter of zero
+// disables the jitter instead of falling back to the default.
+func TestResubmissionJitterSetting(t *testing.T) {
+	h := new(Host)
+	if jitter := h.resubmissionJitter(); jitter != 0 {
+		t.Error("zero ResubmissionJitter was replaced:", jitter)
+	}
+	h.settings.ResubmissionJitter = 7
+	if jitter := h.resubmissionJitter(); jitter != 7 {
+		t.Error("wrong resubmission jitter:", jitter)
+	}
+}
+
+// TestMaxObligations checks that the host refuses to add storage obligations
+// beyond its MaxObligations and stops advertising that it accepts contracts.
+func TestMaxObligations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestObligationsByProofDeadline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	settings := ht.host.InternalSettings()
+	settings.AcceptingContracts = true
+	settings.MaxObligations = 1
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm := ht.host.FinancialMetrics(); fm.ObligationLimit != 1 {
+		t.Fatal("expected an obligation limit of 1, got", fm.ObligationLimit)
+	}
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should decline contracts at the obligation limit")
+	}
+
+	so2, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so2.id())
+	err = ht.host.managedAddStorageObligation(so2)
+	ht.host.managedUnlockStorageObligation(so2.id())
+	if err != errMaxObligationsReached {
+		t.Fatal("expected errMaxObligationsReached, got", err)
+	}
+	if fm := ht.host.FinancialMetrics(); fm.ContractCount != 1 {
+		t.Fatal("rejected obligation should not change the contract count:", fm.ContractCount)
+	}
+
+	// Raising the limit lets the host accept contracts again.
+	settings.MaxObligations = 2
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should accept contracts below the raised limit")
+	}
+}
@@ -0,0 +1,185 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errObligationResolved is returned when a storage proof is requested
+	// for a storage obligation that has already been resolved.
+	errObligationResolved = errors.New("storage obligation has already been resolved")
+
+	// errProofConfirmed is returned when a storage proof is requested for a
+	// storage obligation whose storage proof has already been confirmed.
+	errProofConfirmed = errors.New("storage proof has already been confirmed")
+
+	// errNotInProofWindow is returned when a storage proof is requested for
+	// a storage obligation whose proof window is not open.
+	errNotInProofWindow = errors.New("storage obligation is not in its proof window")
+)
+
+// managedSubmitStorageProof builds the storage proof of a storage obligation
+// from the sectors on disk and submits it to the transaction pool, returning
+// the transaction fee that was paid.
+func (h *Host) managedSubmitStorageProof(so storageObligation) (types.Currency, error) {
+	sp, err := h.managedBuildStorageProof(so)
+	if err != nil {
+		return types.ZeroCurrency, err
+	}
+	return h.managedSubmitStorageProofs([]types.StorageProof{sp})
+}
+
+// managedBuildStorageProof builds the storage proof of a storage obligation
+// from the sectors on disk.
+func (h *Host) managedBuildStorageProof(so storageObligation) (types.StorageProof, error) {
+	if len(so.SectorRoots) == 0 {
+		return types.StorageProof{}, errors.New("storage obligation has no sectors")
+	}
+
+	// Get the index of the segment, and the index of the sector containing
+	// the segment.
+	segmentIndex, err := h.cs.StorageProofSegment(so.id())
+	if err != nil {
+		return types.StorageProof{}, fmt.Errorf("unable to fetch the storage proof segment: %v", err)
+	}
+	sectorIndex := segmentIndex / (modules.SectorSize / crypto.SegmentSize)
+	// Pull the corresponding sector into memory.
+	sectorRoot := so.SectorRoots[sectorIndex]
+	sectorBytes, err := h.ReadSector(sectorRoot)
+	if err != nil {
+		return types.StorageProof{}, fmt.Errorf("unable to read sector %v: %v", sectorRoot, err)
+	}
+
+	// Check that the sector has not been corrupted on disk. A proof built
+	// from bad data would be rejected, wasting the transaction fees.
+	if crypto.MerkleRoot(sectorBytes) != sectorRoot {
+		return types.StorageProof{}, fmt.Errorf("sector %v failed its integrity check", sectorRoot)
+	}
+
+	// Build the storage proof for just the sector.
+	sectorSegment := segmentIndex % (modules.SectorSize / crypto.SegmentSize)
+	base, cachedHashSet := crypto.MerkleProof(sectorBytes, sectorSegment)
+
+	// Using the sector, build a cached root.
+	log2SectorSize := uint64(0)
+	for 1<<log2SectorSize < (modules.SectorSize / crypto.SegmentSize) {
+		log2SectorSize++
+	}
+	ct := crypto.NewCachedTree(log2SectorSize)
+	ct.SetIndex(segmentIndex)
+	for _, root := range so.SectorRoots {
+		ct.Push(root)
+	}
+	hashSet := ct.Prove(base, cachedHashSet)
+	sp := types.StorageProof{
+		ParentID: so.id(),
+		HashSet:  hashSet,
+	}
+	copy(sp.Segment[:], base)
+	return sp, nil
+}
+
+// managedSubmitStorageProofs submits a transaction containing the given
+// storage proofs to the transaction pool, returning the transaction fee that
+// was paid. The transaction is only valid if every proof is valid.
+func (h *Host) managedSubmitStorageProofs(sps []types.StorageProof) (types.Currency, error) {
+	// Create and build the transaction with the storage proofs.
+	builder := h.wallet.StartTransaction()
+	_, feeRecommendation := h.tpool.FeeEstimation()
+	txnSize := uint64(len(encoding.Marshal(sps)) + 300)
+	requiredFee := feeRecommendation.Mul64(txnSize)
+	err := builder.FundSiacoins(requiredFee)
+	if err != nil {
+		return types.ZeroCurrency, fmt.Errorf("unable to fund the storage proof transaction fee: %v", err)
+	}
+	builder.AddMinerFee(requiredFee)
+	for _, sp := range sps {
+		builder.AddStorageProof(sp)
+	}
+	storageProofSet, err := builder.Sign(true)
+	if err != nil {
+		builder.Drop()
+		return types.ZeroCurrency, fmt.Errorf("unable to sign the storage proof transaction: %v", err)
+	}
+	err = h.tpool.AcceptTransactionSet(storageProofSet)
+	if err != nil {
+		return types.ZeroCurrency, fmt.Errorf("unable to submit the storage proof transaction: %v", err)
+	}
+	return requiredFee, nil
+}
+
+// SubmitStorageProof rebuilds the storage proof of a storage obligation and
+// submits it to the transaction pool right away, rather than waiting for the
+// host to handle the obligation on its own. The proof is submitted even if
+// the transaction fee exceeds the revenue of the obligation. An error is
+// returned if the obligation is unknown or resolved, if its storage proof has
+// already been confirmed, or if its proof window is not open.
+func (h *Host) SubmitStorageProof(soid types.FileContractID) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.managedLockStorageObligation(soid)
+	defer h.managedUnlockStorageObligation(soid)
+
+	var so storageObligation
+	h.mu.RLock()
+	blockHeight := h.blockHeight
+	err = h.db.View(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, soid)
+		return err
+	})
+	h.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if so.ObligationStatus != obligationUnresolved {
+		return errObligationResolved
+	}
+	if so.ProofConfirmed {
+		return errProofConfirmed
+	}
+	if blockHeight < so.expiration() || blockHeight > so.proofDeadline() {
+		return errNotInProofWindow
+	}
+
+	fee, err := h.managedSubmitStorageProof(so)
+	if err != nil {
+		h.logObligation(LogWarn, soid, err, "Unable to submit storage proof on demand")
+		return err
+	}
+	h.log.Println("Submitted storage proof on demand, id", soid)
+
+	// Record the fee, and make sure that the host checks whether the storage
+	// proof got confirmed. The consensus set may have updated the obligation
+	// while the proof was submitted, so only the fees are written back.
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err = h.queueActionItem(so.proofDeadline(), soid)
+	if err != nil {
+		h.logObligation(LogWarn, soid, err, "Error queuing action item")
+	}
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		stored, err := getStorageObligation(tx, soid)
+		if err != nil {
+			return err
+		}
+		stored.TransactionFeesAdded = stored.TransactionFeesAdded.Add(fee)
+		return putStorageObligation(tx, stored)
+	})
+	if err != nil {
+		return err
+	}
+	h.financialMetrics.TransactionFeeExpenses = h.financialMetrics.TransactionFeeExpenses.Add(fee)
+	return h.saveSync()
+}
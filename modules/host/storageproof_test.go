@@ -0,0 +1,153 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestSubmitStorageProofErrors checks that storage proofs are only submitted
+// on demand for unresolved storage obligations in their proof window.
+func TestSubmitStorageProofErrors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestSubmitStorageProofErrors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Unknown storage obligation.
+	err = ht.host.SubmitStorageProof(types.FileContractID{1})
+	if err != errNoStorageObligation {
+		t.Fatal("expected errNoStorageObligation, got", err)
+	}
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The proof window has not opened yet.
+	err = ht.host.SubmitStorageProof(so.id())
+	if err != errNotInProofWindow {
+		t.Fatal("expected errNotInProofWindow, got", err)
+	}
+
+	// Resolved storage obligation.
+	ht.host.mu.Lock()
+	err = ht.host.removeStorageObligation(so, obligationRejected)
+	ht.host.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.SubmitStorageProof(so.id())
+	if err != errObligationResolved {
+		t.Fatal("expected errObligationResolved, got", err)
+	}
+}
+
+// TestSubmitStorageProof checks that a storage proof submitted on demand gets
+// confirmed, and that its fee is recorded without overwriting the state that
+// the consensus set keeps on the obligation.
+func TestSubmitStorageProof(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestSubmitStorageProof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Add a storage obligation with a single sector.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sectorRoot, sectorData := randSector()
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+	validPayouts, missedPayouts := so.payouts()
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:              so.id(),
+			UnlockConditions:      types.UnlockConditions{},
+			NewRevisionNumber:     1,
+			NewFileSize:           uint64(len(sectorData)),
+			NewFileMerkleRoot:     sectorRoot,
+			NewWindowStart:        so.expiration(),
+			NewWindowEnd:          so.proofDeadline(),
+			NewValidProofOutputs:  validPayouts,
+			NewMissedProofOutputs: missedPayouts,
+			NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
+		}},
+	}}
+	err = ht.host.modifyStorageObligation(so, sectorEncryption{}, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.tpool.AcceptTransactionSet(so.RevisionTransactionSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mine until the proof window opens, before the host submits the proof
+	// on its own.
+	for ht.host.blockHeight < so.expiration() {
+		_, err := ht.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	expenses := ht.host.FinancialMetrics().TransactionFeeExpenses
+	err = ht.host.SubmitStorageProof(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored storageObligation
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		stored, err = getStorageObligation(tx, so.id())
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.OriginConfirmed || !stored.RevisionConfirmed {
+		t.Fatal("confirmation flags were overwritten by the proof submission")
+	}
+	if stored.TransactionFeesAdded.IsZero() {
+		t.Fatal("the fee of the storage proof was not recorded on the obligation")
+	}
+	expenses = expenses.Add(stored.TransactionFeesAdded)
+	if fm := ht.host.FinancialMetrics(); !fm.TransactionFeeExpenses.Equals(expenses) {
+		t.Fatal("the fee of the storage proof was not recorded as an expense", fm.TransactionFeeExpenses, expenses)
+	}
+
+	// Once the proof is confirmed, it is not submitted again.
+	_, err = ht.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.SubmitStorageProof(so.id())
+	if err != errProofConfirmed {
+		t.Fatal("expected errProofConfirmed, got", err)
+	}
+}
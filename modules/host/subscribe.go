@@ -0,0 +1,71 @@
+package host
+
+import (
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// newStorageObligationEvent creates an event describing a transition of the
+// storage obligation.
+func newStorageObligationEvent(eventType modules.StorageObligationEventType, so storageObligation) modules.StorageObligationEvent {
+	return modules.StorageObligationEvent{
+		Type:         eventType,
+		ObligationID: so.id(),
+
+		DataSize:         so.fileSize(),
+		LockedCollateral: so.LockedCollateral,
+		PotentialRevenue: so.value(),
+		RiskedCollateral: so.RiskedCollateral,
+	}
+}
+
+// notifyStorageObligationSubscribers sends an event describing a transition of
+// the storage obligation to every subscriber. It must be called while the host
+// is locked.
+func (h *Host) notifyStorageObligationSubscribers(eventType modules.StorageObligationEventType, so storageObligation) {
+	if len(h.obligationSubscribers) == 0 {
+		return
+	}
+	h.sendStorageObligationEvents([]modules.StorageObligationEvent{newStorageObligationEvent(eventType, so)})
+}
+
+// sendStorageObligationEvents sends a batch of events to every subscriber, in
+// order. It must be called while the host is locked.
+func (h *Host) sendStorageObligationEvents(events []modules.StorageObligationEvent) {
+	for _, event := range events {
+		for _, subscriber := range h.obligationSubscribers {
+			subscriber.ProcessStorageObligationEvent(event)
+		}
+	}
+}
+
+// StorageObligationSubscribe adds a subscriber to the host. The subscriber
+// will be notified of every transition in the lifecycle of the host's storage
+// obligations from now on.
+func (h *Host) StorageObligationSubscribe(subscriber modules.StorageObligationSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Check that this subscriber is not already subscribed.
+	for _, s := range h.obligationSubscribers {
+		if s == subscriber {
+			build.Critical("refusing to double-subscribe subscriber")
+			return
+		}
+	}
+	h.obligationSubscribers = append(h.obligationSubscribers, subscriber)
+}
+
+// StorageObligationUnsubscribe removes a subscriber from the host. If the
+// subscriber is not subscribed, StorageObligationUnsubscribe does nothing.
+func (h *Host) StorageObligationUnsubscribe(subscriber modules.StorageObligationSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.obligationSubscribers {
+		if h.obligationSubscribers[i] == subscriber {
+			h.obligationSubscribers = append(h.obligationSubscribers[:i], h.obligationSubscribers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// eventRecorder is a storage obligation subscriber that records every event it
+// receives.
+type eventRecorder struct {
+	events []modules.StorageObligationEvent
+}
+
+// ProcessStorageObligationEvent records the event.
+func (er *eventRecorder) ProcessStorageObligationEvent(event modules.StorageObligationEvent) {
+	er.events = append(er.events, event)
+}
+
+// TestStorageObligationSubscribe checks that subscribers are notified when a
+// storage obligation is added and confirmed, and that unsubscribed subscribers
+// are not.
+func TestStorageObligationSubscribe(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestStorageObligationSubscribe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	er := new(eventRecorder)
+	ht.host.StorageObligationSubscribe(er)
+
+	// Add a storage obligation.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+
+	ht.host.mu.RLock()
+	events := append([]modules.StorageObligationEvent(nil), er.events...)
+	ht.host.mu.RUnlock()
+	if len(events) != 1 {
+		t.Fatal("expected one event, got", len(events))
+	}
+	if events[0].Type != modules.StorageObligationAdded {
+		t.Error("expected an added event, got", events[0].Type)
+	}
+	if events[0].ObligationID != so.id() {
+		t.Error("event has the wrong obligation id")
+	}
+	if events[0].DataSize != so.fileSize() || !events[0].LockedCollateral.Equals(so.LockedCollateral) {
+		t.Error("event does not match the storage obligation")
+	}
+
+	// Mine a block to confirm the file contract.
+	_, err = ht.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.mu.RLock()
+	events = append([]modules.StorageObligationEvent(nil), er.events...)
+	ht.host.mu.RUnlock()
+	if len(events) != 2 || events[1].Type != modules.StorageObligationOriginConfirmed {
+		t.Fatal("expected an origin confirmed event:", events)
+	}
+
+	// Unsubscribe and add another storage obligation, no new events should be
+	// received.
+	ht.host.StorageObligationUnsubscribe(er)
+	so2, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so2.id())
+	err = ht.host.managedAddStorageObligation(so2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so2.id())
+	ht.host.mu.RLock()
+	numEvents := len(er.events)
+	ht.host.mu.RUnlock()
+	if numEvents != 2 {
+		t.Error("unsubscribed subscriber received an event")
+	}
+}
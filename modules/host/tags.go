@@ -0,0 +1,84 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+const (
+	// maxObligationTags is the maximum number of tags that a storage
+	// obligation can carry.
+	maxObligationTags = 64
+
+	// maxTagLength is the maximum length of the key and of the value of a
+	// tag, in bytes.
+	maxTagLength = 256
+)
+
+var (
+	// errEmptyTagKey is returned when a tag with an empty key is set.
+	errEmptyTagKey = errors.New("tag key cannot be empty")
+
+	// errTagTooLong is returned when the key or the value of a tag is longer
+	// than maxTagLength.
+	errTagTooLong = fmt.Errorf("tag keys and values are limited to %v bytes", maxTagLength)
+
+	// errTooManyTags is returned when a storage obligation would carry more
+	// than maxObligationTags tags.
+	errTooManyTags = fmt.Errorf("storage obligations are limited to %v tags", maxObligationTags)
+)
+
+// UpdateStorageObligationTags sets the tags in 'set' on a storage obligation,
+// replacing the values of tags which already exist, and then removes the tags
+// whose keys are in 'remove'. Tags are metadata for the operator and have no
+// effect on how the host handles the obligation. Only the storage obligation
+// is written to disk, the rest of the host is left untouched.
+func (h *Host) UpdateStorageObligationTags(soid types.FileContractID, set map[string]string, remove []string) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	for k, v := range set {
+		if k == "" {
+			return errEmptyTagKey
+		}
+		if len(k) > maxTagLength || len(v) > maxTagLength {
+			return errTagTooLong
+		}
+	}
+
+	// The obligation is locked so that the update is not lost to a concurrent
+	// modification of the obligation.
+	h.managedLockStorageObligation(soid)
+	defer h.managedUnlockStorageObligation(soid)
+	return h.db.Update(func(tx *bolt.Tx) error {
+		so, err := getStorageObligation(tx, soid)
+		if err != nil {
+			return err
+		}
+		tags := make(map[string]string)
+		for k, v := range so.Tags {
+			tags[k] = v
+		}
+		for k, v := range set {
+			tags[k] = v
+		}
+		for _, k := range remove {
+			delete(tags, k)
+		}
+		if len(tags) > maxObligationTags {
+			return errTooManyTags
+		}
+		if len(tags) == 0 {
+			tags = nil
+		}
+		so.Tags = tags
+		return putStorageObligation(tx, so)
+	})
+}
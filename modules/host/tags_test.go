@@ -0,0 +1,82 @@
+package host
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestUpdateStorageObligationTags checks that tags can be set on and removed
+// from storage obligations, and that they are reported by StorageObligations.
+func TestUpdateStorageObligationTags(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestUpdateStorageObligationTags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm := ht.host.FinancialMetrics()
+
+	// Set two tags, then overwrite one and remove the other.
+	err = ht.host.UpdateStorageObligationTags(so.id(), map[string]string{"folder": "a", "cohort": "beta"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.UpdateStorageObligationTags(so.id(), map[string]string{"folder": "b"}, []string{"cohort"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sos := ht.host.StorageObligations()
+	if len(sos) != 1 {
+		t.Fatal("expected 1 storage obligation, got", len(sos))
+	}
+	if len(sos[0].Tags) != 1 || sos[0].Tags["folder"] != "b" {
+		t.Fatal("wrong tags:", sos[0].Tags)
+	}
+	// Tags have no effect on the accounting of the host.
+	if fm2 := ht.host.FinancialMetrics(); fm2.ContractCount != fm.ContractCount || !fm2.LockedStorageCollateral.Equals(fm.LockedStorageCollateral) {
+		t.Error("updating tags changed the financial metrics")
+	}
+
+	// Upserting the obligation keeps its tags.
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedUpsertStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ht.host.StorageObligations()[0].Tags["folder"] != "b" {
+		t.Fatal("upsert dropped the tags")
+	}
+
+	// Invalid tags are rejected.
+	err = ht.host.UpdateStorageObligationTags(so.id(), map[string]string{"": "a"}, nil)
+	if err != errEmptyTagKey {
+		t.Fatal("expected errEmptyTagKey, got", err)
+	}
+	err = ht.host.UpdateStorageObligationTags(so.id(), map[string]string{"a": strings.Repeat("a", maxTagLength+1)}, nil)
+	if err != errTagTooLong {
+		t.Fatal("expected errTagTooLong, got", err)
+	}
+	tags := make(map[string]string)
+	for i := 0; i <= maxObligationTags; i++ {
+		tags[strings.Repeat("a", i+1)] = ""
+	}
+	err = ht.host.UpdateStorageObligationTags(so.id(), tags, nil)
+	if err != errTooManyTags {
+		t.Fatal("expected errTooManyTags, got", err)
+	}
+}
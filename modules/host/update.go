@@ -4,8 +4,11 @@ package host
 // it was the *most recent* revision that got confirmed.
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
+	"sort"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
@@ -37,6 +40,9 @@ func (h *Host) initRescan() error {
 			so.OriginConfirmed = false
 			so.RevisionConfirmed = false
 			so.ProofConfirmed = false
+			if so.ObligationStatus == obligationUnresolved {
+				h.watchOriginInputs(so)
+			}
 			allObligations = append(allObligations, so)
 			soBytes, err = json.Marshal(so)
 			if err != nil {
@@ -53,6 +59,21 @@ func (h *Host) initRescan() error {
 		return err
 	}
 
+	// The host only needs the blocks that can contain transactions for its
+	// storage obligations, so the rescan can start from the most recent
+	// consensus checkpoint before the oldest obligation was negotiated.
+	start := modules.ConsensusChangeBeginning
+	scanHeight := h.cs.Height()
+	for _, so := range allObligations {
+		if so.NegotiationHeight < scanHeight {
+			scanHeight = so.NegotiationHeight
+		}
+	}
+	if id, height, exists := h.cs.ConsensusCheckpoint(scanHeight); exists {
+		start = id
+		h.blockHeight = height
+	}
+
 	// Subscribe to the consensus set. This is a blocking call that will not
 	// return until the host has fully caught up to the current block.
 	//
@@ -61,7 +82,7 @@ func (h *Host) initRescan() error {
 	// it happens while blocking, and because there is no actual host lock held
 	// at this time, none of the host external functions are exposed, so it is
 	// save to make the exported call.
-	err = h.cs.ConsensusSetSubscribe(h, modules.ConsensusChangeBeginning, h.tg.StopChan())
+	err = h.cs.ConsensusSetSubscribe(h, start, h.tg.StopChan())
 	if err != nil {
 		return err
 	}
@@ -72,10 +93,7 @@ func (h *Host) initRescan() error {
 	// Re-queue all of the action items for the storage obligations.
 	for i, so := range allObligations {
 		soid := so.id()
-		err1 := h.queueActionItem(h.blockHeight+resubmissionTimeout, soid)
-		err2 := h.queueActionItem(so.expiration()-revisionSubmissionBuffer, soid)
-		err3 := h.queueActionItem(so.expiration()+resubmissionTimeout, soid)
-		err = composeErrors(err1, err2, err3)
+		err = h.queueObligationActionItems(so)
 		if err != nil {
 			h.log.Println("dropping storage obligation during rescan, id", so.id())
 		}
@@ -86,6 +104,8 @@ func (h *Host) initRescan() error {
 			err := h.tpool.AcceptTransactionSet(allObligations[i].OriginTransactionSet)
 			if err != nil {
 				h.log.Println("Unable to submit contract transaction set after rescan:", soid)
+			} else {
+				h.trackTransactionSet(soid, allObligations[i].OriginTransactionSet)
 			}
 		}(i)
 	}
@@ -113,9 +133,137 @@ func (h *Host) initConsensusSubscription() error {
 	h.tg.OnStop(func() {
 		h.cs.Unsubscribe(h)
 	})
+	return h.initRequeueActionItems()
+}
+
+// initRequeueActionItems rebuilds the action items of every unresolved storage
+// obligation from its confirmation flags. Action items which were being
+// handled when the host last shut down are otherwise lost, because the height
+// they were queued at has already been processed.
+func (h *Host) initRequeueActionItems() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var unresolved []storageObligation
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return err
+			}
+			if so.ObligationStatus == obligationUnresolved {
+				unresolved = append(unresolved, so)
+				if !so.OriginConfirmed {
+					h.watchOriginInputs(so)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, so := range unresolved {
+		err = h.queueObligationActionItems(so)
+		if err != nil {
+			h.log.Println("Unable to requeue action items for storage obligation", so.id(), err)
+		}
+	}
 	return nil
 }
 
+// sortByProofDeadline sorts storage obligation ids by the proof deadline of
+// the obligations, earliest first, so that the most urgent obligations are
+// handled first. Ties are broken by id, and obligations without a known
+// deadline go last, so that the order does not depend on map iteration.
+func sortByProofDeadline(soids []types.FileContractID, deadlines map[types.FileContractID]types.BlockHeight) {
+	sort.Slice(soids, func(i, j int) bool {
+		di, iKnown := deadlines[soids[i]]
+		dj, jKnown := deadlines[soids[j]]
+		if iKnown != jKnown {
+			return iKnown
+		}
+		if di != dj {
+			return di < dj
+		}
+		return bytes.Compare(soids[i][:], soids[j][:]) < 0
+	})
+}
+
+// obligationsByProofDeadline sorts the ids of a set of storage obligations by
+// proof deadline using sortByProofDeadline. Only the deadlines are kept while
+// sorting, so that the sector roots of the obligations are not all held in
+// memory at once.
+func obligationsByProofDeadline(tx *bolt.Tx, soids []types.FileContractID) []types.FileContractID {
+	deadlines := make(map[types.FileContractID]types.BlockHeight)
+	for _, soid := range soids {
+		if so, err := getStorageObligation(tx, soid); err == nil {
+			deadlines[soid] = so.proofDeadline()
+		}
+	}
+	sortByProofDeadline(soids, deadlines)
+	return soids
+}
+
+// prioritizeAtRiskObligations moves the storage obligations among a set of
+// action items that are at risk of missing their proof window to the front,
+// keeping the order within both groups. A warning is logged for every
+// obligation that is at risk.
+func (h *Host) prioritizeAtRiskObligations(tx *bolt.Tx, actionItems []types.FileContractID) []types.FileContractID {
+	var atRisk, others []types.FileContractID
+	for _, soid := range actionItems {
+		so, err := getStorageObligation(tx, soid)
+		if err != nil || !so.atRisk(h.blockHeight) {
+			others = append(others, soid)
+			continue
+		}
+		h.logObligation(LogWarn, soid, nil, fmt.Sprintf("Storage obligation has %v blocks left before its proof window closes", so.proofDeadlineRisk(h.blockHeight)))
+		atRisk = append(atRisk, soid)
+	}
+	return append(atRisk, others...)
+}
+
+// requeueRevertedObligations queues action items for the storage obligations
+// which had a transaction reverted by a reorg, so that the reverted
+// transactions are resubmitted. Only the affected obligations are requeued,
+// every other obligation keeps its existing action items. Obligations whose
+// transactions were confirmed again later in the same consensus change are
+// left alone. Obligations are requeued in order of their proof deadline.
+func (h *Host) requeueRevertedObligations(soids map[types.FileContractID]struct{}) {
+	ids := make([]types.FileContractID, 0, len(soids))
+	for soid := range soids {
+		ids = append(ids, soid)
+	}
+	_ = h.db.View(func(tx *bolt.Tx) error {
+		ids = obligationsByProofDeadline(tx, ids)
+		return nil
+	})
+
+	for _, soid := range ids {
+		var so storageObligation
+		err := h.db.View(func(tx *bolt.Tx) error {
+			var err error
+			so, err = getStorageObligation(tx, soid)
+			return err
+		})
+		if err != nil {
+			h.log.Println("Unable to load reverted storage obligation:", err)
+			continue
+		}
+		if so.ObligationStatus != obligationUnresolved {
+			continue
+		}
+		if so.OriginConfirmed && (so.RevisionConfirmed || len(so.RevisionTransactionSet) == 0) && so.ProofConfirmed {
+			continue
+		}
+		err = h.queueObligationActionItems(so)
+		if err != nil {
+			h.log.Println("Unable to requeue action items for reverted storage obligation:", err)
+		}
+	}
+}
+
 // ProcessConsensusChange will be called by the consensus set every time there
 // is a change to the blockchain.
 func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
@@ -128,7 +276,15 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 	// Wrap the whole parsing into a single large database tx to keep things
 	// efficient.
+	//
+	// Every change made by the consensus change is applied in one database
+	// transaction, so that the host never sees a partially applied block.
+	// Events for subscribers are held back until the transaction has been
+	// committed.
 	var actionItems []types.FileContractID
+	var events []modules.StorageObligationEvent
+	revertedObligations := make(map[types.FileContractID]struct{})
+	doubleSpends := make(map[types.FileContractID]doubleSpend)
 	err := h.db.Update(func(tx *bolt.Tx) error {
 		for _, block := range cc.RevertedBlocks {
 			// Look for transactions relevant to open storage obligations.
@@ -150,6 +306,10 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						if so.ObligationStatus == obligationUnresolved {
+							h.watchOriginInputs(so)
+						}
+						revertedObligations[fcid] = struct{}{}
 					}
 				}
 
@@ -169,6 +329,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						revertedObligations[fcr.ParentID] = struct{}{}
 					}
 				}
 
@@ -189,6 +350,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						revertedObligations[sp.ParentID] = struct{}{}
 					}
 				}
 			}
@@ -217,10 +379,13 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 							continue
 						}
 						so.OriginConfirmed = true
+						so.ResubmissionAttempts = 0
 						err = putStorageObligation(tx, so)
 						if err != nil {
 							continue
 						}
+						h.unwatchOriginInputs(so)
+						events = append(events, newStorageObligationEvent(modules.StorageObligationOriginConfirmed, so))
 					}
 				}
 
@@ -236,10 +401,12 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 							continue
 						}
 						so.RevisionConfirmed = true
+						so.ResubmissionAttempts = 0
 						err = putStorageObligation(tx, so)
 						if err != nil {
 							continue
 						}
+						events = append(events, newStorageObligationEvent(modules.StorageObligationRevisionConfirmed, so))
 					}
 				}
 
@@ -259,10 +426,18 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						events = append(events, newStorageObligationEvent(modules.StorageObligationProofConfirmed, so))
 					}
 				}
 			}
 
+			// Look for transactions which spend the outputs of origin
+			// transaction sets that have not been confirmed. The origin
+			// transaction sets confirmed by the block are no longer watched.
+			for soid, ds := range h.findDoubleSpends(block) {
+				doubleSpends[soid] = ds
+			}
+
 			// Height is not adjusted when dealing with the genesis block because
 			// the default height is 0 and the genesis block height is 0. If adding
 			// the genesis block, height will already be at height 0 and should not
@@ -291,11 +466,33 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 				}
 			}
 		}
+
+		// Action items are dispatched in order of the proof deadline of their
+		// obligations, obligations that are at risk of missing their proof
+		// window go first.
+		actionItems = obligationsByProofDeadline(tx, actionItems)
+		actionItems = h.prioritizeAtRiskObligations(tx, actionItems)
 		return nil
 	})
 	if err != nil {
 		h.log.Println(err)
+	} else {
+		h.sendStorageObligationEvents(events)
+	}
+	h.requeueRevertedObligations(revertedObligations)
+	doubleSpendIDs := make([]types.FileContractID, 0, len(doubleSpends))
+	for soid := range doubleSpends {
+		doubleSpendIDs = append(doubleSpendIDs, soid)
+	}
+	_ = h.db.View(func(tx *bolt.Tx) error {
+		doubleSpendIDs = obligationsByProofDeadline(tx, doubleSpendIDs)
+		return nil
+	})
+	for _, soid := range doubleSpendIDs {
+		go h.threadedRejectDoubleSpentObligation(soid, doubleSpends[soid])
 	}
+	// The action items are handled concurrently, storage proofs are admitted
+	// by the proof queue in order of their proof deadline as well.
 	for i := range actionItems {
 		go h.threadedHandleActionItem(actionItems[i])
 	}
@@ -304,9 +501,104 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 	// change.
 	h.recentChange = cc.ID
 
+	// Re-announce the host if its previous announcement is getting old.
+	if h.reannounceDue() {
+		h.reannouncing = true
+		h.reannounceAttempt = h.blockHeight
+		go h.threadedReannounce()
+	}
+
 	// Save the host.
 	err = h.saveSync()
 	if err != nil {
 		h.log.Println("ERROR: could not save during ProcessConsensusChange:", err)
 	}
 }
+
+// pendingTransactionSet is a transaction set that the host submitted to the
+// transaction pool for a storage obligation.
+type pendingTransactionSet struct {
+	soid   types.FileContractID
+	txnSet []types.Transaction
+}
+
+// trackTransactionSet records the final transaction of a transaction set that
+// was submitted to the transaction pool for a storage obligation. If the
+// transaction pool drops the transaction before it is confirmed, the same
+// transaction set is submitted again right away instead of waiting for the
+// next action item of the obligation.
+func (h *Host) trackTransactionSet(soid types.FileContractID, txnSet []types.Transaction) {
+	if len(txnSet) == 0 {
+		return
+	}
+	h.pendingMu.Lock()
+	h.pendingTransactions[txnSet[len(txnSet)-1].ID()] = pendingTransactionSet{
+		soid:   soid,
+		txnSet: txnSet,
+	}
+	h.pendingMu.Unlock()
+}
+
+// threadedResubmitTransactionSet submits a transaction set that was dropped by
+// the transaction pool again, unless its storage obligation has been resolved
+// in the meantime. The transaction set already pays the fee recorded for the
+// obligation, so nothing about the obligation is changed. The action items of
+// the obligation are left alone, they still deal with sets that cannot get
+// back into the transaction pool.
+func (h *Host) threadedResubmitTransactionSet(pending pendingTransactionSet) {
+	err := h.tg.Add()
+	if err != nil {
+		return
+	}
+	defer h.tg.Done()
+
+	var so storageObligation
+	h.mu.RLock()
+	err = h.db.View(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, pending.soid)
+		return err
+	})
+	h.mu.RUnlock()
+	if err != nil || so.ObligationStatus != obligationUnresolved {
+		return
+	}
+
+	err = h.tpool.AcceptTransactionSet(pending.txnSet)
+	if err != nil && err != modules.ErrDuplicateTransactionSet {
+		h.log.Debugln("Could not resubmit dropped transaction set for storage obligation", pending.soid, err)
+		return
+	}
+	h.trackTransactionSet(pending.soid, pending.txnSet)
+}
+
+// ReceiveUpdatedUnconfirmedTransactions checks the transactions that left the
+// transaction pool for transactions submitted by the host. Storage obligations
+// whose transactions were dropped without being confirmed are resubmitted
+// immediately.
+//
+// The transaction pool calls this method while under lock, so the host lock
+// must not be acquired here, as the host calls the transaction pool while
+// holding it.
+func (h *Host) ReceiveUpdatedUnconfirmedTransactions(diff *modules.TransactionPoolDiff) {
+	var dropped []pendingTransactionSet
+	h.pendingMu.Lock()
+	for _, txid := range diff.ConfirmedTransactions {
+		delete(h.pendingTransactions, txid)
+	}
+	for _, txid := range diff.DroppedTransactions {
+		pending, exists := h.pendingTransactions[txid]
+		if !exists {
+			continue
+		}
+		delete(h.pendingTransactions, txid)
+		dropped = append(dropped, pending)
+	}
+	h.pendingMu.Unlock()
+
+	// Only the dropped transaction sets are submitted again, handling the
+	// obligations in full would add fees and resubmission attempts.
+	for _, pending := range dropped {
+		h.log.Debugln("Transaction pool dropped a transaction for storage obligation", pending.soid, "resubmitting")
+		go h.threadedResubmitTransactionSet(pending)
+	}
+}
@@ -1,14 +1,18 @@
 package host
 
 import (
+	"encoding/binary"
 	"io/ioutil"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/fastrand"
+
+	"github.com/coreos/bbolt"
 )
 
 // TestStorageProof checks that the host can create and submit a storage proof.
@@ -177,3 +181,222 @@ func TestIntegrationAutoRescan(t *testing.T) {
 	// cleanly.
 	ht.host = h
 }
+
+// TestRequeueActionItems checks that initRequeueActionItems rebuilds the
+// action items of unresolved storage obligations without duplicating them.
+func TestRequeueActionItems(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestRequeueActionItems")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+
+	// Wipe all of the action items, as though they had been lost.
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket(bucketActionItems)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucket(bucketActionItems)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Requeue the action items twice, the second call should not add any
+	// duplicates.
+	for i := 0; i < 2; i++ {
+		err = ht.host.initRequeueActionItems()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	heights := []types.BlockHeight{
+		ht.host.blockHeight + resubmissionTimeout,
+		so.expiration() + resubmissionTimeout,
+	}
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		bai := tx.Bucket(bucketActionItems)
+		for _, height := range heights {
+			heightBytes := make([]byte, 8)
+			binary.BigEndian.PutUint64(heightBytes, uint64(height))
+			items := bai.Get(heightBytes)
+			if len(items) != crypto.HashSize {
+				t.Errorf("expected one action item at height %v, got %v bytes", height, len(items))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRequeueRevertedObligations checks that a reorg only requeues action items
+// for the storage obligations that had a transaction reverted.
+func TestRequeueRevertedObligations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester("TestRequeueRevertedObligations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Add two storage obligations and confirm them.
+	var sos []storageObligation
+	for i := 0; i < 2; i++ {
+		so, err := ht.newTesterStorageObligation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ht.host.managedLockStorageObligation(so.id())
+		err = ht.host.managedAddStorageObligation(so)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ht.host.managedUnlockStorageObligation(so.id())
+		sos = append(sos, so)
+	}
+	_, err = ht.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wipe all of the action items so that only the requeued items remain.
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket(bucketActionItems)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucket(bucketActionItems)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Revert a block containing only the first file contract, and apply an
+	// empty block in its place.
+	ht.host.ProcessConsensusChange(modules.ConsensusChange{
+		RevertedBlocks: []types.Block{{Transactions: sos[0].OriginTransactionSet}},
+		AppliedBlocks:  []types.Block{{Timestamp: 1}},
+	})
+
+	// Only the first obligation should have been unconfirmed and requeued.
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		so, err := getStorageObligation(tx, sos[0].id())
+		if err != nil {
+			return err
+		}
+		if so.OriginConfirmed {
+			t.Error("reverted obligation is still confirmed")
+		}
+		so, err = getStorageObligation(tx, sos[1].id())
+		if err != nil {
+			return err
+		}
+		if !so.OriginConfirmed {
+			t.Error("unaffected obligation was unconfirmed")
+		}
+
+		var found bool
+		err = tx.Bucket(bucketActionItems).ForEach(func(_, items []byte) error {
+			for i := 0; i+crypto.HashSize <= len(items); i += crypto.HashSize {
+				var soid types.FileContractID
+				copy(soid[:], items[i:i+crypto.HashSize])
+				if soid == sos[1].id() {
+					t.Error("unaffected obligation was requeued")
+				}
+				if soid == sos[0].id() {
+					found = true
+				}
+			}
+			return nil
+		})
+		if !found {
+			t.Error("reverted obligation was not requeued")
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestResubmitDroppedTransactions checks that the host resubmits the origin
+// transaction set of a storage obligation when the transaction pool drops it.
+func TestResubmitDroppedTransactions(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := so.OriginTransactionSet[len(so.OriginTransactionSet)-1].ID()
+	if _, _, exists := ht.tpool.Transaction(txid); !exists {
+		t.Fatal("origin transaction should be in the transaction pool")
+	}
+
+	// Purge the transaction pool. The host should put the origin transaction
+	// back.
+	ht.tpool.PurgeTransactionPool()
+	resubmitted := false
+	for i := 0; i < 50 && !resubmitted; i++ {
+		_, _, resubmitted = ht.tpool.Transaction(txid)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if !resubmitted {
+		t.Fatal("origin transaction was not resubmitted after being dropped")
+	}
+
+	// Resubmitting the dropped set should not have handled the obligation as
+	// an action item.
+	err = ht.host.tg.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var updated storageObligation
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		updated, err = getStorageObligation(tx, so.id())
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ResubmissionAttempts != 0 || !updated.TransactionFeesAdded.IsZero() {
+		t.Fatal("obligation was handled again:", updated.ResubmissionAttempts, updated.TransactionFeesAdded)
+	}
+}
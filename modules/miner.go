@@ -27,6 +27,14 @@ type BlockManager interface {
 	// BlocksMined returns the number of blocks and stale blocks that have been
 	// mined using this miner.
 	BlocksMined() (goodBlocks, staleBlocks int)
+
+	// PayoutAddress returns the custom address that block rewards are paid
+	// to, or the zero hash if the rewards are paid to the wallet.
+	PayoutAddress() types.UnlockHash
+
+	// SetPayoutAddress sets the address that block rewards are paid to. The
+	// zero hash pays the rewards to the wallet.
+	SetPayoutAddress(types.UnlockHash) error
 }
 
 // CPUMiner provides access to a single-threaded cpu miner.
@@ -1,6 +1,7 @@
 package miner
 
 import (
+	"bytes"
 	"errors"
 	"time"
 
@@ -27,13 +28,13 @@ func (m *Miner) blockForWork() types.Block {
 	}
 
 	// Update the address + payouts.
-	err := m.checkAddress()
+	addr, err := m.payoutAddress()
 	if err != nil {
 		m.log.Println(err)
 	}
 	b.MinerPayouts = []types.SiacoinOutput{{
 		Value:      b.CalculateSubsidy(m.persist.Height + 1),
-		UnlockHash: m.persist.Address,
+		UnlockHash: addr,
 	}}
 
 	// Add an arb-data txn to the block to create a unique merkle root.
@@ -80,14 +81,15 @@ func (m *Miner) HeaderForWork() (types.BlockHeader, types.Target, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Return a blank header with an error if the wallet is locked.
-	if !m.wallet.Unlocked() {
+	// Return a blank header with an error if the wallet is locked, unless the
+	// rewards are paid to a custom address.
+	if m.persist.PayoutAddress == (types.UnlockHash{}) && !m.wallet.Unlocked() {
 		return types.BlockHeader{}, types.Target{}, modules.ErrLockedWallet
 	}
 
 	// Check that the wallet has been initialized, and that the miner has
 	// successfully fetched an address.
-	err := m.checkAddress()
+	_, err := m.payoutAddress()
 	if err != nil {
 		return types.BlockHeader{}, types.Target{}, err
 	}
@@ -148,9 +150,13 @@ func (m *Miner) managedSubmitBlock(b types.Block) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Grab a new address for the miner. Call may fail if the wallet is locked
-	// or if the wallet addresses have been exhausted.
+	// Grab a new address for the miner, unless the rewards are paid to a
+	// custom address. Call may fail if the wallet is locked or if the wallet
+	// addresses have been exhausted.
 	m.persist.BlocksFound = append(m.persist.BlocksFound, b.ID())
+	if m.persist.PayoutAddress != (types.UnlockHash{}) {
+		return m.saveSync()
+	}
 	var uc types.UnlockConditions
 	uc, err = m.wallet.NextAddress()
 	if err != nil {
@@ -196,9 +202,17 @@ func (m *Miner) SubmitHeader(bh types.BlockHeader) error {
 
 		// Sanity check - block should have same id as header.
 		bh.Nonce = nonce
-		if types.BlockID(crypto.HashObject(bh)) != b.ID() {
+		id := b.ID()
+		if types.BlockID(crypto.HashObject(bh)) != id {
 			m.log.Critical("block reconstruction failed")
 		}
+
+		// Reject headers that don't meet the target without bothering the
+		// consensus set. Blocks built on an older parent are left to the
+		// consensus set, as their target may differ from the current one.
+		if b.ParentID == m.persist.UnsolvedBlock.ParentID && bytes.Compare(m.persist.Target[:], id[:]) < 0 {
+			return modules.ErrBlockUnsolved
+		}
 		return nil
 	}()
 	if err != nil {
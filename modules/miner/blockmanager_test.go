@@ -98,6 +98,47 @@ func TestIntegrationHeaderForWorkUpdates(t *testing.T) {
 	}
 }
 
+// TestIntegrationHeaderForWorkTransactions checks that HeaderForWork starts
+// returning headers that include new transactions as soon as they arrive in
+// the transaction pool.
+func TestIntegrationHeaderForWorkTransactions(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mt, err := createMinerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get a header to put the source block in use.
+	_, _, err = mt.miner.HeaderForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Send a transaction, which should trigger a header change.
+	txns, err := mt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _, err := mt.miner.HeaderForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt.miner.mu.RLock()
+	b := mt.miner.blockMem[header]
+	mt.miner.mu.RUnlock()
+	included := make(map[types.TransactionID]bool)
+	for _, txn := range b.Transactions {
+		included[txn.ID()] = true
+	}
+	for _, txn := range txns {
+		if !included[txn.ID()] {
+			t.Fatal("header for work does not include the new transactions")
+		}
+	}
+}
+
 // TestIntegrationManyHeaders checks that requesting a full set of headers in a
 // row results in all unique headers, and that all of them can be reassembled
 // into valid blocks.
@@ -219,3 +260,66 @@ func TestIntegrationHeaderRequestOverflow(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestIntegrationPayoutAddress checks that blocks pay their rewards to the
+// custom payout address once it is set, and to the wallet after it is reset.
+func TestIntegrationPayoutAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mt, err := createMinerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	payout := func() types.UnlockHash {
+		header, _, err := mt.miner.HeaderForWork()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt.miner.mu.RLock()
+		defer mt.miner.mu.RUnlock()
+		return mt.miner.blockMem[header].MinerPayouts[0].UnlockHash
+	}
+
+	// Get a header so that the source block is in use when the address
+	// changes.
+	walletAddr := payout()
+	if walletAddr == (types.UnlockHash{}) {
+		t.Fatal("miner should pay to a wallet address by default")
+	}
+
+	// Set a custom payout address. New headers should pay to it immediately.
+	var addr types.UnlockHash
+	fastrand.Read(addr[:])
+	if err := mt.miner.SetPayoutAddress(addr); err != nil {
+		t.Fatal(err)
+	}
+	if mt.miner.PayoutAddress() != addr {
+		t.Fatal("payout address was not set")
+	}
+	if p := payout(); p != addr {
+		t.Fatal("header does not pay to the custom address:", p)
+	}
+
+	// A mined block should pay to the custom address.
+	b, err := mt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.MinerPayouts[0].UnlockHash != addr {
+		t.Fatal("block does not pay to the custom address")
+	}
+
+	// Resetting the address should pay to the wallet again.
+	if err := mt.miner.SetPayoutAddress(types.UnlockHash{}); err != nil {
+		t.Fatal(err)
+	}
+	p := payout()
+	var isWalletAddr bool
+	for _, wa := range mt.wallet.AllAddresses() {
+		isWalletAddr = isWalletAddr || wa == p
+	}
+	if !isWalletAddr {
+		t.Fatal("header does not pay to a wallet address after resetting")
+	}
+}
@@ -268,6 +268,41 @@ func (m *Miner) checkAddress() error {
 	return nil
 }
 
+// payoutAddress returns the address that block rewards are paid to. This is
+// the custom payout address if one has been set, and a wallet address
+// otherwise.
+func (m *Miner) payoutAddress() (types.UnlockHash, error) {
+	if m.persist.PayoutAddress != (types.UnlockHash{}) {
+		return m.persist.PayoutAddress, nil
+	}
+	err := m.checkAddress()
+	return m.persist.Address, err
+}
+
+// PayoutAddress returns the custom address that block rewards are paid to, or
+// the zero hash if the rewards are paid to the wallet.
+func (m *Miner) PayoutAddress() types.UnlockHash {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.persist.PayoutAddress
+}
+
+// SetPayoutAddress sets the address that block rewards are paid to. Setting
+// the zero hash pays the rewards to the wallet again. The source block is
+// rebuilt right away, so that new headers for work use the new address.
+func (m *Miner) SetPayoutAddress(addr types.UnlockHash) error {
+	if err := m.tg.Add(); err != nil {
+		return err
+	}
+	defer m.tg.Done()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.persist.PayoutAddress = addr
+	m.newSourceBlock()
+	return m.saveSync()
+}
+
 // BlocksMined returns the number of good blocks and stale blocks that have
 // been mined by the miner.
 func (m *Miner) BlocksMined() (goodBlocks, staleBlocks int) {
@@ -30,6 +30,7 @@ type (
 		Height        types.BlockHeight
 		Target        types.Target
 		Address       types.UnlockHash
+		PayoutAddress types.UnlockHash
 		BlocksFound   []types.BlockID
 		UnsolvedBlock types.Block
 	}
@@ -309,6 +309,12 @@ func (m *Miner) ReceiveUpdatedUnconfirmedTransactions(diff *modules.TransactionP
 
 	m.deleteReverts(diff)
 	m.addNewTxns(diff)
+
+	// Refresh the source block so that new headers for work include the
+	// updated set of transactions.
+	if len(diff.AppliedTransactions) > 0 || len(diff.RevertedTransactions) > 0 {
+		m.newSourceBlock()
+	}
 }
 
 // removeSplitSetFromUnsolvedBlock removes a split set from the miner's unsolved
@@ -65,6 +65,10 @@ const (
 	// as a reasonable guideline for determining what is too large.
 	NegotiateMaxFileContractSetLen = TransactionSetSizeLimit - 1e3
 
+	// MaxStoragePriceTiers is the maximum number of storage price tiers that
+	// a host can offer.
+	MaxStoragePriceTiers = 16
+
 	// NegotiateMaxHostExternalSettingsLen is the maximum allowed size of an
 	// encoded HostExternalSettings.
 	NegotiateMaxHostExternalSettingsLen = 16000
@@ -136,6 +140,10 @@ var (
 	// wrong number of transaction signatures.
 	ErrRevisionSigCount = errors.New("file contract revision has the wrong number of transaction signatures")
 
+	// ErrTooManyStoragePriceTiers is returned if a host offers more than
+	// MaxStoragePriceTiers storage price tiers.
+	ErrTooManyStoragePriceTiers = errors.New("too many storage price tiers")
+
 	// ErrStopResponse is the error returned by ReadNegotiationAcceptance when
 	// it reads the StopResponse string.
 	ErrStopResponse = errors.New("sender wishes to stop communicating")
@@ -254,6 +262,21 @@ type (
 		// which is the most recent.
 		RevisionNumber uint64 `json:"revisionnumber"`
 		Version        string `json:"version"`
+
+		// StoragePriceTiers lets the host charge a different storage price
+		// for longer contracts. The field is last so that renters running
+		// older versions, which stop decoding after Version, can still read
+		// the settings.
+		StoragePriceTiers []HostStoragePriceTier `json:"storagepricetiers"`
+	}
+
+	// HostStoragePriceTier is a storage price that applies to contracts with
+	// a duration of at least MinDuration blocks. The duration of a contract is
+	// measured from the height at which the contract was formed or renewed to
+	// the end of its proof window.
+	HostStoragePriceTier struct {
+		MinDuration  types.BlockHeight `json:"minduration"`
+		StoragePrice types.Currency    `json:"storageprice"`
 	}
 
 	// A RevisionAction is a description of an edit to be performed on a file
@@ -276,6 +299,82 @@ type (
 	}
 )
 
+// StorageTierDuration returns the duration that selects the storage price
+// tier of a file contract: the number of blocks from the height at which the
+// contract was negotiated to the end of its proof window. Hosts and renters
+// both use it, so that they agree on the tier of a contract.
+func StorageTierDuration(negotiationHeight, windowEnd types.BlockHeight) types.BlockHeight {
+	if windowEnd < negotiationHeight {
+		return 0
+	}
+	return windowEnd - negotiationHeight
+}
+
+// StoragePriceForDuration returns the storage price that the host charges
+// for a contract with a duration of 'duration' blocks. The tier with the
+// largest MinDuration that the duration reaches applies, and StoragePrice
+// applies if the duration is shorter than every tier.
+func (hes HostExternalSettings) StoragePriceForDuration(duration types.BlockHeight) types.Currency {
+	price := hes.StoragePrice
+	var tierDuration types.BlockHeight
+	for _, tier := range hes.StoragePriceTiers {
+		if tier.MinDuration <= duration && tier.MinDuration >= tierDuration {
+			price = tier.StoragePrice
+			tierDuration = tier.MinDuration
+		}
+	}
+	return price
+}
+
+// UnmarshalSia implements the encoding.SiaUnmarshaler interface. Hosts
+// running older versions do not send the storage price tiers, in which case
+// the settings end after Version.
+func (hes *HostExternalSettings) UnmarshalSia(r io.Reader) error {
+	d := encoding.NewDecoder(r)
+	err := d.DecodeAll(
+		&hes.AcceptingContracts,
+		&hes.MaxDownloadBatchSize,
+		&hes.MaxDuration,
+		&hes.MaxReviseBatchSize,
+		&hes.NetAddress,
+		&hes.RemainingStorage,
+		&hes.SectorSize,
+		&hes.TotalStorage,
+		&hes.UnlockHash,
+		&hes.WindowSize,
+		&hes.Collateral,
+		&hes.MaxCollateral,
+		&hes.ContractPrice,
+		&hes.DownloadBandwidthPrice,
+		&hes.StoragePrice,
+		&hes.UploadBandwidthPrice,
+		&hes.RevisionNumber,
+		&hes.Version,
+	)
+	if err != nil {
+		return err
+	}
+	var prefix [8]byte
+	if n, err := io.ReadFull(r, prefix[:]); n == 0 && err == io.EOF {
+		hes.StoragePriceTiers = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	numTiers := encoding.DecUint64(prefix[:])
+	if numTiers > MaxStoragePriceTiers {
+		return ErrTooManyStoragePriceTiers
+	}
+	hes.StoragePriceTiers = make([]HostStoragePriceTier, numTiers)
+	for i := range hes.StoragePriceTiers {
+		err = d.Decode(&hes.StoragePriceTiers[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ReadNegotiationAcceptance reads an accept/reject response from r (usually a
 // net.Conn). If the response is not AcceptResponse, ReadNegotiationAcceptance
 // returns the response as an error. If the response is StopResponse,
@@ -2,9 +2,12 @@ package modules
 
 import (
 	"bytes"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -125,3 +128,75 @@ func TestNegotiationResponses(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestStoragePriceForDuration checks that the storage price tier with the
+// longest duration that a contract reaches is applied.
+func TestStoragePriceForDuration(t *testing.T) {
+	hes := HostExternalSettings{
+		StoragePrice: types.NewCurrency64(1),
+		StoragePriceTiers: []HostStoragePriceTier{
+			{MinDuration: 200, StoragePrice: types.NewCurrency64(3)},
+			{MinDuration: 100, StoragePrice: types.NewCurrency64(2)},
+		},
+	}
+	tests := []struct {
+		duration types.BlockHeight
+		price    uint64
+	}{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{199, 2},
+		{200, 3},
+		{1000, 3},
+	}
+	for _, test := range tests {
+		if price := hes.StoragePriceForDuration(test.duration); !price.Equals64(test.price) {
+			t.Errorf("expected price %v for duration %v, got %v", test.price, test.duration, price)
+		}
+	}
+}
+
+// TestHostExternalSettingsEncoding checks that host settings survive an
+// encoding round trip, and that settings sent by hosts that predate storage
+// price tiers can still be decoded.
+func TestHostExternalSettingsEncoding(t *testing.T) {
+	hes := HostExternalSettings{
+		AcceptingContracts: true,
+		NetAddress:         "foo.com:1234",
+		StoragePrice:       types.NewCurrency64(5),
+		Version:            "1.3.2",
+		StoragePriceTiers: []HostStoragePriceTier{
+			{MinDuration: 100, StoragePrice: types.NewCurrency64(7)},
+		},
+	}
+	var decoded HostExternalSettings
+	err := encoding.Unmarshal(encoding.Marshal(hes), &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hes, decoded) {
+		t.Fatal("settings changed during encoding round trip")
+	}
+
+	// Older hosts do not send the length prefix of the tiers.
+	hes.StoragePriceTiers = nil
+	b := encoding.Marshal(hes)
+	decoded = HostExternalSettings{}
+	err = encoding.Unmarshal(b[:len(b)-8], &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hes, decoded) {
+		t.Fatal("settings of older host were not decoded correctly")
+	}
+
+	// Too many tiers should be rejected.
+	for i := 0; i <= MaxStoragePriceTiers; i++ {
+		hes.StoragePriceTiers = append(hes.StoragePriceTiers, HostStoragePriceTier{MinDuration: types.BlockHeight(i)})
+	}
+	err = encoding.Unmarshal(encoding.Marshal(hes), &decoded)
+	if err == nil || !strings.Contains(err.Error(), ErrTooManyStoragePriceTiers.Error()) {
+		t.Fatal("expected ErrTooManyStoragePriceTiers, got", err)
+	}
+}
@@ -45,6 +45,32 @@ type Allowance struct {
 	RenewWindow types.BlockHeight `json:"renewwindow"`
 }
 
+// ContractHealth is the result of the health scans that the renter performs
+// on the host of a contract. A contract is degraded if its host has been
+// unreachable for longer than the MaxHostDowntime of the renter.
+type ContractHealth struct {
+	ID            types.FileContractID `json:"id"`
+	HostPublicKey types.SiaPublicKey   `json:"hostpublickey"`
+	NetAddress    NetAddress           `json:"netaddress"`
+
+	LastScan            time.Time     `json:"lastscan"`
+	LastSuccess         time.Time     `json:"lastsuccess"`
+	Latency             time.Duration `json:"latency"`
+	ConsecutiveFailures uint64        `json:"consecutivefailures"`
+	UnreachableSince    time.Time     `json:"unreachablesince"`
+	Degraded            bool          `json:"degraded"`
+}
+
+// PriceCapReport reports how many of the candidate hosts of the most recent
+// contract formation were filtered out by each of the renter's price caps. A
+// host that exceeds several caps is counted once for every cap it exceeds.
+type PriceCapReport struct {
+	Candidates                     uint64 `json:"candidates"`
+	StoragePriceFiltered           uint64 `json:"storagepricefiltered"`
+	UploadBandwidthPriceFiltered   uint64 `json:"uploadbandwidthpricefiltered"`
+	DownloadBandwidthPriceFiltered uint64 `json:"downloadbandwidthpricefiltered"`
+}
+
 // ContractUtility contains metrics internal to the contractor that reflect the
 // utility of a given contract.
 type ContractUtility struct {
@@ -85,11 +111,60 @@ type FileInfo struct {
 	Available      bool              `json:"available"`
 	Renewing       bool              `json:"renewing"`
 	Redundancy     float64           `json:"redundancy"`
+	DataPieces     int               `json:"datapieces"`
+	ParityPieces   int               `json:"paritypieces"`
 	UploadedBytes  uint64            `json:"uploadedbytes"`
 	UploadProgress float64           `json:"uploadprogress"`
+	UploadedChunks uint64            `json:"uploadedchunks"`
+	TotalChunks    uint64            `json:"totalchunks"`
+	UploadETA      int64             `json:"uploadeta"` // seconds, -1 if unknown
+	UploadPaused   bool              `json:"uploadpaused"`
 	Expiration     types.BlockHeight `json:"expiration"`
 }
 
+// The health statuses of a file, from best to worst.
+const (
+	// FileHealthHealthy means that every piece of every chunk of the file can
+	// be retrieved from an online host that is good for renewal.
+	FileHealthHealthy = "healthy"
+
+	// FileHealthAtRisk means that every chunk of the file can still be
+	// recovered, but some chunks are missing pieces and need to be repaired.
+	FileHealthAtRisk = "at-risk"
+
+	// FileHealthUnrecoverable means that at least one chunk of the file has
+	// fewer retrievable pieces than are needed to recover it.
+	FileHealthUnrecoverable = "unrecoverable"
+)
+
+// FileHealth describes how many pieces of each chunk of a file can currently
+// be retrieved, and from which hosts.
+type FileHealth struct {
+	SiaPath      string        `json:"siapath"`
+	Status       string        `json:"status"`
+	DataPieces   int           `json:"datapieces"`
+	ParityPieces int           `json:"paritypieces"`
+	Chunks       []ChunkHealth `json:"chunks"`
+}
+
+// ChunkHealth describes the pieces of a single chunk of a file. Pieces is the
+// number of distinct pieces that can be retrieved from online hosts; the chunk
+// can be recovered as long as it is at least the number of data pieces.
+type ChunkHealth struct {
+	Index  uint64      `json:"index"`
+	Pieces int         `json:"pieces"`
+	Hosts  []PieceHost `json:"hosts"`
+}
+
+// PieceHost describes a host that stores a piece of a chunk.
+type PieceHost struct {
+	Piece         uint64             `json:"piece"`
+	HostPublicKey types.SiaPublicKey `json:"hostpublickey"`
+	NetAddress    NetAddress         `json:"netaddress"`
+	Online        bool               `json:"online"`
+	GoodForRenew  bool               `json:"goodforrenew"`
+}
+
 // A HostDBEntry represents one host entry in the Renter's host DB. It
 // aggregates the host's external settings and metrics with its public key.
 type HostDBEntry struct {
@@ -144,6 +219,27 @@ type HostScoreBreakdown struct {
 	VersionAdjustment          float64 `json:"versionadjustment"`
 }
 
+// HostScoreWeights control how much the individual properties of a host count
+// towards its score. Each weight is applied as an exponent to the matching
+// adjustment of the HostScoreBreakdown: a weight of 1 keeps the default
+// scoring, a weight of 0 ignores the property, and a weight of 2 doubles its
+// influence. The zero value selects DefaultHostScoreWeights.
+type HostScoreWeights struct {
+	Collateral float64 `json:"collateral"`
+	Price      float64 `json:"price"`
+	Storage    float64 `json:"storage"`
+	Uptime     float64 `json:"uptime"`
+}
+
+// DefaultHostScoreWeights are the weights used when the renter has not
+// configured any.
+var DefaultHostScoreWeights = HostScoreWeights{
+	Collateral: 1,
+	Price:      1,
+	Storage:    1,
+	Uptime:     1,
+}
+
 // RenterPriceEstimation contains a bunch of files estimating the costs of
 // various operations on the network.
 type RenterPriceEstimation struct {
@@ -163,9 +259,34 @@ type RenterPriceEstimation struct {
 
 // RenterSettings control the behavior of the Renter.
 type RenterSettings struct {
-	Allowance        Allowance `json:"allowance"`
-	MaxUploadSpeed   int64     `json:"maxuploadspeed"`
-	MaxDownloadSpeed int64     `json:"maxdownloadspeed"`
+	Allowance        Allowance        `json:"allowance"`
+	MaxUploadSpeed   int64            `json:"maxuploadspeed"`
+	MaxDownloadSpeed int64            `json:"maxdownloadspeed"`
+	ScoreWeights     HostScoreWeights `json:"scoreweights"`
+
+	// MaxConcurrentRepairs is the number of chunks that are repaired at the
+	// same time, and MaxHostUploadQueue is the number of chunks that can be
+	// queued for upload at a single host. Zero selects the default.
+	MaxConcurrentRepairs int `json:"maxconcurrentrepairs"`
+	MaxHostUploadQueue   int `json:"maxhostuploadqueue"`
+
+	// MaxHostDowntime is the number of seconds that the host of a contract
+	// can be unreachable before the contract is marked as degraded and its
+	// data is repaired onto other hosts. Zero selects the default.
+	MaxHostDowntime uint64 `json:"maxhostdowntime"`
+
+	// MaxStoragePrice, MaxUploadBandwidthPrice and MaxDownloadBandwidthPrice
+	// are the highest prices that the renter pays a host, in hastings per
+	// byte per block for storage and hastings per byte for bandwidth. Hosts
+	// that charge more are not used. Zero selects the default.
+	MaxStoragePrice           types.Currency `json:"maxstorageprice"`
+	MaxUploadBandwidthPrice   types.Currency `json:"maxuploadbandwidthprice"`
+	MaxDownloadBandwidthPrice types.Currency `json:"maxdownloadbandwidthprice"`
+
+	// MaxSmallFileSize is the size limit, in bytes, of the files that can be
+	// uploaded and downloaded in a single blocking call. Zero selects the
+	// default.
+	MaxSmallFileSize uint64 `json:"maxsmallfilesize"`
 }
 
 // HostDBScans represents a sortable slice of scans.
@@ -291,6 +412,21 @@ type Renter interface {
 	// AllHosts returns the full list of hosts known to the renter.
 	AllHosts() []HostDBEntry
 
+	// BlacklistHost prevents the renter from forming or renewing contracts
+	// with a host. Existing contracts with the host are replaced.
+	BlacklistHost(key types.SiaPublicKey) error
+
+	// HostBlacklist returns the public keys of the blacklisted hosts.
+	HostBlacklist() []types.SiaPublicKey
+
+	// UnblacklistHost removes a host from the blacklist.
+	UnblacklistHost(key types.SiaPublicKey) error
+
+	// FormContracts forms a contract with each of the given hosts, bypassing
+	// the automatic host selection. The contracts are kept regardless of how
+	// the hosts score.
+	FormContracts(keys []types.SiaPublicKey) ([]RenterContract, error)
+
 	// Close closes the Renter.
 	Close() error
 
@@ -300,6 +436,23 @@ type Renter interface {
 	// ContractUtility provides the contract utility for a given id
 	ContractUtility(id types.FileContractID) (ContractUtility, bool)
 
+	// ContractHealth returns the results of the health scans of the hosts
+	// of the renter's contracts.
+	ContractHealth() []ContractHealth
+
+	// PriceCapReport reports how many candidate hosts were filtered out by
+	// the price caps during the most recent contract formation.
+	PriceCapReport() PriceCapReport
+
+	// ExpiringContracts returns the contracts that end within threshold
+	// blocks of the current block height. Contracts that are still good for
+	// renewal are renewed automatically by the contractor.
+	ExpiringContracts(threshold types.BlockHeight) []RenterContract
+
+	// CreateBackup writes the contracts and files of the renter to a backup
+	// file that is encrypted with a key derived from the wallet seed.
+	CreateBackup(dst string, seed Seed) error
+
 	// CurrentPeriod returns the height at which the current allowance period
 	// began.
 	CurrentPeriod() types.BlockHeight
@@ -321,6 +474,11 @@ type Renter interface {
 	// FileList returns information on all of the files stored by the renter.
 	FileList() []FileInfo
 
+	// FileHealth reports which pieces of each chunk of a file can currently
+	// be retrieved, and whether the file is healthy, at risk or
+	// unrecoverable.
+	FileHealth(siaPath string) (FileHealth, error)
+
 	// Host provides the DB entry and score breakdown for the requested host.
 	Host(pk types.SiaPublicKey) (HostDBEntry, bool)
 
@@ -336,9 +494,25 @@ type Renter interface {
 	// storage and data operations.
 	PriceEstimation() RenterPriceEstimation
 
+	// MigrateFile moves the pieces of a file that are stored on the given
+	// hosts to other hosts, reconstructing them from the pieces that can
+	// still be retrieved.
+	MigrateFile(siaPath string, hosts []types.SiaPublicKey) error
+
 	// RenameFile changes the path of a file.
 	RenameFile(path, newPath string) error
 
+	// PauseUpload pauses the upload of a file. Pieces of a paused file are
+	// neither uploaded nor repaired until the upload is resumed.
+	PauseUpload(siaPath string) error
+
+	// ResumeUpload resumes the upload of a paused file.
+	ResumeUpload(siaPath string) error
+
+	// RestoreBackup loads the contracts and files from a backup file created
+	// with the same wallet seed into the renter.
+	RestoreBackup(src string, seed Seed) error
+
 	// EstimateHostScore will return the score for a host with the provided
 	// settings, assuming perfect age and uptime adjustments
 	EstimateHostScore(entry HostDBEntry) HostScoreBreakdown
@@ -366,6 +540,14 @@ type Renter interface {
 
 	// Upload uploads a file using the input parameters.
 	Upload(FileUploadParams) error
+
+	// UploadBytes uploads a small file from memory, blocking until every
+	// piece of the file has been uploaded.
+	UploadBytes(siaPath string, data []byte) error
+
+	// DownloadBytes downloads a small file into memory, blocking until the
+	// download has completed.
+	DownloadBytes(siaPath string) ([]byte, error)
 }
 
 // RenterDownloadParameters defines the parameters passed to the Renter's
@@ -0,0 +1,136 @@
+package renter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// backupMetadata is the header of a renter backup file.
+	backupMetadata = persist.Metadata{
+		Header:  "Sia Renter Backup",
+		Version: "1.0",
+	}
+
+	// backupKeySpecifier is mixed into the wallet seed to derive the key that
+	// renter backups are encrypted with.
+	backupKeySpecifier = types.Specifier{'r', 'e', 'n', 't', 'e', 'r', ' ', 'b', 'a', 'c', 'k', 'u', 'p'}
+
+	// errBackupKey is returned if a backup cannot be decrypted with the key
+	// derived from the wallet seed.
+	errBackupKey = errors.New("backup could not be decrypted, it was made with a different wallet seed")
+)
+
+// renterBackup holds everything that is needed to download the files of the
+// renter after its metadata has been lost: the contracts with the hosts, and
+// the mapping of each file to the pieces stored on those hosts.
+type renterBackup struct {
+	Contracts []proto.ContractBackup `json:"contracts"`
+	Files     []byte                 `json:"files"` // .sia encoding of the files
+	Tracking  map[string]trackedFile `json:"tracking"`
+}
+
+// backupKey derives the key that renter backups are encrypted with from the
+// wallet seed, so that the key can be regenerated from the seed alone.
+func backupKey(seed modules.Seed) crypto.TwofishKey {
+	return crypto.TwofishKey(crypto.HashAll(backupKeySpecifier, seed))
+}
+
+// CreateBackup writes the contracts and files of the renter to an encrypted
+// backup file at 'dst'. The backup is encrypted with a key derived from the
+// wallet seed.
+func (r *Renter) CreateBackup(dst string, seed modules.Seed) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	backup := renterBackup{
+		Contracts: r.hostContractor.BackupContracts(),
+		Tracking:  make(map[string]trackedFile),
+	}
+	lockID := r.mu.RLock()
+	files := make([]*file, 0, len(r.files))
+	for _, f := range r.files {
+		files = append(files, f)
+	}
+	for name, tf := range r.tracking {
+		backup.Tracking[name] = tf
+	}
+	buf := new(bytes.Buffer)
+	err := shareFiles(files, buf)
+	r.mu.RUnlock(lockID)
+	if err != nil {
+		return err
+	}
+	backup.Files = buf.Bytes()
+
+	plaintext, err := json.Marshal(backup)
+	if err != nil {
+		return err
+	}
+	return persist.SaveJSON(backupMetadata, backupKey(seed).EncryptBytes(plaintext), dst)
+}
+
+// RestoreBackup loads the contracts and files from the backup file at 'src'
+// into the renter. Each contract is validated against the current consensus
+// state before it is restored, and contracts which are no longer valid are
+// skipped. Files and contracts that the renter already has are left
+// untouched.
+func (r *Renter) RestoreBackup(src string, seed modules.Seed) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	var ciphertext crypto.Ciphertext
+	err := persist.LoadJSON(backupMetadata, &ciphertext, src)
+	if err != nil {
+		return err
+	}
+	plaintext, err := backupKey(seed).DecryptBytes(ciphertext)
+	if err != nil {
+		return errBackupKey
+	}
+	var backup renterBackup
+	err = json.Unmarshal(plaintext, &backup)
+	if err != nil {
+		return err
+	}
+	files, err := readSharedFiles(bytes.NewReader(backup.Files))
+	if err != nil {
+		return err
+	}
+
+	contracts, err := r.hostContractor.RestoreContracts(backup.Contracts)
+	if err != nil {
+		return err
+	}
+
+	lockID := r.mu.Lock()
+	defer r.mu.Unlock(lockID)
+	var restored int
+	for _, f := range files {
+		if _, exists := r.files[f.name]; exists {
+			continue
+		}
+		r.files[f.name] = f
+		if tf, exists := backup.Tracking[f.name]; exists {
+			r.tracking[f.name] = tf
+		}
+		err = r.saveFile(f)
+		if err != nil {
+			return err
+		}
+		restored++
+	}
+	r.log.Printf("Restored %v of %v contracts and %v of %v files from %v\n", len(contracts), len(backup.Contracts), restored, len(files), src)
+	return r.saveSync()
+}
@@ -0,0 +1,65 @@
+package renter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestRenterBackup checks that files can be restored from a renter backup,
+// and that a backup can only be restored with the seed it was made with.
+func TestRenterBackup(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	f := newTestingFile()
+	id := rt.renter.mu.Lock()
+	rt.renter.files[f.name] = f
+	rt.renter.tracking[f.name] = trackedFile{RepairPath: "/foo/bar"}
+	rt.renter.mu.Unlock(id)
+
+	var seed modules.Seed
+	fastrand.Read(seed[:])
+	backupPath := filepath.Join(rt.renter.persistDir, "renter.backup")
+	err = rt.renter.CreateBackup(backupPath, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Forget about the file and restore it from the backup.
+	id = rt.renter.mu.Lock()
+	delete(rt.renter.files, f.name)
+	delete(rt.renter.tracking, f.name)
+	rt.renter.mu.Unlock(id)
+
+	var otherSeed modules.Seed
+	fastrand.Read(otherSeed[:])
+	if err := rt.renter.RestoreBackup(backupPath, otherSeed); err != errBackupKey {
+		t.Fatal("expected errBackupKey, got", err)
+	}
+	err = rt.renter.RestoreBackup(backupPath, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id = rt.renter.mu.RLock()
+	restored, exists := rt.renter.files[f.name]
+	tf := rt.renter.tracking[f.name]
+	rt.renter.mu.RUnlock(id)
+	if !exists {
+		t.Fatal("file was not restored")
+	}
+	if err := equalFiles(f, restored); err != nil {
+		t.Fatal(err)
+	}
+	if tf.RepairPath != "/foo/bar" {
+		t.Fatal("tracking information was not restored")
+	}
+}
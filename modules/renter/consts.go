@@ -18,6 +18,47 @@ var (
 		Standard: uint64(3 * 1 << 28), // 768 MiB
 		Testing:  uint64(1 << 17),     // 128 KiB - 4 KiB sector size, need to test memory exhaustion
 	}).(uint64)
+
+	// defaultMaxConcurrentRepairs is the default number of chunks that the
+	// renter repairs at the same time.
+	defaultMaxConcurrentRepairs = build.Select(build.Var{
+		Dev:      4,
+		Standard: 8,
+		Testing:  4,
+	}).(int)
+
+	// defaultMaxHostUploadQueue is the default number of chunks that can be
+	// queued for upload at a single host.
+	defaultMaxHostUploadQueue = build.Select(build.Var{
+		Dev:      8,
+		Standard: 16,
+		Testing:  8,
+	}).(int)
+
+	// defaultMaxSmallFileSize is the default size limit of the files that
+	// can be uploaded and downloaded with UploadBytes and DownloadBytes.
+	defaultMaxSmallFileSize = build.Select(build.Var{
+		Dev:      uint64(1 << 20), // 1 MiB
+		Standard: uint64(1 << 22), // 4 MiB
+		Testing:  uint64(1 << 14), // 16 KiB
+	}).(uint64)
+
+	// smallFileUploadTimeout is the amount of time that UploadBytes waits for
+	// the pieces of a file to be uploaded. The upload continues in the
+	// background after the timeout.
+	smallFileUploadTimeout = build.Select(build.Var{
+		Dev:      5 * time.Minute,
+		Standard: 10 * time.Minute,
+		Testing:  30 * time.Second,
+	}).(time.Duration)
+
+	// smallFileUploadCheckInterval is the interval at which UploadBytes
+	// checks whether the pieces of a file have been uploaded.
+	smallFileUploadCheckInterval = build.Select(build.Var{
+		Dev:      500 * time.Millisecond,
+		Standard: time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
 )
 
 var (
@@ -57,6 +98,10 @@ const (
 	// permissions are supplied.
 	defaultFilePerm = 0666
 
+	// smallFilesDir is the directory within the renter's persist directory
+	// that holds the local copies of the files uploaded with UploadBytes.
+	smallFilesDir = "smallfiles"
+
 	// downloadFailureCooldown defines how long to wait for a worker after a
 	// worker has experienced a download failure.
 	downloadFailureCooldown = time.Second * 3
@@ -74,6 +119,14 @@ const (
 	// downloadCacheSize is the cache size of the /renter/stream cache in
 	// chunks.
 	downloadCacheSize = 2
+
+	// uploadThroughputSamples is the number of recent piece uploads that are
+	// used to estimate the upload throughput of a file.
+	uploadThroughputSamples = 20
+
+	// minUploadThroughputSamples is the number of piece uploads that are
+	// required before the upload throughput of a file is estimated.
+	minUploadThroughputSamples = 3
 )
 
 var (
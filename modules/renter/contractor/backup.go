@@ -0,0 +1,49 @@
+package contractor
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// BackupContracts returns a backup of every active contract of the
+// contractor.
+func (c *Contractor) BackupContracts() []proto.ContractBackup {
+	return c.contracts.Backup()
+}
+
+// RestoreContracts restores backed up contracts into the contractor. Before a
+// contract is trusted, its most recent revision is checked against the
+// current consensus state. Contracts whose revision is not valid, because the
+// contract never made it onto the blockchain, has reached its proof window or
+// has a newer revision on the blockchain than the backup, are skipped and
+// logged. The contracts that were restored are returned.
+func (c *Contractor) RestoreContracts(backups []proto.ContractBackup) ([]modules.RenterContract, error) {
+	if err := c.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer c.tg.Done()
+
+	var restored []modules.RenterContract
+	for _, b := range backups {
+		_, err := c.cs.TryTransactionSet([]types.Transaction{b.Transaction()})
+		if err != nil {
+			c.log.Printf("WARN: not restoring contract %v, it is not valid in the current consensus state: %v\n", b.ID(), err)
+			continue
+		}
+		contract, err := c.contracts.Restore(b)
+		if err != nil {
+			return restored, err
+		}
+		restored = append(restored, contract)
+	}
+	c.log.Printf("INFO: restored %v of %v backed up contracts\n", len(restored), len(backups))
+
+	// Launch a new round of maintenance so that the restored contracts are
+	// checked for renewal.
+	if len(restored) > 0 {
+		c.managedInterruptContractMaintenance()
+		go c.threadedContractMaintenance()
+	}
+	return restored, nil
+}
@@ -0,0 +1,77 @@
+package contractor
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errHostBlacklisted = errors.New("host is blacklisted")
+	errNotBlacklisted  = errors.New("host is not blacklisted")
+)
+
+// isBlacklisted returns true if the host with the given public key is on the
+// blacklist.
+func (c *Contractor) isBlacklisted(key types.SiaPublicKey) bool {
+	_, blacklisted := c.blacklist[key.String()]
+	return blacklisted
+}
+
+// BlacklistHost adds a host to the blacklist. No new contracts are formed with
+// blacklisted hosts, and existing contracts with them are no longer renewed or
+// used for uploads, so that the repair loop migrates their data to other
+// hosts. The blacklist persists across restarts.
+func (c *Contractor) BlacklistHost(key types.SiaPublicKey) error {
+	if err := c.tg.Add(); err != nil {
+		return err
+	}
+	defer c.tg.Done()
+
+	c.mu.Lock()
+	c.blacklist[key.String()] = key
+	err := c.saveSync()
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	c.log.Println("INFO: blacklisted host", key)
+
+	// Launch a new round of maintenance so that contracts with the host are
+	// marked for replacement right away.
+	c.managedInterruptContractMaintenance()
+	go c.threadedContractMaintenance()
+	return nil
+}
+
+// UnblacklistHost removes a host from the blacklist.
+func (c *Contractor) UnblacklistHost(key types.SiaPublicKey) error {
+	if err := c.tg.Add(); err != nil {
+		return err
+	}
+	defer c.tg.Done()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.isBlacklisted(key) {
+		return errNotBlacklisted
+	}
+	delete(c.blacklist, key.String())
+	c.log.Println("INFO: removed host from blacklist", key)
+	return c.saveSync()
+}
+
+// Blacklist returns the public keys of the blacklisted hosts, sorted.
+func (c *Contractor) Blacklist() []types.SiaPublicKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]types.SiaPublicKey, 0, len(c.blacklist))
+	for _, key := range c.blacklist {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
@@ -0,0 +1,143 @@
+package contractor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errAllowanceNotSet  = errors.New("an allowance must be set to form contracts")
+	errDuplicateHost    = errors.New("host was chosen more than once")
+	errExistingContract = errors.New("renter already has a contract with the host")
+	errHostNotFound     = errors.New("host is not in the hostdb")
+	errNoHostsChosen    = errors.New("no hosts were chosen")
+)
+
+// isChosen returns true if the renter chose the host with the given public key
+// to form a contract with.
+func (c *Contractor) isChosen(key types.SiaPublicKey) bool {
+	_, chosen := c.chosenHosts[key.String()]
+	return chosen
+}
+
+// managedCheckChosenHost returns the hostdb entry of a host that the renter
+// chose to form a contract with, or an error if the contractor would refuse to
+// form a contract with the host.
+func (c *Contractor) managedCheckChosenHost(key types.SiaPublicKey) (modules.HostDBEntry, error) {
+	host, exists := c.hdb.Host(key)
+	if !exists {
+		return modules.HostDBEntry{}, errHostNotFound
+	}
+	if isOffline(host) {
+		return modules.HostDBEntry{}, errHostOffline
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isBlacklisted(key) {
+		return modules.HostDBEntry{}, errHostBlacklisted
+	}
+	if c.priceCaps.tooExpensive(host, c.allowance.Period) {
+		return modules.HostDBEntry{}, errTooExpensive
+	}
+	for _, contract := range c.contracts.ViewAll() {
+		if contract.HostPublicKey.String() == key.String() {
+			return modules.HostDBEntry{}, errExistingContract
+		}
+	}
+	return host, nil
+}
+
+// FormContracts forms a contract with each of the given hosts, bypassing the
+// host selection of the contractor. Each contract is funded with the same
+// share of the allowance as the contracts that the contractor forms on its
+// own. No contracts are formed if any of the hosts is unknown, offline,
+// blacklisted, charges more than the price caps or already has a contract
+// with the renter, or if the allowance can't fund all of the contracts.
+//
+// The hosts are remembered as chosen by the renter, so their contracts are
+// renewed and used for uploads regardless of how the hosts score against the
+// other hosts in the hostdb. The contracts that could be formed are returned
+// along with the errors of the hosts that could not be reached.
+func (c *Contractor) FormContracts(keys []types.SiaPublicKey) ([]modules.RenterContract, error) {
+	if err := c.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer c.tg.Done()
+
+	if len(keys) == 0 {
+		return nil, errNoHostsChosen
+	}
+	c.mu.RLock()
+	allowance := c.allowance
+	c.mu.RUnlock()
+	if allowance.Hosts == 0 {
+		return nil, errAllowanceNotSet
+	}
+
+	// Check all of the hosts before forming any contracts.
+	var hosts []modules.HostDBEntry
+	var errs []error
+	seen := make(map[string]struct{})
+	for _, key := range keys {
+		if _, exists := seen[key.String()]; exists {
+			errs = append(errs, fmt.Errorf("host %v: %v", key, errDuplicateHost))
+			continue
+		}
+		seen[key.String()] = struct{}{}
+		host, err := c.managedCheckChosenHost(key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("host %v: %v", key, err))
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+	if len(errs) > 0 {
+		return nil, build.ComposeErrors(errs...)
+	}
+
+	// Prevent the maintenance loop from spending the same funds while the
+	// contracts are formed.
+	c.managedInterruptContractMaintenance()
+	c.maintenanceLock.Lock()
+	defer c.maintenanceLock.Unlock()
+
+	c.mu.RLock()
+	contractFunds := c.allowance.Funds.Div64(c.allowance.Hosts).Div64(3)
+	fundsAvailable := c.availableFunds()
+	endHeight := c.contractEndHeight()
+	c.mu.RUnlock()
+	if fundsAvailable.Cmp(contractFunds.Mul64(uint64(len(hosts)))) < 0 {
+		return nil, ErrInsufficientAllowance
+	}
+
+	var contracts []modules.RenterContract
+	for _, host := range hosts {
+		contract, err := c.managedNewContract(host, contractFunds, endHeight)
+		if err != nil {
+			c.log.Printf("Attempted to form a contract with chosen host %v, but negotiation failed: %v\n", host.NetAddress, err)
+			errs = append(errs, fmt.Errorf("host %v: %v", host.PublicKey, err))
+			continue
+		}
+
+		c.mu.Lock()
+		c.chosenHosts[host.PublicKey.String()] = host.PublicKey
+		err = c.updateContractUtility(contract.ID, modules.ContractUtility{
+			GoodForUpload: true,
+			GoodForRenew:  true,
+		})
+		if err == nil {
+			err = c.saveSync()
+		}
+		c.mu.Unlock()
+		if err != nil {
+			c.log.Println("Unable to save the contract with a chosen host:", err)
+		}
+		contracts = append(contracts, contract)
+	}
+	return contracts, build.ComposeErrors(errs...)
+}
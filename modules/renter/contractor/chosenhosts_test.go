@@ -0,0 +1,76 @@
+package contractor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationFormChosenContracts tests that the contractor forms contracts
+// with hosts chosen by the renter and refuses hosts it can't use.
+func TestIntegrationFormChosenContracts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	if _, err := c.FormContracts(nil); err != errNoHostsChosen {
+		t.Fatal("expected errNoHostsChosen, got", err)
+	}
+	if _, err := c.FormContracts([]types.SiaPublicKey{h.PublicKey()}); err != errAllowanceNotSet {
+		t.Fatal("expected errAllowanceNotSet, got", err)
+	}
+
+	// Set the allowance directly so that the maintenance loop doesn't form
+	// a contract with the host on its own.
+	c.mu.Lock()
+	c.allowance = modules.Allowance{
+		Funds:       types.SiacoinPrecision.Mul64(500),
+		Hosts:       1,
+		Period:      50,
+		RenewWindow: 10,
+	}
+	c.mu.Unlock()
+
+	// No contracts are formed if any of the hosts is unknown.
+	unknown := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte("unknown")}
+	_, err = c.FormContracts([]types.SiaPublicKey{h.PublicKey(), unknown})
+	if err == nil || !strings.Contains(err.Error(), errHostNotFound.Error()) {
+		t.Fatal("expected errHostNotFound, got", err)
+	}
+	if len(c.Contracts()) != 0 {
+		t.Fatal("contracts were formed despite an unknown host")
+	}
+
+	contracts, err := c.FormContracts([]types.SiaPublicKey{h.PublicKey()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey := h.PublicKey()
+	if len(contracts) != 1 || contracts[0].HostPublicKey.String() != hostKey.String() {
+		t.Fatal("expected a contract with the host, got", contracts)
+	}
+	c.mu.RLock()
+	chosen := c.isChosen(h.PublicKey())
+	c.mu.RUnlock()
+	if !chosen {
+		t.Fatal("host was not marked as chosen")
+	}
+	if utility, ok := c.ContractUtility(contracts[0].ID); !ok || !utility.GoodForUpload || !utility.GoodForRenew {
+		t.Fatal("contract with the chosen host is not marked as good:", utility)
+	}
+
+	// A second contract with the same host is refused.
+	_, err = c.FormContracts([]types.SiaPublicKey{h.PublicKey()})
+	if err == nil || !strings.Contains(err.Error(), errExistingContract.Error()) {
+		t.Fatal("expected errExistingContract, got", err)
+	}
+}
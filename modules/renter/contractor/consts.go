@@ -1,6 +1,8 @@
 package contractor
 
 import (
+	"time"
+
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
@@ -25,10 +27,14 @@ var (
 // Constants related to the safety values for when the contractor is forming
 // contracts.
 var (
-	maxCollateral    = types.SiacoinPrecision.Mul64(1e3) // 1k SC
-	maxDownloadPrice = maxStoragePrice.Mul64(3 * 4320)
-	maxStoragePrice  = types.SiacoinPrecision.Mul64(30e3).Div(modules.BlockBytesPerMonthTerabyte) // 30k SC / TB / Month
-	maxUploadPrice   = maxStoragePrice.Mul64(3 * 4320)                                            // 3 months of storage
+	maxCollateral = types.SiacoinPrecision.Mul64(1e3) // 1k SC
+
+	// defaultMaxStoragePrice, defaultMaxUploadPrice and
+	// defaultMaxDownloadPrice are the price caps that are used when the
+	// renter has not set its own.
+	defaultMaxDownloadPrice = defaultMaxStoragePrice.Mul64(3 * 4320)
+	defaultMaxStoragePrice  = types.SiacoinPrecision.Mul64(30e3).Div(modules.BlockBytesPerMonthTerabyte) // 30k SC / TB / Month
+	defaultMaxUploadPrice   = defaultMaxStoragePrice.Mul64(3 * 4320)                                     // 3 months of storage
 
 	// scoreLeeway defines the factor by which a host can miss the goal score
 	// for a set of hosts. To determine the goal score, a new set of hosts is
@@ -37,3 +43,40 @@ var (
 	// host is allowed to have before being marked as !GoodForUpload.
 	scoreLeeway = types.NewCurrency64(100)
 )
+
+// Constants related to the health scans of the hosts that the contractor has
+// contracts with.
+var (
+	// healthScanInterval is the interval between two health scans of a host
+	// which is reachable.
+	healthScanInterval = build.Select(build.Var{
+		Dev:      1 * time.Minute,
+		Standard: 10 * time.Minute,
+		Testing:  500 * time.Millisecond,
+	}).(time.Duration)
+
+	// maxHealthScanBackoff is the maximum interval between two health scans
+	// of a host which is unreachable. The interval doubles with every failed
+	// scan, up to this limit.
+	maxHealthScanBackoff = build.Select(build.Var{
+		Dev:      10 * time.Minute,
+		Standard: 4 * time.Hour,
+		Testing:  4 * time.Second,
+	}).(time.Duration)
+
+	// healthScanTimeout is the amount of time that a host has to accept a
+	// connection during a health scan.
+	healthScanTimeout = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: 30 * time.Second,
+		Testing:  1 * time.Second,
+	}).(time.Duration)
+
+	// defaultMaxHostDowntime is the default amount of time that a host can be
+	// unreachable before its contracts are marked as degraded.
+	defaultMaxHostDowntime = build.Select(build.Var{
+		Dev:      10 * time.Minute,
+		Standard: 24 * time.Hour,
+		Testing:  2 * time.Second,
+	}).(time.Duration)
+)
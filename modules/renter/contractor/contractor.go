@@ -12,7 +12,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/renter/proto"
@@ -63,6 +65,28 @@ type Contractor struct {
 	contracts    *proto.ContractSet
 	oldContracts map[types.FileContractID]modules.RenterContract
 	renewedIDs   map[types.FileContractID]types.FileContractID
+
+	// blacklist contains the hosts that the contractor won't form or renew
+	// contracts with, keyed by the string form of their public key.
+	blacklist map[string]types.SiaPublicKey
+
+	// chosenHosts contains the hosts that the renter chose to form contracts
+	// with, keyed by the string form of their public key. Their contracts
+	// are kept regardless of the score of the host.
+	chosenHosts map[string]types.SiaPublicKey
+
+	// health contains the results of the health scans of the hosts that the
+	// contractor has contracts with, keyed by the string form of their public
+	// key. Contracts are degraded once their host has been unreachable for
+	// longer than maxHostDowntime.
+	health          map[string]*hostHealth
+	maxHostDowntime time.Duration
+
+	// priceCaps are the highest prices that the contractor pays a host, and
+	// priceCapReport counts the hosts that they filtered out during the most
+	// recent contract formation.
+	priceCaps      priceCaps
+	priceCapReport modules.PriceCapReport
 }
 
 // readlockResolveID returns the ID of the most recent renewal of id.
@@ -82,32 +106,40 @@ func (c *Contractor) Allowance() modules.Allowance {
 	return c.allowance
 }
 
+// addContractSpending adds the fees and spending of a contract to the
+// provided spending metrics.
+func addContractSpending(spending *modules.ContractorSpending, contract modules.RenterContract) {
+	// Calculate ContractFees
+	spending.ContractFees = spending.ContractFees.Add(contract.ContractFee)
+	spending.ContractFees = spending.ContractFees.Add(contract.TxnFee)
+	spending.ContractFees = spending.ContractFees.Add(contract.SiafundFee)
+	// Calculate TotalAllocated
+	spending.TotalAllocated = spending.TotalAllocated.Add(contract.TotalCost)
+	spending.ContractSpendingDeprecated = spending.TotalAllocated
+	// Calculate Spending
+	spending.DownloadSpending = spending.DownloadSpending.Add(contract.DownloadSpending)
+	spending.UploadSpending = spending.UploadSpending.Add(contract.UploadSpending)
+	spending.StorageSpending = spending.StorageSpending.Add(contract.StorageSpending)
+}
+
 // PeriodSpending returns the amount spent on contracts during the current
-// billing period.
+// billing period. Besides the active contracts, this includes the contracts
+// that were formed during the current period and have since been renewed or
+// have expired, so that the spending does not drop when a contract is
+// renewed. Contracts formed before the current period are not counted once
+// they are archived, which resets the spending at the start of each period.
 func (c *Contractor) PeriodSpending() modules.ContractorSpending {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	var spending modules.ContractorSpending
 	for _, contract := range c.contracts.ViewAll() {
-		// Calculate ContractFees
-		spending.ContractFees = spending.ContractFees.Add(contract.ContractFee)
-		spending.ContractFees = spending.ContractFees.Add(contract.TxnFee)
-		spending.ContractFees = spending.ContractFees.Add(contract.SiafundFee)
-		// Calculate TotalAllocated
-		spending.TotalAllocated = spending.TotalAllocated.Add(contract.TotalCost)
-		spending.ContractSpendingDeprecated = spending.TotalAllocated
-		// Calculate Spending
-		spending.DownloadSpending = spending.DownloadSpending.Add(contract.DownloadSpending)
-		spending.UploadSpending = spending.UploadSpending.Add(contract.UploadSpending)
-		spending.StorageSpending = spending.StorageSpending.Add(contract.StorageSpending)
-		// TODO: fix PreviousContracts
-		// for _, pre := range contract.PreviousContracts {
-		// 	spending.ContractSpending = spending.ContractSpending.Add(pre.TotalCost)
-		// 	spending.DownloadSpending = spending.DownloadSpending.Add(pre.DownloadSpending)
-		// 	spending.UploadSpending = spending.UploadSpending.Add(pre.UploadSpending)
-		// 	spending.StorageSpending = spending.StorageSpending.Add(pre.StorageSpending)
-		// }
+		addContractSpending(&spending, contract)
+	}
+	for _, contract := range c.oldContracts {
+		if contract.StartHeight >= c.currentPeriod {
+			addContractSpending(&spending, contract)
+		}
 	}
 	// Calculate amount of spent money to get unspent money.
 	allSpending := spending.ContractFees
@@ -138,6 +170,23 @@ func (c *Contractor) Contracts() []modules.RenterContract {
 	return c.contracts.ViewAll()
 }
 
+// ExpiringContracts returns the contracts that end within threshold blocks of
+// the current block height, sorted by end height.
+func (c *Contractor) ExpiringContracts(threshold types.BlockHeight) []modules.RenterContract {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var expiring []modules.RenterContract
+	for _, contract := range c.contracts.ViewAll() {
+		if c.blockHeight+threshold >= contract.EndHeight {
+			expiring = append(expiring, contract)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].EndHeight < expiring[j].EndHeight
+	})
+	return expiring
+}
+
 // ContractUtility returns the utility fields for the given contract.
 func (c *Contractor) ContractUtility(id types.FileContractID) (modules.ContractUtility, bool) {
 	c.mu.RLock()
@@ -225,13 +274,19 @@ func NewCustomContractor(cs consensusSet, w wallet, tp transactionPool, hdb host
 
 		interruptMaintenance: make(chan struct{}),
 
+		blacklist:    make(map[string]types.SiaPublicKey),
+		chosenHosts:  make(map[string]types.SiaPublicKey),
 		contracts:    contractSet,
 		downloaders:  make(map[types.FileContractID]*hostDownloader),
 		editors:      make(map[types.FileContractID]*hostEditor),
+		health:       make(map[string]*hostHealth),
 		oldContracts: make(map[types.FileContractID]modules.RenterContract),
 		renewedIDs:   make(map[types.FileContractID]types.FileContractID),
 		renewing:     make(map[types.FileContractID]bool),
 		revising:     make(map[types.FileContractID]bool),
+
+		maxHostDowntime: defaultMaxHostDowntime,
+		priceCaps:       defaultPriceCaps(),
 	}
 
 	// Close the contract set and logger upon shutdown.
@@ -275,5 +330,8 @@ func NewCustomContractor(cs consensusSet, w wallet, tp transactionPool, hdb host
 		return nil, err
 	}
 
+	// Start scanning the health of the contracted hosts.
+	go c.threadedHealthScan()
+
 	return c, nil
 }
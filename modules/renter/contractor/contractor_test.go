@@ -20,7 +20,10 @@ type newStub struct{}
 func (newStub) ConsensusSetSubscribe(modules.ConsensusSetSubscriber, modules.ConsensusChangeID, <-chan struct{}) error {
 	return nil
 }
-func (newStub) Synced() bool                               { return true }
+func (newStub) Synced() bool { return true }
+func (newStub) TryTransactionSet([]types.Transaction) (cc modules.ConsensusChange, err error) {
+	return
+}
 func (newStub) Unsubscribe(modules.ConsensusSetSubscriber) { return }
 
 // wallet stubs
@@ -334,7 +337,15 @@ func TestAllowanceSpending(t *testing.T) {
 	}
 
 	var expectedFees types.Currency
-	for _, contract := range c.Contracts() {
+	contracts := c.Contracts()
+	c.mu.RLock()
+	for _, contract := range c.oldContracts {
+		if contract.StartHeight >= c.currentPeriod {
+			contracts = append(contracts, contract)
+		}
+	}
+	c.mu.RUnlock()
+	for _, contract := range contracts {
 		expectedFees = expectedFees.Add(contract.TxnFee)
 		expectedFees = expectedFees.Add(contract.SiafundFee)
 		expectedFees = expectedFees.Add(contract.ContractFee)
@@ -374,6 +385,56 @@ func TestAllowanceSpending(t *testing.T) {
 	}
 }
 
+// TestPeriodSpendingOldContracts checks that PeriodSpending counts archived
+// contracts that were formed during the current period, and ignores those
+// formed in earlier periods.
+func TestPeriodSpendingOldContracts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	var stub newStub
+	c, err := New(stub, stub, stub, stub, build.TempDir("contractor", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.mu.Lock()
+	c.allowance.Funds = types.NewCurrency64(1000)
+	c.currentPeriod = 100
+	c.oldContracts[types.FileContractID{1}] = modules.RenterContract{
+		ID:               types.FileContractID{1},
+		StartHeight:      100,
+		TotalCost:        types.NewCurrency64(300),
+		ContractFee:      types.NewCurrency64(10),
+		TxnFee:           types.NewCurrency64(20),
+		SiafundFee:       types.NewCurrency64(30),
+		StorageSpending:  types.NewCurrency64(40),
+		UploadSpending:   types.NewCurrency64(50),
+		DownloadSpending: types.NewCurrency64(60),
+	}
+	c.oldContracts[types.FileContractID{2}] = modules.RenterContract{
+		ID:              types.FileContractID{2},
+		StartHeight:     99,
+		TotalCost:       types.NewCurrency64(500),
+		StorageSpending: types.NewCurrency64(500),
+	}
+	c.mu.Unlock()
+
+	spending := c.PeriodSpending()
+	if !spending.TotalAllocated.Equals64(300) {
+		t.Error("wrong total allocated:", spending.TotalAllocated)
+	}
+	if !spending.ContractFees.Equals64(60) {
+		t.Error("wrong contract fees:", spending.ContractFees)
+	}
+	if !spending.StorageSpending.Equals64(40) || !spending.UploadSpending.Equals64(50) || !spending.DownloadSpending.Equals64(60) {
+		t.Error("wrong spending:", spending.StorageSpending, spending.UploadSpending, spending.DownloadSpending)
+	}
+	if !spending.Unspent.Equals64(1000 - 60 - 40 - 50 - 60) {
+		t.Error("wrong unspent:", spending.Unspent)
+	}
+}
+
 // TestIntegrationSetAllowance tests the SetAllowance method.
 func TestIntegrationSetAllowance(t *testing.T) {
 	if testing.Short() {
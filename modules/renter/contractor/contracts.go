@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
@@ -20,6 +21,7 @@ var (
 	// than the amount necessary to store at least one sector
 	ErrInsufficientAllowance = errors.New("allowance is not large enough to cover fees of contract creation")
 	errTooExpensive          = errors.New("host price was too high")
+	errHostOffline           = errors.New("host is offline")
 )
 
 // contractEndHeight returns the height at which the Contractor's contracts
@@ -107,8 +109,41 @@ func (c *Contractor) managedMarkContractsUtility() error {
 				u.GoodForRenew = false
 				return
 			}
-			// Contract has no utility if the score is poor.
-			if !minScore.IsZero() && c.hdb.ScoreBreakdown(host).Score.Cmp(minScore) < 0 {
+			// Contract has no utility if the host is blacklisted.
+			c.mu.RLock()
+			blacklisted := c.isBlacklisted(contract.HostPublicKey)
+			c.mu.RUnlock()
+			if blacklisted {
+				u.GoodForUpload = false
+				u.GoodForRenew = false
+				return
+			}
+			// Contract has no utility if the host has been unreachable for too
+			// long.
+			c.mu.RLock()
+			degraded := c.isDegraded(contract.HostPublicKey, time.Now())
+			c.mu.RUnlock()
+			if degraded {
+				u.GoodForUpload = false
+				u.GoodForRenew = false
+				return
+			}
+			// Contract has no utility if the host charges more than the
+			// price caps.
+			c.mu.RLock()
+			tooExpensive := c.priceCaps.tooExpensive(host, c.allowance.Period)
+			c.mu.RUnlock()
+			if tooExpensive {
+				u.GoodForUpload = false
+				u.GoodForRenew = false
+				return
+			}
+			// Contract has no utility if the score is poor, unless the
+			// renter chose the host.
+			c.mu.RLock()
+			chosen := c.isChosen(contract.HostPublicKey)
+			c.mu.RUnlock()
+			if !chosen && !minScore.IsZero() && c.hdb.ScoreBreakdown(host).Score.Cmp(minScore) < 0 {
 				u.GoodForUpload = false
 				u.GoodForRenew = false
 				return
@@ -152,11 +187,70 @@ func (c *Contractor) managedMarkContractsUtility() error {
 	return nil
 }
 
+// availableFunds returns the funds of the allowance that are available for
+// renewing and forming contracts in the current period.
+func (c *Contractor) availableFunds() types.Currency {
+	// Determine how many funds have been used already in this billing
+	// cycle, and how many funds are remaining. We have to calculate these
+	// numbers separately to avoid underflow, and then re-join them later to
+	// get the full picture for how many funds are available.
+	var fundsAvailable, fundsUsed types.Currency
+	for _, contract := range c.contracts.ViewAll() {
+		// Calculate the cost of the contract line.
+		contractLineCost := contract.TotalCost
+		// TODO: add previous contracts here
+
+		// Check if the contract is expiring. The funds in the contract are
+		// handled differently based on this information.
+		if c.blockHeight+c.allowance.RenewWindow >= contract.EndHeight {
+			// The contract is expiring. Some of the funds are locked down
+			// to renew the contract, and then the remaining funds can be
+			// allocated to 'availableFunds'.
+			fundsUsed = fundsUsed.Add(contractLineCost).Sub(contract.RenterFunds)
+			fundsAvailable = fundsAvailable.Add(contract.RenterFunds)
+		} else {
+			// The contract is not expiring. None of the funds in the
+			// contract are available to renew or form contracts.
+			fundsUsed = fundsUsed.Add(contractLineCost)
+		}
+	}
+
+	// Add any unspent funds from the allowance to the available funds. If
+	// the allowance has been decreased, it's possible that we actually need
+	// to reduce the number of funds available to compensate.
+	if fundsAvailable.Add(c.allowance.Funds).Cmp(fundsUsed) > 0 {
+		fundsAvailable = fundsAvailable.Add(c.allowance.Funds).Sub(fundsUsed)
+	} else {
+		// Figure out how much we need to remove from fundsAvailable to
+		// clear the allowance.
+		overspend := fundsUsed.Sub(c.allowance.Funds).Sub(fundsAvailable)
+		if fundsAvailable.Cmp(overspend) > 0 {
+			// We still have some funds available.
+			fundsAvailable = fundsAvailable.Sub(overspend)
+		} else {
+			// The overspend exceeds the available funds, set available
+			// funds to zero.
+			fundsAvailable = types.ZeroCurrency
+		}
+	}
+	return fundsAvailable
+}
+
 // managedNewContract negotiates an initial file contract with the specified
 // host, saves it, and returns it.
 func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFunding types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error) {
+	// reject blacklisted hosts
+	c.mu.RLock()
+	blacklisted := c.isBlacklisted(host.PublicKey)
+	c.mu.RUnlock()
+	if blacklisted {
+		return modules.RenterContract{}, errHostBlacklisted
+	}
 	// reject hosts that are too expensive
-	if host.StoragePrice.Cmp(maxStoragePrice) > 0 {
+	c.mu.RLock()
+	tooExpensive := c.priceCaps.tooExpensive(host, c.allowance.Period)
+	c.mu.RUnlock()
+	if tooExpensive {
 		return modules.RenterContract{}, errTooExpensive
 	}
 	// cap host.MaxCollateral
@@ -211,10 +305,21 @@ func (c *Contractor) managedRenew(sc *proto.SafeContract, contractFunding types.
 	}
 
 	// Fetch the host associated with this contract.
+	c.mu.RLock()
+	blacklisted := c.isBlacklisted(contract.HostPublicKey)
+	c.mu.RUnlock()
+	if blacklisted {
+		return modules.RenterContract{}, errHostBlacklisted
+	}
 	host, ok := c.hdb.Host(contract.HostPublicKey)
+	c.mu.RLock()
+	tooExpensive := c.priceCaps.tooExpensive(host, c.allowance.Period)
+	c.mu.RUnlock()
 	if !ok {
 		return modules.RenterContract{}, errors.New("no record of that host")
-	} else if host.StoragePrice.Cmp(maxStoragePrice) > 0 {
+	} else if isOffline(host) {
+		return modules.RenterContract{}, errHostOffline
+	} else if tooExpensive {
 		return modules.RenterContract{}, errTooExpensive
 	}
 	// cap host.MaxCollateral
@@ -317,49 +422,9 @@ func (c *Contractor) threadedContractMaintenance() {
 		// Grab the end height that should be used for the contracts.
 		endHeight = c.currentPeriod + c.allowance.Period
 
-		// Determine how many funds have been used already in this billing
-		// cycle, and how many funds are remaining. We have to calculate these
-		// numbers separately to avoid underflow, and then re-join them later to
-		// get the full picture for how many funds are available.
-		var fundsUsed types.Currency
-		for _, contract := range c.contracts.ViewAll() {
-			// Calculate the cost of the contract line.
-			contractLineCost := contract.TotalCost
-			// TODO: add previous contracts here
-
-			// Check if the contract is expiring. The funds in the contract are
-			// handled differently based on this information.
-			if c.blockHeight+c.allowance.RenewWindow >= contract.EndHeight {
-				// The contract is expiring. Some of the funds are locked down
-				// to renew the contract, and then the remaining funds can be
-				// allocated to 'availableFunds'.
-				fundsUsed = fundsUsed.Add(contractLineCost).Sub(contract.RenterFunds)
-				fundsAvailable = fundsAvailable.Add(contract.RenterFunds)
-			} else {
-				// The contract is not expiring. None of the funds in the
-				// contract are available to renew or form contracts.
-				fundsUsed = fundsUsed.Add(contractLineCost)
-			}
-		}
-
-		// Add any unspent funds from the allowance to the available funds. If
-		// the allowance has been decreased, it's possible that we actually need
-		// to reduce the number of funds available to compensate.
-		if fundsAvailable.Add(c.allowance.Funds).Cmp(fundsUsed) > 0 {
-			fundsAvailable = fundsAvailable.Add(c.allowance.Funds).Sub(fundsUsed)
-		} else {
-			// Figure out how much we need to remove from fundsAvailable to
-			// clear the allowance.
-			overspend := fundsUsed.Sub(c.allowance.Funds).Sub(fundsAvailable)
-			if fundsAvailable.Cmp(overspend) > 0 {
-				// We still have some funds available.
-				fundsAvailable = fundsAvailable.Sub(overspend)
-			} else {
-				// The overspend exceeds the available funds, set available
-				// funds to zero.
-				fundsAvailable = types.ZeroCurrency
-			}
-		}
+		// Determine how many funds are available for renewing and forming
+		// contracts.
+		fundsAvailable = c.availableFunds()
 
 		// Iterate through the contracts again, figuring out which contracts to
 		// renew and how much extra funds to renew them with.
@@ -416,7 +481,7 @@ func (c *Contractor) threadedContractMaintenance() {
 				// Skip this host if its prices are too high.
 				// managedMarkContractsUtility should make this redundant, but
 				// this is here for extra safety.
-				if host.StoragePrice.Cmp(maxStoragePrice) > 0 || host.UploadBandwidthPrice.Cmp(maxUploadPrice) > 0 {
+				if c.priceCaps.tooExpensive(host, c.allowance.Period) {
 					continue
 				}
 
@@ -582,13 +647,16 @@ func (c *Contractor) threadedContractMaintenance() {
 	}
 
 	// Assemble an exclusion list that includes all of the hosts that we already
-	// have contracts with, then select a new batch of hosts to attempt contract
-	// formation with.
+	// have contracts with and all blacklisted hosts, then select a new batch of
+	// hosts to attempt contract formation with.
 	c.mu.RLock()
 	var exclude []types.SiaPublicKey
 	for _, contract := range c.contracts.ViewAll() {
 		exclude = append(exclude, contract.HostPublicKey)
 	}
+	for _, key := range c.blacklist {
+		exclude = append(exclude, key)
+	}
 	initialContractFunds := c.allowance.Funds.Div64(c.allowance.Hosts).Div64(3)
 	c.mu.RUnlock()
 	hosts, err := c.hdb.RandomHosts(neededContracts*2+randomHostsBufferForScore, exclude)
@@ -597,6 +665,15 @@ func (c *Contractor) threadedContractMaintenance() {
 		return
 	}
 
+	// Filter out the hosts that charge more than the price caps.
+	c.mu.Lock()
+	hosts, c.priceCapReport = c.priceCaps.filterHosts(hosts, c.allowance.Period)
+	report := c.priceCapReport
+	c.mu.Unlock()
+	if len(hosts) < int(report.Candidates) {
+		c.log.Printf("Price caps filtered out %v of %v candidate hosts\n", report.Candidates-uint64(len(hosts)), report.Candidates)
+	}
+
 	// Form contracts with the hosts one at a time, until we have enough
 	// contracts.
 	for _, host := range hosts {
@@ -14,6 +14,7 @@ type (
 	consensusSet interface {
 		ConsensusSetSubscribe(modules.ConsensusSetSubscriber, modules.ConsensusChangeID, <-chan struct{}) error
 		Synced() bool
+		TryTransactionSet([]types.Transaction) (modules.ConsensusChange, error)
 		Unsubscribe(modules.ConsensusSetSubscriber)
 	}
 	// In order to restrict the modules.TransactionBuilder interface, we must
@@ -33,6 +33,7 @@ type hostDownloader struct {
 	contractID   types.FileContractID
 	contractor   *Contractor
 	downloader   *proto.Downloader
+	hostKey      types.SiaPublicKey
 	hostSettings modules.HostExternalSettings
 	invalid      bool   // true if invalidate has been called
 	speed        uint64 // Bytes per second.
@@ -93,6 +94,12 @@ func (hd *hostDownloader) Sector(root crypto.Hash) ([]byte, error) {
 		return nil, errInvalidDownloader
 	}
 
+	// Stop revising the contract if the host has raised its prices above the
+	// price caps since the downloader was created.
+	if hd.contractor.managedTooExpensive(hd.hostKey, true) {
+		return nil, errTooExpensive
+	}
+
 	// Download the sector.
 	_, sector, err := hd.downloader.Sector(root)
 	if err != nil {
@@ -130,7 +137,11 @@ func (c *Contractor) Downloader(id types.FileContractID, cancel <-chan struct{})
 		return nil, errors.New("contract has already ended")
 	} else if !haveHost {
 		return nil, errors.New("no record of that host")
-	} else if host.DownloadBandwidthPrice.Cmp(maxDownloadPrice) > 0 {
+	}
+	c.mu.RLock()
+	_, _, tooExpensive := c.priceCaps.exceeds(host, c.allowance.Period)
+	c.mu.RUnlock()
+	if tooExpensive {
 		return nil, errTooExpensive
 	}
 
@@ -168,6 +179,7 @@ func (c *Contractor) Downloader(id types.FileContractID, cancel <-chan struct{})
 		contractID:   contract.ID,
 		contractor:   c,
 		downloader:   d,
+		hostKey:      contract.HostPublicKey,
 		hostSettings: host.HostExternalSettings,
 	}
 	c.mu.Lock()
@@ -44,6 +44,7 @@ type hostEditor struct {
 	contractor *Contractor
 	editor     *proto.Editor
 	endHeight  types.BlockHeight
+	hostKey    types.SiaPublicKey
 	id         types.FileContractID
 	invalid    bool // true if invalidate has been called
 	netAddress modules.NetAddress
@@ -105,6 +106,12 @@ func (he *hostEditor) Upload(data []byte) (_ crypto.Hash, err error) {
 		return crypto.Hash{}, errInvalidEditor
 	}
 
+	// Stop revising the contract if the host has raised its prices above the
+	// price caps since the editor was created.
+	if he.contractor.managedTooExpensive(he.hostKey, false) {
+		return crypto.Hash{}, errTooExpensive
+	}
+
 	// Perform the upload.
 	_, sectorRoot, err := he.editor.Upload(data)
 	if err != nil {
@@ -146,9 +153,11 @@ func (c *Contractor) Editor(id types.FileContractID, cancel <-chan struct{}) (_
 		return nil, errors.New("contract has already ended")
 	} else if !haveHost {
 		return nil, errors.New("no record of that host")
-	} else if host.StoragePrice.Cmp(maxStoragePrice) > 0 {
-		return nil, errTooExpensive
-	} else if host.UploadBandwidthPrice.Cmp(maxUploadPrice) > 0 {
+	}
+	c.mu.RLock()
+	storageTooExpensive, uploadTooExpensive, _ := c.priceCaps.exceeds(host, c.allowance.Period)
+	c.mu.RUnlock()
+	if storageTooExpensive || uploadTooExpensive {
 		return nil, errTooExpensive
 	}
 
@@ -182,6 +191,7 @@ func (c *Contractor) Editor(id types.FileContractID, cancel <-chan struct{}) (_
 		contractor: c,
 		editor:     e,
 		endHeight:  contract.EndHeight,
+		hostKey:    contract.HostPublicKey,
 		id:         contract.ID,
 		netAddress: host.NetAddress,
 	}
@@ -0,0 +1,218 @@
+package contractor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var errHostUnknown = errors.New("host is not in the hostdb")
+
+// hostHealth is the result of the health scans of a host that the contractor
+// has contracts with. Health scans are not persisted, they start over when
+// the contractor is restarted.
+type hostHealth struct {
+	LastScan            time.Time
+	LastSuccess         time.Time
+	Latency             time.Duration
+	ConsecutiveFailures uint64
+	UnreachableSince    time.Time
+	NextScan            time.Time
+}
+
+// healthScanBackoff returns the interval until the next health scan of a host
+// which failed its most recent scans. The interval doubles with every failed
+// scan, up to maxHealthScanBackoff.
+func healthScanBackoff(failures uint64) time.Duration {
+	backoff := healthScanInterval
+	for i := uint64(0); i < failures && backoff < maxHealthScanBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxHealthScanBackoff {
+		backoff = maxHealthScanBackoff
+	}
+	return backoff
+}
+
+// update records the outcome of a health scan.
+func (hh *hostHealth) update(success bool, latency time.Duration, now time.Time) {
+	hh.LastScan = now
+	if success {
+		hh.LastSuccess = now
+		hh.Latency = latency
+		hh.ConsecutiveFailures = 0
+		hh.UnreachableSince = time.Time{}
+		hh.NextScan = now.Add(healthScanInterval)
+		return
+	}
+	if hh.ConsecutiveFailures == 0 {
+		hh.UnreachableSince = now
+	}
+	hh.ConsecutiveFailures++
+	hh.NextScan = now.Add(healthScanBackoff(hh.ConsecutiveFailures))
+}
+
+// isDegraded returns true if the health scans have found the host with the
+// given public key unreachable for longer than the maximum host downtime.
+func (c *Contractor) isDegraded(key types.SiaPublicKey, now time.Time) bool {
+	hh, exists := c.health[key.String()]
+	return exists && !hh.UnreachableSince.IsZero() && now.Sub(hh.UnreachableSince) >= c.maxHostDowntime
+}
+
+// managedPingHost checks whether a host accepts connections, returning the
+// time it took to connect.
+func (c *Contractor) managedPingHost(key types.SiaPublicKey) (time.Duration, error) {
+	host, exists := c.hdb.Host(key)
+	if !exists {
+		return 0, errHostUnknown
+	}
+	start := time.Now()
+	conn, err := c.deps.DialTimeout(host.NetAddress, healthScanTimeout)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	return latency, conn.Close()
+}
+
+// managedHealthScan scans the hosts of the contracts which are due for a scan.
+// Contracts whose host has become degraded are marked as not good for upload
+// or renewal right away, so that the repair loop moves their data to other
+// hosts.
+func (c *Contractor) managedHealthScan() {
+	// Collect the hosts that are due for a scan, and forget the hosts that
+	// the contractor no longer has contracts with.
+	now := time.Now()
+	var due []types.SiaPublicKey
+	hosts := make(map[string]struct{})
+	c.mu.Lock()
+	for _, contract := range c.contracts.ViewAll() {
+		key := contract.HostPublicKey.String()
+		if _, exists := hosts[key]; exists {
+			continue
+		}
+		hosts[key] = struct{}{}
+		hh, exists := c.health[key]
+		if !exists {
+			hh = new(hostHealth)
+			c.health[key] = hh
+		}
+		if !now.Before(hh.NextScan) {
+			due = append(due, contract.HostPublicKey)
+		}
+	}
+	for key := range c.health {
+		if _, exists := hosts[key]; !exists {
+			delete(c.health, key)
+		}
+	}
+	c.mu.Unlock()
+
+	degraded := make(map[string]struct{})
+	for _, key := range due {
+		select {
+		case <-c.tg.StopChan():
+			return
+		default:
+		}
+		latency, err := c.managedPingHost(key)
+
+		now := time.Now()
+		c.mu.Lock()
+		hh, exists := c.health[key.String()]
+		if exists {
+			wasDegraded := c.isDegraded(key, now)
+			hh.update(err == nil, latency, now)
+			if !wasDegraded && c.isDegraded(key, now) {
+				c.log.Printf("WARN: host %v has been unreachable since %v, marking its contracts as degraded: %v\n", key, hh.UnreachableSince, err)
+				degraded[key.String()] = struct{}{}
+			}
+		}
+		c.mu.Unlock()
+	}
+	if len(degraded) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, contract := range c.contracts.ViewAll() {
+		if _, exists := degraded[contract.HostPublicKey.String()]; !exists {
+			continue
+		}
+		err := c.updateContractUtility(contract.ID, modules.ContractUtility{})
+		if err != nil {
+			c.log.Println("Unable to mark contract as degraded:", err)
+		}
+	}
+}
+
+// threadedHealthScan periodically scans the hosts that the contractor has
+// contracts with.
+func (c *Contractor) threadedHealthScan() {
+	if err := c.tg.Add(); err != nil {
+		return
+	}
+	defer c.tg.Done()
+	for {
+		c.managedHealthScan()
+		select {
+		case <-c.tg.StopChan():
+			return
+		case <-time.After(healthScanInterval):
+		}
+	}
+}
+
+// ContractHealth returns the results of the health scans of the hosts of the
+// contractor's contracts.
+func (c *Contractor) ContractHealth() []modules.ContractHealth {
+	now := time.Now()
+	var chs []modules.ContractHealth
+	c.mu.RLock()
+	for _, contract := range c.contracts.ViewAll() {
+		ch := modules.ContractHealth{
+			ID:            contract.ID,
+			HostPublicKey: contract.HostPublicKey,
+		}
+		if hh, exists := c.health[contract.HostPublicKey.String()]; exists {
+			ch.LastScan = hh.LastScan
+			ch.LastSuccess = hh.LastSuccess
+			ch.Latency = hh.Latency
+			ch.ConsecutiveFailures = hh.ConsecutiveFailures
+			ch.UnreachableSince = hh.UnreachableSince
+			ch.Degraded = c.isDegraded(contract.HostPublicKey, now)
+		}
+		chs = append(chs, ch)
+	}
+	c.mu.RUnlock()
+
+	// The hostdb must not be called under lock.
+	for i := range chs {
+		if host, exists := c.hdb.Host(chs[i].HostPublicKey); exists {
+			chs[i].NetAddress = host.NetAddress
+		}
+	}
+	return chs
+}
+
+// MaxHostDowntime returns the amount of time that a host can be unreachable
+// before its contracts are marked as degraded.
+func (c *Contractor) MaxHostDowntime() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.maxHostDowntime
+}
+
+// SetMaxHostDowntime sets the amount of time that a host can be unreachable
+// before its contracts are marked as degraded. Zero selects the default.
+func (c *Contractor) SetMaxHostDowntime(d time.Duration) {
+	if d == 0 {
+		d = defaultMaxHostDowntime
+	}
+	c.mu.Lock()
+	c.maxHostDowntime = d
+	c.mu.Unlock()
+}
@@ -0,0 +1,72 @@
+package contractor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestHealthScanBackoff tests that the interval between the health scans of a
+// host that is down doubles with every failed scan, up to a maximum.
+func TestHealthScanBackoff(t *testing.T) {
+	if b := healthScanBackoff(0); b != healthScanInterval {
+		t.Fatalf("expected %v, got %v", healthScanInterval, b)
+	}
+	if b := healthScanBackoff(1); b != 2*healthScanInterval {
+		t.Fatalf("expected %v, got %v", 2*healthScanInterval, b)
+	}
+	for failures := uint64(1); failures < 100; failures++ {
+		if healthScanBackoff(failures) < healthScanBackoff(failures-1) {
+			t.Fatal("backoff decreased after", failures, "failures")
+		}
+	}
+	if b := healthScanBackoff(100); b != maxHealthScanBackoff {
+		t.Fatalf("expected %v, got %v", maxHealthScanBackoff, b)
+	}
+}
+
+// TestHostHealthDegraded tests that a host is degraded once it has been
+// unreachable for longer than the maximum host downtime, and that a single
+// successful scan restores it.
+func TestHostHealthDegraded(t *testing.T) {
+	key := types.SiaPublicKey{Key: []byte{1}}
+	c := &Contractor{
+		health:          map[string]*hostHealth{key.String(): new(hostHealth)},
+		maxHostDowntime: time.Hour,
+	}
+	hh := c.health[key.String()]
+	start := time.Now()
+
+	hh.update(true, time.Millisecond, start)
+	if c.isDegraded(key, start) || hh.Latency != time.Millisecond || !hh.NextScan.Equal(start.Add(healthScanInterval)) {
+		t.Fatal("unexpected health after a successful scan:", hh)
+	}
+
+	// The first failure marks the start of the downtime.
+	hh.update(false, 0, start.Add(time.Minute))
+	hh.update(false, 0, start.Add(time.Minute+30*time.Second))
+	if hh.ConsecutiveFailures != 2 || !hh.UnreachableSince.Equal(start.Add(time.Minute)) {
+		t.Fatal("unexpected health after failed scans:", hh)
+	}
+	if !hh.NextScan.Equal(start.Add(time.Minute + 30*time.Second + healthScanBackoff(2))) {
+		t.Fatal("next scan was not backed off:", hh.NextScan)
+	}
+	if c.isDegraded(key, start.Add(time.Hour)) {
+		t.Fatal("host degraded before the maximum downtime")
+	}
+	if !c.isDegraded(key, start.Add(time.Hour+time.Minute)) {
+		t.Fatal("host not degraded after the maximum downtime")
+	}
+
+	// A successful scan clears the downtime.
+	hh.update(true, time.Millisecond, start.Add(2*time.Hour))
+	if c.isDegraded(key, start.Add(2*time.Hour)) || hh.ConsecutiveFailures != 0 || !hh.UnreachableSince.IsZero() {
+		t.Fatal("host still unhealthy after a successful scan:", hh)
+	}
+
+	// Hosts that haven't been scanned are never degraded.
+	if c.isDegraded(types.SiaPublicKey{Key: []byte{2}}, start.Add(2*time.Hour)) {
+		t.Fatal("unknown host is degraded")
+	}
+}
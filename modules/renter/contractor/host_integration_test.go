@@ -201,6 +201,103 @@ func TestIntegrationFormContract(t *testing.T) {
 	}
 }
 
+// TestIntegrationExpiringContracts tests that the contractor reports contracts
+// that end within a given threshold.
+func TestIntegrationExpiringContracts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	// get the host's entry from the db
+	hostEntry, ok := c.hdb.Host(h.PublicKey())
+	if !ok {
+		t.Fatal("no entry for host in db")
+	}
+
+	// form a contract with the host
+	contract, err := c.managedNewContract(hostEntry, types.SiacoinPrecision.Mul64(50), c.blockHeight+100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the contract should only be reported once it is within the threshold
+	if expiring := c.ExpiringContracts(99); len(expiring) != 0 {
+		t.Fatal("expected no expiring contracts, got", len(expiring))
+	}
+	expiring := c.ExpiringContracts(100)
+	if len(expiring) != 1 || expiring[0].ID != contract.ID {
+		t.Fatal("expected the contract to be expiring, got", expiring)
+	}
+}
+
+// TestIntegrationBlacklistHost tests that the contractor refuses to form
+// contracts with blacklisted hosts and marks existing contracts with them for
+// replacement.
+func TestIntegrationBlacklistHost(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	// get the host's entry from the db
+	hostEntry, ok := c.hdb.Host(h.PublicKey())
+	if !ok {
+		t.Fatal("no entry for host in db")
+	}
+
+	// form a contract with the host
+	contract, err := c.managedNewContract(hostEntry, types.SiacoinPrecision.Mul64(50), c.blockHeight+100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// blacklist the host
+	hostKey := h.PublicKey()
+	if err := c.BlacklistHost(hostKey); err != nil {
+		t.Fatal(err)
+	}
+	if bl := c.Blacklist(); len(bl) != 1 || bl[0].String() != hostKey.String() {
+		t.Fatal("unexpected blacklist:", bl)
+	}
+
+	// the existing contract should no longer be used or renewed
+	err = build.Retry(50, 100*time.Millisecond, c.managedMarkContractsUtility)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utility, ok := c.ContractUtility(contract.ID)
+	if !ok || utility.GoodForUpload || utility.GoodForRenew {
+		t.Fatal("contract with blacklisted host should have no utility:", utility)
+	}
+
+	// no new contracts should be formed with the host
+	_, err = c.managedNewContract(hostEntry, types.SiacoinPrecision.Mul64(50), c.blockHeight+100)
+	if err != errHostBlacklisted {
+		t.Fatal("expected errHostBlacklisted, got", err)
+	}
+
+	// remove the host from the blacklist
+	if err := c.UnblacklistHost(hostKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UnblacklistHost(hostKey); err != errNotBlacklisted {
+		t.Fatal("expected errNotBlacklisted, got", err)
+	}
+}
+
 // TestIntegrationReviseContract tests that the contractor can revise a
 // contract previously formed with a host.
 func TestIntegrationReviseContract(t *testing.T) {
@@ -14,7 +14,9 @@ import (
 // contractorPersist defines what Contractor data persists across sessions.
 type contractorPersist struct {
 	Allowance     modules.Allowance         `json:"allowance"`
+	Blacklist     []types.SiaPublicKey      `json:"blacklist"`
 	BlockHeight   types.BlockHeight         `json:"blockheight"`
+	ChosenHosts   []types.SiaPublicKey      `json:"chosenhosts"`
 	CurrentPeriod types.BlockHeight         `json:"currentperiod"`
 	LastChange    modules.ConsensusChangeID `json:"lastchange"`
 	OldContracts  []modules.RenterContract  `json:"oldcontracts"`
@@ -30,6 +32,12 @@ func (c *Contractor) persistData() contractorPersist {
 		LastChange:    c.lastChange,
 		RenewedIDs:    make(map[string]string),
 	}
+	for _, key := range c.blacklist {
+		data.Blacklist = append(data.Blacklist, key)
+	}
+	for _, key := range c.chosenHosts {
+		data.ChosenHosts = append(data.ChosenHosts, key)
+	}
 	for _, contract := range c.oldContracts {
 		data.OldContracts = append(data.OldContracts, contract)
 	}
@@ -47,7 +55,13 @@ func (c *Contractor) load() error {
 		return err
 	}
 	c.allowance = data.Allowance
+	for _, key := range data.Blacklist {
+		c.blacklist[key.String()] = key
+	}
 	c.blockHeight = data.BlockHeight
+	for _, key := range data.ChosenHosts {
+		c.chosenHosts[key.String()] = key
+	}
 	c.currentPeriod = data.CurrentPeriod
 	c.lastChange = data.LastChange
 	for _, contract := range data.OldContracts {
@@ -35,6 +35,10 @@ func TestSaveLoad(t *testing.T) {
 		{1}: {ID: types.FileContractID{1}, HostPublicKey: types.SiaPublicKey{Key: []byte("bar")}},
 		{2}: {ID: types.FileContractID{2}, HostPublicKey: types.SiaPublicKey{Key: []byte("baz")}},
 	}
+	blacklisted := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte("qux")}
+	c.blacklist = map[string]types.SiaPublicKey{
+		blacklisted.String(): blacklisted,
+	}
 
 	// save, clear, and reload
 	err := c.save()
@@ -42,6 +46,7 @@ func TestSaveLoad(t *testing.T) {
 		t.Fatal(err)
 	}
 	c.hdb = stubHostDB{}
+	c.blacklist = make(map[string]types.SiaPublicKey)
 	c.renewedIDs = make(map[types.FileContractID]types.FileContractID)
 	c.oldContracts = make(map[types.FileContractID]modules.RenterContract)
 	err = c.load()
@@ -61,6 +66,9 @@ func TestSaveLoad(t *testing.T) {
 	if !ok0 || !ok1 || !ok2 {
 		t.Fatal("oldContracts were not restored properly:", c.oldContracts)
 	}
+	if !c.isBlacklisted(blacklisted) || len(c.blacklist) != 1 {
+		t.Fatal("blacklist was not restored properly:", c.blacklist)
+	}
 
 	// use stdPersist instead of mock
 	c.persist = NewPersist(build.TempDir("contractor", t.Name()))
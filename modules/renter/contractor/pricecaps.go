@@ -0,0 +1,116 @@
+package contractor
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// priceCaps are the highest prices that the contractor pays a host. Hosts
+// that charge more are not selected for new contracts, their contracts are
+// neither used nor renewed, and editors and downloaders stop revising their
+// contracts.
+type priceCaps struct {
+	storage  types.Currency
+	upload   types.Currency
+	download types.Currency
+}
+
+// defaultPriceCaps returns the price caps that are used when the renter has
+// not set its own.
+func defaultPriceCaps() priceCaps {
+	return priceCaps{
+		storage:  defaultMaxStoragePrice,
+		upload:   defaultMaxUploadPrice,
+		download: defaultMaxDownloadPrice,
+	}
+}
+
+// exceeds reports which of the price caps are exceeded by the prices of a
+// host. The storage price is the one that the host charges for a contract
+// lasting the allowance period.
+func (pc priceCaps) exceeds(host modules.HostDBEntry, period types.BlockHeight) (storage, upload, download bool) {
+	storage = host.StoragePriceForDuration(period+host.WindowSize).Cmp(pc.storage) > 0
+	upload = host.UploadBandwidthPrice.Cmp(pc.upload) > 0
+	download = host.DownloadBandwidthPrice.Cmp(pc.download) > 0
+	return
+}
+
+// tooExpensive returns true if the host exceeds any of the price caps.
+func (pc priceCaps) tooExpensive(host modules.HostDBEntry, period types.BlockHeight) bool {
+	storage, upload, download := pc.exceeds(host, period)
+	return storage || upload || download
+}
+
+// filterHosts removes the hosts that exceed any of the price caps, returning
+// the remaining hosts and how many hosts were filtered out by each cap.
+func (pc priceCaps) filterHosts(hosts []modules.HostDBEntry, period types.BlockHeight) ([]modules.HostDBEntry, modules.PriceCapReport) {
+	report := modules.PriceCapReport{Candidates: uint64(len(hosts))}
+	var filtered []modules.HostDBEntry
+	for _, host := range hosts {
+		storage, upload, download := pc.exceeds(host, period)
+		if storage {
+			report.StoragePriceFiltered++
+		}
+		if upload {
+			report.UploadBandwidthPriceFiltered++
+		}
+		if download {
+			report.DownloadBandwidthPriceFiltered++
+		}
+		if !storage && !upload && !download {
+			filtered = append(filtered, host)
+		}
+	}
+	return filtered, report
+}
+
+// managedTooExpensive returns true if the current prices of the host with the
+// given public key exceed the caps that apply to uploads, or to downloads if
+// download is set. Hosts that are not in the hostdb are not checked.
+func (c *Contractor) managedTooExpensive(key types.SiaPublicKey, download bool) bool {
+	host, exists := c.hdb.Host(key)
+	if !exists {
+		return false
+	}
+	c.mu.RLock()
+	storage, upload, dl := c.priceCaps.exceeds(host, c.allowance.Period)
+	c.mu.RUnlock()
+	if download {
+		return dl
+	}
+	return storage || upload
+}
+
+// PriceCaps returns the highest storage, upload bandwidth and download
+// bandwidth prices that the contractor pays a host.
+func (c *Contractor) PriceCaps() (storage, upload, download types.Currency) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.priceCaps.storage, c.priceCaps.upload, c.priceCaps.download
+}
+
+// SetPriceCaps sets the highest storage, upload bandwidth and download
+// bandwidth prices that the contractor pays a host. Zero selects the default.
+func (c *Contractor) SetPriceCaps(storage, upload, download types.Currency) {
+	pc := defaultPriceCaps()
+	if !storage.IsZero() {
+		pc.storage = storage
+	}
+	if !upload.IsZero() {
+		pc.upload = upload
+	}
+	if !download.IsZero() {
+		pc.download = download
+	}
+	c.mu.Lock()
+	c.priceCaps = pc
+	c.mu.Unlock()
+}
+
+// PriceCapReport reports how many candidate hosts were filtered out by the
+// price caps during the most recent contract formation.
+func (c *Contractor) PriceCapReport() modules.PriceCapReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.priceCapReport
+}
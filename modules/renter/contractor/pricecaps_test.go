@@ -0,0 +1,79 @@
+package contractor
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestPriceCapsFilterHosts tests that hosts which exceed any of the price caps
+// are filtered out, and that every cap counts the hosts it filtered out.
+func TestPriceCapsFilterHosts(t *testing.T) {
+	pc := priceCaps{
+		storage:  types.NewCurrency64(10),
+		upload:   types.NewCurrency64(20),
+		download: types.NewCurrency64(30),
+	}
+	host := func(storage, upload, download uint64) (h modules.HostDBEntry) {
+		h.StoragePrice = types.NewCurrency64(storage)
+		h.UploadBandwidthPrice = types.NewCurrency64(upload)
+		h.DownloadBandwidthPrice = types.NewCurrency64(download)
+		return
+	}
+	hosts := []modules.HostDBEntry{
+		host(10, 20, 30), // exactly at the caps
+		host(11, 20, 30),
+		host(10, 21, 31),
+		host(1, 1, 1),
+		host(11, 21, 31),
+	}
+	filtered, report := pc.filterHosts(hosts, 100)
+	if len(filtered) != 2 || pc.tooExpensive(filtered[0], 100) || pc.tooExpensive(filtered[1], 100) {
+		t.Fatal("wrong hosts after filtering:", filtered)
+	}
+	expected := modules.PriceCapReport{
+		Candidates:                     5,
+		StoragePriceFiltered:           2,
+		UploadBandwidthPriceFiltered:   2,
+		DownloadBandwidthPriceFiltered: 2,
+	}
+	if report != expected {
+		t.Fatalf("expected report %v, got %v", expected, report)
+	}
+}
+
+// TestPriceCapsStorageTiers tests that the storage price cap is checked
+// against the storage price that the host charges for the allowance period.
+func TestPriceCapsStorageTiers(t *testing.T) {
+	pc := priceCaps{
+		storage:  types.NewCurrency64(10),
+		upload:   types.NewCurrency64(20),
+		download: types.NewCurrency64(30),
+	}
+	var host modules.HostDBEntry
+	host.StoragePrice = types.NewCurrency64(11)
+	host.WindowSize = 10
+	host.StoragePriceTiers = []modules.HostStoragePriceTier{{MinDuration: 100, StoragePrice: types.NewCurrency64(9)}}
+	if !pc.tooExpensive(host, 50) {
+		t.Fatal("host should be too expensive for a period below the tier")
+	}
+	if pc.tooExpensive(host, 90) {
+		t.Fatal("host should not be too expensive for a period reaching the tier")
+	}
+}
+
+// TestSetPriceCaps tests that zero price caps select the defaults.
+func TestSetPriceCaps(t *testing.T) {
+	c := &Contractor{priceCaps: defaultPriceCaps()}
+	c.SetPriceCaps(types.NewCurrency64(1), types.ZeroCurrency, types.NewCurrency64(3))
+	storage, upload, download := c.PriceCaps()
+	if !storage.Equals64(1) || !upload.Equals(defaultMaxUploadPrice) || !download.Equals64(3) {
+		t.Fatal("wrong price caps:", storage, upload, download)
+	}
+	c.SetPriceCaps(types.ZeroCurrency, types.ZeroCurrency, types.ZeroCurrency)
+	storage, upload, download = c.PriceCaps()
+	if !storage.Equals(defaultMaxStoragePrice) || !upload.Equals(defaultMaxUploadPrice) || !download.Equals(defaultMaxDownloadPrice) {
+		t.Fatal("zero price caps should select the defaults")
+	}
+}
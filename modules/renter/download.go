@@ -395,17 +395,14 @@ func (r *Renter) Download(p modules.RenterDownloadParameters) error {
 	if p.Destination != "" && !filepath.IsAbs(p.Destination) {
 		return errors.New("destination must be an absolute path")
 	}
-	if p.Offset == file.size {
-		return errors.New("offset equals filesize")
+	if p.Offset >= file.size {
+		return fmt.Errorf("offset is beyond the end of the file, max byte is at index %d", file.size-1)
 	}
-	// Sentinel: if length == 0, download the entire file.
-	if p.Length == 0 {
+	// Sentinel: if length == 0, download the entire file. Ranges that reach
+	// past the end of the file are clamped to the file size.
+	if p.Length == 0 || p.Length > file.size-p.Offset {
 		p.Length = file.size - p.Offset
 	}
-	// Check whether offset and length is valid.
-	if p.Offset < 0 || p.Offset+p.Length > file.size {
-		return fmt.Errorf("offset and length combination invalid, max byte is at index %d", file.size-1)
-	}
 
 	// Instantiate the correct downloadWriter implementation.
 	var dw downloadDestination
@@ -11,12 +11,29 @@ import (
 )
 
 type (
+	// streamChunk is a chunk of a streamed file that is downloaded into
+	// memory.
+	streamChunk struct {
+		index    uint64
+		offset   int64 // offset of the chunk within the file
+		buf      *bytes.Buffer
+		download *download
+	}
+
 	// streamer is a io.ReadSeeker that can be used to stream downloads from
 	// the sia network.
 	streamer struct {
 		file   *file
 		offset int64
 		r      *Renter
+
+		// current is the chunk covering the offset of the most recent Read.
+		// next is the chunk following it, which is downloaded while the
+		// caller consumes current. The streamer never fetches more than one
+		// chunk ahead of the caller, so reconstruction can't run arbitrarily
+		// far ahead of a slow consumer.
+		current *streamChunk
+		next    *streamChunk
 	}
 )
 
@@ -49,63 +66,108 @@ func (r *Renter) Streamer(siaPath string) (string, io.ReadSeeker, error) {
 	return file.name, s, nil
 }
 
-// Read implements the standard Read interface. It will download the requested
-// data from the sia network and block until the download is complete.  To
-// prevent http.ServeContent from requesting too much data at once, Read can
-// only request a single chunk at once.
-func (s *streamer) Read(p []byte) (n int, err error) {
-	// Get the file's size
+// managedFetchChunk starts downloading the chunk with the given index into
+// memory. It returns nil if the chunk lies beyond the end of the file.
+func (s *streamer) managedFetchChunk(index uint64) (*streamChunk, error) {
 	s.file.mu.RLock()
-	fileSize := int64(s.file.size)
+	fileSize := s.file.size
 	s.file.mu.RUnlock()
 
-	// Make sure we haven't reached the EOF yet.
-	if s.offset >= fileSize {
-		return 0, io.EOF
-	}
-
-	// Calculate how much we can download. We never download more than a single chunk.
 	chunkSize := s.file.staticChunkSize()
-	remainingData := uint64(fileSize - s.offset)
-	requestedData := uint64(len(p))
-	remainingChunk := chunkSize - uint64(s.offset)%chunkSize
-	length := min(remainingData, requestedData, remainingChunk)
-
-	// Download data
-	buffer := bytes.NewBuffer([]byte{})
+	offset := index * chunkSize
+	if offset >= fileSize {
+		return nil, nil
+	}
+	sc := &streamChunk{
+		index:  index,
+		offset: int64(offset),
+		buf:    bytes.NewBuffer([]byte{}),
+	}
 	d, err := s.r.newDownload(downloadParams{
-		destination:       newDownloadDestinationWriteCloserFromWriter(buffer),
+		destination:       newDownloadDestinationWriteCloserFromWriter(sc.buf),
 		destinationType:   destinationTypeSeekStream,
 		destinationString: "httpresponse",
 		file:              s.file,
 
 		latencyTarget: 50 * time.Millisecond, // TODO low default until full latency suport is added.
-		length:        length,
+		length:        min(chunkSize, fileSize-offset),
 		needsMemory:   true,
-		offset:        uint64(s.offset),
+		offset:        offset,
 		overdrive:     5,    // TODO: high default until full overdrive support is added.
 		priority:      1000, // TODO: high default until full priority support is added.
 	})
 	if err != nil {
-		return 0, errors.AddContext(err, "failed to create new download")
+		return nil, errors.AddContext(err, "failed to create new download")
+	}
+	sc.download = d
+	return sc, nil
+}
+
+// managedChunk returns the chunk with the given index once it has been
+// downloaded. If the chunk was already fetched ahead of time, that download is
+// used instead of starting a new one.
+func (s *streamer) managedChunk(index uint64) (*streamChunk, error) {
+	if s.current == nil || s.current.index != index {
+		if s.next != nil && s.next.index == index {
+			s.current, s.next = s.next, nil
+		} else {
+			// The caller seeked away from the chunks that were fetched. The
+			// download of the chunk that was fetched ahead can't be stopped,
+			// but its data is no longer needed.
+			sc, err := s.managedFetchChunk(index)
+			if err != nil {
+				return nil, err
+			}
+			s.current, s.next = sc, nil
+		}
 	}
 
 	// Block until the download has completed.
 	select {
-	case <-d.completeChan:
-		if d.Err() != nil {
-			return 0, errors.AddContext(d.Err(), "download failed")
+	case <-s.current.download.completeChan:
+		if err := s.current.download.Err(); err != nil {
+			s.current = nil
+			return nil, errors.AddContext(err, "download failed")
 		}
 	case <-s.r.tg.StopChan():
-		return 0, errors.New("download interrupted by shutdown")
+		return nil, errors.New("download interrupted by shutdown")
 	}
+	return s.current, nil
+}
 
-	// Copy downloaded data into buffer.
-	copy(p, buffer.Bytes())
+// Read implements the standard Read interface. It returns data as soon as the
+// chunk covering the current offset has been recovered, and starts
+// downloading the following chunk so that it is ready by the time the caller
+// reaches it. Data is never returned across a chunk boundary within a single
+// call.
+func (s *streamer) Read(p []byte) (n int, err error) {
+	// Get the file's size
+	s.file.mu.RLock()
+	fileSize := int64(s.file.size)
+	s.file.mu.RUnlock()
+
+	// Make sure we haven't reached the EOF yet.
+	if s.offset >= fileSize {
+		return 0, io.EOF
+	}
+
+	// Wait for the chunk covering the offset.
+	index := uint64(s.offset) / s.file.staticChunkSize()
+	sc, err := s.managedChunk(index)
+	if err != nil {
+		return 0, err
+	}
+
+	// Fetch the next chunk while the caller consumes this one. If this fails,
+	// the error will be returned once the caller reaches that chunk.
+	if s.next == nil {
+		s.next, _ = s.managedFetchChunk(index + 1)
+	}
 
-	// Adjust offset
-	s.offset += int64(length)
-	return int(length), nil
+	// Copy the downloaded data into the buffer and adjust the offset.
+	n = copy(p, sc.buf.Bytes()[s.offset-sc.offset:])
+	s.offset += int64(n)
+	return n, nil
 }
 
 // Seek sets the offset for the next Read to offset, interpreted
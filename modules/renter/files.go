@@ -5,7 +5,9 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -21,6 +23,16 @@ var (
 	ErrPathOverload = errors.New("a file already exists at that location")
 	// ErrUnknownPath is an error when a file cannot be found with the given path
 	ErrUnknownPath = errors.New("no file known with that path")
+	// ErrNotUploading is an error when a file is not being uploaded or
+	// repaired by the renter
+	ErrNotUploading = errors.New("file is not being uploaded")
+	// ErrFileUnrecoverable is an error when a file has lost too many pieces
+	// to be reconstructed
+	ErrFileUnrecoverable = errors.New("file has lost too many pieces to be reconstructed")
+
+	// errNoMigrationHosts is returned if a migration is requested without
+	// specifying any hosts to migrate away from.
+	errNoMigrationHosts = errors.New("no hosts specified to migrate the file away from")
 )
 
 // A file is a single file that has been uploaded to the network. Files are
@@ -40,6 +52,11 @@ type file struct {
 
 	staticUID string // A UID assigned to the file when it gets created.
 
+	// uploadTimes contains the completion times of the most recent piece
+	// uploads. It is used to estimate the remaining upload time and is not
+	// persisted.
+	uploadTimes []time.Time
+
 	mu sync.RWMutex
 }
 
@@ -131,6 +148,59 @@ func (f *file) uploadProgress() float64 {
 	return math.Min(100*(float64(uploaded)/float64(desired)), 100)
 }
 
+// uploadedChunks returns the number of chunks for which all pieces have been
+// uploaded.
+func (f *file) uploadedChunks() uint64 {
+	chunkPieces := make([]int, f.numChunks())
+	for _, fc := range f.contracts {
+		for _, p := range fc.Pieces {
+			if p.Chunk < uint64(len(chunkPieces)) {
+				chunkPieces[p.Chunk]++
+			}
+		}
+	}
+	var uploaded uint64
+	for _, n := range chunkPieces {
+		if n >= f.erasureCode.NumPieces() {
+			uploaded++
+		}
+	}
+	return uploaded
+}
+
+// recordPieceUpload records that a piece of the file finished uploading. The
+// most recent uploads are used to estimate the remaining upload time.
+func (f *file) recordPieceUpload(t time.Time) {
+	f.uploadTimes = append(f.uploadTimes, t)
+	if len(f.uploadTimes) > uploadThroughputSamples {
+		f.uploadTimes = f.uploadTimes[len(f.uploadTimes)-uploadThroughputSamples:]
+	}
+}
+
+// uploadETA estimates the number of seconds until the file (plus redundancy)
+// is fully uploaded, based on the throughput of the most recent piece uploads.
+// Because every piece occupies a full sector on a host, the estimate reflects
+// the bytes that are actually pushed to hosts. -1 is returned if there are not
+// enough recent uploads to estimate the throughput.
+func (f *file) uploadETA(now time.Time) int64 {
+	desired := modules.SectorSize * uint64(f.erasureCode.NumPieces()) * f.numChunks()
+	uploaded := f.uploadedBytes()
+	if uploaded >= desired {
+		return 0
+	}
+	if len(f.uploadTimes) < minUploadThroughputSamples {
+		return -1
+	}
+	// The time until now is included so that the estimate grows if the
+	// upload stalls.
+	elapsed := now.Sub(f.uploadTimes[0]).Seconds()
+	if elapsed <= 0 {
+		return -1
+	}
+	throughput := float64(uint64(len(f.uploadTimes)-1)*modules.SectorSize) / elapsed
+	return int64(math.Ceil(float64(desired-uploaded) / throughput))
+}
+
 // redundancy returns the redundancy of the least redundant chunk. A file
 // becomes available when this redundancy is >= 1. Assumes that every piece is
 // unique within a file contract. -1 is returned if the file has size 0. It
@@ -191,6 +261,62 @@ func (f *file) redundancy(contractStatus func(types.FileContractID) (bool, bool)
 	return redundancy
 }
 
+// health returns the pieces of each chunk of the file along with their hosts,
+// and classifies the file by its least healthy chunk. pieceHost returns the
+// host of the pieces of a contract; the Piece field is filled in by health.
+func (f *file) health(pieceHost func(fileContract) modules.PieceHost) modules.FileHealth {
+	minPieces := f.erasureCode.MinPieces()
+	numPieces := f.erasureCode.NumPieces()
+	health := modules.FileHealth{
+		SiaPath:      f.name,
+		Status:       modules.FileHealthHealthy,
+		DataPieces:   minPieces,
+		ParityPieces: numPieces - minPieces,
+	}
+	// Empty files don't need any pieces to be recovered.
+	if f.size == 0 {
+		return health
+	}
+
+	chunks := make([]modules.ChunkHealth, f.numChunks())
+	for _, fc := range f.contracts {
+		ph := pieceHost(fc)
+		for _, p := range fc.Pieces {
+			ph.Piece = p.Piece
+			chunks[p.Chunk].Hosts = append(chunks[p.Chunk].Hosts, ph)
+		}
+	}
+	for i := range chunks {
+		chunks[i].Index = uint64(i)
+		hosts := chunks[i].Hosts
+		sort.Slice(hosts, func(a, b int) bool {
+			return hosts[a].Piece < hosts[b].Piece
+		})
+
+		// Count the distinct pieces that can be retrieved. A piece stored on
+		// multiple hosts only counts once.
+		retrievable := make(map[uint64]struct{})
+		goodForRenew := make(map[uint64]struct{})
+		for _, ph := range hosts {
+			if !ph.Online {
+				continue
+			}
+			retrievable[ph.Piece] = struct{}{}
+			if ph.GoodForRenew {
+				goodForRenew[ph.Piece] = struct{}{}
+			}
+		}
+		chunks[i].Pieces = len(retrievable)
+		if len(retrievable) < minPieces {
+			health.Status = modules.FileHealthUnrecoverable
+		} else if len(goodForRenew) < numPieces && health.Status == modules.FileHealthHealthy {
+			health.Status = modules.FileHealthAtRisk
+		}
+	}
+	health.Chunks = chunks
+	return health
+}
+
 // expiration returns the lowest height at which any of the file's contracts
 // will expire.
 func (f *file) expiration() types.BlockHeight {
@@ -232,6 +358,7 @@ func (r *Renter) DeleteFile(nickname string) error {
 		r.mu.Unlock(lockID)
 		return ErrUnknownPath
 	}
+	tf, tracked := r.tracking[nickname]
 	delete(r.files, nickname)
 	delete(r.tracking, nickname)
 
@@ -239,6 +366,13 @@ func (r *Renter) DeleteFile(nickname string) error {
 	if err != nil {
 		r.log.Println("WARN: couldn't remove file :", err)
 	}
+	// Files uploaded with UploadBytes have a local copy in the persist
+	// directory of the renter, which is removed along with the file.
+	if tracked && filepath.Dir(tf.RepairPath) == filepath.Join(r.persistDir, smallFilesDir) {
+		if err := os.Remove(tf.RepairPath); err != nil {
+			r.log.Println("WARN: couldn't remove the local copy of a small file:", err)
+		}
+	}
 
 	r.saveSync()
 	r.mu.Unlock(lockID)
@@ -282,6 +416,7 @@ func (r *Renter) FileList() []modules.FileInfo {
 		if exists {
 			localPath = tf.RepairPath
 		}
+		paused := exists && tf.Paused
 		fileList = append(fileList, modules.FileInfo{
 			SiaPath:        f.name,
 			LocalPath:      localPath,
@@ -289,8 +424,14 @@ func (r *Renter) FileList() []modules.FileInfo {
 			Renewing:       renewing,
 			Available:      f.available(contractStatus),
 			Redundancy:     f.redundancy(contractStatus),
+			DataPieces:     f.erasureCode.MinPieces(),
+			ParityPieces:   f.erasureCode.NumPieces() - f.erasureCode.MinPieces(),
 			UploadedBytes:  f.uploadedBytes(),
 			UploadProgress: f.uploadProgress(),
+			UploadedChunks: f.uploadedChunks(),
+			TotalChunks:    f.numChunks(),
+			UploadETA:      f.uploadETA(time.Now()),
+			UploadPaused:   paused,
 			Expiration:     f.expiration(),
 		})
 		f.mu.RUnlock()
@@ -299,6 +440,157 @@ func (r *Renter) FileList() []modules.FileInfo {
 	return fileList
 }
 
+// FileHealth reports which pieces of each chunk of a file can currently be
+// retrieved, and from which hosts. A piece is considered retrievable if the
+// host storing it was online during its most recent scan, so the report
+// reflects the current availability of the hosts rather than the layout at
+// upload time.
+func (r *Renter) FileHealth(siaPath string) (modules.FileHealth, error) {
+	lockID := r.mu.RLock()
+	f, exists := r.files[siaPath]
+	r.mu.RUnlock(lockID)
+	if !exists {
+		return modules.FileHealth{}, ErrUnknownPath
+	}
+
+	// Look up the host of a contract using its most recent renewal.
+	pieceHost := func(fc fileContract) modules.PieceHost {
+		id := r.hostContractor.ResolveID(fc.ID)
+		ph := modules.PieceHost{
+			NetAddress: fc.IP,
+			Online:     !r.hostContractor.IsOffline(id),
+		}
+		if cu, ok := r.hostContractor.ContractUtility(id); ok {
+			ph.GoodForRenew = cu.GoodForRenew
+		}
+		if contract, ok := r.hostContractor.ContractByID(id); ok {
+			ph.HostPublicKey = contract.HostPublicKey
+			if host, ok := r.hostDB.Host(contract.HostPublicKey); ok {
+				ph.NetAddress = host.NetAddress
+			}
+		}
+		return ph
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.health(pieceHost), nil
+}
+
+// PauseUpload pauses the upload of a file. Pieces that are already being
+// uploaded finish uploading, but no further pieces of the file are uploaded or
+// repaired until the upload is resumed. The uploaded pieces are recorded in the
+// file, so resuming the upload does not upload them again.
+func (r *Renter) PauseUpload(siaPath string) error {
+	return r.managedSetUploadPaused(siaPath, true)
+}
+
+// ResumeUpload resumes the upload of a paused file.
+func (r *Renter) ResumeUpload(siaPath string) error {
+	err := r.managedSetUploadPaused(siaPath, false)
+	if err != nil {
+		return err
+	}
+	// Signal the repair loop to pick up the unfinished chunks of the file.
+	select {
+	case r.uploadHeap.newUploads <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// managedSetUploadPaused sets the paused state of a file's upload.
+func (r *Renter) managedSetUploadPaused(siaPath string, paused bool) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	lockID := r.mu.Lock()
+	defer r.mu.Unlock(lockID)
+	if _, exists := r.files[siaPath]; !exists {
+		return ErrUnknownPath
+	}
+	tf, exists := r.tracking[siaPath]
+	if !exists {
+		return ErrNotUploading
+	}
+	tf.Paused = paused
+	r.tracking[siaPath] = tf
+	return r.saveSync()
+}
+
+// managedUploadPaused returns true if the upload of the file is paused.
+func (r *Renter) managedUploadPaused(f *file) bool {
+	lockID := r.mu.RLock()
+	defer r.mu.RUnlock(lockID)
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return r.tracking[f.name].Paused
+}
+
+// MigrateFile moves the pieces of a file that are stored on the given hosts to
+// other hosts in the renter's contract set. The migration is carried out by
+// the repair loop: pieces on the given hosts no longer count towards the
+// redundancy of the file, so the affected chunks are fetched, re-encoded and
+// uploaded to hosts that don't store a piece of the chunk yet. The data is
+// read from the local copy of the file if it is available and downloaded from
+// the hosts otherwise, including the hosts that are being migrated away from.
+// Once a chunk is fully uploaded to the other hosts, its pieces on the given
+// hosts are dropped from the file.
+//
+// ErrFileUnrecoverable is returned if any chunk of the file has fewer
+// retrievable pieces than are needed to reconstruct it.
+func (r *Renter) MigrateFile(siaPath string, hosts []types.SiaPublicKey) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	if len(hosts) == 0 {
+		return errNoMigrationHosts
+	}
+
+	health, err := r.FileHealth(siaPath)
+	if err != nil {
+		return err
+	}
+	if health.Status == modules.FileHealthUnrecoverable {
+		return ErrFileUnrecoverable
+	}
+
+	lockID := r.mu.Lock()
+	if _, exists := r.files[siaPath]; !exists {
+		r.mu.Unlock(lockID)
+		return ErrUnknownPath
+	}
+	// Files without a tracking entry are tracked without a repair path, which
+	// makes the repair loop download the data of the chunks it migrates.
+	tf := r.tracking[siaPath]
+	known := make(map[string]struct{})
+	for _, host := range tf.MigrateFrom {
+		known[host.String()] = struct{}{}
+	}
+	for _, host := range hosts {
+		if _, exists := known[host.String()]; !exists {
+			known[host.String()] = struct{}{}
+			tf.MigrateFrom = append(tf.MigrateFrom, host)
+		}
+	}
+	r.tracking[siaPath] = tf
+	err = r.saveSync()
+	r.mu.Unlock(lockID)
+	if err != nil {
+		return err
+	}
+
+	// Signal the repair loop to pick up the chunks of the file.
+	select {
+	case r.uploadHeap.newUploads <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
 // RenameFile takes an existing file and changes the nickname. The original
 // file must exist, and there must not be any file that already has the
 // replacement nickname.
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
@@ -105,6 +106,71 @@ func TestFileUploadProgressPinning(t *testing.T) {
 	}
 }
 
+// TestFileUploadETA checks that the upload ETA and the number of uploaded
+// chunks are computed correctly, and that the ETA is unknown without enough
+// throughput samples.
+func TestFileUploadETA(t *testing.T) {
+	rsc, _ := NewRSCode(1, 1)
+	f := &file{
+		size:        2 * 10,
+		pieceSize:   10,
+		erasureCode: rsc,
+		contracts:   make(map[types.FileContractID]fileContract),
+	}
+	f.contracts[types.FileContractID{}] = fileContract{
+		ID:     types.FileContractID{},
+		Pieces: []pieceData{{Chunk: 0, Piece: 0}},
+	}
+
+	// Without any samples the ETA is unknown.
+	now := time.Now()
+	if eta := f.uploadETA(now); eta != -1 {
+		t.Fatal("expected unknown ETA, got", eta)
+	}
+	if n := f.uploadedChunks(); n != 0 {
+		t.Fatal("expected 0 uploaded chunks, got", n)
+	}
+
+	// Three uploads in two seconds is one sector per second. Three of the
+	// four sectors (2 chunks with 2 pieces each) are still missing.
+	for i := 0; i < 3; i++ {
+		f.recordPieceUpload(now.Add(time.Duration(i) * time.Second))
+	}
+	if eta := f.uploadETA(now.Add(2 * time.Second)); eta != 3 {
+		t.Fatal("expected an ETA of 3 seconds, got", eta)
+	}
+	// If the upload stalls, the ETA should grow.
+	if eta := f.uploadETA(now.Add(4 * time.Second)); eta != 6 {
+		t.Fatal("expected an ETA of 6 seconds, got", eta)
+	}
+
+	// Complete the first chunk.
+	fc := f.contracts[types.FileContractID{}]
+	fc.Pieces = append(fc.Pieces, pieceData{Chunk: 0, Piece: 1})
+	f.contracts[types.FileContractID{}] = fc
+	if n := f.uploadedChunks(); n != 1 {
+		t.Fatal("expected 1 uploaded chunk, got", n)
+	}
+
+	// Complete the file. The ETA should be 0.
+	fc.Pieces = append(fc.Pieces, pieceData{Chunk: 1, Piece: 0}, pieceData{Chunk: 1, Piece: 1})
+	f.contracts[types.FileContractID{}] = fc
+	if n := f.uploadedChunks(); n != 2 {
+		t.Fatal("expected 2 uploaded chunks, got", n)
+	}
+	if eta := f.uploadETA(now); eta != 0 {
+		t.Fatal("expected an ETA of 0, got", eta)
+	}
+
+	// Only the most recent samples are kept.
+	for i := 0; i < 2*uploadThroughputSamples; i++ {
+		f.recordPieceUpload(now)
+	}
+	if len(f.uploadTimes) != uploadThroughputSamples {
+		t.Fatal("expected samples to be capped, got", len(f.uploadTimes))
+	}
+}
+
 // TestFileRedundancy tests that redundancy is correctly calculated for files
 // with varying number of filecontracts and erasure code settings.
 func TestFileRedundancy(t *testing.T) {
@@ -247,8 +313,89 @@ func TestFileExpiration(t *testing.T) {
 	}
 }
 
+// TestFileHealth probes the health method of the file type.
+func TestFileHealth(t *testing.T) {
+	rsc, _ := NewRSCode(2, 1)
+	f := &file{
+		size:        1000,
+		pieceSize:   100,
+		contracts:   make(map[types.FileContractID]fileContract),
+		erasureCode: rsc,
+	}
+	hosts := make(map[types.FileContractID]modules.PieceHost)
+	pieceHost := func(fc fileContract) modules.PieceHost {
+		return hosts[fc.ID]
+	}
+
+	// An empty file is always healthy.
+	empty := &file{erasureCode: rsc}
+	if h := empty.health(pieceHost); h.Status != modules.FileHealthHealthy {
+		t.Error("expected empty file to be healthy, got", h.Status)
+	}
+
+	// A file without any pieces is unrecoverable.
+	h := f.health(pieceHost)
+	if h.Status != modules.FileHealthUnrecoverable || len(h.Chunks) != int(f.numChunks()) {
+		t.Fatal("expected unrecoverable file with every chunk, got", h.Status, len(h.Chunks))
+	}
+
+	// Store piece i of every chunk in contract i.
+	for i := 0; i < rsc.NumPieces(); i++ {
+		fc := fileContract{ID: types.FileContractID{byte(i)}}
+		for c := uint64(0); c < f.numChunks(); c++ {
+			fc.Pieces = append(fc.Pieces, pieceData{Chunk: c, Piece: uint64(i)})
+		}
+		f.contracts[fc.ID] = fc
+		hosts[fc.ID] = modules.PieceHost{Online: true, GoodForRenew: true}
+	}
+	h = f.health(pieceHost)
+	if h.Status != modules.FileHealthHealthy {
+		t.Fatal("expected healthy file, got", h.Status)
+	}
+	for i, c := range h.Chunks {
+		if c.Index != uint64(i) || c.Pieces != rsc.NumPieces() || len(c.Hosts) != rsc.NumPieces() {
+			t.Fatal("unexpected chunk health:", c)
+		}
+		for j, ph := range c.Hosts {
+			if ph.Piece != uint64(j) {
+				t.Fatal("hosts are not sorted by piece:", c.Hosts)
+			}
+		}
+	}
+
+	// A host that is not good for renewal puts the file at risk.
+	hosts[types.FileContractID{2}] = modules.PieceHost{Online: true}
+	if h = f.health(pieceHost); h.Status != modules.FileHealthAtRisk {
+		t.Fatal("expected file to be at risk, got", h.Status)
+	}
+
+	// Losing a piece leaves the file at risk, as long as enough pieces remain.
+	hosts[types.FileContractID{1}] = modules.PieceHost{}
+	h = f.health(pieceHost)
+	if h.Status != modules.FileHealthAtRisk || h.Chunks[0].Pieces != 2 {
+		t.Fatal("expected file to be at risk with 2 pieces, got", h.Status, h.Chunks[0].Pieces)
+	}
+
+	// Losing another piece makes the file unrecoverable.
+	hosts[types.FileContractID{0}] = modules.PieceHost{}
+	h = f.health(pieceHost)
+	if h.Status != modules.FileHealthUnrecoverable || h.Chunks[0].Pieces != 1 {
+		t.Fatal("expected unrecoverable file with 1 piece, got", h.Status, h.Chunks[0].Pieces)
+	}
+
+	// A copy of a piece that is already retrievable doesn't add redundancy.
+	fc := f.contracts[types.FileContractID{2}]
+	fc.ID = types.FileContractID{3}
+	f.contracts[fc.ID] = fc
+	hosts[fc.ID] = modules.PieceHost{Online: true, GoodForRenew: true}
+	h = f.health(pieceHost)
+	if h.Status != modules.FileHealthUnrecoverable || h.Chunks[0].Pieces != 1 {
+		t.Fatal("duplicate piece should only be counted once, got", h.Status, h.Chunks[0].Pieces)
+	}
+}
+
 // TestRenterFileListLocalPath verifies that FileList() returns the correct
-// local path information for an uploaded file.
+// local path and erasure coding information for an uploaded file.
 func TestRenterFileListLocalPath(t *testing.T) {
 	if testing.Short() {
 		t.SkipNow()
@@ -273,6 +420,133 @@ func TestRenterFileListLocalPath(t *testing.T) {
 	if files[0].LocalPath != "TestPath" {
 		t.Fatal("file had wrong LocalPath: got", files[0].LocalPath, "wanted TestPath")
 	}
+	if files[0].DataPieces != f.erasureCode.MinPieces() {
+		t.Fatal("file had wrong DataPieces: got", files[0].DataPieces, "wanted", f.erasureCode.MinPieces())
+	}
+	if files[0].DataPieces+files[0].ParityPieces != f.erasureCode.NumPieces() {
+		t.Fatal("file had wrong ParityPieces: got", files[0].ParityPieces, "wanted", f.erasureCode.NumPieces()-f.erasureCode.MinPieces())
+	}
+}
+
+// TestRenterPauseUpload checks that uploads can be paused and resumed, that
+// paused files are skipped by the repair loop, and that the paused state is
+// persisted.
+func TestRenterPauseUpload(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	// Unknown and untracked files cannot be paused.
+	if err := rt.renter.PauseUpload("dne"); err != ErrUnknownPath {
+		t.Fatal("expected ErrUnknownPath, got", err)
+	}
+	f := newTestingFile()
+	id := rt.renter.mu.Lock()
+	rt.renter.files[f.name] = f
+	rt.renter.mu.Unlock(id)
+	if err := rt.renter.PauseUpload(f.name); err != ErrNotUploading {
+		t.Fatal("expected ErrNotUploading, got", err)
+	}
+
+	// Pause a tracked file.
+	id = rt.renter.mu.Lock()
+	rt.renter.tracking[f.name] = trackedFile{RepairPath: "TestPath"}
+	rt.renter.mu.Unlock(id)
+	if err := rt.renter.PauseUpload(f.name); err != nil {
+		t.Fatal(err)
+	}
+	if files := rt.renter.FileList(); len(files) != 1 || !files[0].UploadPaused {
+		t.Fatal("file should be reported as paused:", files)
+	}
+	if !rt.renter.managedUploadPaused(f) {
+		t.Fatal("file should be paused")
+	}
+	id = rt.renter.mu.Lock()
+	chunks := rt.renter.buildUnfinishedChunks(f, nil)
+	rt.renter.mu.Unlock(id)
+	if len(chunks) != 0 {
+		t.Fatal("paused file should not have any chunks to repair")
+	}
+
+	// The paused state should be persisted.
+	id = rt.renter.mu.Lock()
+	rt.renter.tracking = make(map[string]trackedFile)
+	err = rt.renter.load()
+	rt.renter.mu.Unlock(id)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if !rt.renter.managedUploadPaused(f) {
+		t.Fatal("paused state was not persisted")
+	}
+
+	// Resume the upload.
+	if err := rt.renter.ResumeUpload(f.name); err != nil {
+		t.Fatal(err)
+	}
+	if files := rt.renter.FileList(); len(files) != 1 || files[0].UploadPaused {
+		t.Fatal("file should not be reported as paused:", files)
+	}
+}
+
+// TestRenterMigrateFile checks that migrations are refused for unknown and
+// unrecoverable files, and that the hosts to migrate away from are persisted.
+func TestRenterMigrateFile(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	hostA := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	hostB := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{2}}
+	if err := rt.renter.MigrateFile("dne", []types.SiaPublicKey{hostA}); err != ErrUnknownPath {
+		t.Fatal("expected ErrUnknownPath, got", err)
+	}
+
+	// A file without any retrievable pieces can't be migrated.
+	f := newTestingFile()
+	f.size = 1
+	id := rt.renter.mu.Lock()
+	rt.renter.files[f.name] = f
+	rt.renter.mu.Unlock(id)
+	if err := rt.renter.MigrateFile(f.name, []types.SiaPublicKey{hostA}); err != ErrFileUnrecoverable {
+		t.Fatal("expected ErrFileUnrecoverable, got", err)
+	}
+
+	// Empty files are always recoverable. Requesting the same host twice
+	// should only record it once.
+	f.size = 0
+	if err := rt.renter.MigrateFile(f.name, nil); err != errNoMigrationHosts {
+		t.Fatal("expected errNoMigrationHosts, got", err)
+	}
+	if err := rt.renter.MigrateFile(f.name, []types.SiaPublicKey{hostA}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.renter.MigrateFile(f.name, []types.SiaPublicKey{hostA, hostB}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The migration should be persisted.
+	id = rt.renter.mu.Lock()
+	rt.renter.tracking = make(map[string]trackedFile)
+	err = rt.renter.load()
+	migrateFrom := rt.renter.tracking[f.name].MigrateFrom
+	rt.renter.mu.Unlock(id)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if len(migrateFrom) != 2 || migrateFrom[0].String() != hostA.String() || migrateFrom[1].String() != hostB.String() {
+		t.Fatal("wrong hosts to migrate away from:", migrateFrom)
+	}
 }
 
 // TestRenterDeleteFile probes the DeleteFile method of the renter type.
@@ -438,7 +712,7 @@ func TestRenterRenameFile(t *testing.T) {
 	}
 
 	// Renaming should also update the tracking set
-	rt.renter.tracking["1"] = trackedFile{"foo"}
+	rt.renter.tracking["1"] = trackedFile{RepairPath: "foo"}
 	err = rt.renter.RenameFile("1", "1b")
 	if err != nil {
 		t.Fatal(err)
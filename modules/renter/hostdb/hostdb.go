@@ -22,6 +22,7 @@ var (
 	// ErrInitialScanIncomplete is returned whenever an operation is not
 	// allowed to be executed before the initial host scan has finished.
 	ErrInitialScanIncomplete = errors.New("initial hostdb scan is not yet completed")
+	errInvalidScoreWeight    = errors.New("score weights must be non-negative numbers")
 	errNilCS                 = errors.New("cannot create hostdb with nil consensus set")
 	errNilGateway            = errors.New("cannot create hostdb with nil gateway")
 )
@@ -44,6 +45,14 @@ type HostDB struct {
 	// random.
 	hostTree *hosttree.HostTree
 
+	// scoreWeights determine how much the properties of a host count towards
+	// its weight in the hostTree.
+	scoreWeights modules.HostScoreWeights
+
+	// allowance is the allowance of the renter. The storage price of a host
+	// is scored for a contract lasting the allowance period.
+	allowance modules.Allowance
+
 	// the scanPool is a set of hosts that need to be scanned. There are a
 	// handful of goroutines constantly waiting on the channel for hosts to
 	// scan. The scan map is used to prevent duplicates from entering the scan
@@ -82,7 +91,8 @@ func NewCustomHostDB(g modules.Gateway, cs modules.ConsensusSet, persistDir stri
 		gateway:    g,
 		persistDir: persistDir,
 
-		scanMap: make(map[string]struct{}),
+		scanMap:      make(map[string]struct{}),
+		scoreWeights: modules.DefaultHostScoreWeights,
 	}
 
 	// Create the persist directory if it does not yet exist.
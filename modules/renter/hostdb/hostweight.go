@@ -162,7 +162,8 @@ func (hdb *HostDB) priceAdjustments(entry modules.HostDBEntry) float64 {
 	adjustedUploadPrice := entry.UploadBandwidthPrice.Div64(24192)              // Adjust upload price to match a single upload over 24 weeks.
 	adjustedDownloadPrice := entry.DownloadBandwidthPrice.Div64(12096).Div64(3) // Adjust download price to match one download over 12 weeks, 1 redundancy.
 	siafundFee := adjustedContractPrice.Add(adjustedUploadPrice).Add(adjustedDownloadPrice).Add(entry.Collateral).MulTax()
-	totalPrice := entry.StoragePrice.Add(adjustedContractPrice).Add(adjustedUploadPrice).Add(adjustedDownloadPrice).Add(siafundFee)
+	storagePrice := entry.StoragePriceForDuration(hdb.allowance.Period + entry.WindowSize)
+	totalPrice := storagePrice.Add(adjustedContractPrice).Add(adjustedUploadPrice).Add(adjustedDownloadPrice).Add(siafundFee)
 
 	// Set a minimum on the price, then normalize to a sane precision.
 	if totalPrice.Cmp(minTotalPrice) < 0 {
@@ -366,15 +367,21 @@ func (hdb *HostDB) uptimeAdjustments(entry modules.HostDBEntry) float64 {
 	return math.Pow(uptimeRatio, exp)
 }
 
+// weightedAdjustment applies a score weight to an adjustment. A weight of 1
+// leaves the adjustment untouched and a weight of 0 neutralizes it.
+func weightedAdjustment(adjustment, weight float64) float64 {
+	return math.Pow(adjustment, weight)
+}
+
 // calculateHostWeight returns the weight of a host according to the settings of
 // the host database entry.
 func (hdb *HostDB) calculateHostWeight(entry modules.HostDBEntry) types.Currency {
-	collateralReward := hdb.collateralAdjustments(entry)
+	collateralReward := weightedAdjustment(hdb.collateralAdjustments(entry), hdb.scoreWeights.Collateral)
 	interactionPenalty := hdb.interactionAdjustments(entry)
 	lifetimePenalty := hdb.lifetimeAdjustments(entry)
-	pricePenalty := hdb.priceAdjustments(entry)
-	storageRemainingPenalty := storageRemainingAdjustments(entry)
-	uptimePenalty := hdb.uptimeAdjustments(entry)
+	pricePenalty := weightedAdjustment(hdb.priceAdjustments(entry), hdb.scoreWeights.Price)
+	storageRemainingPenalty := weightedAdjustment(storageRemainingAdjustments(entry), hdb.scoreWeights.Storage)
+	uptimePenalty := weightedAdjustment(hdb.uptimeAdjustments(entry), hdb.scoreWeights.Uptime)
 	versionPenalty := versionAdjustments(entry)
 
 	// Combine the adjustments.
@@ -411,11 +418,14 @@ func (hdb *HostDB) calculateConversionRate(score types.Currency) float64 {
 // EstimateHostScore takes a HostExternalSettings and returns the estimated
 // score of that host in the hostdb, assuming no penalties for age or uptime.
 func (hdb *HostDB) EstimateHostScore(entry modules.HostDBEntry) modules.HostScoreBreakdown {
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+
 	// Grab the adjustments. Age, and uptime penalties are set to '1', to
 	// assume best behavior from the host.
-	collateralReward := hdb.collateralAdjustments(entry)
-	pricePenalty := hdb.priceAdjustments(entry)
-	storageRemainingPenalty := storageRemainingAdjustments(entry)
+	collateralReward := weightedAdjustment(hdb.collateralAdjustments(entry), hdb.scoreWeights.Collateral)
+	pricePenalty := weightedAdjustment(hdb.priceAdjustments(entry), hdb.scoreWeights.Price)
+	storageRemainingPenalty := weightedAdjustment(storageRemainingAdjustments(entry), hdb.scoreWeights.Storage)
 	versionPenalty := versionAdjustments(entry)
 
 	// Combine into a full penalty, then determine the resulting estimated
@@ -454,11 +464,65 @@ func (hdb *HostDB) ScoreBreakdown(entry modules.HostDBEntry) modules.HostScoreBr
 
 		AgeAdjustment:              hdb.lifetimeAdjustments(entry),
 		BurnAdjustment:             1,
-		CollateralAdjustment:       hdb.collateralAdjustments(entry),
+		CollateralAdjustment:       weightedAdjustment(hdb.collateralAdjustments(entry), hdb.scoreWeights.Collateral),
 		InteractionAdjustment:      hdb.interactionAdjustments(entry),
-		PriceAdjustment:            hdb.priceAdjustments(entry),
-		StorageRemainingAdjustment: storageRemainingAdjustments(entry),
-		UptimeAdjustment:           hdb.uptimeAdjustments(entry),
+		PriceAdjustment:            weightedAdjustment(hdb.priceAdjustments(entry), hdb.scoreWeights.Price),
+		StorageRemainingAdjustment: weightedAdjustment(storageRemainingAdjustments(entry), hdb.scoreWeights.Storage),
+		UptimeAdjustment:           weightedAdjustment(hdb.uptimeAdjustments(entry), hdb.scoreWeights.Uptime),
 		VersionAdjustment:          versionAdjustments(entry),
 	}
 }
+
+// ScoreWeights returns the weights that are applied to the properties of a host
+// when calculating its score.
+func (hdb *HostDB) ScoreWeights() modules.HostScoreWeights {
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+	return hdb.scoreWeights
+}
+
+// SetAllowance sets the allowance that the hosts are scored for. The storage
+// price of a host is the one that it charges for a contract lasting the
+// allowance period. All hosts are re-weighted if the period changes.
+func (hdb *HostDB) SetAllowance(a modules.Allowance) {
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+	period := hdb.allowance.Period
+	hdb.allowance = a
+	if a.Period == period {
+		return
+	}
+	for _, entry := range hdb.hostTree.All() {
+		if err := hdb.hostTree.Modify(entry); err != nil {
+			hdb.log.Println("ERROR: unable to re-weight host:", err)
+		}
+	}
+}
+
+// SetScoreWeights changes the weights that are applied to the properties of a
+// host when calculating its score. All hosts are re-weighted right away, so
+// the next host selection uses the new weights. The zero value restores the
+// default weights.
+func (hdb *HostDB) SetScoreWeights(w modules.HostScoreWeights) error {
+	if w == (modules.HostScoreWeights{}) {
+		w = modules.DefaultHostScoreWeights
+	}
+	for _, weight := range []float64{w.Collateral, w.Price, w.Storage, w.Uptime} {
+		if !(weight >= 0) || math.IsInf(weight, 1) {
+			return errInvalidScoreWeight
+		}
+	}
+
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+	if w == hdb.scoreWeights {
+		return nil
+	}
+	hdb.scoreWeights = w
+	for _, entry := range hdb.hostTree.All() {
+		if err := hdb.hostTree.Modify(entry); err != nil {
+			hdb.log.Println("ERROR: unable to re-weight host:", err)
+		}
+	}
+	return hdb.saveSync()
+}
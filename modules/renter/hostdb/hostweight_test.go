@@ -84,6 +84,32 @@ func TestHostWeightCollateralDifferences(t *testing.T) {
 	}
 }
 
+// TestHostWeightStoragePriceTiers checks that hosts are scored for the
+// storage price that they charge for a contract lasting the allowance period.
+func TestHostWeightStoragePriceTiers(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	hdb := bareHostDB()
+	var entry modules.HostDBEntry
+	entry.Version = build.Version
+	entry.RemainingStorage = 250e3
+	entry.WindowSize = 10
+	entry.StoragePrice = types.NewCurrency64(300).Mul(types.SiacoinPrecision).Div64(4032).Div64(1e9)
+	entry.StoragePriceTiers = []modules.HostStoragePriceTier{{
+		MinDuration:  1000,
+		StoragePrice: types.NewCurrency64(100).Mul(types.SiacoinPrecision).Div64(4032).Div64(1e9),
+	}}
+
+	hdb.SetAllowance(modules.Allowance{Period: 500})
+	w1 := hdb.calculateHostWeight(entry)
+	hdb.SetAllowance(modules.Allowance{Period: 1000})
+	w2 := hdb.calculateHostWeight(entry)
+	if w1.Cmp(w2) >= 0 {
+		t.Error("Host should weigh more for a period that reaches a cheaper tier")
+	}
+}
+
 func TestHostWeightStorageRemainingDifferences(t *testing.T) {
 	if testing.Short() {
 		t.SkipNow()
@@ -286,3 +312,42 @@ func TestHostWeightUptimeDifferences4(t *testing.T) {
 		t.Error("Been around longer should have more weight")
 	}
 }
+
+// TestHostWeightScoreWeights checks that the score weights change how much
+// each attribute of a host affects its weight.
+func TestHostWeightScoreWeights(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	hdb := bareHostDB()
+	var entry modules.HostDBEntry
+	entry.Version = build.Version
+	entry.RemainingStorage = 250e3
+	entry.StoragePrice = types.NewCurrency64(300).Mul(types.SiacoinPrecision).Div64(4032).Div64(1e9)
+	entry2 := entry
+	entry2.StoragePrice = types.NewCurrency64(600).Mul(types.SiacoinPrecision).Div64(4032).Div64(1e9)
+
+	// With the default weights the cheaper host should be preferred.
+	w1 := hdb.calculateHostWeight(entry)
+	w2 := hdb.calculateHostWeight(entry2)
+	if w1.Cmp(w2) <= 0 {
+		t.Fatal("cheaper host should have more weight")
+	}
+	defaultRatio := w1.Div(w2)
+
+	// Doubling the price weight should widen the gap between the hosts.
+	hdb.scoreWeights.Price = 2
+	w1 = hdb.calculateHostWeight(entry)
+	w2 = hdb.calculateHostWeight(entry2)
+	if w1.Div(w2).Cmp(defaultRatio) <= 0 {
+		t.Error("a larger price weight should favor the cheaper host more strongly")
+	}
+
+	// With a price weight of zero the price should be ignored.
+	hdb.scoreWeights.Price = 0
+	w1 = hdb.calculateHostWeight(entry)
+	w2 = hdb.calculateHostWeight(entry2)
+	if w1.Cmp(w2) != 0 {
+		t.Error("hosts should have the same weight when the price is ignored")
+	}
+}
@@ -24,9 +24,10 @@ var (
 
 // hdbPersist defines what HostDB data persists across sessions.
 type hdbPersist struct {
-	AllHosts    []modules.HostDBEntry
-	BlockHeight types.BlockHeight
-	LastChange  modules.ConsensusChangeID
+	AllHosts     []modules.HostDBEntry
+	BlockHeight  types.BlockHeight
+	LastChange   modules.ConsensusChangeID
+	ScoreWeights modules.HostScoreWeights
 }
 
 // persistData returns the data in the hostdb that will be saved to disk.
@@ -34,6 +35,7 @@ func (hdb *HostDB) persistData() (data hdbPersist) {
 	data.AllHosts = hdb.hostTree.All()
 	data.BlockHeight = hdb.blockHeight
 	data.LastChange = hdb.lastChange
+	data.ScoreWeights = hdb.scoreWeights
 	return data
 }
 
@@ -54,6 +56,9 @@ func (hdb *HostDB) load() error {
 	// Set the hostdb internal values.
 	hdb.blockHeight = data.BlockHeight
 	hdb.lastChange = data.LastChange
+	if data.ScoreWeights != (modules.HostScoreWeights{}) {
+		hdb.scoreWeights = data.ScoreWeights
+	}
 
 	// Load each of the hosts into the host tree.
 	for _, host := range data.AllHosts {
@@ -58,6 +58,16 @@ func TestSaveLoad(t *testing.T) {
 	hdbt.hdb.hostTree.Insert(host2)
 	hdbt.hdb.hostTree.Insert(host3)
 
+	// Change the score weights, which also saves the hostdb. Invalid weights
+	// should be rejected.
+	weights := modules.HostScoreWeights{Collateral: 1, Price: 2, Storage: 0.5, Uptime: 1}
+	if err := hdbt.hdb.SetScoreWeights(modules.HostScoreWeights{Price: -1}); err != errInvalidScoreWeight {
+		t.Fatal("expected errInvalidScoreWeight, got", err)
+	}
+	if err := hdbt.hdb.SetScoreWeights(weights); err != nil {
+		t.Fatal(err)
+	}
+
 	// Save, close, and reload.
 	hdbt.hdb.mu.Lock()
 	hdbt.hdb.lastChange = modules.ConsensusChangeID{1, 2, 3}
@@ -84,6 +94,11 @@ func TestSaveLoad(t *testing.T) {
 		t.Error("wrong consensus change ID was loaded:", hdbt.hdb.lastChange)
 	}
 
+	// Check that the score weights were loaded.
+	if hdbt.hdb.ScoreWeights() != weights {
+		t.Error("score weights were not restored properly:", hdbt.hdb.ScoreWeights())
+	}
+
 	// Check that AllHosts was loaded.
 	h1, ok0 := hdbt.hdb.hostTree.Select(host1.PublicKey)
 	h2, ok1 := hdbt.hdb.hostTree.Select(host2.PublicKey)
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/encoding"
@@ -190,9 +191,18 @@ func (r *Renter) saveFile(f *file) error {
 
 // saveSync stores the current renter data to disk and then syncs to disk.
 func (r *Renter) saveSync() error {
+	repairs, hostQueue := r.repairPool.managedLimits()
+	storage, upload, download := r.hostContractor.PriceCaps()
 	data := struct {
-		Tracking map[string]trackedFile
-	}{r.tracking}
+		Tracking                  map[string]trackedFile
+		MaxConcurrentRepairs      int
+		MaxHostUploadQueue        int
+		MaxHostDowntime           time.Duration
+		MaxStoragePrice           types.Currency
+		MaxUploadBandwidthPrice   types.Currency
+		MaxDownloadBandwidthPrice types.Currency
+		MaxSmallFileSize          uint64
+	}{r.tracking, repairs, hostQueue, r.hostContractor.MaxHostDowntime(), storage, upload, download, r.maxSmallFileSize}
 
 	return persist.SaveJSON(saveMetadata, data, filepath.Join(r.persistDir, PersistFilename))
 }
@@ -236,8 +246,15 @@ func (r *Renter) load() error {
 
 	// Load contracts, repair set, and entropy.
 	data := struct {
-		Tracking  map[string]trackedFile
-		Repairing map[string]string // COMPATv0.4.8
+		Tracking                  map[string]trackedFile
+		Repairing                 map[string]string // COMPATv0.4.8
+		MaxConcurrentRepairs      int
+		MaxHostUploadQueue        int
+		MaxHostDowntime           time.Duration
+		MaxStoragePrice           types.Currency
+		MaxUploadBandwidthPrice   types.Currency
+		MaxDownloadBandwidthPrice types.Currency
+		MaxSmallFileSize          uint64
 	}{}
 	err = persist.LoadJSON(saveMetadata, &data, filepath.Join(r.persistDir, PersistFilename))
 	if err != nil {
@@ -246,6 +263,14 @@ func (r *Renter) load() error {
 	if data.Tracking != nil {
 		r.tracking = data.Tracking
 	}
+	if data.MaxConcurrentRepairs > 0 && data.MaxHostUploadQueue > 0 {
+		r.repairPool.managedSetLimits(data.MaxConcurrentRepairs, data.MaxHostUploadQueue)
+	}
+	r.hostContractor.SetMaxHostDowntime(data.MaxHostDowntime)
+	r.hostContractor.SetPriceCaps(data.MaxStoragePrice, data.MaxUploadBandwidthPrice, data.MaxDownloadBandwidthPrice)
+	if data.MaxSmallFileSize > 0 {
+		r.maxSmallFileSize = data.MaxSmallFileSize
+	}
 
 	return nil
 }
@@ -337,9 +362,8 @@ func (r *Renter) ShareFilesASCII(nicknames []string) (string, error) {
 	return buf.String(), nil
 }
 
-// loadSharedFiles reads .sia data from reader and registers the contained
-// files in the renter. It returns the nicknames of the loaded files.
-func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
+// readSharedFiles decodes the files in the .sia data read from reader.
+func readSharedFiles(reader io.Reader) ([]*file, error) {
 	// read header
 	var header [15]byte
 	var version string
@@ -372,7 +396,18 @@ func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
+	}
+	return files, nil
+}
 
+// loadSharedFiles reads .sia data from reader and registers the contained
+// files in the renter. It returns the nicknames of the loaded files.
+func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
+	files, err := readSharedFiles(reader)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
 		// Make sure the file's name does not conflict with existing files.
 		dupCount := 0
 		origName := files[i].name
@@ -387,7 +422,7 @@ func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
 	}
 
 	// Add files to renter.
-	names := make([]string, numFiles)
+	names := make([]string, len(files))
 	for i, f := range files {
 		r.files[f.name] = f
 		names[i] = f.name
@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/ratelimit"
@@ -132,6 +133,51 @@ func (cs *ContractSet) ViewAll() []modules.RenterContract {
 	return contracts
 }
 
+// A ContractBackup is a portable copy of a contract, holding everything that
+// is needed to restore the contract into a contract set.
+type ContractBackup struct {
+	Header contractHeader
+	Roots  []crypto.Hash
+}
+
+// ID returns the ID of the backed up contract.
+func (b ContractBackup) ID() types.FileContractID {
+	return b.Header.ID()
+}
+
+// Transaction returns the signed transaction containing the most recent
+// revision of the backed up contract.
+func (b ContractBackup) Transaction() types.Transaction {
+	return b.Header.copyTransaction()
+}
+
+// Backup returns a backup of every contract in the set. Each contract is
+// locked while it is being copied.
+func (cs *ContractSet) Backup() []ContractBackup {
+	var backups []ContractBackup
+	for _, id := range cs.IDs() {
+		sc, ok := cs.Acquire(id)
+		if !ok {
+			continue
+		}
+		backups = append(backups, ContractBackup{
+			Header: sc.header,
+			Roots:  append([]crypto.Hash(nil), sc.merkleRoots...),
+		})
+		cs.Return(sc)
+	}
+	return backups
+}
+
+// Restore inserts a backed up contract into the set. Contracts which are
+// already in the set are left untouched.
+func (cs *ContractSet) Restore(b ContractBackup) (modules.RenterContract, error) {
+	if c, exists := cs.View(b.ID()); exists {
+		return c, nil
+	}
+	return cs.managedInsertContract(b.Header, b.Roots)
+}
+
 // Close closes all contracts in a contract set, this means rendering it unusable for I/O
 func (cs *ContractSet) Close() error {
 	for _, c := range cs.contracts {
@@ -68,7 +68,8 @@ func (he *Editor) Upload(data []byte) (_ modules.RenterContract, _ crypto.Hash,
 	// calculate price
 	// TODO: height is never updated, so we'll wind up overpaying on long-running uploads
 	blockBytes := types.NewCurrency64(modules.SectorSize * uint64(contract.LastRevision().NewWindowEnd-he.height))
-	sectorStoragePrice := he.host.StoragePrice.Mul(blockBytes)
+	storagePrice := he.host.StoragePriceForDuration(modules.StorageTierDuration(contract.StartHeight, contract.LastRevision().NewWindowEnd))
+	sectorStoragePrice := storagePrice.Mul(blockBytes)
 	sectorBandwidthPrice := he.host.UploadBandwidthPrice.Mul64(modules.SectorSize)
 	sectorCollateral := he.host.Collateral.Mul(blockBytes)
 
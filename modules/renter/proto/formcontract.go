@@ -23,6 +23,10 @@ func (cs *ContractSet) FormContract(params ContractParams, txnBuilder transactio
 	// Extract vars from params, for convenience.
 	host, funding, startHeight, endHeight, refundAddress := params.Host, params.Funding, params.StartHeight, params.EndHeight, params.RefundAddress
 
+	// Use the storage price that the host charges for the duration of the
+	// contract.
+	host.StoragePrice = host.StoragePriceForDuration(modules.StorageTierDuration(startHeight, endHeight+host.WindowSize))
+
 	// Create our key.
 	ourSK, ourPK := crypto.GenerateKeyPair()
 	// Create unlock conditions.
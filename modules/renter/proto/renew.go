@@ -22,6 +22,10 @@ func (cs *ContractSet) Renew(oldContract *SafeContract, params ContractParams, t
 	ourSK := contract.SecretKey
 	lastRev := contract.LastRevision()
 
+	// Use the storage price that the host charges for the duration of the
+	// renewed contract.
+	host.StoragePrice = host.StoragePriceForDuration(modules.StorageTierDuration(startHeight, endHeight+host.WindowSize))
+
 	// Calculate additional basePrice and baseCollateral. If the contract height
 	// did not increase, basePrice and baseCollateral are zero.
 	var basePrice, baseCollateral types.Currency
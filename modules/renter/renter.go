@@ -20,11 +20,13 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/renter/contractor"
 	"github.com/NebulousLabs/Sia/modules/renter/hostdb"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
 	"github.com/NebulousLabs/Sia/persist"
 	siasync "github.com/NebulousLabs/Sia/sync"
 	"github.com/NebulousLabs/Sia/types"
@@ -84,6 +86,17 @@ type hostDB interface {
 	// EstimateHostScore returns the estimated score breakdown of a host with the
 	// provided settings.
 	EstimateHostScore(modules.HostDBEntry) modules.HostScoreBreakdown
+
+	// ScoreWeights returns the weights applied to the properties of a host
+	// when calculating its score.
+	ScoreWeights() modules.HostScoreWeights
+
+	// SetScoreWeights changes the weights applied to the properties of a host
+	// when calculating its score.
+	SetScoreWeights(modules.HostScoreWeights) error
+
+	// SetAllowance sets the allowance that hosts are scored for.
+	SetAllowance(modules.Allowance)
 }
 
 // A hostContractor negotiates, revises, renews, and provides access to file
@@ -101,6 +114,19 @@ type hostContractor interface {
 	// Close closes the hostContractor.
 	Close() error
 
+	// Blacklist returns the public keys of the blacklisted hosts.
+	Blacklist() []types.SiaPublicKey
+
+	// BlacklistHost prevents the contractor from forming or renewing
+	// contracts with a host.
+	BlacklistHost(types.SiaPublicKey) error
+
+	// UnblacklistHost removes a host from the blacklist.
+	UnblacklistHost(types.SiaPublicKey) error
+
+	// FormContracts forms a contract with each of the given hosts.
+	FormContracts([]types.SiaPublicKey) ([]modules.RenterContract, error)
+
 	// Contracts returns the contracts formed by the contractor.
 	Contracts() []modules.RenterContract
 
@@ -111,6 +137,10 @@ type hostContractor interface {
 	// with a bool indicating if it exists.
 	ContractUtility(types.FileContractID) (modules.ContractUtility, bool)
 
+	// ExpiringContracts returns the contracts that end within the given
+	// number of blocks.
+	ExpiringContracts(types.BlockHeight) []modules.RenterContract
+
 	// CurrentPeriod returns the height at which the current allowance period
 	// began.
 	CurrentPeriod() types.BlockHeight
@@ -130,12 +160,45 @@ type hostContractor interface {
 	// allowing the retrieval of sectors.
 	Downloader(types.FileContractID, <-chan struct{}) (contractor.Downloader, error)
 
+	// BackupContracts returns a backup of every active contract.
+	BackupContracts() []proto.ContractBackup
+
+	// RestoreContracts restores backed up contracts that are still valid in
+	// the current consensus state, returning the restored contracts.
+	RestoreContracts([]proto.ContractBackup) ([]modules.RenterContract, error)
+
 	// ResolveID returns the most recent renewal of the specified ID.
 	ResolveID(types.FileContractID) types.FileContractID
 
 	// SetRateLimits sets the bandwidth limits for connections created by the
 	// contractor and its submodules.
 	SetRateLimits(int64, int64, uint64)
+
+	// ContractHealth returns the results of the health scans of the hosts of
+	// the contractor's contracts.
+	ContractHealth() []modules.ContractHealth
+
+	// MaxHostDowntime returns the amount of time that a host can be
+	// unreachable before its contracts are marked as degraded.
+	MaxHostDowntime() time.Duration
+
+	// SetMaxHostDowntime sets the amount of time that a host can be
+	// unreachable before its contracts are marked as degraded. Zero selects
+	// the default.
+	SetMaxHostDowntime(time.Duration)
+
+	// PriceCaps returns the highest storage, upload bandwidth and download
+	// bandwidth prices that the contractor pays a host.
+	PriceCaps() (storage, upload, download types.Currency)
+
+	// SetPriceCaps sets the highest storage, upload bandwidth and download
+	// bandwidth prices that the contractor pays a host. Zero selects the
+	// default.
+	SetPriceCaps(storage, upload, download types.Currency)
+
+	// PriceCapReport reports how many candidate hosts were filtered out by
+	// the price caps during the most recent contract formation.
+	PriceCapReport() modules.PriceCapReport
 }
 
 // A trackedFile contains metadata about files being tracked by the Renter.
@@ -145,6 +208,14 @@ type hostContractor interface {
 type trackedFile struct {
 	// location of original file on disk
 	RepairPath string
+
+	// Paused indicates that the user paused the upload of the file. Paused
+	// files are skipped by the repair loop until they are resumed.
+	Paused bool
+
+	// MigrateFrom contains the hosts that the pieces of the file are being
+	// migrated away from. It is cleared once the migration is complete.
+	MigrateFrom []types.SiaPublicKey
 }
 
 // A Renter is responsible for tracking all of the files that a user has
@@ -183,11 +254,16 @@ type Renter struct {
 
 	// List of workers that can be used for uploading and/or downloading.
 	memoryManager *memoryManager
+	repairPool    *repairPool
 	workerPool    map[types.FileContractID]*worker
 
 	// Cache the last price estimation result.
 	lastEstimation modules.RenterPriceEstimation
 
+	// maxSmallFileSize is the size limit of the files that can be uploaded
+	// and downloaded with UploadBytes and DownloadBytes.
+	maxSmallFileSize uint64
+
 	// Utilities.
 	chunkCache     map[string][]byte
 	cmu            *sync.Mutex
@@ -234,7 +310,9 @@ func (r *Renter) PriceEstimation() modules.RenterPriceEstimation {
 		return modules.RenterPriceEstimation{}
 	}
 
-	// Add up the costs for each host.
+	// Add up the costs for each host. The storage price is the one that the
+	// host charges for a contract lasting the allowance period.
+	period := r.hostContractor.Allowance().Period
 	var totalContractCost types.Currency
 	var totalDownloadCost types.Currency
 	var totalStorageCost types.Currency
@@ -242,7 +320,7 @@ func (r *Renter) PriceEstimation() modules.RenterPriceEstimation {
 	for _, host := range hosts {
 		totalContractCost = totalContractCost.Add(host.ContractPrice)
 		totalDownloadCost = totalDownloadCost.Add(host.DownloadBandwidthPrice)
-		totalStorageCost = totalStorageCost.Add(host.StoragePrice)
+		totalStorageCost = totalStorageCost.Add(host.StoragePriceForDuration(period + host.WindowSize))
 		totalUploadCost = totalUploadCost.Add(host.UploadBandwidthPrice)
 	}
 
@@ -285,11 +363,18 @@ func (r *Renter) PriceEstimation() modules.RenterPriceEstimation {
 
 // SetSettings will update the settings for the renter.
 func (r *Renter) SetSettings(s modules.RenterSettings) error {
+	// Set the host score weights first, so that contracts formed after
+	// setting the allowance already use them.
+	err := r.hostDB.SetScoreWeights(s.ScoreWeights)
+	if err != nil {
+		return err
+	}
 	// Set allowance.
-	err := r.hostContractor.SetAllowance(s.Allowance)
+	err = r.hostContractor.SetAllowance(s.Allowance)
 	if err != nil {
 		return err
 	}
+	r.hostDB.SetAllowance(s.Allowance)
 	// Set ratelimit
 	if s.MaxDownloadSpeed < 0 || s.MaxUploadSpeed < 0 {
 		return errors.New("download/upload rate limit can't be below 0")
@@ -302,6 +387,29 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 		// the user wants to limit the connection.
 		r.hostContractor.SetRateLimits(s.MaxDownloadSpeed, s.MaxUploadSpeed, 4*4096)
 	}
+	// Set the repair limits. Zero values select the defaults.
+	if s.MaxConcurrentRepairs < 0 || s.MaxHostUploadQueue < 0 {
+		return errors.New("repair limits can't be below 0")
+	}
+	if s.MaxConcurrentRepairs == 0 {
+		s.MaxConcurrentRepairs = defaultMaxConcurrentRepairs
+	}
+	if s.MaxHostUploadQueue == 0 {
+		s.MaxHostUploadQueue = defaultMaxHostUploadQueue
+	}
+	if s.MaxSmallFileSize == 0 {
+		s.MaxSmallFileSize = defaultMaxSmallFileSize
+	}
+	r.hostContractor.SetMaxHostDowntime(time.Duration(s.MaxHostDowntime) * time.Second)
+	r.hostContractor.SetPriceCaps(s.MaxStoragePrice, s.MaxUploadBandwidthPrice, s.MaxDownloadBandwidthPrice)
+	id := r.mu.Lock()
+	r.repairPool.managedSetLimits(s.MaxConcurrentRepairs, s.MaxHostUploadQueue)
+	r.maxSmallFileSize = s.MaxSmallFileSize
+	err = r.saveSync()
+	r.mu.Unlock(id)
+	if err != nil {
+		return err
+	}
 
 	r.managedUpdateWorkerPool()
 	return nil
@@ -326,9 +434,43 @@ func (r *Renter) EstimateHostScore(e modules.HostDBEntry) modules.HostScoreBreak
 	return r.hostDB.EstimateHostScore(e)
 }
 
+// BlacklistHost prevents the renter from forming or renewing contracts with a
+// host.
+func (r *Renter) BlacklistHost(key types.SiaPublicKey) error {
+	return r.hostContractor.BlacklistHost(key)
+}
+
+// HostBlacklist returns the public keys of the blacklisted hosts.
+func (r *Renter) HostBlacklist() []types.SiaPublicKey { return r.hostContractor.Blacklist() }
+
+// FormContracts forms a contract with each of the given hosts, bypassing the
+// host selection of the contractor.
+func (r *Renter) FormContracts(keys []types.SiaPublicKey) ([]modules.RenterContract, error) {
+	return r.hostContractor.FormContracts(keys)
+}
+
+// UnblacklistHost removes a host from the blacklist.
+func (r *Renter) UnblacklistHost(key types.SiaPublicKey) error {
+	return r.hostContractor.UnblacklistHost(key)
+}
+
 // Contracts returns an array of host contractor's contracts
 func (r *Renter) Contracts() []modules.RenterContract { return r.hostContractor.Contracts() }
 
+// ExpiringContracts returns the contracts that end within threshold blocks of
+// the current block height.
+func (r *Renter) ExpiringContracts(threshold types.BlockHeight) []modules.RenterContract {
+	return r.hostContractor.ExpiringContracts(threshold)
+}
+
+// ContractHealth returns the results of the health scans of the hosts of the
+// renter's contracts.
+func (r *Renter) ContractHealth() []modules.ContractHealth { return r.hostContractor.ContractHealth() }
+
+// PriceCapReport reports how many candidate hosts were filtered out by the
+// price caps during the most recent contract formation.
+func (r *Renter) PriceCapReport() modules.PriceCapReport { return r.hostContractor.PriceCapReport() }
+
 // CurrentPeriod returns the host contractor's current period
 func (r *Renter) CurrentPeriod() types.BlockHeight { return r.hostContractor.CurrentPeriod() }
 
@@ -343,8 +485,21 @@ func (r *Renter) PeriodSpending() modules.ContractorSpending { return r.hostCont
 
 // Settings returns the host contractor's allowance
 func (r *Renter) Settings() modules.RenterSettings {
+	repairs, hostQueue := r.repairPool.managedLimits()
+	storage, upload, download := r.hostContractor.PriceCaps()
+	id := r.mu.RLock()
+	maxSmallFileSize := r.maxSmallFileSize
+	r.mu.RUnlock(id)
 	return modules.RenterSettings{
-		Allowance: r.hostContractor.Allowance(),
+		Allowance:                 r.hostContractor.Allowance(),
+		ScoreWeights:              r.hostDB.ScoreWeights(),
+		MaxConcurrentRepairs:      repairs,
+		MaxHostUploadQueue:        hostQueue,
+		MaxHostDowntime:           uint64(r.hostContractor.MaxHostDowntime() / time.Second),
+		MaxStoragePrice:           storage,
+		MaxUploadBandwidthPrice:   upload,
+		MaxDownloadBandwidthPrice: download,
+		MaxSmallFileSize:          maxSmallFileSize,
 	}
 }
 
@@ -426,6 +581,8 @@ func NewCustomRenter(g modules.Gateway, cs modules.ConsensusSet, tpool modules.T
 
 		workerPool: make(map[types.FileContractID]*worker),
 
+		maxSmallFileSize: defaultMaxSmallFileSize,
+
 		chunkCache:     make(map[string][]byte),
 		cmu:            new(sync.Mutex),
 		cs:             cs,
@@ -438,12 +595,18 @@ func NewCustomRenter(g modules.Gateway, cs modules.ConsensusSet, tpool modules.T
 		tpool:          tpool,
 	}
 	r.memoryManager = newMemoryManager(defaultMemory, r.tg.StopChan())
+	r.repairPool = newRepairPool(defaultMaxConcurrentRepairs, defaultMaxHostUploadQueue, r.tg.StopChan())
 
 	// Load all saved data.
 	if err := r.initPersist(); err != nil {
 		return nil, err
 	}
 
+	// Score the hosts for the allowance that the contractor has loaded.
+	if hdb != nil {
+		hdb.SetAllowance(hc.Allowance())
+	}
+
 	// Subscribe to the consensus set.
 	err := cs.ConsensusSetSubscribe(r, modules.ConsensusChangeRecent, r.tg.StopChan())
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -119,6 +120,11 @@ func (stubHostDB) Host(types.SiaPublicKey) (modules.HostDBEntry, bool) {
 func (stubHostDB) ScoreBreakdown(modules.HostDBEntry) modules.HostScoreBreakdown {
 	return modules.HostScoreBreakdown{}
 }
+func (stubHostDB) ScoreWeights() modules.HostScoreWeights {
+	return modules.DefaultHostScoreWeights
+}
+func (stubHostDB) SetScoreWeights(modules.HostScoreWeights) error { return nil }
+func (stubHostDB) SetAllowance(modules.Allowance)                 {}
 
 // stubContractor is the minimal implementation of the hostContractor
 // interface.
@@ -223,3 +229,68 @@ func TestRenterSiapathValidate(t *testing.T) {
 		}
 	}
 }
+
+// TestRenterRepairLimits checks that the repair limits can be set, that they
+// bound the number of concurrent repairs, and that they are persisted.
+func TestRenterRepairLimits(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	// The defaults are reported until the limits are changed.
+	s := rt.renter.Settings()
+	if s.MaxConcurrentRepairs != defaultMaxConcurrentRepairs || s.MaxHostUploadQueue != defaultMaxHostUploadQueue {
+		t.Fatal("wrong default repair limits:", s.MaxConcurrentRepairs, s.MaxHostUploadQueue)
+	}
+	s.MaxConcurrentRepairs = -1
+	if err := rt.renter.SetSettings(s); err == nil {
+		t.Fatal("negative repair limit should be rejected")
+	}
+	s.MaxConcurrentRepairs = 2
+	s.MaxHostUploadQueue = 3
+	if err := rt.renter.SetSettings(s); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only two repairs should be able to run at the same time.
+	if !rt.renter.repairPool.managedAcquire() || !rt.renter.repairPool.managedAcquire() {
+		t.Fatal("unable to acquire repair slots")
+	}
+	acquired := make(chan bool)
+	go func() {
+		acquired <- rt.renter.repairPool.managedAcquire()
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("third repair was started while two were running")
+	case <-time.After(100 * time.Millisecond):
+	}
+	rt.renter.repairPool.managedRelease()
+	select {
+	case ok := <-acquired:
+		if !ok {
+			t.Fatal("repair slot was not acquired")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("repair slot was not handed out after a repair finished")
+	}
+	rt.renter.repairPool.managedRelease()
+	rt.renter.repairPool.managedRelease()
+
+	// The limits should be persisted.
+	rt.renter.repairPool.managedSetLimits(defaultMaxConcurrentRepairs, defaultMaxHostUploadQueue)
+	id := rt.renter.mu.Lock()
+	err = rt.renter.load()
+	rt.renter.mu.Unlock(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := rt.renter.Settings(); s.MaxConcurrentRepairs != 2 || s.MaxHostUploadQueue != 3 {
+		t.Fatal("repair limits were not persisted:", s.MaxConcurrentRepairs, s.MaxHostUploadQueue)
+	}
+}
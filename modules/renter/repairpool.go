@@ -0,0 +1,88 @@
+package renter
+
+import (
+	"sync"
+)
+
+// repairPool bounds the number of chunks that the renter is repairing at the
+// same time, as well as the number of chunks that can be queued for upload at
+// a single host. Chunks are taken from the upload heap least healthy first, so
+// the chunks that are most at risk are the first to get a slot in the pool.
+//
+// A chunk holds its slot from the moment it is taken from the upload heap
+// until every worker has finished with it, which covers fetching the logical
+// data, erasure coding and uploading the pieces. Each worker uploads a single
+// piece at a time, so limiting the queue of a worker limits how much of the
+// repair work can pile up at a single slow host.
+type repairPool struct {
+	active    int
+	limit     int
+	hostLimit int
+	freed     chan struct{}
+	stop      <-chan struct{}
+	mu        sync.Mutex
+}
+
+// newRepairPool creates a repair pool with the provided limits.
+func newRepairPool(limit, hostLimit int, stop <-chan struct{}) *repairPool {
+	return &repairPool{
+		limit:     limit,
+		hostLimit: hostLimit,
+		freed:     make(chan struct{}, 1),
+		stop:      stop,
+	}
+}
+
+// managedAcquire blocks until a slot in the pool is available and takes it.
+// False is returned if the renter shut down before a slot became available.
+func (rp *repairPool) managedAcquire() bool {
+	for {
+		rp.mu.Lock()
+		if rp.active < rp.limit {
+			rp.active++
+			rp.mu.Unlock()
+			return true
+		}
+		rp.mu.Unlock()
+
+		select {
+		case <-rp.freed:
+		case <-rp.stop:
+			return false
+		}
+	}
+}
+
+// managedRelease returns a slot to the pool.
+func (rp *repairPool) managedRelease() {
+	rp.mu.Lock()
+	rp.active--
+	rp.mu.Unlock()
+	rp.managedNotify()
+}
+
+// managedNotify wakes up a thread waiting for a slot in the pool.
+func (rp *repairPool) managedNotify() {
+	select {
+	case rp.freed <- struct{}{}:
+	default:
+	}
+}
+
+// managedLimits returns the maximum number of concurrent repairs and the
+// maximum number of chunks queued at a single host.
+func (rp *repairPool) managedLimits() (limit, hostLimit int) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return rp.limit, rp.hostLimit
+}
+
+// managedSetLimits updates the limits of the pool. Repairs that are already
+// running are not interrupted if the limit is lowered.
+func (rp *repairPool) managedSetLimits(limit, hostLimit int) {
+	rp.mu.Lock()
+	rp.limit = limit
+	rp.hostLimit = hostLimit
+	rp.mu.Unlock()
+	rp.managedNotify()
+}
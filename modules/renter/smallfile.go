@@ -0,0 +1,153 @@
+package renter
+
+// smallfile.go provides blocking uploads and downloads of small files that are
+// held in memory. They use the same erasure coding, upload heap and download
+// code as regular files, but return only once the transfer has completed.
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/fastrand"
+)
+
+var (
+	// errEmptySmallFile is returned when an empty file is uploaded with
+	// UploadBytes.
+	errEmptySmallFile = errors.New("cannot upload an empty file")
+
+	// errSmallFileTooLarge is returned when a file that is larger than the
+	// maximum small file size is uploaded or downloaded with UploadBytes or
+	// DownloadBytes.
+	errSmallFileTooLarge = errors.New("file is larger than the maximum small file size")
+)
+
+// managedMaxSmallFileSize returns the size limit of the files that can be
+// uploaded and downloaded with UploadBytes and DownloadBytes.
+func (r *Renter) managedMaxSmallFileSize() uint64 {
+	id := r.mu.RLock()
+	defer r.mu.RUnlock(id)
+	return r.maxSmallFileSize
+}
+
+// managedUploadComplete returns true once every piece of the file at siaPath
+// has been uploaded.
+func (r *Renter) managedUploadComplete(siaPath string) (bool, error) {
+	id := r.mu.RLock()
+	f, exists := r.files[siaPath]
+	r.mu.RUnlock(id)
+	if !exists {
+		return false, ErrUnknownPath
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.uploadProgress() >= 100, nil
+}
+
+// UploadBytes uploads a small file from memory and blocks until every piece of
+// the file has been uploaded. The data is written to a local file in the
+// renter's persist directory, which serves as the source of the repairs of
+// the file in the same way as the source of a regular upload. If the pieces
+// are not uploaded within smallFileUploadTimeout, an error is returned and
+// the upload continues in the background.
+func (r *Renter) UploadBytes(siaPath string, data []byte) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	if len(data) == 0 {
+		return errEmptySmallFile
+	}
+	if uint64(len(data)) > r.managedMaxSmallFileSize() {
+		return errSmallFileTooLarge
+	}
+	if err := validateSiapath(siaPath); err != nil {
+		return err
+	}
+
+	// Write the data to the local copy of the file.
+	dir := filepath.Join(r.persistDir, smallFilesDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	source := filepath.Join(dir, hex.EncodeToString(fastrand.Bytes(16)))
+	f, err := os.OpenFile(source, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = r.Upload(modules.FileUploadParams{
+			Source:  source,
+			SiaPath: siaPath,
+		})
+	}
+	if err != nil {
+		os.Remove(source)
+		return err
+	}
+
+	// Wait for the pieces to be uploaded.
+	timeout := time.After(smallFileUploadTimeout)
+	for {
+		complete, err := r.managedUploadComplete(siaPath)
+		if err != nil {
+			return err
+		} else if complete {
+			return nil
+		}
+		select {
+		case <-r.tg.StopChan():
+			return errors.New("renter shut down before the upload completed")
+		case <-timeout:
+			return fmt.Errorf("upload did not complete within %v, it continues in the background", smallFileUploadTimeout)
+		case <-time.After(smallFileUploadCheckInterval):
+		}
+	}
+}
+
+// DownloadBytes downloads a small file into memory, blocking until the
+// download has completed.
+func (r *Renter) DownloadBytes(siaPath string) ([]byte, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+
+	id := r.mu.RLock()
+	f, exists := r.files[siaPath]
+	maxSize := r.maxSmallFileSize
+	r.mu.RUnlock(id)
+	if !exists {
+		return nil, ErrUnknownPath
+	}
+	f.mu.RLock()
+	size := f.size
+	f.mu.RUnlock()
+	if size > maxSize {
+		return nil, errSmallFileTooLarge
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	err := r.Download(modules.RenterDownloadParameters{
+		Httpwriter: buf,
+		SiaPath:    siaPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
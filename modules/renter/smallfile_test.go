@@ -0,0 +1,59 @@
+package renter
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestUploadBytesRejected checks that UploadBytes rejects invalid uploads
+// without leaving a local copy of the data behind, and that DownloadBytes
+// rejects files that are too large.
+func TestUploadBytesRejected(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	maxSize := rt.renter.managedMaxSmallFileSize()
+	if err := rt.renter.UploadBytes("test", nil); err != errEmptySmallFile {
+		t.Fatal("expected errEmptySmallFile, got", err)
+	}
+	if err := rt.renter.UploadBytes("test", fastrand.Bytes(int(maxSize)+1)); err != errSmallFileTooLarge {
+		t.Fatal("expected errSmallFileTooLarge, got", err)
+	}
+	if err := rt.renter.UploadBytes("", fastrand.Bytes(10)); err != ErrEmptyFilename {
+		t.Fatal("expected ErrEmptyFilename, got", err)
+	}
+
+	// Uploading to a siapath that is in use fails after the local copy has
+	// been written, which should then be removed.
+	f := newTestingFile()
+	f.size = maxSize + 1
+	id := rt.renter.mu.Lock()
+	rt.renter.files[f.name] = f
+	rt.renter.mu.Unlock(id)
+	if err := rt.renter.UploadBytes(f.name, fastrand.Bytes(10)); err != ErrPathOverload {
+		t.Fatal("expected ErrPathOverload, got", err)
+	}
+	fis, err := ioutil.ReadDir(filepath.Join(rt.renter.persistDir, smallFilesDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 0 {
+		t.Fatal("local copies were not removed:", len(fis))
+	}
+
+	if _, err := rt.renter.DownloadBytes(f.name); err != errSmallFileTooLarge {
+		t.Fatal("expected errSmallFileTooLarge, got", err)
+	}
+	if _, err := rt.renter.DownloadBytes("unknown"); err != ErrUnknownPath {
+		t.Fatal("expected ErrUnknownPath, got", err)
+	}
+}
@@ -39,6 +39,15 @@ type unfinishedUploadChunk struct {
 	offset         int64  // Offset of the chunk within the file.
 	piecesNeeded   int    // number of pieces to achieve a 100% complete upload
 
+	// repairSlot indicates that the chunk holds a slot in the repair pool,
+	// which is released once every worker has finished with the chunk.
+	repairSlot bool
+
+	// migrating indicates that some pieces of the chunk are stored on hosts
+	// that the file is being migrated away from. Migrating chunks are
+	// downloaded even if only a few pieces are missing.
+	migrating bool
+
 	// The logical data is the data that is presented to the user when the user
 	// requests the chunk. The physical data is all of the pieces that get
 	// stored across the network.
@@ -268,7 +277,7 @@ func (r *Renter) managedFetchLogicalChunkData(chunk *unfinishedUploadChunk) erro
 	// Only download this file if more than 25% of the redundancy is missing.
 	numParityPieces := float64(chunk.piecesNeeded - chunk.minimumPieces)
 	minMissingPiecesToDownload := int(numParityPieces * RemoteRepairDownloadThreshold)
-	download := chunk.piecesCompleted+minMissingPiecesToDownload < chunk.piecesNeeded
+	download := chunk.migrating || chunk.piecesCompleted+minMissingPiecesToDownload < chunk.piecesNeeded
 
 	// Download the chunk if it's not on disk.
 	if chunk.localPath == "" && download {
@@ -345,6 +354,7 @@ func (r *Renter) managedCleanUpUploadChunk(uc *unfinishedUploadChunk) {
 	if chunkComplete && !released {
 		uc.released = true
 	}
+	repairSlot := uc.repairSlot
 	uc.memoryReleased += uint64(memoryReleased)
 	totalMemoryReleased := uc.memoryReleased
 	uc.mu.Unlock()
@@ -366,6 +376,9 @@ func (r *Renter) managedCleanUpUploadChunk(uc *unfinishedUploadChunk) {
 		r.uploadHeap.mu.Lock()
 		delete(r.uploadHeap.activeChunks, uc.id)
 		r.uploadHeap.mu.Unlock()
+		if repairSlot {
+			r.repairPool.managedRelease()
+		}
 	}
 	// Sanity check - all memory should be released if the chunk is complete.
 	if chunkComplete && totalMemoryReleased != uc.memoryNeeded {
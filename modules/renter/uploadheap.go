@@ -23,6 +23,7 @@ import (
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 // uploadHeap contains a priority-sorted heap of all the chunks being uploaded
@@ -105,9 +106,10 @@ func (r *Renter) buildUnfinishedChunks(f *file, hosts map[string]struct{}) []*un
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	// If the file is not being tracked, don't repair it.
+	// If the file is not being tracked, or if its upload was paused, don't
+	// repair it.
 	trackedFile, exists := r.tracking[f.name]
-	if !exists {
+	if !exists || trackedFile.Paused {
 		return nil
 	}
 
@@ -116,6 +118,12 @@ func (r *Renter) buildUnfinishedChunks(f *file, hosts map[string]struct{}) []*un
 		return nil
 	}
 
+	// Collect the hosts that the file is being migrated away from.
+	migrateFrom := make(map[string]struct{})
+	for _, host := range trackedFile.MigrateFrom {
+		migrateFrom[host.String()] = struct{}{}
+	}
+
 	// Assemble the set of chunks.
 	//
 	// TODO / NOTE: Future files may have a different method for determining the
@@ -155,9 +163,12 @@ func (r *Renter) buildUnfinishedChunks(f *file, hosts map[string]struct{}) []*un
 			pieceUsage:  make([]bool, f.erasureCode.NumPieces()),
 			unusedHosts: make(map[string]struct{}),
 		}
-		// Every chunk can have a different set of unused hosts.
+		// Every chunk can have a different set of unused hosts. Hosts that the
+		// file is being migrated away from don't receive any new pieces.
 		for host := range hosts {
-			newUnfinishedChunks[i].unusedHosts[host] = struct{}{}
+			if _, exists := migrateFrom[host]; !exists {
+				newUnfinishedChunks[i].unusedHosts[host] = struct{}{}
+			}
 		}
 	}
 
@@ -165,6 +176,7 @@ func (r *Renter) buildUnfinishedChunks(f *file, hosts map[string]struct{}) []*un
 	// already in use for the chunk. As you delete hosts from the 'unusedHosts'
 	// map, also increment the 'piecesCompleted' value.
 	saveFile := false
+	var migratingContracts []types.FileContractID
 	for fcid, fileContract := range f.contracts {
 		recentContract, exists := r.hostContractor.ContractByID(fcid)
 		contractUtility, exists2 := r.hostContractor.ContractUtility(fcid)
@@ -179,12 +191,21 @@ func (r *Renter) buildUnfinishedChunks(f *file, hosts map[string]struct{}) []*un
 			saveFile = true
 			continue
 		}
+		hpk := recentContract.HostPublicKey
+		if _, exists := migrateFrom[hpk.String()]; exists {
+			// The pieces in this contract are being migrated to other hosts,
+			// so they don't count for redundancy.
+			for _, piece := range fileContract.Pieces {
+				newUnfinishedChunks[piece.Chunk].migrating = true
+			}
+			migratingContracts = append(migratingContracts, fcid)
+			continue
+		}
 		if !contractUtility.GoodForRenew {
 			// We are no longer renewing with this contract, so it does not
 			// count for redundancy.
 			continue
 		}
-		hpk := recentContract.HostPublicKey
 
 		// Mark the chunk set based on the pieces in this contract.
 		for _, piece := range fileContract.Pieces {
@@ -203,6 +224,40 @@ func (r *Renter) buildUnfinishedChunks(f *file, hosts map[string]struct{}) []*un
 			}
 		}
 	}
+	// Drop the pieces on the hosts that the file is being migrated away from
+	// for every chunk that has been fully uploaded to other hosts. Once no
+	// chunk is left to migrate, the migration is complete.
+	migrating := false
+	for _, fcid := range migratingContracts {
+		fileContract := f.contracts[fcid]
+		remaining := fileContract.Pieces[:0]
+		for _, piece := range fileContract.Pieces {
+			chunk := newUnfinishedChunks[piece.Chunk]
+			if chunk.piecesCompleted < chunk.piecesNeeded {
+				remaining = append(remaining, piece)
+				migrating = true
+			}
+		}
+		if len(remaining) == len(fileContract.Pieces) {
+			continue
+		}
+		if len(remaining) == 0 {
+			delete(f.contracts, fcid)
+		} else {
+			fileContract.Pieces = remaining
+			f.contracts[fcid] = fileContract
+		}
+		saveFile = true
+	}
+	if len(migrateFrom) > 0 && !migrating {
+		trackedFile.MigrateFrom = nil
+		r.tracking[f.name] = trackedFile
+		if err := r.saveSync(); err != nil {
+			r.log.Println("error while saving the renter after migrating a file:", err)
+		}
+		r.log.Printf("Migration of %v complete\n", f.name)
+	}
+
 	// If 'saveFile' is marked, it means we deleted some dead contracts and
 	// cleaned up the file a bit. Save the file to clean up some space on disk
 	// and prevent the same work from being repeated after the next restart.
@@ -250,10 +305,19 @@ func (r *Renter) managedBuildChunkHeap(hosts map[string]struct{}) {
 // from the network), erasure coding the logical data into the physical data,
 // and then finally passing the work onto the workers.
 func (r *Renter) managedPrepareNextChunk(uuc *unfinishedUploadChunk, hosts map[string]struct{}) {
+	// Wait for a slot in the repair pool, which bounds the number of chunks
+	// that are repaired concurrently. The slot is released once every worker
+	// has finished with the chunk.
+	if !r.repairPool.managedAcquire() {
+		return
+	}
+	uuc.repairSlot = true
+
 	// Grab the next chunk, loop until we have enough memory, update the amount
 	// of memory available, and then spin up a thread to asynchronously handle
 	// the rest of the chunk tasks.
 	if !r.memoryManager.Request(uuc.memoryNeeded, memoryPriorityLow) {
+		r.repairPool.managedRelease()
 		return
 	}
 	// Fetch the chunk in a separate goroutine, as it can take a long time and
@@ -337,6 +401,16 @@ func (r *Renter) threadedUploadLoop() {
 				break
 			}
 
+			// Skip chunks of files that were paused after the heap was built.
+			// The chunk is removed from the set of active chunks so that it
+			// can be added again once the upload is resumed.
+			if r.managedUploadPaused(nextChunk.renterFile) {
+				r.uploadHeap.mu.Lock()
+				delete(r.uploadHeap.activeChunks, nextChunk.id)
+				r.uploadHeap.mu.Unlock()
+				continue
+			}
+
 			// Make sure we have enough workers for this chunk to reach minimum
 			// redundancy. Otherwise we ignore this chunk for now and try again
 			// the next time we rebuild the heap and refresh the workers.
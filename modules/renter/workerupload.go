@@ -76,9 +76,12 @@ func (w *worker) managedQueueUploadChunk(uc *unfinishedUploadChunk) {
 	// worker lock.
 	utility, exists := w.renter.hostContractor.ContractUtility(w.contract.ID)
 	goodForUpload := exists && utility.GoodForUpload
+	_, hostLimit := w.renter.repairPool.managedLimits()
 	w.mu.Lock()
-	if !goodForUpload || w.uploadTerminated || w.onUploadCooldown() {
-		// The worker should not be uploading, remove the chunk.
+	if !goodForUpload || w.uploadTerminated || w.onUploadCooldown() || len(w.unprocessedChunks) >= hostLimit {
+		// The worker should not be uploading, or already has enough chunks
+		// queued to keep the host busy. Remove the chunk, it will be offered
+		// to the host again the next time the upload heap is rebuilt.
 		w.mu.Unlock()
 		w.managedDropChunk(uc)
 		return
@@ -129,12 +132,26 @@ func (w *worker) managedUpload(uc *unfinishedUploadChunk, pieceIndex uint64) {
 			WindowStart: endHeight,
 		}
 	}
-	contract.Pieces = append(contract.Pieces, pieceData{
+	// Replace any earlier upload of the same piece to this contract, so that
+	// repeating a repair doesn't record the piece twice.
+	pd := pieceData{
 		Chunk:      uc.index,
 		Piece:      pieceIndex,
 		MerkleRoot: root,
-	})
+	}
+	replaced := false
+	for i, piece := range contract.Pieces {
+		if piece.Chunk == pd.Chunk && piece.Piece == pd.Piece {
+			contract.Pieces[i] = pd
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		contract.Pieces = append(contract.Pieces, pd)
+	}
 	uc.renterFile.contracts[w.contract.ID] = contract
+	uc.renterFile.recordPieceUpload(time.Now())
 	w.renter.saveFile(uc.renterFile)
 	uc.renterFile.mu.Unlock()
 	w.renter.mu.Unlock(id)
@@ -170,14 +187,16 @@ func (w *worker) managedProcessUploadChunk(uc *unfinishedUploadChunk) (nextChunk
 	w.mu.Lock()
 	onCooldown := w.onUploadCooldown()
 	w.mu.Unlock()
+	paused := w.renter.managedUploadPaused(uc.renterFile)
 
 	// Determine what sort of help this chunk needs.
 	uc.mu.Lock()
 	_, candidateHost := uc.unusedHosts[w.hostPubKey.String()]
 	chunkComplete := uc.piecesNeeded <= uc.piecesCompleted
 	needsHelp := uc.piecesNeeded > uc.piecesCompleted+uc.piecesRegistered
-	// If the chunk does not need help from this worker, release the chunk.
-	if chunkComplete || !candidateHost || !goodForUpload || onCooldown {
+	// If the chunk does not need help from this worker, or if the upload of
+	// the file was paused, release the chunk.
+	if chunkComplete || !candidateHost || !goodForUpload || onCooldown || paused {
 		// This worker no longer needs to track this chunk.
 		uc.mu.Unlock()
 		w.managedDropChunk(uc)
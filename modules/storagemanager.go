@@ -72,6 +72,12 @@ type (
 		// The storage manager needs to be able to shut down.
 		Close() error
 
+		// DefragStorageFolders starts consolidating free space in the
+		// background, moving the sectors out of nearly unused storage folders
+		// and into the other storage folders. Progress is reported through
+		// the progress fields of the storage folder being emptied.
+		DefragStorageFolders() error
+
 		// DeleteSector deletes a sector, meaning that the manager will be
 		// unable to upload that sector and be unable to provide a storage
 		// proof on that sector. DeleteSector is for removing the data
@@ -66,9 +66,34 @@ type (
 	// A TransactionPoolDiff indicates the adding or removal of a transaction set to
 	// the transaction pool. The transactions in the pool are not persisted, so at
 	// startup modules should assume an empty transaction pool.
+	//
+	// Transaction sets are regrouped whenever the pool changes, so a
+	// transaction can move from a reverted set to an applied set without ever
+	// leaving the pool. AcceptedTransactions, ConfirmedTransactions and
+	// DroppedTransactions report the individual transactions that entered or
+	// left the pool instead: accepted transactions are new to the pool,
+	// confirmed transactions left the pool because they were included in a
+	// block, and dropped transactions left the pool without being confirmed.
 	TransactionPoolDiff struct {
 		AppliedTransactions  []*UnconfirmedTransactionSet
 		RevertedTransactions []TransactionSetID
+
+		AcceptedTransactions  []types.Transaction
+		ConfirmedTransactions []types.TransactionID
+		DroppedTransactions   []types.TransactionID
+	}
+
+	// UnconfirmedAddressSet contains the unconfirmed transactions that create
+	// or spend outputs of an address, along with the pending change to the
+	// balance of the address. The pending change of the balance is the
+	// incoming value minus the outgoing value.
+	UnconfirmedAddressSet struct {
+		Transactions []types.Transaction
+
+		IncomingSiacoins types.Currency
+		OutgoingSiacoins types.Currency
+		IncomingSiafunds types.Currency
+		OutgoingSiafunds types.Currency
 	}
 
 	// UnconfirmedTransactionSet defines a new unconfirmed transaction that has
@@ -93,6 +118,8 @@ type (
 		// ReceiveTransactionPoolUpdate notifies subscribers of a change to the
 		// consensus set and/or unconfirmed set, and includes the consensus change
 		// that would result if all of the transactions made it into a block.
+		// Transactions are only reported after they have been validated and
+		// accepted into the pool.
 		ReceiveUpdatedUnconfirmedTransactions(*TransactionPoolDiff)
 	}
 
@@ -102,6 +129,11 @@ type (
 		// transactions.
 		AcceptTransactionSet([]types.Transaction) error
 
+		// AddressTransactions returns the unconfirmed transactions that create
+		// or spend siacoin or siafund outputs of the address, and the value
+		// that they add to and remove from the balance of the address.
+		AddressTransactions(types.UnlockHash) UnconfirmedAddressSet
+
 		// Broadcast broadcasts a transaction set to all of the transaction pool's
 		// peers.
 		Broadcast(ts []types.Transaction)
@@ -117,6 +149,12 @@ type (
 		// within 10 blocks.
 		FeeEstimation() (minimumRecommended, maximumRecommended types.Currency)
 
+		// MinimumFee returns the smallest fee per byte that a transaction set
+		// needs to pay to be accepted into the transaction pool. Once the pool
+		// is full, new sets need to pay more than the cheapest sets in the
+		// pool, which are evicted to make room.
+		MinimumFee() types.Currency
+
 		// PurgeTransactionPool is a temporary function available to the miner. In
 		// the event that a miner mines an unacceptable block, the transaction pool
 		// will be purged to clear out the transaction pool and get rid of the
@@ -181,11 +181,18 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 		}
 	}
 	if requiredFees.Cmp(setFees) > 0 {
-		// TODO: check if there is an existing set with lower fees that we can
-		// kick out.
 		return errLowMinerFees
 	}
 
+	// Check that there is room for the transaction set, evicting cheaper sets
+	// if the pool is full. The conflicts are replaced by the superset, so they
+	// don't need to make room.
+	tsetSize := len(encoding.Marshal(superset))
+	evict, err := tp.setsToEvict(tsetSize, feeRate(superset, tsetSize), supersetMap)
+	if err != nil {
+		return err
+	}
+
 	// Check that the transaction set is valid.
 	cc, err := txnFn(superset)
 	if err != nil {
@@ -194,15 +201,19 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 
 	// Remove the conflicts from the transaction pool.
 	for conflict := range supersetMap {
-		conflictSet := tp.transactionSets[conflict]
-		tp.transactionListSize -= len(encoding.Marshal(conflictSet))
+		tp.transactionListSize -= tp.pooledSets[conflict].size
+		tp.unindexTransactionSet(conflict)
 		delete(tp.transactionSets, conflict)
 		delete(tp.transactionSetDiffs, conflict)
 	}
+	for _, id := range evict {
+		tp.evictTransactionSet(id)
+	}
 
 	// Add the transaction set to the pool.
 	setID := TransactionSetID(crypto.HashObject(superset))
 	tp.transactionSets[setID] = superset
+	tp.indexTransactionSet(setID, superset, tsetSize)
 	for _, diff := range cc.SiacoinOutputDiffs {
 		tp.knownObjects[ObjectID(diff.ID)] = setID
 	}
@@ -213,7 +224,6 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 		tp.knownObjects[ObjectID(diff.ID)] = setID
 	}
 	tp.transactionSetDiffs[setID] = &cc
-	tsetSize := len(encoding.Marshal(superset))
 	tp.transactionListSize += tsetSize
 
 	// debug logging
@@ -267,8 +277,6 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction, txnFn fu
 		}
 	}
 	if requiredFees.Cmp(setFees) > 0 {
-		// TODO: check if there is an existing set with lower fees that we can
-		// kick out.
 		return errLowMinerFees
 	}
 
@@ -286,19 +294,30 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction, txnFn fu
 	if len(conflicts) > 0 {
 		return tp.handleConflicts(ts, conflicts, txnFn)
 	}
+
+	// Check that there is room for the transaction set, evicting cheaper sets
+	// if the pool is full.
+	tsetSize := len(encoding.Marshal(ts))
+	evict, err := tp.setsToEvict(tsetSize, feeRate(ts, tsetSize), nil)
+	if err != nil {
+		return err
+	}
 	cc, err := txnFn(ts)
 	if err != nil {
 		return modules.NewConsensusConflict("provided transaction set is standalone and invalid: " + err.Error())
 	}
+	for _, id := range evict {
+		tp.evictTransactionSet(id)
+	}
 
 	// Add the transaction set to the pool.
 	setID := TransactionSetID(crypto.HashObject(ts))
 	tp.transactionSets[setID] = ts
+	tp.indexTransactionSet(setID, ts, tsetSize)
 	for _, oid := range oids {
 		tp.knownObjects[oid] = setID
 	}
 	tp.transactionSetDiffs[setID] = &cc
-	tsetSize := len(encoding.Marshal(ts))
 	tp.transactionListSize += tsetSize
 	for _, txn := range ts {
 		if _, exists := tp.transactionHeights[txn.ID()]; !exists {
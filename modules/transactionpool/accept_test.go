@@ -3,6 +3,8 @@ package transactionpool
 import (
 	"testing"
 
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/fastrand"
@@ -229,6 +231,174 @@ func TestCheckMinerFees(t *testing.T) {
 	}
 }
 
+// TestEvictLowFeeTransactionSets checks that a full transaction pool evicts
+// the transaction sets paying the lowest fees per byte to make room for sets
+// paying more, and rejects sets that don't outbid the cheapest sets.
+func TestEvictLowFeeTransactionSets(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Fund a few outputs that anyone can spend and confirm them, so that
+	// every graph built on them is a distinct transaction set.
+	fund := types.SiacoinPrecision.Mul64(1000)
+	var outputs []types.SiacoinOutput
+	for i := 0; i < 5; i++ {
+		outputs = append(outputs, types.SiacoinOutput{
+			UnlockHash: types.UnlockConditions{}.UnlockHash(),
+			Value:      fund,
+		})
+	}
+	txns, err := tpt.wallet.SendSiacoinsMulti(outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tpt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fundTxn := txns[len(txns)-1]
+	graph := func(i int, fee types.Currency) []types.Transaction {
+		edge := types.TransactionGraphEdge{
+			Dest:   1,
+			Fee:    fee,
+			Source: 0,
+			Value:  fund.Sub(fee),
+		}
+		g, err := types.TransactionGraph(fundTxn.SiacoinOutputID(uint64(i)), []types.TransactionGraphEdge{edge})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+
+	// Fill the pool with three sets and limit it to their size. The limit
+	// leaves a little slack, as sets paying higher fees encode to slightly
+	// larger sizes.
+	cheapest := graph(0, types.SiacoinPrecision)
+	for i, set := range [][]types.Transaction{cheapest, graph(1, types.SiacoinPrecision.Mul64(2)), graph(2, types.SiacoinPrecision.Mul64(3))} {
+		if err := tpt.tpool.AcceptTransactionSet(set); err != nil {
+			t.Fatal(i, err)
+		}
+	}
+	tpt.tpool.mu.Lock()
+	tpt.tpool.sizeLimit = tpt.tpool.transactionListSize + 10
+	tpt.tpool.mu.Unlock()
+
+	// The minimum fee should now be above the fee rate of the cheapest set.
+	setSize := len(encoding.Marshal(cheapest))
+	if tpt.tpool.MinimumFee().Cmp(feeRate(cheapest, setSize)) <= 0 {
+		t.Error("minimum fee should outbid the cheapest set in a full pool")
+	}
+
+	// A set paying less than the cheapest set should be rejected.
+	err = tpt.tpool.AcceptTransactionSet(graph(3, types.SiacoinPrecision.Div64(2)))
+	if err != errFullTransactionPool {
+		t.Fatal("expected errFullTransactionPool, got", err)
+	}
+
+	// A set paying more should evict the cheapest set.
+	err = tpt.tpool.AcceptTransactionSet(graph(4, types.SiacoinPrecision.Mul64(4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, exists := tpt.tpool.Transaction(cheapest[0].ID()); exists {
+		t.Error("cheapest transaction set was not evicted")
+	}
+	if len(tpt.tpool.TransactionList()) != 3 {
+		t.Error("expected 3 transactions in the pool, got", len(tpt.tpool.TransactionList()))
+	}
+}
+
+// TestEvictTransactionSetDependents checks that evicting a transaction set
+// also evicts the sets that depend on it.
+func TestEvictTransactionSetDependents(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := blankTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Add a parent set, a set spending its output and an unrelated set.
+	parent := []types.Transaction{{
+		SiacoinOutputs: []types.SiacoinOutput{{Value: types.SiacoinPrecision}},
+	}}
+	child := []types.Transaction{{
+		SiacoinInputs: []types.SiacoinInput{{ParentID: parent[0].SiacoinOutputID(0)}},
+	}}
+	unrelated := []types.Transaction{{
+		ArbitraryData: [][]byte{fastrand.Bytes(16)},
+	}}
+	tp := tpt.tpool
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	var ids []TransactionSetID
+	for _, set := range [][]types.Transaction{parent, child, unrelated} {
+		id := TransactionSetID(crypto.HashObject(set))
+		size := len(encoding.Marshal(set))
+		tp.transactionSets[id] = set
+		tp.indexTransactionSet(id, set, size)
+		tp.transactionListSize += size
+		ids = append(ids, id)
+	}
+
+	tp.evictTransactionSet(ids[0])
+	if _, exists := tp.transactionSets[ids[1]]; exists {
+		t.Error("dependent set was not evicted")
+	}
+	if _, exists := tp.transactionSets[ids[2]]; !exists {
+		t.Error("unrelated set was evicted")
+	}
+	if tp.transactionListSize != len(encoding.Marshal(unrelated)) {
+		t.Error("transaction list size was not updated:", tp.transactionListSize)
+	}
+	if sets := tp.setsByFeeRate(); len(sets) != 1 || sets[0].id != ids[2] {
+		t.Error("evicted sets were not removed from the fee rate index:", sets)
+	}
+}
+
+// TestFeeRateIndex checks that the fee rate index stays ordered as transaction
+// sets are added to and removed from it.
+func TestFeeRateIndex(t *testing.T) {
+	tp := &TransactionPool{
+		pooledSets: make(map[TransactionSetID]pooledSet),
+	}
+	var ids []TransactionSetID
+	for i := 0; i < 50; i++ {
+		set := []types.Transaction{{
+			MinerFees:     []types.Currency{types.NewCurrency64(fastrand.Uint64n(10))},
+			ArbitraryData: [][]byte{fastrand.Bytes(16)},
+		}}
+		id := TransactionSetID(crypto.HashObject(set))
+		tp.indexTransactionSet(id, set, len(encoding.Marshal(set)))
+		ids = append(ids, id)
+	}
+	for _, i := range fastrand.Perm(len(ids))[:25] {
+		tp.unindexTransactionSet(ids[i])
+	}
+
+	sets := tp.setsByFeeRate()
+	if len(sets) != 25 || len(tp.pooledSets) != 25 {
+		t.Fatal("expected 25 sets in the index, got", len(sets), len(tp.pooledSets))
+	}
+	for i, ps := range sets {
+		if _, exists := tp.pooledSets[ps.id]; !exists {
+			t.Fatal("index does not match the pooled sets")
+		}
+		if i > 0 && !sets[i-1].less(ps) {
+			t.Fatal("index is not ordered by fee rate")
+		}
+	}
+}
+
 // TestTransactionGraph checks that the TransactionGraph method of the types
 // package is able to create transasctions that actually validate and can get
 // inserted into the tpool.
@@ -37,6 +37,12 @@ const (
 	// TransactionPoolSizeTarget defines the target size of the pool when the
 	// transactions are paying 1 SC / kb in fees.
 	TransactionPoolSizeTarget = 3e6
+
+	// TransactionPoolSizeLimit defines the largest size that the transaction
+	// pool is allowed to grow to. Once the limit is reached, a new transaction
+	// set is only accepted if it pays more fees per byte than the sets that
+	// are evicted to make room for it.
+	TransactionPoolSizeLimit = 2 * TransactionPoolSizeTarget
 )
 
 // Constants related to fee estimation.
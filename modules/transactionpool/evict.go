@@ -0,0 +1,204 @@
+package transactionpool
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// feeRate returns the miner fees per byte paid by a transaction set of the
+// given encoded size.
+func feeRate(ts []types.Transaction, size int) types.Currency {
+	var fees types.Currency
+	for _, txn := range ts {
+		for _, fee := range txn.MinerFees {
+			fees = fees.Add(fee)
+		}
+	}
+	if size == 0 {
+		return fees
+	}
+	return fees.Div64(uint64(size))
+}
+
+// pooledSet is a transaction set in the pool along with its size and fee
+// rate.
+type pooledSet struct {
+	id      TransactionSetID
+	size    int
+	feeRate types.Currency
+}
+
+// less orders pooled sets by their fee rate, breaking ties by their id.
+func (ps pooledSet) less(other pooledSet) bool {
+	if c := ps.feeRate.Cmp(other.feeRate); c != 0 {
+		return c < 0
+	}
+	return bytes.Compare(ps.id[:], other.id[:]) < 0
+}
+
+// searchFeeRateIndex returns the position of the pooled set in the fee rate
+// index, or the position it would be inserted at.
+func (tp *TransactionPool) searchFeeRateIndex(ps pooledSet) int {
+	return sort.Search(len(tp.feeRateIndex), func(i int) bool {
+		return !tp.feeRateIndex[i].less(ps)
+	})
+}
+
+// indexTransactionSet adds a transaction set that was added to the pool to the
+// fee rate index.
+func (tp *TransactionPool) indexTransactionSet(id TransactionSetID, ts []types.Transaction, size int) {
+	ps := pooledSet{
+		id:      id,
+		size:    size,
+		feeRate: feeRate(ts, size),
+	}
+	i := tp.searchFeeRateIndex(ps)
+	tp.feeRateIndex = append(tp.feeRateIndex, pooledSet{})
+	copy(tp.feeRateIndex[i+1:], tp.feeRateIndex[i:])
+	tp.feeRateIndex[i] = ps
+	tp.pooledSets[id] = ps
+}
+
+// unindexTransactionSet removes a transaction set that was removed from the
+// pool from the fee rate index.
+func (tp *TransactionPool) unindexTransactionSet(id TransactionSetID) {
+	ps, exists := tp.pooledSets[id]
+	if !exists {
+		return
+	}
+	delete(tp.pooledSets, id)
+	i := tp.searchFeeRateIndex(ps)
+	tp.feeRateIndex = append(tp.feeRateIndex[:i], tp.feeRateIndex[i+1:]...)
+}
+
+// setsByFeeRate returns the transaction sets in the pool ordered by their fee
+// per byte, cheapest first. The returned slice must not be modified.
+func (tp *TransactionPool) setsByFeeRate() []pooledSet {
+	return tp.feeRateIndex
+}
+
+// minimumFee returns the smallest fee per byte that a transaction set needs to
+// pay to be accepted into the pool. Once the pool is close to its size limit,
+// a set has to outbid the cheapest sets in the pool.
+func (tp *TransactionPool) minimumFee() types.Currency {
+	min := tp.requiredFeesToExtendTpool()
+	if tp.transactionListSize+modules.TransactionSizeLimit <= tp.sizeLimit {
+		return min
+	}
+	sets := tp.setsByFeeRate()
+	if len(sets) == 0 {
+		return min
+	}
+	outbid := sets[0].feeRate.Add(types.NewCurrency64(1))
+	if min.Cmp(outbid) < 0 {
+		min = outbid
+	}
+	return min
+}
+
+// setsToEvict returns the cheapest transaction sets that need to be evicted to
+// make room for a new set of the given size and fee rate. Sets that are
+// replaced by the new set are not counted against the pool. An error is
+// returned if enough room can only be made by evicting sets that pay at least
+// as much as the new set.
+func (tp *TransactionPool) setsToEvict(size int, rate types.Currency, replaced map[TransactionSetID]struct{}) ([]TransactionSetID, error) {
+	poolSize := tp.transactionListSize
+	for id := range replaced {
+		poolSize -= tp.pooledSets[id].size
+	}
+	if poolSize+size <= tp.sizeLimit {
+		return nil, nil
+	}
+
+	var evict []TransactionSetID
+	for _, set := range tp.setsByFeeRate() {
+		if poolSize+size <= tp.sizeLimit {
+			break
+		}
+		if _, exists := replaced[set.id]; exists {
+			continue
+		}
+		if set.feeRate.Cmp(rate) >= 0 {
+			break
+		}
+		evict = append(evict, set.id)
+		poolSize -= set.size
+	}
+	if poolSize+size > tp.sizeLimit {
+		return nil, errFullTransactionPool
+	}
+	return evict, nil
+}
+
+// evictTransactionSet removes a transaction set from the pool, along with
+// every set that depends on the objects it creates.
+func (tp *TransactionPool) evictTransactionSet(id TransactionSetID) {
+	ts, exists := tp.transactionSets[id]
+	if !exists {
+		return
+	}
+	for _, oid := range relatedObjectIDs(ts) {
+		if tp.knownObjects[oid] == id {
+			delete(tp.knownObjects, oid)
+		}
+	}
+	for _, txn := range ts {
+		delete(tp.transactionHeights, txn.ID())
+	}
+	tp.transactionListSize -= tp.pooledSets[id].size
+	tp.unindexTransactionSet(id)
+	delete(tp.transactionSets, id)
+	delete(tp.transactionSetDiffs, id)
+	tp.log.Debugf("evicted transaction set %v, tpool size is %vB\n", id, tp.transactionListSize)
+
+	// Evict the sets that spend objects created by the evicted set, as they
+	// are no longer valid without it.
+	created := make(map[ObjectID]struct{})
+	for _, txn := range ts {
+		for i := range txn.SiacoinOutputs {
+			created[ObjectID(txn.SiacoinOutputID(uint64(i)))] = struct{}{}
+		}
+		for i := range txn.FileContracts {
+			created[ObjectID(txn.FileContractID(uint64(i)))] = struct{}{}
+		}
+		for i := range txn.SiafundOutputs {
+			created[ObjectID(txn.SiafundOutputID(uint64(i)))] = struct{}{}
+		}
+	}
+	for depID, depSet := range tp.transactionSets {
+		if spendsObject(depSet, created) {
+			tp.evictTransactionSet(depID)
+		}
+	}
+}
+
+// spendsObject returns true if any transaction in the set spends or revises
+// one of the provided objects.
+func spendsObject(ts []types.Transaction, objects map[ObjectID]struct{}) bool {
+	for _, txn := range ts {
+		for _, sci := range txn.SiacoinInputs {
+			if _, exists := objects[ObjectID(sci.ParentID)]; exists {
+				return true
+			}
+		}
+		for _, fcr := range txn.FileContractRevisions {
+			if _, exists := objects[ObjectID(fcr.ParentID)]; exists {
+				return true
+			}
+		}
+		for _, sp := range txn.StorageProofs {
+			if _, exists := objects[ObjectID(sp.ParentID)]; exists {
+				return true
+			}
+		}
+		for _, sfi := range txn.SiafundInputs {
+			if _, exists := objects[ObjectID(sfi.ParentID)]; exists {
+				return true
+			}
+		}
+	}
+	return false
+}
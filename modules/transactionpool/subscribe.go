@@ -59,6 +59,28 @@ func (tp *TransactionPool) updateSubscribersTransactions() {
 		diff.AppliedTransactions = append(diff.AppliedTransactions, ut)
 	}
 
+	// Report the individual transactions that entered or left the pool.
+	currentTxns := make(map[types.TransactionID]struct{}, len(tp.subscriberTxns))
+	for _, ut := range tp.subscriberSets {
+		for i, id := range ut.IDs {
+			currentTxns[id] = struct{}{}
+			if _, exists := tp.subscriberTxns[id]; !exists {
+				diff.AcceptedTransactions = append(diff.AcceptedTransactions, ut.Transactions[i])
+			}
+		}
+	}
+	for id := range tp.subscriberTxns {
+		if _, exists := currentTxns[id]; exists {
+			continue
+		}
+		if tp.transactionConfirmed(tp.dbTx, id) {
+			diff.ConfirmedTransactions = append(diff.ConfirmedTransactions, id)
+		} else {
+			diff.DroppedTransactions = append(diff.DroppedTransactions, id)
+		}
+	}
+	tp.subscriberTxns = currentTxns
+
 	for _, subscriber := range tp.subscribers {
 		subscriber.ReceiveUpdatedUnconfirmedTransactions(diff)
 	}
@@ -86,6 +108,7 @@ func (tp *TransactionPool) TransactionPoolSubscribe(subscriber modules.Transacti
 	diff.AppliedTransactions = make([]*modules.UnconfirmedTransactionSet, 0, len(tp.subscriberSets))
 	for _, ut := range tp.subscriberSets {
 		diff.AppliedTransactions = append(diff.AppliedTransactions, ut)
+		diff.AcceptedTransactions = append(diff.AcceptedTransactions, ut.Transactions...)
 	}
 	subscriber.ReceiveUpdatedUnconfirmedTransactions(diff)
 }
@@ -93,7 +116,9 @@ func (tp *TransactionPool) TransactionPoolSubscribe(subscriber modules.Transacti
 // Unsubscribe removes a subscriber from the transaction pool. If the
 // subscriber is not in tp.subscribers, Unsubscribe does nothing. If the
 // subscriber occurs more than once in tp.subscribers, only the earliest
-// occurrence is removed (unsubscription fails).
+// occurrence is removed (unsubscription fails). Subscribers are notified while
+// the transaction pool is locked, so the subscriber receives no further
+// updates once Unsubscribe returns.
 func (tp *TransactionPool) Unsubscribe(subscriber modules.TransactionPoolSubscriber) {
 	tp.mu.Lock()
 	defer tp.mu.Unlock()
@@ -5,6 +5,7 @@ import (
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
 )
 
 // mockSubscriber receives transactions from the transaction pool it is
@@ -12,6 +13,10 @@ import (
 type mockSubscriber struct {
 	txnMap map[modules.TransactionSetID][]types.Transaction
 	txns   []types.Transaction
+
+	accepted  []types.Transaction
+	confirmed []types.TransactionID
+	dropped   []types.TransactionID
 }
 
 // ReceiveUpdatedUnconfirmedTransactions receives transactinos from the
@@ -29,6 +34,9 @@ func (ms *mockSubscriber) ReceiveUpdatedUnconfirmedTransactions(diff *modules.Tr
 	for _, txnSet := range ms.txnMap {
 		ms.txns = append(ms.txns, txnSet...)
 	}
+	ms.accepted = append(ms.accepted, diff.AcceptedTransactions...)
+	ms.confirmed = append(ms.confirmed, diff.ConfirmedTransactions...)
+	ms.dropped = append(ms.dropped, diff.DroppedTransactions...)
 }
 
 // TestSubscription checks that calling Unsubscribe on a mockSubscriber
@@ -82,3 +90,75 @@ func TestSubscription(t *testing.T) {
 		t.Error("transaction pool failed to unsubscribe mock subscriber")
 	}
 }
+
+// TestSubscriptionTransactionEvents checks that subscribers are told which
+// transactions were accepted into the pool, and which ones left the pool
+// because they were confirmed or dropped.
+func TestSubscriptionTransactionEvents(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	ms := mockSubscriber{
+		txnMap: make(map[modules.TransactionSetID][]types.Transaction),
+	}
+	tpt.tpool.TransactionPoolSubscribe(&ms)
+
+	// Sending coins should report every transaction of the set as accepted,
+	// and nothing else.
+	txns, err := tpt.wallet.SendSiacoins(types.NewCurrency64(100), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms.accepted) != len(txns) || len(ms.confirmed) != 0 || len(ms.dropped) != 0 {
+		t.Fatal("unexpected events after sending coins:", len(ms.accepted), len(ms.confirmed), len(ms.dropped))
+	}
+	for i := range txns {
+		if ms.accepted[i].ID() != txns[i].ID() {
+			t.Fatal("accepted transactions don't match the sent transactions")
+		}
+	}
+
+	// Mining a block should report the transactions as confirmed.
+	_, err = tpt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms.confirmed) != len(txns) || len(ms.dropped) != 0 || len(ms.accepted) != len(txns) {
+		t.Fatal("unexpected events after mining a block:", len(ms.accepted), len(ms.confirmed), len(ms.dropped))
+	}
+
+	// A transaction that leaves the pool without being confirmed should be
+	// reported as dropped.
+	arbTxn := func() types.Transaction {
+		return types.Transaction{ArbitraryData: [][]byte{append(modules.PrefixNonSia[:], fastrand.Bytes(16)...)}}
+	}
+	dropped := arbTxn()
+	if err := tpt.tpool.AcceptTransactionSet([]types.Transaction{dropped}); err != nil {
+		t.Fatal(err)
+	}
+	tpt.tpool.PurgeTransactionPool()
+	if err := tpt.tpool.AcceptTransactionSet([]types.Transaction{arbTxn()}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ms.dropped) != 1 || ms.dropped[0] != dropped.ID() {
+		t.Fatal("purged transaction was not reported as dropped:", ms.dropped)
+	}
+	if len(ms.accepted) != len(txns)+2 {
+		t.Fatal("expected two more accepted transactions, got", len(ms.accepted)-len(txns))
+	}
+
+	// After unsubscribing, no further updates should be received.
+	tpt.tpool.Unsubscribe(&ms)
+	if err := tpt.tpool.AcceptTransactionSet([]types.Transaction{arbTxn()}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ms.accepted) != len(txns)+2 {
+		t.Fatal("unsubscribed subscriber received an update")
+	}
+}
@@ -47,10 +47,19 @@ type (
 		// diffs that resulted from the transaction set.
 		knownObjects        map[ObjectID]TransactionSetID
 		subscriberSets      map[TransactionSetID]*modules.UnconfirmedTransactionSet
+		subscriberTxns      map[types.TransactionID]struct{}
 		transactionHeights  map[types.TransactionID]types.BlockHeight
 		transactionSets     map[TransactionSetID][]types.Transaction
 		transactionSetDiffs map[TransactionSetID]*modules.ConsensusChange
 		transactionListSize int
+		sizeLimit           int
+
+		// The fee rate index keeps the transaction sets in the pool ordered by
+		// their fee per byte, cheapest first, so that the cheapest sets can be
+		// found without re-encoding the pool. pooledSets maps each set to its
+		// entry in the index.
+		pooledSets   map[TransactionSetID]pooledSet
+		feeRateIndex []pooledSet
 
 		// Variables related to the blockchain.
 		blockHeight     types.BlockHeight
@@ -90,9 +99,12 @@ func New(cs modules.ConsensusSet, g modules.Gateway, persistDir string) (*Transa
 
 		knownObjects:        make(map[ObjectID]TransactionSetID),
 		subscriberSets:      make(map[TransactionSetID]*modules.UnconfirmedTransactionSet),
+		subscriberTxns:      make(map[types.TransactionID]struct{}),
 		transactionHeights:  make(map[types.TransactionID]types.BlockHeight),
 		transactionSets:     make(map[TransactionSetID][]types.Transaction),
 		transactionSetDiffs: make(map[TransactionSetID]*modules.ConsensusChange),
+		sizeLimit:           TransactionPoolSizeLimit,
+		pooledSets:          make(map[TransactionSetID]pooledSet),
 
 		persistDir: persistDir,
 	}
@@ -158,6 +170,16 @@ func (tp *TransactionPool) FeeEstimation() (min, max types.Currency) {
 		max = requiredMax
 	}
 
+	// The minimum should always be enough to get into the pool, even if
+	// cheaper transaction sets need to be evicted.
+	minimumFee := tp.minimumFee()
+	if min.Cmp(minimumFee) < 0 {
+		min = minimumFee
+	}
+	if max.Cmp(minimumFee.Mul64(maxMultiplier)) < 0 {
+		max = minimumFee.Mul64(maxMultiplier)
+	}
+
 	// Method three: sane mimimums.
 	if min.Cmp(minEstimation) < 0 {
 		min = minEstimation
@@ -169,6 +191,19 @@ func (tp *TransactionPool) FeeEstimation() (min, max types.Currency) {
 	return
 }
 
+// MinimumFee returns the smallest fee per byte that a transaction set needs to
+// pay to be accepted into the transaction pool.
+func (tp *TransactionPool) MinimumFee() types.Currency {
+	err := tp.tg.Add()
+	if err != nil {
+		return types.ZeroCurrency
+	}
+	defer tp.tg.Done()
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	return tp.minimumFee()
+}
+
 // TransactionList returns a list of all transactions in the transaction pool.
 // The transactions are provided in an order that can acceptably be put into a
 // block.
@@ -274,6 +309,78 @@ func (tp *TransactionPool) TransactionSet(oid crypto.Hash) []types.Transaction {
 	return parents
 }
 
+// AddressTransactions returns the unconfirmed transactions that create or
+// spend siacoin or siafund outputs of the address, and the value that they add
+// to and remove from the balance of the address. The values are taken from the
+// diffs of the transaction sets, so outputs that are created and spent within
+// the pool count towards both the incoming and the outgoing value.
+func (tp *TransactionPool) AddressTransactions(addr types.UnlockHash) modules.UnconfirmedAddressSet {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	var uas modules.UnconfirmedAddressSet
+	for setID, tSet := range tp.transactionSets {
+		relevant := false
+		for _, txn := range tSet {
+			if transactionAffectsAddress(txn, addr) {
+				uas.Transactions = append(uas.Transactions, txn)
+				relevant = true
+			}
+		}
+		cc, exists := tp.transactionSetDiffs[setID]
+		if !relevant || !exists {
+			continue
+		}
+		for _, diff := range cc.SiacoinOutputDiffs {
+			if diff.SiacoinOutput.UnlockHash != addr {
+				continue
+			}
+			if diff.Direction == modules.DiffApply {
+				uas.IncomingSiacoins = uas.IncomingSiacoins.Add(diff.SiacoinOutput.Value)
+			} else {
+				uas.OutgoingSiacoins = uas.OutgoingSiacoins.Add(diff.SiacoinOutput.Value)
+			}
+		}
+		for _, diff := range cc.SiafundOutputDiffs {
+			if diff.SiafundOutput.UnlockHash != addr {
+				continue
+			}
+			if diff.Direction == modules.DiffApply {
+				uas.IncomingSiafunds = uas.IncomingSiafunds.Add(diff.SiafundOutput.Value)
+			} else {
+				uas.OutgoingSiafunds = uas.OutgoingSiafunds.Add(diff.SiafundOutput.Value)
+			}
+		}
+	}
+	return uas
+}
+
+// transactionAffectsAddress returns true if the transaction creates or spends
+// a siacoin or siafund output of the address.
+func transactionAffectsAddress(txn types.Transaction, addr types.UnlockHash) bool {
+	for _, sci := range txn.SiacoinInputs {
+		if sci.UnlockConditions.UnlockHash() == addr {
+			return true
+		}
+	}
+	for _, sco := range txn.SiacoinOutputs {
+		if sco.UnlockHash == addr {
+			return true
+		}
+	}
+	for _, sfi := range txn.SiafundInputs {
+		if sfi.UnlockConditions.UnlockHash() == addr {
+			return true
+		}
+	}
+	for _, sfo := range txn.SiafundOutputs {
+		if sfo.UnlockHash == addr {
+			return true
+		}
+	}
+	return false
+}
+
 // Broadcast broadcasts a transaction set to all of the transaction pool's
 // peers.
 func (tp *TransactionPool) Broadcast(ts []types.Transaction) {
@@ -1296,3 +1296,73 @@ func TestTpoolRevert(t *testing.T) {
 		t.Error("Expected highest fee from second block to be greater than lowest fee from second block.")
 	}
 }
+
+// TestAddressTransactions checks that the transaction pool reports the
+// unconfirmed transactions of an address along with the pending change to its
+// balance, and that confirmed transactions are no longer reported.
+func TestAddressTransactions(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	emptyUH := types.UnlockConditions{}.UnlockHash()
+	if uas := tpt.tpool.AddressTransactions(emptyUH); len(uas.Transactions) != 0 || !uas.IncomingSiacoins.IsZero() {
+		t.Fatal("empty pool reported transactions:", uas)
+	}
+
+	// Send coins to the address, and spend them again in a child transaction.
+	value := types.NewCurrency64(35e6)
+	fee := types.NewCurrency64(3e2)
+	txnBuilder := tpt.wallet.StartTransaction()
+	err = txnBuilder.FundSiacoins(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddMinerFee(fee)
+	txnBuilder.AddSiacoinOutput(types.SiacoinOutput{
+		Value:      value.Sub(fee),
+		UnlockHash: emptyUH,
+	})
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID: txnSet[len(txnSet)-1].SiacoinOutputID(0),
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      value.Sub(fee),
+			UnlockHash: emptyUH,
+		}},
+	}
+	err = tpt.tpool.AcceptTransactionSet(append(txnSet, child))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uas := tpt.tpool.AddressTransactions(emptyUH)
+	if len(uas.Transactions) != 2 {
+		t.Fatal("expected 2 transactions, got", len(uas.Transactions))
+	}
+	if !uas.IncomingSiacoins.Equals(value.Sub(fee).Mul64(2)) || !uas.OutgoingSiacoins.Equals(value.Sub(fee)) {
+		t.Fatal("wrong pending siacoins:", uas.IncomingSiacoins, uas.OutgoingSiacoins)
+	}
+	if !uas.IncomingSiafunds.IsZero() || !uas.OutgoingSiafunds.IsZero() {
+		t.Fatal("wrong pending siafunds:", uas.IncomingSiafunds, uas.OutgoingSiafunds)
+	}
+
+	// Once the transactions are confirmed they are no longer reported.
+	_, err = tpt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uas := tpt.tpool.AddressTransactions(emptyUH); len(uas.Transactions) != 0 || !uas.IncomingSiacoins.IsZero() {
+		t.Fatal("confirmed transactions are still reported:", uas)
+	}
+}
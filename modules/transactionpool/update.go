@@ -166,6 +166,8 @@ func (tp *TransactionPool) purge() {
 	tp.transactionSets = make(map[TransactionSetID][]types.Transaction)
 	tp.transactionSetDiffs = make(map[TransactionSetID]*modules.ConsensusChange)
 	tp.transactionListSize = 0
+	tp.pooledSets = make(map[TransactionSetID]pooledSet)
+	tp.feeRateIndex = nil
 }
 
 // ProcessConsensusChange gets called to inform the transaction pool of changes
@@ -421,5 +423,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 func (tp *TransactionPool) PurgeTransactionPool() {
 	tp.mu.Lock()
 	tp.purge()
+	// Inform subscribers that the transactions have been dropped.
+	tp.updateSubscribersTransactions()
 	tp.mu.Unlock()
 }
@@ -34,6 +34,10 @@ var (
 	// being 'unconfirmed' yet.
 	ErrIncompleteTransactions = errors.New("wallet has coins spent in incomplete transactions - not enough remaining coins")
 
+	// ErrInvalidMessageSignature is returned when a message signature does
+	// not match the message or the address.
+	ErrInvalidMessageSignature = errors.New("message signature is invalid")
+
 	// ErrLockedWallet is returned when an action cannot be performed due to
 	// the wallet being locked.
 	ErrLockedWallet = errors.New("wallet must be unlocked before it can be used")
@@ -41,9 +45,115 @@ var (
 	// ErrLowBalance is returned if the wallet does not have enough funds to
 	// complete the desired action.
 	ErrLowBalance = errors.New("insufficient balance")
+
+	// ErrUnknownFeeTier is returned if a FeeTier other than FeeTierLow,
+	// FeeTierMedium or FeeTierHigh is requested.
+	ErrUnknownFeeTier = errors.New("unknown fee tier")
+
+	// SpecifierSignedMessage is hashed together with a message before the
+	// message is signed. It separates message signatures from transaction
+	// signatures, so that a signed message can never be used to sign a
+	// transaction.
+	SpecifierSignedMessage = types.Specifier{'s', 'i', 'g', 'n', 'e', 'd', ' ', 'm', 'e', 's', 's', 'a', 'g', 'e'}
+)
+
+const (
+	// FeeTierLow selects the cheapest fee recommendation. Transactions using
+	// this tier may take longer to confirm.
+	FeeTierLow FeeTier = "low"
+
+	// FeeTierMedium selects the typical fee recommendation.
+	FeeTierMedium FeeTier = "medium"
+
+	// FeeTierHigh selects the most expensive fee recommendation.
+	// Transactions using this tier should be confirmed quickly.
+	FeeTierHigh FeeTier = "high"
+)
+
+const (
+	// HistoryCategoryIncoming marks a transaction that sends siacoins to the
+	// wallet.
+	HistoryCategoryIncoming HistoryCategory = "incoming"
+
+	// HistoryCategoryOutgoing marks a transaction that sends siacoins from the
+	// wallet.
+	HistoryCategoryOutgoing HistoryCategory = "outgoing"
+
+	// HistoryCategoryContract marks a transaction that forms, revises or
+	// proves a file contract, including the storage proofs that pay out to
+	// the wallet.
+	HistoryCategoryContract HistoryCategory = "contract"
+
+	// HistoryCategoryMinerPayout marks the miner payouts of a block.
+	HistoryCategoryMinerPayout HistoryCategory = "minerpayout"
 )
 
 type (
+	// FeeTier is a target confirmation speed, used to select one of the fee
+	// recommendations of a FeeEstimate.
+	FeeTier string
+
+	// FeeEstimate contains fee recommendations in hastings per byte, derived
+	// from the fees paid in recently confirmed blocks.
+	FeeEstimate struct {
+		Low    types.Currency `json:"low"`
+		Medium types.Currency `json:"medium"`
+		High   types.Currency `json:"high"`
+	}
+
+	// A MessageSignature proves that the owner of an address signed a
+	// message. The address must have standard unlock conditions, with a
+	// single ed25519 public key and no timelock.
+	MessageSignature struct {
+		Address   types.UnlockHash   `json:"address"`
+		PublicKey types.SiaPublicKey `json:"publickey"`
+		Signature []byte             `json:"signature"`
+	}
+
+	// An AddressLabel is a human-readable label for an address.
+	AddressLabel struct {
+		Address types.UnlockHash `json:"address"`
+		Label   string           `json:"label"`
+	}
+
+	// A SiafundOutputClaim is a confirmed siafund output owned by the wallet,
+	// along with the siacoins that it has accumulated from the siafund pool.
+	// The claim is paid out when the output is spent, using the value of the
+	// siafund pool at that time.
+	SiafundOutputClaim struct {
+		ID         types.SiafundOutputID `json:"id"`
+		UnlockHash types.UnlockHash      `json:"unlockhash"`
+		Value      types.Currency        `json:"value"`
+		ClaimStart types.Currency        `json:"claimstart"`
+		Claim      types.Currency        `json:"claim"`
+	}
+
+	// HistoryCategory describes what kind of transaction a HistoryEntry
+	// refers to.
+	HistoryCategory string
+
+	// A HistoryEntry summarizes how a transaction changed the siacoin
+	// balance of the wallet. Unconfirmed entries have a confirmation height
+	// and timestamp of math.MaxUint64.
+	HistoryEntry struct {
+		TransactionID         types.TransactionID `json:"transactionid"`
+		Category              HistoryCategory     `json:"category"`
+		Confirmed             bool                `json:"confirmed"`
+		ConfirmationHeight    types.BlockHeight   `json:"confirmationheight"`
+		ConfirmationTimestamp types.Timestamp     `json:"confirmationtimestamp"`
+
+		// Incoming and Outgoing are the siacoins that the transaction sent to
+		// and from the wallet. NetValue is their difference in hastings as a
+		// decimal string, prefixed with '-' if the wallet lost siacoins.
+		Incoming types.Currency `json:"incoming"`
+		Outgoing types.Currency `json:"outgoing"`
+		NetValue string         `json:"netvalue"`
+
+		// Labels contains the labels of the addresses involved in the
+		// transaction.
+		Labels []AddressLabel `json:"labels"`
+	}
+
 	// Seed is cryptographic entropy that is used to derive spendable wallet
 	// addresses.
 	Seed [crypto.EntropySize]byte
@@ -67,13 +177,15 @@ type (
 	// mature at all (in the event of storage proofs).
 	//
 	// Fund type can either be 'SiacoinOutput', 'SiafundOutput', 'ClaimOutput',
-	// 'MinerPayout', or 'MinerFee'. All outputs except the miner fee create
-	// outputs accessible to an address. Miner fees are not spendable, and
-	// instead contribute to the block subsidy.
+	// 'MinerPayout', 'StorageProofOutput', or 'MinerFee'. All outputs except
+	// the miner fee create outputs accessible to an address. Miner fees are
+	// not spendable, and instead contribute to the block subsidy.
 	//
 	// MaturityHeight indicates at what block height the output becomes
 	// available. SiacoinInputs and SiafundInputs become available immediately.
 	// ClaimInputs and MinerPayouts become available after 144 confirmations.
+	// SiacoinOutputs sent to a timelocked wallet address become available
+	// once the timelock expires.
 	ProcessedOutput struct {
 		ID             types.OutputID    `json:"id"`
 		FundType       types.Specifier   `json:"fundtype"`
@@ -120,6 +232,13 @@ type (
 		// transaction failed.
 		FundSiacoins(amount types.Currency) error
 
+		// FundSiacoinsFromOutputs functions like FundSiacoins, but funds the
+		// transaction using exactly the provided wallet outputs instead of
+		// selecting them automatically. An error is returned if any of the
+		// outputs is not owned by the wallet or not spendable, or if the
+		// outputs do not cover 'amount'. Any excess is refunded to the wallet.
+		FundSiacoinsFromOutputs(amount types.Currency, ids []types.SiacoinOutputID) error
+
 		// FundSiafunds will add a siafund input of exactly 'amount' to the
 		// transaction. A parent transaction may be needed to achieve an input
 		// with the correct value. The siafund input will not be signed until
@@ -147,6 +266,13 @@ type (
 		// the index of the siacoin output within the transaction.
 		AddSiacoinOutput(types.SiacoinOutput) uint64
 
+		// AddTimelockedSiacoinOutput adds a siacoin output to the
+		// transaction that can only be spent by the provided public key once
+		// the blockchain has reached the provided height. The index of the
+		// siacoin output within the transaction is returned, along with the
+		// unlock conditions that are needed to spend the output.
+		AddTimelockedSiacoinOutput(value types.Currency, pk types.SiaPublicKey, timelock types.BlockHeight) (uint64, types.UnlockConditions)
+
 		// AddFileContract adds a file contract to the transaction, returning
 		// the index of the file contract within the transaction.
 		AddFileContract(types.FileContract) uint64
@@ -316,6 +442,10 @@ type (
 		// seed.
 		NextAddresses(uint64) ([]types.UnlockConditions, error)
 
+		// NextTimelockedAddress returns new unlock conditions generated from
+		// the primary seed that cannot be spent until the provided height.
+		NextTimelockedAddress(types.BlockHeight) (types.UnlockConditions, error)
+
 		// PrimarySeed returns the unencrypted primary seed of the wallet,
 		// along with a uint64 indicating how many addresses may be safely
 		// generated from the seed.
@@ -327,6 +457,36 @@ type (
 		// outputs, minus the fee. If only siafunds were found, the fee is
 		// deducted from the wallet.
 		SweepSeed(seed Seed) (coins, funds types.Currency, err error)
+
+		// SweepDust consolidates the confirmed siacoin outputs below
+		// threshold into a single output owned by the wallet, deducting the
+		// fee from the consolidated value. It returns the number of outputs
+		// that were consolidated and the fee that was paid. The sweep is
+		// refused if the outputs are not worth more than the fee.
+		SweepDust(threshold types.Currency) (outputs uint64, fee types.Currency, err error)
+
+		// AddressLabels returns the labels of all labeled addresses, sorted
+		// by address.
+		AddressLabels() []AddressLabel
+
+		// LabelsForAddresses returns the labels of the provided addresses.
+		// Addresses without a label are skipped.
+		LabelsForAddresses([]types.UnlockHash) []AddressLabel
+
+		// SetAddressLabel associates a label with an address, which may or
+		// may not belong to the wallet. An empty label removes the label.
+		// Labels do not affect how the wallet handles the address.
+		SetAddressLabel(addr types.UnlockHash, label string) error
+
+		// WatchAddresses adds a set of addresses to the wallet as watch-only
+		// addresses. The wallet tracks outputs sent to these addresses, but
+		// cannot spend them. Adding new addresses triggers a rescan of the
+		// blockchain.
+		WatchAddresses([]types.UnlockHash) error
+
+		// WatchOnlyAddresses returns the set of watch-only addresses tracked
+		// by the wallet. Addresses are returned sorted in byte-order.
+		WatchOnlyAddresses() []types.UnlockHash
 	}
 
 	// Wallet stores and manages siacoins and siafunds. The wallet file is
@@ -351,6 +511,15 @@ type (
 		// not considered in the unconfirmed balance.
 		UnconfirmedBalance() (outgoingSiacoins types.Currency, incomingSiacoins types.Currency)
 
+		// SiafundOutputs returns the confirmed siafund outputs of the wallet
+		// and the siacoins that each of them can currently claim.
+		SiafundOutputs() []SiafundOutputClaim
+
+		// WatchOnlyBalance returns the confirmed siacoin balance of the
+		// watch-only addresses. This balance cannot be spent by the wallet
+		// and is not included in ConfirmedBalance.
+		WatchOnlyBalance() types.Currency
+
 		// Height returns the wallet's internal processed consensus height
 		Height() types.BlockHeight
 
@@ -372,6 +541,10 @@ type (
 		// included.
 		Transactions(startHeight types.BlockHeight, endHeight types.BlockHeight) ([]ProcessedTransaction, error)
 
+		// History returns a chronological summary of the confirmed and
+		// unconfirmed transactions that are relevant to the wallet.
+		History() ([]HistoryEntry, error)
+
 		// UnconfirmedTransactions returns all unconfirmed transactions
 		// relative to the wallet.
 		UnconfirmedTransactions() []ProcessedTransaction
@@ -400,6 +573,10 @@ type (
 		// are also returned to the caller.
 		SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
 
+		// SendSiacoinsWithFeeTier functions like SendSiacoins, but pays the
+		// fee recommended by EstimateFee for the provided tier.
+		SendSiacoinsWithFeeTier(amount types.Currency, dest types.UnlockHash, tier FeeTier) ([]types.Transaction, error)
+
 		// SendSiacoinsMulti sends coins to multiple addresses.
 		SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error)
 
@@ -409,9 +586,25 @@ type (
 		// are also returned to the caller.
 		SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
 
+		// ClaimSiafunds spends the siafund outputs of the wallet back to the
+		// wallet, which pays out their claims as siacoins. The returned claim
+		// is an estimate, since consensus computes the claims from the
+		// siafund pool at the height where the transaction is confirmed.
+		ClaimSiafunds() (claim types.Currency, txns []types.Transaction, err error)
+
+		// SignMessage signs an arbitrary message with the key of an address
+		// owned by the wallet. The signature can be checked with
+		// VerifyMessageSignature.
+		SignMessage(addr types.UnlockHash, message []byte) (MessageSignature, error)
+
 		// DustThreshold returns the quantity per byte below which a Currency is
 		// considered to be Dust.
 		DustThreshold() types.Currency
+
+		// EstimateFee returns low, medium and high fee recommendations based
+		// on the fees paid in recently confirmed blocks. The recommendations
+		// never fall below the minimum fee of the transaction pool.
+		EstimateFee() FeeEstimate
 	}
 
 	// WalletSettings control the behavior of the Wallet.
@@ -420,12 +613,53 @@ type (
 	}
 )
 
+// Tier returns the fee per byte recommended for the provided tier.
+func (fe FeeEstimate) Tier(tier FeeTier) (types.Currency, error) {
+	switch tier {
+	case FeeTierLow:
+		return fe.Low, nil
+	case FeeTierMedium:
+		return fe.Medium, nil
+	case FeeTierHigh:
+		return fe.High, nil
+	}
+	return types.Currency{}, ErrUnknownFeeTier
+}
+
 // CalculateWalletTransactionID is a helper function for determining the id of
 // a wallet transaction.
 func CalculateWalletTransactionID(tid types.TransactionID, oid types.OutputID) WalletTransactionID {
 	return WalletTransactionID(crypto.HashAll(tid, oid))
 }
 
+// MessageSignatureHash returns the hash that is signed to sign a message.
+func MessageSignatureHash(message []byte) crypto.Hash {
+	return crypto.HashAll(SpecifierSignedMessage, message)
+}
+
+// VerifyMessageSignature checks that a message signature was made for the
+// message with the key of the signature's address.
+func VerifyMessageSignature(message []byte, ms MessageSignature) error {
+	uc := types.UnlockConditions{
+		PublicKeys:         []types.SiaPublicKey{ms.PublicKey},
+		SignaturesRequired: 1,
+	}
+	if uc.UnlockHash() != ms.Address {
+		return ErrInvalidMessageSignature
+	}
+	if ms.PublicKey.Algorithm != types.SignatureEd25519 || len(ms.PublicKey.Key) != crypto.PublicKeySize || len(ms.Signature) != crypto.SignatureSize {
+		return ErrInvalidMessageSignature
+	}
+	var pk crypto.PublicKey
+	copy(pk[:], ms.PublicKey.Key)
+	var sig crypto.Signature
+	copy(sig[:], ms.Signature)
+	if crypto.VerifyHash(MessageSignatureHash(message), pk, sig) != nil {
+		return ErrInvalidMessageSignature
+	}
+	return nil
+}
+
 // SeedToString converts a wallet seed to a human friendly string.
 func SeedToString(seed Seed, did mnemonics.DictionaryID) (string, error) {
 	fullChecksum := crypto.HashObject(seed)
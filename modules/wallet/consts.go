@@ -15,9 +15,30 @@ const (
 	// defragThreshold is the number of outputs a wallet is allowed before it is
 	// defragmented.
 	defragThreshold = 50
+
+	// sweepBatchSize is the maximum number of outputs that are consolidated
+	// by a single dust sweep, which keeps the sweep transaction well below
+	// the transaction size limit.
+	sweepBatchSize = 100
+
+	// sweepInputSize is the estimated size in bytes of a signed siacoin input,
+	// used to compute the fee of a dust sweep.
+	sweepInputSize = 250
+
+	// sweepOverheadSize is the estimated size in bytes of a sweep transaction
+	// without any inputs.
+	sweepOverheadSize = 250
 )
 
 var (
+	// feeEstimationDepth is the number of recent blocks whose transaction fees
+	// are sampled by EstimateFee.
+	feeEstimationDepth = build.Select(build.Var{
+		Dev:      20,
+		Standard: 144,
+		Testing:  6,
+	}).(int)
+
 	// lookaheadBuffer together with lookaheadRescanThreshold defines the constant part
 	// of the maxLookahead
 	lookaheadBuffer = build.Select(build.Var{
@@ -23,6 +23,9 @@ var (
 	// bucketProcessedTxnIndex maps a ProcessedTransactions ID to it's
 	// autoincremented index in bucketProcessedTransactions
 	bucketProcessedTxnIndex = []byte("bucketProcessedTxnKey")
+	// bucketAddrLabels maps an UnlockHash to the label that the user gave
+	// it. Labels are purely informational.
+	bucketAddrLabels = []byte("bucketAddrLabels")
 	// bucketAddrTransactions maps an UnlockHash to the
 	// ProcessedTransactions that it appears in.
 	bucketAddrTransactions = []byte("bucketAddrTransactions")
@@ -42,15 +45,25 @@ var (
 	// bucketWallet contains various fields needed by the wallet, such as its
 	// UID, EncryptionVerification, and PrimarySeedFile.
 	bucketWallet = []byte("bucketWallet")
+	// bucketWatchedAddrs stores the set of watch-only addresses. The wallet
+	// tracks outputs sent to these addresses, but cannot spend them.
+	bucketWatchedAddrs = []byte("bucketWatchedAddrs")
+	// bucketWatchedSiacoinOutputs maps a SiacoinOutputID to its SiacoinOutput.
+	// Only outputs sent to watch-only addresses are stored. These outputs are
+	// never used to fund transactions.
+	bucketWatchedSiacoinOutputs = []byte("bucketWatchedSiacoinOutputs")
 
 	dbBuckets = [][]byte{
 		bucketProcessedTransactions,
 		bucketProcessedTxnIndex,
+		bucketAddrLabels,
 		bucketAddrTransactions,
 		bucketSiacoinOutputs,
 		bucketSiafundOutputs,
 		bucketSpentOutputs,
 		bucketWallet,
+		bucketWatchedAddrs,
+		bucketWatchedSiacoinOutputs,
 	}
 
 	errNoKey = errors.New("key does not exist")
@@ -64,6 +77,7 @@ var (
 	keyPrimarySeedProgress    = []byte("keyPrimarySeedProgress")
 	keySiafundPool            = []byte("keySiafundPool")
 	keySpendableKeyFiles      = []byte("keySpendableKeyFiles")
+	keyTimelockedKeys         = []byte("keyTimelockedKeys")
 	keyUID                    = []byte("keyUID")
 )
 
@@ -141,6 +155,7 @@ func dbReset(tx *bolt.Tx) error {
 	wb.Put(keyConsensusHeight, encoding.Marshal(uint64(0)))
 	wb.Put(keyAuxiliarySeedFiles, encoding.Marshal([]seedFile{}))
 	wb.Put(keySpendableKeyFiles, encoding.Marshal([]spendableKeyFile{}))
+	wb.Put(keyTimelockedKeys, encoding.Marshal([]timelockedKey{}))
 	dbPutConsensusHeight(tx, 0)
 	dbPutConsensusChangeID(tx, modules.ConsensusChangeBeginning)
 	dbPutSiafundPool(tx, types.ZeroCurrency)
@@ -221,6 +236,33 @@ func dbForEachSiafundOutput(tx *bolt.Tx, fn func(types.SiafundOutputID, types.Si
 	return dbForEach(tx.Bucket(bucketSiafundOutputs), fn)
 }
 
+func dbPutWatchedSiacoinOutput(tx *bolt.Tx, id types.SiacoinOutputID, output types.SiacoinOutput) error {
+	return dbPut(tx.Bucket(bucketWatchedSiacoinOutputs), id, output)
+}
+func dbDeleteWatchedSiacoinOutput(tx *bolt.Tx, id types.SiacoinOutputID) error {
+	return dbDelete(tx.Bucket(bucketWatchedSiacoinOutputs), id)
+}
+func dbForEachWatchedSiacoinOutput(tx *bolt.Tx, fn func(types.SiacoinOutputID, types.SiacoinOutput)) error {
+	return dbForEach(tx.Bucket(bucketWatchedSiacoinOutputs), fn)
+}
+
+func dbPutWatchedAddr(tx *bolt.Tx, addr types.UnlockHash) error {
+	return dbPut(tx.Bucket(bucketWatchedAddrs), addr, struct{}{})
+}
+func dbForEachWatchedAddr(tx *bolt.Tx, fn func(types.UnlockHash, struct{})) error {
+	return dbForEach(tx.Bucket(bucketWatchedAddrs), fn)
+}
+
+func dbPutAddrLabel(tx *bolt.Tx, addr types.UnlockHash, label string) error {
+	return dbPut(tx.Bucket(bucketAddrLabels), addr, label)
+}
+func dbDeleteAddrLabel(tx *bolt.Tx, addr types.UnlockHash) error {
+	return dbDelete(tx.Bucket(bucketAddrLabels), addr)
+}
+func dbForEachAddrLabel(tx *bolt.Tx, fn func(types.UnlockHash, string)) error {
+	return dbForEach(tx.Bucket(bucketAddrLabels), fn)
+}
+
 func dbPutSpentOutput(tx *bolt.Tx, id types.OutputID, height types.BlockHeight) error {
 	return dbPut(tx.Bucket(bucketSpentOutputs), id, height)
 }
@@ -429,6 +471,19 @@ func dbPutPrimarySeedProgress(tx *bolt.Tx, progress uint64) error {
 	return tx.Bucket(bucketWallet).Put(keyPrimarySeedProgress, encoding.Marshal(progress))
 }
 
+// dbGetTimelockedKeys returns the timelocked keys that have been generated
+// from the primary seed.
+func dbGetTimelockedKeys(tx *bolt.Tx) (tks []timelockedKey, err error) {
+	err = encoding.Unmarshal(tx.Bucket(bucketWallet).Get(keyTimelockedKeys), &tks)
+	return
+}
+
+// dbPutTimelockedKeys sets the timelocked keys that have been generated from
+// the primary seed.
+func dbPutTimelockedKeys(tx *bolt.Tx, tks []timelockedKey) error {
+	return tx.Bucket(bucketWallet).Put(keyTimelockedKeys, encoding.Marshal(tks))
+}
+
 // dbGetConsensusChangeID returns the ID of the last ConsensusChange processed by the wallet.
 func dbGetConsensusChangeID(tx *bolt.Tx) (cc modules.ConsensusChangeID) {
 	copy(cc[:], tx.Bucket(bucketWallet).Get(keyConsensusChange))
@@ -5,11 +5,20 @@ import (
 	"sort"
 
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
 var (
 	errDefragNotNeeded = errors.New("defragging not needed, wallet is already sufficiently defragged")
+
+	// errSweepNotNeeded is returned by SweepDust if there are fewer than two
+	// outputs below the threshold.
+	errSweepNotNeeded = errors.New("sweep not needed, wallet has fewer than two outputs below the threshold")
+
+	// errSweepUnprofitable is returned by SweepDust if the outputs below the
+	// threshold are worth less than the fee of consolidating them.
+	errSweepUnprofitable = errors.New("sweep not performed, the outputs below the threshold are worth less than the fee")
 )
 
 // managedCreateDefragTransaction creates a transaction that spends multiple existing
@@ -185,3 +194,114 @@ func (w *Wallet) threadedDefragWallet() {
 		w.log.Println("Wallet defrag: \t", txn.ID())
 	}
 }
+
+// managedCreateSweepTransaction creates a transaction that spends the wallet's
+// confirmed outputs below 'threshold' into a single output at a new wallet
+// address, paying 'feePerByte' for the estimated size of the transaction. The
+// number of consolidated outputs and the fee are returned.
+func (w *Wallet) managedCreateSweepTransaction(threshold, feePerByte types.Currency) (types.Transaction, uint64, types.Currency, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, 0, types.Currency{}, err
+	}
+
+	// Collect a value-sorted set of the spendable outputs below the
+	// threshold. Outputs that count as dust for regular transactions are
+	// included, as consolidating them is what makes them usable again.
+	var so sortedOutputs
+	err = dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if sco.Value.Cmp(threshold) < 0 && w.checkOutput(w.dbTx, consensusHeight, scoid, sco, types.ZeroCurrency) == nil {
+			so.ids = append(so.ids, scoid)
+			so.outputs = append(so.outputs, sco)
+		}
+	})
+	if err != nil {
+		return types.Transaction{}, 0, types.Currency{}, err
+	}
+	if len(so.ids) < 2 {
+		return types.Transaction{}, 0, types.Currency{}, errSweepNotNeeded
+	}
+
+	// If there are more outputs than fit into one sweep, sweep the largest
+	// ones, as they contribute the most value for the same fee.
+	sort.Sort(sort.Reverse(so))
+	if len(so.ids) > sweepBatchSize {
+		so.ids = so.ids[:sweepBatchSize]
+		so.outputs = so.outputs[:sweepBatchSize]
+	}
+	var amount types.Currency
+	var txn types.Transaction
+	for i, scoid := range so.ids {
+		txn.SiacoinInputs = append(txn.SiacoinInputs, types.SiacoinInput{
+			ParentID:         scoid,
+			UnlockConditions: w.keys[so.outputs[i].UnlockHash].UnlockConditions,
+		})
+		amount = amount.Add(so.outputs[i].Value)
+	}
+	fee := feePerByte.Mul64(sweepOverheadSize + sweepInputSize*uint64(len(so.ids)))
+	if amount.Cmp(fee) <= 0 {
+		return types.Transaction{}, 0, types.Currency{}, errSweepUnprofitable
+	}
+
+	// Send the outputs, minus the fee, to a new wallet address.
+	uc, err := w.nextPrimarySeedAddress(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, 0, types.Currency{}, err
+	}
+	txn.SiacoinOutputs = []types.SiacoinOutput{{
+		Value:      amount.Sub(fee),
+		UnlockHash: uc.UnlockHash(),
+	}}
+	txn.MinerFees = []types.Currency{fee}
+	for _, sci := range txn.SiacoinInputs {
+		addSignatures(&txn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), w.keys[sci.UnlockConditions.UnlockHash()])
+	}
+
+	// Mark all outputs that were spent as spent.
+	for _, scoid := range so.ids {
+		if err = dbPutSpentOutput(w.dbTx, types.OutputID(scoid), consensusHeight); err != nil {
+			return types.Transaction{}, 0, types.Currency{}, err
+		}
+	}
+	return txn, uint64(len(so.ids)), fee, nil
+}
+
+// SweepDust consolidates the wallet's confirmed siacoin outputs below
+// 'threshold' into a single output at a new wallet address, deducting the
+// transaction fee from the consolidated value. At most sweepBatchSize outputs
+// are consolidated per call. The number of consolidated outputs and the fee
+// are returned. The sweep is refused if the outputs are not worth more than
+// the fee.
+func (w *Wallet) SweepDust(threshold types.Currency) (outputs uint64, fee types.Currency, err error) {
+	if err := w.tg.Add(); err != nil {
+		return 0, types.Currency{}, err
+	}
+	defer w.tg.Done()
+	w.mu.RLock()
+	unlocked := w.unlocked
+	w.mu.RUnlock()
+	if !unlocked {
+		return 0, types.Currency{}, modules.ErrLockedWallet
+	}
+
+	_, feePerByte := w.tpool.FeeEstimation()
+	txn, outputs, fee, err := w.managedCreateSweepTransaction(threshold, feePerByte)
+	if err != nil {
+		return 0, types.Currency{}, err
+	}
+	err = w.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		// Release the outputs so that they can be spent again.
+		w.mu.Lock()
+		for _, sci := range txn.SiacoinInputs {
+			dbDeleteSpentOutput(w.dbTx, types.OutputID(sci.ParentID))
+		}
+		w.mu.Unlock()
+		return 0, types.Currency{}, err
+	}
+	w.log.Printf("Swept %v outputs into a single output, paying a fee of %v, ID: %v\n", outputs, fee.HumanString(), txn.ID())
+	return outputs, fee, nil
+}
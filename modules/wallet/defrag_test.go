@@ -119,6 +119,78 @@ func TestDefragWalletDust(t *testing.T) {
 	}
 }
 
+// TestSweepDust checks that SweepDust consolidates the outputs below the
+// threshold, and refuses to sweep outputs that are worth less than the fee.
+func TestSweepDust(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// sendOutputs creates n outputs of the given value at wallet addresses.
+	sendOutputs := func(n int, value types.Currency) {
+		uc, err := wt.wallet.NextAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tbuilder := wt.wallet.StartTransaction()
+		if err := tbuilder.FundSiacoins(value.Mul64(uint64(n))); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < n; i++ {
+			tbuilder.AddSiacoinOutput(types.SiacoinOutput{
+				Value:      value,
+				UnlockHash: uc.UnlockHash(),
+			})
+		}
+		txns, err := tbuilder.Sign(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := wt.tpool.AcceptTransactionSet(txns); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Outputs that are worth less than the fee should not be swept.
+	dustValue := types.NewCurrency64(10000)
+	sendOutputs(5, dustValue)
+	if _, _, err := wt.wallet.SweepDust(dustValue.Mul64(2)); err != errSweepUnprofitable {
+		t.Fatal("expected errSweepUnprofitable, got", err)
+	}
+
+	// Add outputs that are worth sweeping. All outputs below the threshold,
+	// including the dust, should be consolidated.
+	sendOutputs(5, types.SiacoinPrecision)
+	outputs, fee, err := wt.wallet.SweepDust(types.SiacoinPrecision.Mul64(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs != 10 {
+		t.Fatal("expected 10 outputs to be swept, got", outputs)
+	}
+	if fee.IsZero() || fee.Cmp(types.SiacoinPrecision.Mul64(5)) >= 0 {
+		t.Fatal("unexpected sweep fee:", fee)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The consolidated output is above the threshold, so there should be
+	// nothing left to sweep.
+	if _, _, err := wt.wallet.SweepDust(types.SiacoinPrecision.Mul64(2)); err != errSweepNotNeeded {
+		t.Fatal("expected errSweepNotNeeded, got", err)
+	}
+}
+
 // TestDefragOutputExhaustion verifies that sending transactions still succeeds
 // even when the defragger is under heavy stress.
 func TestDefragOutputExhaustion(t *testing.T) {
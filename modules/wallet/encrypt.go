@@ -109,6 +109,7 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 	var primarySeedProgress uint64
 	var auxiliarySeedFiles []seedFile
 	var unseededKeyFiles []spendableKeyFile
+	var timelockedKeys []timelockedKey
 	err := func() error {
 		w.mu.Lock()
 		defer w.mu.Unlock()
@@ -145,6 +146,12 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 			return err
 		}
 
+		// timelockedKeys
+		timelockedKeys, err = dbGetTimelockedKeys(w.dbTx)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	}()
 	if err != nil {
@@ -164,6 +171,7 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 		w.integrateSeed(primarySeed, primarySeedProgress)
 		w.primarySeed = primarySeed
 		w.regenerateLookahead(primarySeedProgress)
+		w.integrateTimelockedKeys(timelockedKeys)
 
 		// auxiliarySeedFiles
 		for _, sf := range auxiliarySeedFiles {
@@ -337,6 +345,8 @@ func (w *Wallet) Reset() error {
 	w.wipeSecrets()
 	w.keys = make(map[types.UnlockHash]spendableKey)
 	w.lookahead = make(map[types.UnlockHash]uint64)
+	w.watchedAddrs = make(map[types.UnlockHash]struct{})
+	w.addrLabels = make(map[types.UnlockHash]string)
 	w.seeds = []modules.Seed{}
 	w.unconfirmedProcessedTransactions = []modules.ProcessedTransaction{}
 	w.unlocked = false
@@ -0,0 +1,129 @@
+// +build dev testing
+
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// The faucet is only compiled when build.Release is "dev" or "testing", so
+// that it cannot be built into or invoked on the standard release.
+
+var (
+	// errFaucetFunds is returned if the faucet does not have enough
+	// confirmed, unspent coins to cover a request.
+	errFaucetFunds = errors.New("faucet does not have enough coins")
+
+	// faucetEntropy is the entropy of the faucet key. The key is well known,
+	// so any node on a test network can hand out the coins sent to the
+	// faucet.
+	faucetEntropy = crypto.HashObject(types.Specifier{'f', 'a', 'u', 'c', 'e', 't'})
+)
+
+// faucetKey returns the well-known spendable key of the faucet.
+func faucetKey() spendableKey {
+	sk, pk := crypto.GenerateKeyPairDeterministic(faucetEntropy)
+	return spendableKey{
+		UnlockConditions: types.UnlockConditions{
+			PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(pk)},
+			SignaturesRequired: 1,
+		},
+		SecretKeys: []crypto.SecretKey{sk},
+	}
+}
+
+// FaucetAddress returns the address of the faucet. Coins sent to this address
+// can be handed out to other addresses with FaucetSend.
+func FaucetAddress() types.UnlockHash {
+	return faucetKey().UnlockConditions.UnlockHash()
+}
+
+// FaucetSend creates a transaction that sends 'amount' from the faucet to
+// 'dest', and submits it to the transaction pool. The wallet finds the coins
+// of the faucet by watching its address, which triggers a rescan the first
+// time the faucet is used.
+func (w *Wallet) FaucetSend(amount types.Currency, dest types.UnlockHash) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, err
+	}
+	defer w.tg.Done()
+
+	w.mu.RLock()
+	unlocked := w.unlocked
+	w.mu.RUnlock()
+	if !unlocked {
+		return types.Transaction{}, modules.ErrLockedWallet
+	}
+	key := faucetKey()
+	faucetAddr := key.UnlockConditions.UnlockHash()
+	if err := w.WatchAddresses([]types.UnlockHash{faucetAddr}); err != nil {
+		return types.Transaction{}, err
+	}
+
+	_, feePerByte := w.tpool.FeeEstimation()
+	fee := feePerByte.Mul64(750) // Estimated transaction size in bytes
+	txn := types.Transaction{
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      amount,
+			UnlockHash: dest,
+		}},
+		MinerFees: []types.Currency{fee},
+	}
+
+	w.mu.Lock()
+	err := func() error {
+		height, err := dbGetConsensusHeight(w.dbTx)
+		if err != nil {
+			return err
+		}
+		var fund types.Currency
+		err = dbForEachWatchedSiacoinOutput(w.dbTx, func(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+			if sco.UnlockHash != faucetAddr || fund.Cmp(amount.Add(fee)) >= 0 {
+				return
+			}
+			if spendHeight, err := dbGetSpentOutput(w.dbTx, types.OutputID(id)); err == nil && spendHeight+RespendTimeout > height {
+				return
+			}
+			txn.SiacoinInputs = append(txn.SiacoinInputs, types.SiacoinInput{
+				ParentID:         id,
+				UnlockConditions: key.UnlockConditions,
+			})
+			fund = fund.Add(sco.Value)
+		})
+		if err != nil {
+			return err
+		}
+		if fund.Cmp(amount.Add(fee)) < 0 {
+			return errFaucetFunds
+		}
+		if change := fund.Sub(amount).Sub(fee); !change.IsZero() {
+			txn.SiacoinOutputs = append(txn.SiacoinOutputs, types.SiacoinOutput{
+				Value:      change,
+				UnlockHash: faucetAddr,
+			})
+		}
+		for _, sci := range txn.SiacoinInputs {
+			addSignatures(&txn, types.FullCoveredFields, key.UnlockConditions, crypto.Hash(sci.ParentID), key)
+			if err := dbPutSpentOutput(w.dbTx, types.OutputID(sci.ParentID), height); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	w.mu.Unlock()
+	if err != nil {
+		return types.Transaction{}, build.ExtendErr("unable to fund faucet transaction", err)
+	}
+
+	err = w.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		return types.Transaction{}, build.ExtendErr("unable to get transaction accepted", err)
+	}
+	w.log.Println("Submitted a faucet transaction for value", amount.HumanString(), "to", dest)
+	return txn, nil
+}
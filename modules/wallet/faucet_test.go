@@ -0,0 +1,56 @@
+// +build testing
+
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestFaucetSend checks that the faucet hands out the coins that were sent to
+// the faucet address, and refuses requests it cannot cover.
+func TestFaucetSend(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// The faucet starts out empty.
+	var dest types.UnlockHash
+	fastrand.Read(dest[:])
+	amount := types.SiacoinPrecision.Mul64(2)
+	if _, err := wt.wallet.FaucetSend(amount, dest); err == nil {
+		t.Fatal("empty faucet sent coins")
+	}
+
+	// Fund the faucet and request coins from it.
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), FaucetAddress()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	txn, err := wt.wallet.FaucetSend(amount, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	sco, exists := wt.cs.SiacoinOutput(txn.SiacoinOutputID(0))
+	if !exists || sco.UnlockHash != dest || !sco.Value.Equals(amount) {
+		t.Fatal("faucet coins did not arrive at the destination")
+	}
+
+	// The faucet cannot hand out more than it holds.
+	if _, err := wt.wallet.FaucetSend(types.SiacoinPrecision.Mul64(10), dest); err == nil {
+		t.Fatal("faucet sent more coins than it holds")
+	}
+}
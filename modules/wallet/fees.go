@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"sort"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// The percentiles of the recent fee distribution that are recommended for
+// each fee tier.
+const (
+	feePercentileLow    = 25
+	feePercentileMedium = 50
+	feePercentileHigh   = 90
+)
+
+// transactionFeeDensity returns the fee per byte paid by a transaction, and
+// false if the transaction does not pay any fees.
+func transactionFeeDensity(txn types.Transaction) (types.Currency, bool) {
+	if len(txn.MinerFees) == 0 {
+		return types.Currency{}, false
+	}
+	var fees types.Currency
+	for _, fee := range txn.MinerFees {
+		fees = fees.Add(fee)
+	}
+	if fees.IsZero() {
+		return types.Currency{}, false
+	}
+	return fees.Div64(uint64(len(encoding.Marshal(txn)))), true
+}
+
+// updateRecentBlockFees uses a consensus change to update the fee samples of
+// the most recent blocks.
+func (w *Wallet) updateRecentBlockFees(cc modules.ConsensusChange) {
+	for range cc.RevertedBlocks {
+		if len(w.recentBlockFees) > 0 {
+			w.recentBlockFees = w.recentBlockFees[:len(w.recentBlockFees)-1]
+		}
+	}
+
+	// Only the most recent blocks of the change can end up in the sample, so
+	// skip the rest. This keeps a rescan from the genesis block cheap.
+	applied := cc.AppliedBlocks
+	if len(applied) > feeEstimationDepth {
+		applied = applied[len(applied)-feeEstimationDepth:]
+	}
+	for _, block := range applied {
+		var fees []types.Currency
+		for _, txn := range block.Transactions {
+			if fee, ok := transactionFeeDensity(txn); ok {
+				fees = append(fees, fee)
+			}
+		}
+		w.recentBlockFees = append(w.recentBlockFees, fees)
+	}
+	if len(w.recentBlockFees) > feeEstimationDepth {
+		w.recentBlockFees = w.recentBlockFees[len(w.recentBlockFees)-feeEstimationDepth:]
+	}
+}
+
+// feePercentile returns the fee at percentile p of a sorted set of fees.
+func feePercentile(fees []types.Currency, p int) types.Currency {
+	return fees[(len(fees)-1)*p/100]
+}
+
+// EstimateFee returns low, medium and high fee recommendations based on the
+// fees paid in recently confirmed blocks. If no fees were paid recently, the
+// estimates of the transaction pool are used instead. The recommendations
+// never fall below the minimum fee of the transaction pool.
+func (w *Wallet) EstimateFee() modules.FeeEstimate {
+	// The transaction pool estimate has to be obtained separate from the lock.
+	minFee, maxFee := w.tpool.FeeEstimation()
+
+	w.mu.RLock()
+	var fees []types.Currency
+	for _, blockFees := range w.recentBlockFees {
+		fees = append(fees, blockFees...)
+	}
+	w.mu.RUnlock()
+
+	// Fall back to the transaction pool estimate during periods without any
+	// fee-paying transactions.
+	if len(fees) == 0 {
+		return modules.FeeEstimate{
+			Low:    minFee,
+			Medium: minFee.Add(maxFee).Div64(2),
+			High:   maxFee,
+		}
+	}
+
+	sort.Slice(fees, func(i, j int) bool {
+		return fees[i].Cmp(fees[j]) < 0
+	})
+	fe := modules.FeeEstimate{
+		Low:    feePercentile(fees, feePercentileLow),
+		Medium: feePercentile(fees, feePercentileMedium),
+		High:   feePercentile(fees, feePercentileHigh),
+	}
+	if fe.Low.Cmp(minFee) < 0 {
+		fe.Low = minFee
+	}
+	if fe.Medium.Cmp(fe.Low) < 0 {
+		fe.Medium = fe.Low
+	}
+	if fe.High.Cmp(fe.Medium) < 0 {
+		fe.High = fe.Medium
+	}
+	return fe
+}
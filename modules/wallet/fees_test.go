@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestEstimateFee checks that EstimateFee falls back to the transaction pool
+// estimate when no fees were paid recently, and that it follows the fees of
+// recently confirmed transactions otherwise.
+func TestEstimateFee(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// No fee-paying transactions have been confirmed yet, so the estimate
+	// should come from the transaction pool.
+	minFee, maxFee := wt.tpool.FeeEstimation()
+	fe := wt.wallet.EstimateFee()
+	if !fe.Low.Equals(minFee) || !fe.High.Equals(maxFee) {
+		t.Fatal("expected the transaction pool estimate to be used:", fe)
+	}
+	if fe.Low.IsZero() || fe.Medium.Cmp(fe.Low) < 0 || fe.High.Cmp(fe.Medium) < 0 {
+		t.Fatal("bad fee estimate:", fe)
+	}
+
+	// Confirm a transaction paying the high fee tier. Every sample now pays
+	// the same fee, so all tiers should match it.
+	txns, err := wt.wallet.SendSiacoinsWithFeeTier(types.SiacoinPrecision, types.UnlockHash{}, modules.FeeTierHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	expected, ok := transactionFeeDensity(txns[len(txns)-1])
+	if !ok {
+		t.Fatal("sent transaction did not pay a fee")
+	}
+	if expected.Cmp(minFee) < 0 {
+		expected = minFee
+	}
+	fe = wt.wallet.EstimateFee()
+	if !fe.Low.Equals(expected) || !fe.Medium.Equals(expected) || !fe.High.Equals(expected) {
+		t.Fatalf("expected all tiers to be %v, got %v", expected, fe)
+	}
+
+	// Once the block leaves the estimation window, the estimate should fall
+	// back to the transaction pool again.
+	for i := 0; i < feeEstimationDepth; i++ {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	minFee, maxFee = wt.tpool.FeeEstimation()
+	fe = wt.wallet.EstimateFee()
+	if !fe.Low.Equals(minFee) || !fe.High.Equals(maxFee) {
+		t.Fatal("expected the transaction pool estimate to be used:", fe)
+	}
+
+	// Unknown tiers should be rejected.
+	_, err = wt.wallet.SendSiacoinsWithFeeTier(types.SiacoinPrecision, types.UnlockHash{}, modules.FeeTier("urgent"))
+	if err != modules.ErrUnknownFeeTier {
+		t.Fatal("expected ErrUnknownFeeTier, got", err)
+	}
+}
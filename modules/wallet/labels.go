@@ -0,0 +1,103 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// maxLabelLength is the maximum length in bytes of an address label.
+	maxLabelLength = 256
+)
+
+var (
+	errLabelTooLong = errors.New("address label is too long")
+)
+
+// AddressLabels returns the labels of all labeled addresses, sorted in
+// byte-order of the address.
+func (w *Wallet) AddressLabels() []modules.AddressLabel {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	labels := make([]modules.AddressLabel, 0, len(w.addrLabels))
+	for addr, label := range w.addrLabels {
+		labels = append(labels, modules.AddressLabel{Address: addr, Label: label})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return bytes.Compare(labels[i].Address[:], labels[j].Address[:]) < 0
+	})
+	return labels
+}
+
+// SetAddressLabel associates a label with an address. The address does not
+// need to belong to the wallet, so that frequently used recipients can be
+// labeled as well. An empty label removes the label of the address.
+func (w *Wallet) SetAddressLabel(addr types.UnlockHash, label string) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+	if len(label) > maxLabelLength {
+		return errLabelTooLong
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if label == "" {
+		if err := dbDeleteAddrLabel(w.dbTx, addr); err != nil {
+			return err
+		}
+		delete(w.addrLabels, addr)
+	} else {
+		if err := dbPutAddrLabel(w.dbTx, addr, label); err != nil {
+			return err
+		}
+		w.addrLabels[addr] = label
+	}
+	return w.syncDB()
+}
+
+// labelsFor returns the labels of the provided addresses, skipping unlabeled
+// and duplicate addresses.
+func (w *Wallet) labelsFor(addrs []types.UnlockHash) []modules.AddressLabel {
+	var labels []modules.AddressLabel
+	seen := make(map[types.UnlockHash]struct{})
+	for _, addr := range addrs {
+		if _, exists := seen[addr]; exists {
+			continue
+		}
+		seen[addr] = struct{}{}
+		if label, exists := w.addrLabels[addr]; exists {
+			labels = append(labels, modules.AddressLabel{Address: addr, Label: label})
+		}
+	}
+	return labels
+}
+
+// transactionLabels returns the labels of the addresses involved in a
+// processed transaction.
+func (w *Wallet) transactionLabels(pt modules.ProcessedTransaction) []modules.AddressLabel {
+	var addrs []types.UnlockHash
+	for _, input := range pt.Inputs {
+		addrs = append(addrs, input.RelatedAddress)
+	}
+	for _, output := range pt.Outputs {
+		if output.FundType != types.SpecifierMinerFee {
+			addrs = append(addrs, output.RelatedAddress)
+		}
+	}
+	return w.labelsFor(addrs)
+}
+
+// LabelsForAddresses returns the labels of the provided addresses. Addresses
+// without a label are skipped.
+func (w *Wallet) LabelsForAddresses(addrs []types.UnlockHash) []modules.AddressLabel {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.labelsFor(addrs)
+}
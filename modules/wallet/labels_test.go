@@ -0,0 +1,90 @@
+package wallet
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestAddressLabels checks that address labels can be set, edited and
+// removed, that they are persisted, and that the history resolves them.
+func TestAddressLabels(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Label one of the wallet's own addresses and an external recipient.
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	own := uc.UnlockHash()
+	var recipient types.UnlockHash
+	fastrand.Read(recipient[:])
+	if err := wt.wallet.SetAddressLabel(own, "savings"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.SetAddressLabel(recipient, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.SetAddressLabel(recipient, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.SetAddressLabel(recipient, strings.Repeat("a", maxLabelLength+1)); err != errLabelTooLong {
+		t.Fatal("expected errLabelTooLong, got", err)
+	}
+	labels := wt.wallet.AddressLabels()
+	if len(labels) != 2 {
+		t.Fatal("expected 2 labels, got", len(labels))
+	}
+	for _, l := range labels {
+		if (l.Address == own && l.Label != "savings") || (l.Address == recipient && l.Label != "bob") {
+			t.Fatal("wrong label for address:", l.Label)
+		}
+	}
+
+	// The history should resolve the label of the recipient.
+	_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := wt.wallet.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, he := range history {
+		for _, l := range he.Labels {
+			found = found || (l.Address == recipient && l.Label == "bob")
+		}
+	}
+	if !found {
+		t.Fatal("history did not resolve the recipient label")
+	}
+
+	// Remove a label and restart the wallet. The remaining label should
+	// persist.
+	if err := wt.wallet.SetAddressLabel(own, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet, err = New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels = wt.wallet.AddressLabels()
+	if len(labels) != 1 || labels[0].Address != recipient || labels[0].Label != "bob" {
+		t.Fatal("labels were not persisted correctly:", labels)
+	}
+}
@@ -8,6 +8,20 @@ import (
 	"github.com/NebulousLabs/Sia/types"
 )
 
+var (
+	// errNoOutputs is returned by SendSiacoinsMulti if no outputs were
+	// provided.
+	errNoOutputs = errors.New("at least one output must be provided")
+
+	// errNoSiafundClaim is returned by ClaimSiafunds if the siafund outputs
+	// of the wallet have not accumulated any siacoins.
+	errNoSiafundClaim = errors.New("the siafunds of the wallet have nothing to claim")
+
+	// errClaimUnprofitable is returned by ClaimSiafunds if the claim is not
+	// worth more than the fee of the claim transaction.
+	errClaimUnprofitable = errors.New("claim not performed, the siafund claim is worth less than the fee")
+)
+
 // sortedOutputs is a struct containing a slice of siacoin outputs and their
 // corresponding ids. sortedOutputs can be sorted using the sort package.
 type sortedOutputs struct {
@@ -46,17 +60,46 @@ func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalan
 	}
 	dbForEachSiafundOutput(w.dbTx, func(_ types.SiafundOutputID, sfo types.SiafundOutput) {
 		siafundBalance = siafundBalance.Add(sfo.Value)
-		if sfo.ClaimStart.Cmp(siafundPool) > 0 {
-			// Skip claims larger than the siafund pool. This should only
-			// occur if the siafund pool has not been initialized yet.
-			w.log.Debugf("skipping claim with start value %v because siafund pool is only %v", sfo.ClaimStart, siafundPool)
-			return
-		}
-		siafundClaimBalance = siafundClaimBalance.Add(siafundPool.Sub(sfo.ClaimStart).Mul(sfo.Value).Div(types.SiafundCount))
+		siafundClaimBalance = siafundClaimBalance.Add(w.siafundClaim(sfo, siafundPool))
 	})
 	return
 }
 
+// siafundClaim returns the siacoins that a siafund output can claim from a
+// siafund pool of the given value.
+func (w *Wallet) siafundClaim(sfo types.SiafundOutput, siafundPool types.Currency) types.Currency {
+	if sfo.ClaimStart.Cmp(siafundPool) > 0 {
+		// Skip claims larger than the siafund pool. This should only
+		// occur if the siafund pool has not been initialized yet.
+		w.log.Debugf("skipping claim with start value %v because siafund pool is only %v", sfo.ClaimStart, siafundPool)
+		return types.ZeroCurrency
+	}
+	return siafundPool.Sub(sfo.ClaimStart).Mul(sfo.Value).Div(types.SiafundCount)
+}
+
+// SiafundOutputs returns the confirmed siafund outputs of the wallet and the
+// siacoins that each of them can claim from the current siafund pool.
+func (w *Wallet) SiafundOutputs() []modules.SiafundOutputClaim {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	siafundPool, err := dbGetSiafundPool(w.dbTx)
+	if err != nil {
+		return nil
+	}
+	var outputs []modules.SiafundOutputClaim
+	dbForEachSiafundOutput(w.dbTx, func(id types.SiafundOutputID, sfo types.SiafundOutput) {
+		outputs = append(outputs, modules.SiafundOutputClaim{
+			ID:         id,
+			UnlockHash: sfo.UnlockHash,
+			Value:      sfo.Value,
+			ClaimStart: sfo.ClaimStart,
+			Claim:      w.siafundClaim(sfo, siafundPool),
+		})
+	})
+	return outputs
+}
+
 // UnconfirmedBalance returns the number of outgoing and incoming siacoins in
 // the unconfirmed transaction set. Refund outputs are included in this
 // reporting.
@@ -90,6 +133,30 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) (txn
 	}
 	defer w.tg.Done()
 
+	_, feePerByte := w.tpool.FeeEstimation()
+	return w.managedSendSiacoins(amount, dest, feePerByte)
+}
+
+// SendSiacoinsWithFeeTier creates a transaction sending 'amount' to 'dest',
+// paying the fee that EstimateFee recommends for 'tier'. The transaction is
+// submitted to the transaction pool and is also returned.
+func (w *Wallet) SendSiacoinsWithFeeTier(amount types.Currency, dest types.UnlockHash, tier modules.FeeTier) (txns []types.Transaction, err error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+
+	feePerByte, err := w.EstimateFee().Tier(tier)
+	if err != nil {
+		return nil, err
+	}
+	return w.managedSendSiacoins(amount, dest, feePerByte)
+}
+
+// managedSendSiacoins creates a transaction sending 'amount' to 'dest' with a
+// fee of 'feePerByte' hastings per byte, and submits it to the transaction
+// pool.
+func (w *Wallet) managedSendSiacoins(amount types.Currency, dest types.UnlockHash, feePerByte types.Currency) (txns []types.Transaction, err error) {
 	w.mu.RLock()
 	unlocked := w.unlocked
 	w.mu.RUnlock()
@@ -98,8 +165,7 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) (txn
 		return nil, modules.ErrLockedWallet
 	}
 
-	_, tpoolFee := w.tpool.FeeEstimation()
-	tpoolFee = tpoolFee.Mul64(750) // Estimated transaction size in bytes
+	tpoolFee := feePerByte.Mul64(750) // Estimated transaction size in bytes
 	output := types.SiacoinOutput{
 		Value:      amount,
 		UnlockHash: dest,
@@ -154,6 +220,9 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) (txns []types.
 		w.log.Println("Attempt to send coins has failed - wallet is locked")
 		return nil, modules.ErrLockedWallet
 	}
+	if len(outputs) == 0 {
+		return nil, errNoOutputs
+	}
 
 	txnBuilder := w.StartTransaction()
 	defer func() {
@@ -258,6 +327,76 @@ func (w *Wallet) SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]t
 	return txnSet, nil
 }
 
+// ClaimSiafunds spends all of the confirmed siafund outputs of the wallet to a
+// new wallet address, which pays out the siacoins that the outputs have
+// accumulated from the siafund pool to the wallet. Consensus computes the
+// claims from the value of the siafund pool at the height where the
+// transaction is confirmed, so the returned claim is an estimate based on the
+// current pool. The claimed siacoins become spendable once they mature. The
+// claim is refused if it is not worth more than the transaction fee, which is
+// paid from the siacoin balance of the wallet.
+func (w *Wallet) ClaimSiafunds() (types.Currency, []types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Currency{}, nil, err
+	}
+	defer w.tg.Done()
+	w.mu.RLock()
+	unlocked := w.unlocked
+	w.mu.RUnlock()
+	if !unlocked {
+		return types.Currency{}, nil, modules.ErrLockedWallet
+	}
+
+	_, siafunds, claim := w.ConfirmedBalance()
+	if siafunds.IsZero() || claim.IsZero() {
+		return types.Currency{}, nil, errNoSiafundClaim
+	}
+	_, tpoolFee := w.tpool.FeeEstimation()
+	tpoolFee = tpoolFee.Mul64(750) // Estimated transaction size in bytes
+	tpoolFee = tpoolFee.Mul64(5)   // use large fee to ensure siafund transactions are selected by miners
+	if claim.Cmp(tpoolFee) <= 0 {
+		return types.Currency{}, nil, errClaimUnprofitable
+	}
+	dest, err := w.NextAddress()
+	if err != nil {
+		return types.Currency{}, nil, err
+	}
+
+	// Funding the siafunds spends the wallet's outputs into an intermediate
+	// output, which is where the claims are paid out.
+	txnBuilder := w.StartTransaction()
+	err = txnBuilder.FundSiacoins(tpoolFee)
+	if err != nil {
+		txnBuilder.Drop()
+		return types.Currency{}, nil, err
+	}
+	err = txnBuilder.FundSiafunds(siafunds)
+	if err != nil {
+		txnBuilder.Drop()
+		return types.Currency{}, nil, err
+	}
+	txnBuilder.AddMinerFee(tpoolFee)
+	txnBuilder.AddSiafundOutput(types.SiafundOutput{
+		Value:      siafunds,
+		UnlockHash: dest.UnlockHash(),
+	})
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		txnBuilder.Drop()
+		return types.Currency{}, nil, err
+	}
+	err = w.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		txnBuilder.Drop()
+		return types.Currency{}, nil, err
+	}
+	w.log.Println("Submitted a siafund claim transaction set for an estimated claim of", claim.HumanString(), "with fees", tpoolFee.HumanString(), "IDs:")
+	for _, txn := range txnSet {
+		w.log.Println("\t", txn.ID())
+	}
+	return claim, txnSet, nil
+}
+
 // Len returns the number of elements in the sortedOutputs struct.
 func (so sortedOutputs) Len() int {
 	if build.DEBUG && len(so.ids) != len(so.outputs) {
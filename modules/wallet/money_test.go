@@ -257,3 +257,124 @@ func TestSendSiacoinsAcceptTxnSetFailed(t *testing.T) {
 		t.Fatalf("SendSiacoins failed: %v", err)
 	}
 }
+
+// TestSendSiacoinsMultiLowBalance checks that SendSiacoinsMulti rejects an
+// empty set of outputs, and that a send which cannot be funded leaves no
+// outputs marked as spent.
+func TestSendSiacoinsMultiLowBalance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if _, err := wt.wallet.SendSiacoinsMulti(nil); err != errNoOutputs {
+		t.Fatal("expected errNoOutputs, got", err)
+	}
+
+	// Send slightly more than the wallet holds across two outputs.
+	balance, _, _ := wt.wallet.ConfirmedBalance()
+	scos := []types.SiacoinOutput{
+		{Value: balance.Div64(2), UnlockHash: types.UnlockHash{1}},
+		{Value: balance.Div64(2).Add(types.SiacoinPrecision), UnlockHash: types.UnlockHash{2}},
+	}
+	if _, err := wt.wallet.SendSiacoinsMulti(scos); err == nil {
+		t.Fatal("SendSiacoinsMulti should have failed due to a low balance")
+	}
+	wt.wallet.mu.Lock()
+	wt.wallet.syncDB()
+	spent := wt.wallet.dbTx.Bucket(bucketSpentOutputs).Stats().KeyN
+	wt.wallet.mu.Unlock()
+	if spent != 0 {
+		t.Fatal("failed send left outputs marked as spent:", spent)
+	}
+	if bal, _, _ := wt.wallet.ConfirmedBalance(); !bal.Equals(balance) {
+		t.Fatal("failed send changed the confirmed balance")
+	}
+}
+
+// TestIntegrationClaimSiafunds checks that the wallet reports the claims of
+// its siafund outputs and that ClaimSiafunds pays them out.
+func TestIntegrationClaimSiafunds(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	err = wt.wallet.LoadSiagKeys(wt.walletMasterKey, []string{"../../types/siag0of1of1.siakey"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := wt.wallet.ClaimSiafunds(); err != errNoSiafundClaim {
+		t.Fatal("expected errNoSiafundClaim, got", err)
+	}
+
+	// Grow the siafund pool by forming a file contract.
+	height := wt.cs.Height()
+	payout := types.SiacoinPrecision.Mul64(100e3)
+	fc := types.FileContract{
+		WindowStart:        height + 10,
+		WindowEnd:          height + 20,
+		Payout:             payout,
+		ValidProofOutputs:  []types.SiacoinOutput{{Value: types.PostTax(height, payout)}},
+		MissedProofOutputs: []types.SiacoinOutput{{Value: types.PostTax(height, payout)}},
+	}
+	builder := wt.wallet.StartTransaction()
+	err = builder.FundSiacoins(payout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.AddFileContract(fc)
+	txnSet, err := builder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The claims of the outputs add up to the claim balance.
+	_, siafunds, claimBalance := wt.wallet.ConfirmedBalance()
+	if claimBalance.IsZero() {
+		t.Fatal("siafunds did not accumulate a claim")
+	}
+	var value, claims types.Currency
+	for _, sfo := range wt.wallet.SiafundOutputs() {
+		value = value.Add(sfo.Value)
+		claims = claims.Add(sfo.Claim)
+	}
+	if !value.Equals(siafunds) || !claims.Equals(claimBalance) {
+		t.Fatalf("outputs don't match the balance: %v/%v SF, %v/%v H", value, siafunds, claims, claimBalance)
+	}
+
+	claim, _, err := wt.wallet.ClaimSiafunds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claim.Equals(claimBalance) {
+		t.Fatal("expected a claim of", claimBalance, "got", claim)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, newSiafunds, newClaimBalance := wt.wallet.ConfirmedBalance()
+	if !newSiafunds.Equals(siafunds) {
+		t.Fatal("claiming changed the siafund balance:", newSiafunds)
+	}
+	if !newClaimBalance.IsZero() {
+		t.Fatal("claim balance was not paid out:", newClaimBalance)
+	}
+}
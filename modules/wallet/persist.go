@@ -70,6 +70,9 @@ func (w *Wallet) openDB(filename string) (err error) {
 		if wb.Get(keySpendableKeyFiles) == nil {
 			wb.Put(keySpendableKeyFiles, encoding.Marshal([]spendableKeyFile{}))
 		}
+		if wb.Get(keyTimelockedKeys) == nil {
+			wb.Put(keyTimelockedKeys, encoding.Marshal([]timelockedKey{}))
+		}
 		if wb.Get(keySiafundPool) == nil {
 			wb.Put(keySiafundPool, encoding.Marshal(types.ZeroCurrency))
 		}
@@ -29,6 +29,14 @@ type (
 		EncryptionVerification crypto.Ciphertext
 		Seed                   crypto.Ciphertext
 	}
+
+	// timelockedKey records a primary seed key that the wallet handed out
+	// with a timelock. Only the index and the timelock are stored; the key
+	// itself is regenerated from the primary seed when the wallet unlocks.
+	timelockedKey struct {
+		Index    uint64
+		Timelock types.BlockHeight
+	}
 )
 
 // generateSpendableKey creates the keys and unlock conditions for seed at a
@@ -151,6 +159,40 @@ func (w *Wallet) nextPrimarySeedAddress(tx *bolt.Tx) (types.UnlockConditions, er
 	return ucs[0], nil
 }
 
+// integrateTimelockedKeys regenerates the timelocked keys from the primary
+// seed and loads them into the wallet.
+func (w *Wallet) integrateTimelockedKeys(tks []timelockedKey) {
+	for _, tk := range tks {
+		sk := generateSpendableKey(w.primarySeed, tk.Index)
+		sk.UnlockConditions.Timelock = tk.Timelock
+		w.keys[sk.UnlockConditions.UnlockHash()] = sk
+	}
+}
+
+// nextTimelockedAddress consumes the next key of the primary seed and returns
+// unlock conditions for it that cannot be spent before 'timelock'.
+func (w *Wallet) nextTimelockedAddress(tx *bolt.Tx, timelock types.BlockHeight) (types.UnlockConditions, error) {
+	index, err := dbGetPrimarySeedProgress(tx)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	uc, err := w.nextPrimarySeedAddress(tx)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	tks, err := dbGetTimelockedKeys(tx)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	tk := timelockedKey{Index: index, Timelock: timelock}
+	if err := dbPutTimelockedKeys(tx, append(tks, tk)); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	w.integrateTimelockedKeys([]timelockedKey{tk})
+	uc.Timelock = timelock
+	return uc, nil
+}
+
 // AllSeeds returns a list of all seeds known to and used by the wallet.
 func (w *Wallet) AllSeeds() ([]modules.Seed, error) {
 	w.mu.Lock()
@@ -219,6 +261,23 @@ func (w *Wallet) NextAddress() (types.UnlockConditions, error) {
 	return ucs[0], nil
 }
 
+// NextTimelockedAddress returns unlock conditions that are ready to receive
+// siacoins which cannot be spent until the blockchain reaches 'timelock'. The
+// key is generated using the primary address seed, and the wallet tracks the
+// timelocked address alongside it.
+func (w *Wallet) NextTimelockedAddress(timelock types.BlockHeight) (types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	uc, err := w.nextTimelockedAddress(w.dbTx, timelock)
+	w.syncDB() // ensure durability of reported address
+	w.mu.Unlock()
+	return uc, err
+}
+
 // LoadSeed will track all of the addresses generated by the input seed,
 // reclaiming any funds that were lost due to a deleted file or lost encryption
 // key. An error will be returned if the seed has already been integrated with
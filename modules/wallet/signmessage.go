@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errUnknownAddress     = errors.New("address does not belong to the wallet")
+	errNonStandardAddress = errors.New("only addresses with a single public key and no timelock can sign messages")
+)
+
+// SignMessage signs an arbitrary message with the key of an address owned by
+// the wallet. The message is hashed together with
+// modules.SpecifierSignedMessage before it is signed, so the signature can
+// never be used as a transaction signature.
+func (w *Wallet) SignMessage(addr types.UnlockHash, message []byte) (modules.MessageSignature, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.MessageSignature{}, err
+	}
+	defer w.tg.Done()
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.unlocked {
+		return modules.MessageSignature{}, modules.ErrLockedWallet
+	}
+	sk, exists := w.keys[addr]
+	if !exists {
+		return modules.MessageSignature{}, errUnknownAddress
+	}
+	uc := sk.UnlockConditions
+	if uc.Timelock != 0 || uc.SignaturesRequired != 1 || len(uc.PublicKeys) != 1 || len(sk.SecretKeys) != 1 || uc.PublicKeys[0].Algorithm != types.SignatureEd25519 {
+		return modules.MessageSignature{}, errNonStandardAddress
+	}
+
+	sig := crypto.SignHash(modules.MessageSignatureHash(message), sk.SecretKeys[0])
+	return modules.MessageSignature{
+		Address:   addr,
+		PublicKey: uc.PublicKeys[0],
+		Signature: sig[:],
+	}, nil
+}
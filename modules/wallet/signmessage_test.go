@@ -0,0 +1,76 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestSignMessage checks that the wallet signs messages with the keys of its
+// addresses, and that the signatures only verify for the signed message and
+// address.
+func TestSignMessage(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("I own this address")
+	ms, err := wt.wallet.SignMessage(uc.UnlockHash(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := modules.VerifyMessageSignature(message, ms); err != nil {
+		t.Fatal(err)
+	}
+
+	// The signature is only valid for the signed message and address.
+	if err := modules.VerifyMessageSignature([]byte("I own this address!"), ms); err != modules.ErrInvalidMessageSignature {
+		t.Fatal("expected ErrInvalidMessageSignature, got", err)
+	}
+	uc2, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := ms
+	forged.Address = uc2.UnlockHash()
+	if err := modules.VerifyMessageSignature(message, forged); err != modules.ErrInvalidMessageSignature {
+		t.Fatal("expected ErrInvalidMessageSignature, got", err)
+	}
+
+	// A message signature is not a signature of the raw hash of the message,
+	// so it can't be passed off as a transaction signature.
+	var sig crypto.Signature
+	copy(sig[:], ms.Signature)
+	var pk crypto.PublicKey
+	copy(pk[:], ms.PublicKey.Key)
+	if crypto.VerifyHash(crypto.HashBytes(message), pk, sig) == nil {
+		t.Fatal("message signature verified against the raw message hash")
+	}
+
+	// Addresses that don't belong to the wallet can't sign.
+	var unknown types.UnlockHash
+	fastrand.Read(unknown[:])
+	if _, err := wt.wallet.SignMessage(unknown, message); err != errUnknownAddress {
+		t.Fatal("expected errUnknownAddress, got", err)
+	}
+
+	// A locked wallet can't sign.
+	if err := wt.wallet.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SignMessage(uc.UnlockHash(), message); err != modules.ErrLockedWallet {
+		t.Fatal("expected ErrLockedWallet, got", err)
+	}
+}
@@ -19,12 +19,24 @@ var (
 	// meaning that future calls to Sign will result in an invalid transaction.
 	errBuilderAlreadySigned = errors.New("sign has already been called on this transaction builder, multiple calls can cause issues")
 
+	// errDuplicateOutput indicates that the same output was selected more than
+	// once.
+	errDuplicateOutput = errors.New("output was selected more than once")
+
 	// errDustOutput indicates an output is not spendable because it is dust.
 	errDustOutput = errors.New("output is too small")
 
+	// errFundsTimelocked indicates that the wallet only has enough siacoins
+	// if outputs whose timelock has not expired yet are included.
+	errFundsTimelocked = errors.New("wallet has coins in timelocked outputs that cannot be spent until their timelock height")
+
 	// errOutputTimelock indicates an output's timelock is still active.
 	errOutputTimelock = errors.New("wallet consensus set height is lower than the output timelock")
 
+	// errUnknownOutput indicates that a selected output is not owned by the
+	// wallet, or has already been spent.
+	errUnknownOutput = errors.New("output is not owned by the wallet or has already been spent")
+
 	// errSpendHeightTooHigh indicates an output's spend height is greater than
 	// the allowed height.
 	errSpendHeightTooHigh = errors.New("output spend height exceeds the allowed height")
@@ -159,8 +171,10 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 	// provide the user with a more useful error message in the event that they
 	// are overspending.
 	var potentialFund types.Currency
+	// timelockedFund tracks the value of outputs that cannot be spent yet
+	// because their timelock has not expired.
+	var timelockedFund types.Currency
 	parentTxn := types.Transaction{}
-	var spentScoids []types.SiacoinOutputID
 	for i := range so.ids {
 		scoid := so.ids[i]
 		sco := so.outputs[i]
@@ -168,6 +182,8 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		if err := tb.wallet.checkOutput(tb.wallet.dbTx, consensusHeight, scoid, sco, dustThreshold); err != nil {
 			if err == errSpendHeightTooHigh {
 				potentialFund = potentialFund.Add(sco.Value)
+			} else if err == errOutputTimelock {
+				timelockedFund = timelockedFund.Add(sco.Value)
 			}
 			continue
 		}
@@ -178,7 +194,6 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 			UnlockConditions: tb.wallet.keys[sco.UnlockHash].UnlockConditions,
 		}
 		parentTxn.SiacoinInputs = append(parentTxn.SiacoinInputs, sci)
-		spentScoids = append(spentScoids, scoid)
 
 		// Add the output to the total fund
 		fund = fund.Add(sco.Value)
@@ -190,10 +205,85 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
 		return modules.ErrIncompleteTransactions
 	}
+	if potentialFund.Add(timelockedFund).Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
+		return errFundsTimelocked
+	}
+	if fund.Cmp(amount) < 0 {
+		return modules.ErrLowBalance
+	}
+	return tb.addSiacoinParent(parentTxn, amount, fund, consensusHeight)
+}
+
+// FundSiacoinsFromOutputs will add a siacoin input of exactly 'amount' to the
+// transaction, funded by exactly the provided wallet outputs. Every output
+// must be owned by the wallet and spendable, and together they must cover
+// 'amount'. Any excess is refunded to a new wallet address. As with
+// FundSiacoins, the siacoin input will not be signed until 'Sign' is called
+// on the transaction builder.
+func (tb *transactionBuilder) FundSiacoinsFromOutputs(amount types.Currency, ids []types.SiacoinOutputID) error {
+	// dustThreshold has to be obtained separate from the lock
+	dustThreshold := tb.wallet.DustThreshold()
+
+	tb.wallet.mu.Lock()
+	defer tb.wallet.mu.Unlock()
+
+	consensusHeight, err := dbGetConsensusHeight(tb.wallet.dbTx)
+	if err != nil {
+		return err
+	}
+
+	// Collect the unconfirmed outputs that belong to the wallet, so that
+	// they can be selected as well.
+	unconfirmed := make(map[types.SiacoinOutputID]types.SiacoinOutput)
+	for _, upt := range tb.wallet.unconfirmedProcessedTransactions {
+		for i, sco := range upt.Transaction.SiacoinOutputs {
+			if _, exists := tb.wallet.keys[sco.UnlockHash]; exists {
+				unconfirmed[upt.Transaction.SiacoinOutputID(uint64(i))] = sco
+			}
+		}
+	}
+
+	var fund types.Currency
+	parentTxn := types.Transaction{}
+	selected := make(map[types.SiacoinOutputID]struct{})
+	for _, scoid := range ids {
+		if _, exists := selected[scoid]; exists {
+			return errDuplicateOutput
+		}
+		selected[scoid] = struct{}{}
+
+		sco, err := dbGetSiacoinOutput(tb.wallet.dbTx, scoid)
+		if err == errNoKey {
+			var exists bool
+			sco, exists = unconfirmed[scoid]
+			if !exists {
+				return errUnknownOutput
+			}
+		} else if err != nil {
+			return err
+		}
+		if err := tb.wallet.checkOutput(tb.wallet.dbTx, consensusHeight, scoid, sco, dustThreshold); err != nil {
+			return err
+		}
+
+		parentTxn.SiacoinInputs = append(parentTxn.SiacoinInputs, types.SiacoinInput{
+			ParentID:         scoid,
+			UnlockConditions: tb.wallet.keys[sco.UnlockHash].UnlockConditions,
+		})
+		fund = fund.Add(sco.Value)
+	}
 	if fund.Cmp(amount) < 0 {
 		return modules.ErrLowBalance
 	}
+	return tb.addSiacoinParent(parentTxn, amount, fund, consensusHeight)
+}
 
+// addSiacoinParent completes a parent transaction that spends 'fund' siacoins
+// into an output of exactly 'amount', refunding the rest to the wallet. The
+// parent is added to the transaction builder, and its exact output is added as
+// an input to the transaction. All outputs spent by the parent are marked as
+// spent.
+func (tb *transactionBuilder) addSiacoinParent(parentTxn types.Transaction, amount, fund types.Currency, consensusHeight types.BlockHeight) error {
 	// Create and add the output that will be used to fund the standard
 	// transaction.
 	parentUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
@@ -242,8 +332,8 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 	tb.transaction.SiacoinInputs = append(tb.transaction.SiacoinInputs, newInput)
 
 	// Mark all outputs that were spent as spent.
-	for _, scoid := range spentScoids {
-		err = dbPutSpentOutput(tb.wallet.dbTx, types.OutputID(scoid), consensusHeight)
+	for _, sci := range parentTxn.SiacoinInputs {
+		err = dbPutSpentOutput(tb.wallet.dbTx, types.OutputID(sci.ParentID), consensusHeight)
 		if err != nil {
 			return err
 		}
@@ -446,6 +536,23 @@ func (tb *transactionBuilder) AddSiacoinOutput(output types.SiacoinOutput) uint6
 	return uint64(len(tb.transaction.SiacoinOutputs) - 1)
 }
 
+// AddTimelockedSiacoinOutput adds a siacoin output to the transaction that can
+// only be spent by 'pk' once the blockchain has reached 'timelock'. The index
+// of the siacoin output within the transaction is returned, along with the
+// unlock conditions that the recipient needs to spend the output.
+func (tb *transactionBuilder) AddTimelockedSiacoinOutput(value types.Currency, pk types.SiaPublicKey, timelock types.BlockHeight) (uint64, types.UnlockConditions) {
+	uc := types.UnlockConditions{
+		Timelock:           timelock,
+		PublicKeys:         []types.SiaPublicKey{pk},
+		SignaturesRequired: 1,
+	}
+	index := tb.AddSiacoinOutput(types.SiacoinOutput{
+		Value:      value,
+		UnlockHash: uc.UnlockHash(),
+	})
+	return index, uc
+}
+
 // AddFileContract adds a file contract to the transaction, returning the index
 // of the file contract within the transaction.
 func (tb *transactionBuilder) AddFileContract(fc types.FileContract) uint64 {
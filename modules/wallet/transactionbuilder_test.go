@@ -499,3 +499,169 @@ func TestUnconfirmedParents(t *testing.T) {
 		}
 	}
 }
+
+// TestFundSiacoinsFromOutputs checks that a transaction can be funded with an
+// explicit set of wallet outputs, and that invalid selections are rejected.
+func TestFundSiacoinsFromOutputs(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Mine a few more blocks so that the wallet has several outputs.
+	for i := 0; i < 3; i++ {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wt.wallet.mu.Lock()
+	var ids []types.SiacoinOutputID
+	var outputs []types.SiacoinOutput
+	dbForEachSiacoinOutput(wt.wallet.dbTx, func(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+		ids = append(ids, id)
+		outputs = append(outputs, sco)
+	})
+	wt.wallet.mu.Unlock()
+	if len(ids) < 2 {
+		t.Fatal("wallet should have at least two outputs, has", len(ids))
+	}
+
+	// Selecting an unknown output, or the same output twice, should fail.
+	b := wt.wallet.StartTransaction()
+	if err := b.FundSiacoinsFromOutputs(types.NewCurrency64(1), []types.SiacoinOutputID{{1}}); err != errUnknownOutput {
+		t.Fatal("expected errUnknownOutput, got", err)
+	}
+	if err := b.FundSiacoinsFromOutputs(types.NewCurrency64(1), []types.SiacoinOutputID{ids[0], ids[0]}); err != errDuplicateOutput {
+		t.Fatal("expected errDuplicateOutput, got", err)
+	}
+	// Requesting more than the selected outputs hold should fail.
+	if err := b.FundSiacoinsFromOutputs(outputs[0].Value.Add(types.NewCurrency64(1)), ids[:1]); err != modules.ErrLowBalance {
+		t.Fatal("expected ErrLowBalance, got", err)
+	}
+
+	// Fund a transaction using only the second output.
+	fee := types.SiacoinPrecision
+	if err := b.FundSiacoinsFromOutputs(fee, ids[1:2]); err != nil {
+		t.Fatal(err)
+	}
+	b.AddMinerFee(fee)
+	txnSet, err := b.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := txnSet[0]
+	if len(parent.SiacoinInputs) != 1 || parent.SiacoinInputs[0].ParentID != ids[1] {
+		t.Fatal("parent transaction did not spend exactly the selected output")
+	}
+	if len(parent.SiacoinOutputs) != 2 || !parent.SiacoinOutputs[1].Value.Equals(outputs[1].Value.Sub(fee)) {
+		t.Fatal("parent transaction did not refund the excess")
+	}
+	if err := wt.tpool.AcceptTransactionSet(txnSet); err != nil {
+		t.Fatal(err)
+	}
+
+	// The selected output has been spent, so it cannot be selected again.
+	b = wt.wallet.StartTransaction()
+	if err := b.FundSiacoinsFromOutputs(fee, ids[1:2]); err != errSpendHeightTooHigh {
+		t.Fatal("expected errSpendHeightTooHigh, got", err)
+	}
+	b.Drop()
+}
+
+// TestTimelockedSiacoinOutput checks that the transaction builder can create
+// timelocked outputs, that the wallet reports when timelocked outputs it
+// receives will mature, and that they can only be spent once they have.
+func TestTimelockedSiacoinOutput(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Have the wallet generate a timelocked address for itself.
+	timelock := wt.cs.Height() + 10
+	tluc, err := wt.wallet.NextTimelockedAddress(timelock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tluc.Timelock != timelock {
+		t.Fatal("wrong timelock in unlock conditions:", tluc.Timelock)
+	}
+
+	// Send coins to the timelocked address.
+	value := types.SiacoinPrecision.Mul64(10)
+	b := wt.wallet.StartTransaction()
+	if err := b.FundSiacoins(value); err != nil {
+		t.Fatal(err)
+	}
+	index, uc := b.AddTimelockedSiacoinOutput(value, tluc.PublicKeys[0], timelock)
+	if uc.UnlockHash() != tluc.UnlockHash() {
+		t.Fatal("timelocked output has the wrong unlock conditions")
+	}
+	txnSet, err := b.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet(txnSet); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	scoid := txnSet[len(txnSet)-1].SiacoinOutputID(index)
+
+	// The wallet should still track the address after being locked and
+	// unlocked again.
+	if err := wt.wallet.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// The wallet should report the output as maturing at the timelock height.
+	pt, exists := wt.wallet.Transaction(txnSet[len(txnSet)-1].ID())
+	if !exists {
+		t.Fatal("wallet did not record the transaction")
+	}
+	if po := pt.Outputs[index]; po.ID != types.OutputID(scoid) || !po.WalletAddress || po.MaturityHeight != timelock {
+		t.Fatalf("timelocked output was not reported correctly: %+v", po)
+	}
+
+	// Spending the output before the timelock expires should fail.
+	b = wt.wallet.StartTransaction()
+	if err := b.FundSiacoinsFromOutputs(value, []types.SiacoinOutputID{scoid}); err != errOutputTimelock {
+		t.Fatal("expected errOutputTimelock, got", err)
+	}
+	balance, _, _ := wt.wallet.ConfirmedBalance()
+	if err := b.FundSiacoins(balance); err != errFundsTimelocked {
+		t.Fatal("expected errFundsTimelocked, got", err)
+	}
+	b.Drop()
+
+	// Once the timelock has expired the output should be spendable.
+	for wt.cs.Height() < timelock {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b = wt.wallet.StartTransaction()
+	if err := b.FundSiacoinsFromOutputs(value, []types.SiacoinOutputID{scoid}); err != nil {
+		t.Fatal(err)
+	}
+	b.AddSiacoinOutput(types.SiacoinOutput{Value: value, UnlockHash: types.UnlockHash{}})
+	txnSet, err = b.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet(txnSet); err != nil {
+		t.Fatal(err)
+	}
+}
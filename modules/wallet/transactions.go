@@ -4,6 +4,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"sort"
 
 	"github.com/NebulousLabs/Sia/build"
@@ -66,6 +68,85 @@ func (w *Wallet) AddressUnconfirmedTransactions(uh types.UnlockHash) (pts []modu
 	return pts
 }
 
+// historyEntry categorizes a processed transaction and sums up how it changed
+// the siacoin balance of the wallet.
+func historyEntry(pt modules.ProcessedTransaction, confirmed bool) modules.HistoryEntry {
+	he := modules.HistoryEntry{
+		TransactionID:         pt.TransactionID,
+		Confirmed:             confirmed,
+		ConfirmationHeight:    pt.ConfirmationHeight,
+		ConfirmationTimestamp: pt.ConfirmationTimestamp,
+	}
+	for _, input := range pt.Inputs {
+		if input.FundType == types.SpecifierSiacoinInput && input.WalletAddress {
+			he.Outgoing = he.Outgoing.Add(input.Value)
+		}
+	}
+	var minerPayout, proofOutput bool
+	for _, output := range pt.Outputs {
+		if !output.WalletAddress {
+			continue
+		}
+		switch output.FundType {
+		case types.SpecifierMinerPayout:
+			minerPayout = true
+		case types.SpecifierStorageProofOutput:
+			proofOutput = true
+		case types.SpecifierSiacoinOutput:
+		default:
+			continue
+		}
+		he.Incoming = he.Incoming.Add(output.Value)
+	}
+	he.NetValue = new(big.Int).Sub(he.Incoming.Big(), he.Outgoing.Big()).String()
+
+	txn := pt.Transaction
+	switch {
+	case minerPayout:
+		he.Category = modules.HistoryCategoryMinerPayout
+	case proofOutput || len(txn.FileContracts) > 0 || len(txn.FileContractRevisions) > 0 || len(txn.StorageProofs) > 0:
+		he.Category = modules.HistoryCategoryContract
+	case he.Incoming.Cmp(he.Outgoing) >= 0:
+		he.Category = modules.HistoryCategoryIncoming
+	default:
+		he.Category = modules.HistoryCategoryOutgoing
+	}
+	return he
+}
+
+// History returns a chronological summary of the transactions relevant to the
+// wallet. Confirmed transactions are listed first, followed by the
+// transactions that are still in the transaction pool.
+func (w *Wallet) History() ([]modules.HistoryEntry, error) {
+	confirmed, err := w.Transactions(0, types.BlockHeight(math.MaxUint64))
+	if err != nil {
+		return nil, err
+	}
+	unconfirmed := w.UnconfirmedTransactions()
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	history := make([]modules.HistoryEntry, 0, len(confirmed)+len(unconfirmed))
+	confirmedIDs := make(map[types.TransactionID]struct{})
+	for _, pt := range confirmed {
+		he := historyEntry(pt, true)
+		he.Labels = w.transactionLabels(pt)
+		history = append(history, he)
+		confirmedIDs[pt.TransactionID] = struct{}{}
+	}
+	for _, pt := range unconfirmed {
+		// A transaction that was just confirmed may not have been removed
+		// from the unconfirmed set yet.
+		if _, exists := confirmedIDs[pt.TransactionID]; exists {
+			continue
+		}
+		he := historyEntry(pt, false)
+		he.Labels = w.transactionLabels(pt)
+		history = append(history, he)
+	}
+	return history, nil
+}
+
 // Transaction returns the transaction with the given id. 'False' is returned
 // if the transaction does not exist.
 func (w *Wallet) Transaction(txid types.TransactionID) (pt modules.ProcessedTransaction, found bool) {
@@ -77,6 +77,137 @@ func TestIntegrationTransactions(t *testing.T) {
 	}
 }
 
+// TestWalletHistory checks that the wallet history categorizes transactions
+// and reports pending transactions as confirmed once they are mined.
+func TestWalletHistory(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// The history should start out with the miner payouts of the blocks
+	// mined by the wallet tester.
+	history, err := wt.wallet.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != int(types.MaturityDelay+1) {
+		t.Fatal("unexpected history length:", len(history))
+	}
+	for _, he := range history {
+		if he.Category != modules.HistoryCategoryMinerPayout || !he.Confirmed {
+			t.Fatal("expected a confirmed miner payout, got", he.Category, he.Confirmed)
+		}
+	}
+
+	// Send siacoins to an address outside of the wallet. The send should
+	// show up as a pending outgoing transaction.
+	_, err = wt.wallet.SendSiacoins(types.NewCurrency64(5000), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err = wt.wallet.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sendID types.TransactionID
+	for _, he := range history[types.MaturityDelay+1:] {
+		if he.Confirmed {
+			t.Fatal("unconfirmed transaction reported as confirmed")
+		}
+		if he.Category == modules.HistoryCategoryOutgoing {
+			sendID = he.TransactionID
+		}
+	}
+	if sendID == (types.TransactionID{}) {
+		t.Fatal("send is missing from the history")
+	}
+
+	// Once mined, the send should be reported as confirmed.
+	b, _ := wt.miner.FindBlock()
+	err = wt.cs.AcceptBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err = wt.wallet.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, he := range history {
+		if !he.Confirmed {
+			t.Fatal("history still contains unconfirmed transactions")
+		}
+		if he.TransactionID == sendID {
+			found = true
+			if he.Category != modules.HistoryCategoryOutgoing || he.ConfirmationHeight != wt.cs.Height() {
+				t.Fatal("confirmed send has the wrong category or height")
+			}
+		}
+	}
+	if !found {
+		t.Fatal("confirmed send is missing from the history")
+	}
+}
+
+// TestHistoryEntryCategories checks that historyEntry distinguishes contract
+// transactions from ordinary transfers.
+func TestHistoryEntryCategories(t *testing.T) {
+	proof := modules.ProcessedTransaction{
+		Transaction: types.Transaction{
+			StorageProofs: []types.StorageProof{{}},
+		},
+		Outputs: []modules.ProcessedOutput{{
+			FundType:      types.SpecifierStorageProofOutput,
+			WalletAddress: true,
+			Value:         types.NewCurrency64(100),
+		}},
+	}
+	he := historyEntry(proof, true)
+	if he.Category != modules.HistoryCategoryContract {
+		t.Error("storage proof payout categorized as", he.Category)
+	}
+	if !he.Incoming.Equals64(100) || he.NetValue != "100" {
+		t.Error("wrong value for storage proof payout:", he.Incoming, he.NetValue)
+	}
+
+	formation := modules.ProcessedTransaction{
+		Transaction: types.Transaction{
+			FileContracts: []types.FileContract{{}},
+		},
+		Inputs: []modules.ProcessedInput{{
+			FundType:      types.SpecifierSiacoinInput,
+			WalletAddress: true,
+			Value:         types.NewCurrency64(100),
+		}},
+		Outputs: []modules.ProcessedOutput{{
+			FundType:      types.SpecifierSiacoinOutput,
+			WalletAddress: true,
+			Value:         types.NewCurrency64(30),
+		}},
+	}
+	he = historyEntry(formation, false)
+	if he.Category != modules.HistoryCategoryContract {
+		t.Error("contract formation categorized as", he.Category)
+	}
+	if he.NetValue != "-70" || he.Confirmed {
+		t.Error("wrong entry for contract formation:", he.NetValue, he.Confirmed)
+	}
+
+	formation.Transaction = types.Transaction{}
+	if he = historyEntry(formation, true); he.Category != modules.HistoryCategoryOutgoing {
+		t.Error("ordinary send categorized as", he.Category)
+	}
+	formation.Outputs[0].Value = types.NewCurrency64(130)
+	if he = historyEntry(formation, true); he.Category != modules.HistoryCategoryIncoming {
+		t.Error("ordinary receive categorized as", he.Category)
+	}
+}
+
 // TestTransactionsSingleTxn checks if it is possible to find a txn that was
 // appended to the processed transactions and is also the only txn for a
 // certain block height.
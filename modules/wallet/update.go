@@ -13,6 +13,7 @@ import (
 type (
 	spentSiacoinOutputSet map[types.SiacoinOutputID]types.SiacoinOutput
 	spentSiafundOutputSet map[types.SiafundOutputID]types.SiafundOutput
+	resolvedContractSet   map[types.FileContractID]types.FileContract
 )
 
 // threadedResetSubscriptions unsubscribes the wallet from the consensus set and transaction pool
@@ -77,6 +78,13 @@ func (w *Wallet) isWalletAddress(uh types.UnlockHash) bool {
 	return exists
 }
 
+// isWatchedAddress is a helper function that checks if an UnlockHash is one
+// of the wallet's watch-only addresses.
+func (w *Wallet) isWatchedAddress(uh types.UnlockHash) bool {
+	_, exists := w.watchedAddrs[uh]
+	return exists
+}
+
 // updateLookahead uses a consensus change to update the seed progress if one of the outputs
 // contains an unlock hash of the lookahead set. Returns true if a blockchain rescan is required
 func (w *Wallet) updateLookahead(tx *bolt.Tx, cc modules.ConsensusChange) (bool, error) {
@@ -106,6 +114,23 @@ func (w *Wallet) updateLookahead(tx *bolt.Tx, cc modules.ConsensusChange) (bool,
 // outputs as understood by the wallet.
 func (w *Wallet) updateConfirmedSet(tx *bolt.Tx, cc modules.ConsensusChange) error {
 	for _, diff := range cc.SiacoinOutputDiffs {
+		// Outputs sent to watch-only addresses are tracked separately so
+		// that they are never used to fund transactions.
+		if !w.isWalletAddress(diff.SiacoinOutput.UnlockHash) && w.isWatchedAddress(diff.SiacoinOutput.UnlockHash) {
+			var err error
+			if diff.Direction == modules.DiffApply {
+				w.log.Println("Wallet has gained a watch-only siacoin output:", diff.ID, "::", diff.SiacoinOutput.Value.HumanString())
+				err = dbPutWatchedSiacoinOutput(tx, diff.ID, diff.SiacoinOutput)
+			} else {
+				w.log.Println("Wallet has lost a watch-only siacoin output:", diff.ID, "::", diff.SiacoinOutput.Value.HumanString())
+				err = dbDeleteWatchedSiacoinOutput(tx, diff.ID)
+			}
+			if err != nil {
+				w.log.Severe("Could not update watch-only siacoin output:", err)
+				return err
+			}
+			continue
+		}
 		// Verify that the diff is relevant to the wallet.
 		if !w.isWalletAddress(diff.SiacoinOutput.UnlockHash) {
 			continue
@@ -232,10 +257,24 @@ func computeSpentSiafundOutputSet(diffs []modules.SiafundOutputDiff) spentSiafun
 	return outputs
 }
 
+// computeResolvedContractSet scans a slice of file contract diffs for removed
+// contracts and collects them in a map of FileContractID -> FileContract.
+func computeResolvedContractSet(diffs []modules.FileContractDiff) resolvedContractSet {
+	contracts := make(resolvedContractSet)
+	for _, diff := range diffs {
+		if diff.Direction == modules.DiffRevert {
+			// DiffRevert means resolved, either by a storage proof or by
+			// expiring.
+			contracts[diff.ID] = diff.FileContract
+		}
+	}
+	return contracts
+}
+
 // computeProcessedTransactionsFromBlock searches all the miner payouts and
 // transactions in a block and computes a ProcessedTransaction slice containing
 // all of the transactions processed for the given block.
-func (w *Wallet) computeProcessedTransactionsFromBlock(tx *bolt.Tx, block types.Block, spentSiacoinOutputs spentSiacoinOutputSet, spentSiafundOutputs spentSiafundOutputSet, consensusHeight types.BlockHeight) []modules.ProcessedTransaction {
+func (w *Wallet) computeProcessedTransactionsFromBlock(tx *bolt.Tx, block types.Block, spentSiacoinOutputs spentSiacoinOutputSet, spentSiafundOutputs spentSiafundOutputSet, resolvedContracts resolvedContractSet, consensusHeight types.BlockHeight) []modules.ProcessedTransaction {
 	var pts []modules.ProcessedTransaction
 
 	// Find ProcessedTransactions from miner payouts.
@@ -282,6 +321,11 @@ func (w *Wallet) computeProcessedTransactionsFromBlock(tx *bolt.Tx, block types.
 		for _, sfo := range txn.SiafundOutputs {
 			relevant = relevant || w.isWalletAddress(sfo.UnlockHash)
 		}
+		for _, sp := range txn.StorageProofs {
+			for _, sco := range resolvedContracts[sp.ParentID].ValidProofOutputs {
+				relevant = relevant || w.isWalletAddress(sco.UnlockHash)
+			}
+		}
 
 		// Only create a ProcessedTransaction if transaction is relevant.
 		if !relevant {
@@ -313,10 +357,16 @@ func (w *Wallet) computeProcessedTransactionsFromBlock(tx *bolt.Tx, block types.
 		}
 
 		for i, sco := range txn.SiacoinOutputs {
+			// Outputs sent to timelocked wallet addresses cannot be spent
+			// until the timelock expires.
+			maturityHeight := consensusHeight
+			if key, exists := w.keys[sco.UnlockHash]; exists && key.UnlockConditions.Timelock > maturityHeight {
+				maturityHeight = key.UnlockConditions.Timelock
+			}
 			po := modules.ProcessedOutput{
 				ID:             types.OutputID(txn.SiacoinOutputID(uint64(i))),
 				FundType:       types.SpecifierSiacoinOutput,
-				MaturityHeight: consensusHeight,
+				MaturityHeight: maturityHeight,
 				WalletAddress:  w.isWalletAddress(sco.UnlockHash),
 				RelatedAddress: sco.UnlockHash,
 				Value:          sco.Value,
@@ -381,6 +431,26 @@ func (w *Wallet) computeProcessedTransactionsFromBlock(tx *bolt.Tx, block types.
 			}
 		}
 
+		// The valid proof outputs of a contract are created when its storage
+		// proof is confirmed.
+		for _, sp := range txn.StorageProofs {
+			for i, sco := range resolvedContracts[sp.ParentID].ValidProofOutputs {
+				po := modules.ProcessedOutput{
+					ID:             types.OutputID(sp.ParentID.StorageProofOutputID(types.ProofValid, uint64(i))),
+					FundType:       types.SpecifierStorageProofOutput,
+					MaturityHeight: consensusHeight + types.MaturityDelay,
+					WalletAddress:  w.isWalletAddress(sco.UnlockHash),
+					RelatedAddress: sco.UnlockHash,
+					Value:          sco.Value,
+				}
+				pt.Outputs = append(pt.Outputs, po)
+				// Log any wallet-relevant outputs.
+				if po.WalletAddress {
+					w.log.Println("\tStorage Proof Output:", po.ID, "::", po.Value.HumanString())
+				}
+			}
+		}
+
 		for _, fee := range txn.MinerFees {
 			pt.Outputs = append(pt.Outputs, modules.ProcessedOutput{
 				FundType:       types.SpecifierMinerFee,
@@ -398,6 +468,7 @@ func (w *Wallet) computeProcessedTransactionsFromBlock(tx *bolt.Tx, block types.
 func (w *Wallet) applyHistory(tx *bolt.Tx, cc modules.ConsensusChange) error {
 	spentSiacoinOutputs := computeSpentSiacoinOutputSet(cc.SiacoinOutputDiffs)
 	spentSiafundOutputs := computeSpentSiafundOutputSet(cc.SiafundOutputDiffs)
+	resolvedContracts := computeResolvedContractSet(cc.FileContractDiffs)
 
 	for _, block := range cc.AppliedBlocks {
 		consensusHeight, err := dbGetConsensusHeight(tx)
@@ -413,7 +484,7 @@ func (w *Wallet) applyHistory(tx *bolt.Tx, cc modules.ConsensusChange) error {
 			}
 		}
 
-		pts := w.computeProcessedTransactionsFromBlock(tx, block, spentSiacoinOutputs, spentSiafundOutputs, consensusHeight)
+		pts := w.computeProcessedTransactionsFromBlock(tx, block, spentSiacoinOutputs, spentSiafundOutputs, resolvedContracts, consensusHeight)
 		for _, pt := range pts {
 			err := dbAppendProcessedTransaction(tx, pt)
 			if err != nil {
@@ -458,6 +529,7 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 		w.log.Severe("ERROR: failed to update consensus change ID:", err)
 		w.dbRollback = true
 	}
+	w.updateRecentBlockFees(cc)
 
 	if cc.Synced {
 		go w.threadedDefragWallet()
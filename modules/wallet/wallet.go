@@ -74,6 +74,21 @@ type Wallet struct {
 	keys      map[types.UnlockHash]spendableKey
 	lookahead map[types.UnlockHash]uint64
 
+	// watchedAddrs is the set of watch-only addresses. Outputs sent to these
+	// addresses are tracked separately from the spendable outputs, and are
+	// never used to fund transactions.
+	watchedAddrs map[types.UnlockHash]struct{}
+
+	// addrLabels maps addresses to the labels that the user gave them. The
+	// addresses may belong to the wallet or to anyone else.
+	addrLabels map[types.UnlockHash]string
+
+	// recentBlockFees contains the fee per byte of every fee-paying
+	// transaction in each of the most recent blocks, oldest block first. It
+	// is used by EstimateFee and is rebuilt from the consensus changes that
+	// arrive after startup.
+	recentBlockFees [][]types.Currency
+
 	// unconfirmedProcessedTransactions tracks unconfirmed transactions.
 	//
 	// TODO: Replace this field with a linked list. Currently when a new
@@ -150,8 +165,10 @@ func NewCustomWallet(cs modules.ConsensusSet, tpool modules.TransactionPool, per
 		cs:    cs,
 		tpool: tpool,
 
-		keys:      make(map[types.UnlockHash]spendableKey),
-		lookahead: make(map[types.UnlockHash]uint64),
+		keys:         make(map[types.UnlockHash]spendableKey),
+		lookahead:    make(map[types.UnlockHash]uint64),
+		watchedAddrs: make(map[types.UnlockHash]struct{}),
+		addrLabels:   make(map[types.UnlockHash]string),
 
 		unconfirmedSets: make(map[modules.TransactionSetID][]types.TransactionID),
 
@@ -181,6 +198,23 @@ func NewCustomWallet(cs modules.ConsensusSet, tpool modules.TransactionPool, per
 		w.syncDB()
 	}
 
+	// load the watch-only addresses. Unlike the spendable keys, these are not
+	// encrypted, so they can be loaded before the wallet is unlocked.
+	err = dbForEachWatchedAddr(w.dbTx, func(addr types.UnlockHash, _ struct{}) {
+		w.watchedAddrs[addr] = struct{}{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// load the address labels.
+	err = dbForEachAddrLabel(w.dbTx, func(addr types.UnlockHash, label string) {
+		w.addrLabels[addr] = label
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// make sure we commit on shutdown
 	w.tg.AfterStop(func() {
 		err := w.dbTx.Commit()
@@ -0,0 +1,116 @@
+package wallet
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// WatchOnlyAddresses returns the set of watch-only addresses tracked by the
+// wallet. Addresses are returned sorted in byte-order.
+func (w *Wallet) WatchOnlyAddresses() []types.UnlockHash {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	addrs := make([]types.UnlockHash, 0, len(w.watchedAddrs))
+	for addr := range w.watchedAddrs {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	return addrs
+}
+
+// WatchOnlyBalance returns the confirmed siacoin balance of the watch-only
+// addresses. This balance is not spendable and is not included in
+// ConfirmedBalance.
+func (w *Wallet) WatchOnlyBalance() (siacoinBalance types.Currency) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	dbForEachWatchedSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		siacoinBalance = siacoinBalance.Add(sco.Value)
+	})
+	return
+}
+
+// WatchAddresses adds a set of addresses to the wallet as watch-only
+// addresses. Outputs sent to these addresses are tracked by the wallet, but
+// cannot be spent by it. If any new addresses were added, the blockchain is
+// rescanned so that outputs which were sent to them in the past are credited.
+func (w *Wallet) WatchAddresses(addrs []types.UnlockHash) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	if !w.scanLock.TryLock() {
+		return errScanInProgress
+	}
+	defer w.scanLock.Unlock()
+
+	var subscribed bool
+	added, err := func() (bool, error) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		var added bool
+		for _, addr := range addrs {
+			if w.isWatchedAddress(addr) {
+				continue
+			}
+			if err := dbPutWatchedAddr(w.dbTx, addr); err != nil {
+				return false, err
+			}
+			w.watchedAddrs[addr] = struct{}{}
+			added = true
+		}
+		if !added {
+			return false, nil
+		}
+
+		// delete the set of processed transactions; they will be recreated
+		// when we rescan
+		if err := w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
+			return false, err
+		}
+		if _, err := w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
+			return false, err
+		}
+		w.unconfirmedProcessedTransactions = nil
+
+		// reset the consensus change ID and height in preparation for rescan
+		if err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning); err != nil {
+			return false, err
+		}
+		subscribed = w.subscribed
+		return true, dbPutConsensusHeight(w.dbTx, 0)
+	}()
+	if err != nil || !added {
+		return err
+	}
+
+	// If the wallet has not subscribed to the consensus set yet, the rescan
+	// will happen when the wallet is first unlocked.
+	if !subscribed {
+		return nil
+	}
+
+	// rescan the blockchain
+	w.cs.Unsubscribe(w)
+	w.tpool.Unsubscribe(w)
+
+	done := make(chan struct{})
+	go w.rescanMessage(done)
+	defer close(done)
+
+	err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
+	if err != nil {
+		return err
+	}
+	w.tpool.TransactionPoolSubscribe(w)
+	return nil
+}
@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestWatchAddresses checks that outputs sent to watch-only addresses are
+// credited to the watch-only balance, including outputs that were sent
+// before the address was added, and that they are never spendable.
+func TestWatchAddresses(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Send coins to an address that the wallet does not control, and confirm
+	// the transaction.
+	var addr types.UnlockHash
+	fastrand.Read(addr[:])
+	sendValue := types.SiacoinPrecision.Mul64(3)
+	if _, err := wt.wallet.SendSiacoins(sendValue, addr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if !wt.wallet.WatchOnlyBalance().IsZero() {
+		t.Fatal("watch-only balance should be zero before any addresses are watched")
+	}
+	confirmedBal, _, _ := wt.wallet.ConfirmedBalance()
+
+	// Watch the address. The rescan should credit the earlier output.
+	if err := wt.wallet.WatchAddresses([]types.UnlockHash{addr}); err != nil {
+		t.Fatal(err)
+	}
+	if addrs := wt.wallet.WatchOnlyAddresses(); len(addrs) != 1 || addrs[0] != addr {
+		t.Fatal("watch-only address was not added:", addrs)
+	}
+	if bal := wt.wallet.WatchOnlyBalance(); !bal.Equals(sendValue) {
+		t.Fatalf("expected watch-only balance of %v, got %v", sendValue, bal)
+	}
+	if bal, _, _ := wt.wallet.ConfirmedBalance(); !bal.Equals(confirmedBal) {
+		t.Fatal("watch-only outputs should not be included in the confirmed balance")
+	}
+
+	// Outputs sent after the address was added should also be credited.
+	if _, err := wt.wallet.SendSiacoins(sendValue, addr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if bal := wt.wallet.WatchOnlyBalance(); !bal.Equals(sendValue.Mul64(2)) {
+		t.Fatalf("expected watch-only balance of %v, got %v", sendValue.Mul64(2), bal)
+	}
+
+	// The watch-only outputs must never be used to fund transactions.
+	wt.wallet.mu.Lock()
+	_, exists := wt.wallet.keys[addr]
+	wt.wallet.mu.Unlock()
+	if exists {
+		t.Fatal("watch-only address should not have a spendable key")
+	}
+
+	// Watching the same address again should not trigger a rescan or change
+	// the balance.
+	if err := wt.wallet.WatchAddresses([]types.UnlockHash{addr}); err != nil {
+		t.Fatal(err)
+	}
+	if bal := wt.wallet.WatchOnlyBalance(); !bal.Equals(sendValue.Mul64(2)) {
+		t.Fatal("watch-only balance changed after watching a known address")
+	}
+}
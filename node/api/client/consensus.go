@@ -3,6 +3,7 @@ package client
 import (
 	"fmt"
 
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/node/api"
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -24,3 +25,17 @@ func (c *Client) ConsensusBlocksHeightGet(height types.BlockHeight) (block types
 	err = c.get("/consensus/blocks?height="+fmt.Sprint(height), &block)
 	return
 }
+
+// ConsensusSiacoinOutputsGet requests the /consensus/siacoinoutputs/:id api
+// resource
+func (c *Client) ConsensusSiacoinOutputsGet(id types.SiacoinOutputID) (csog api.ConsensusSiacoinOutputGET, err error) {
+	err = c.get("/consensus/siacoinoutputs/"+crypto.Hash(id).String(), &csog)
+	return
+}
+
+// ConsensusSiafundOutputsGet requests the /consensus/siafundoutputs/:id api
+// resource
+func (c *Client) ConsensusSiafundOutputsGet(id types.SiafundOutputID) (csog api.ConsensusSiafundOutputGET, err error) {
+	err = c.get("/consensus/siafundoutputs/"+crypto.Hash(id).String(), &csog)
+	return
+}
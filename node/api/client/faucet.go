@@ -0,0 +1,20 @@
+// +build dev testing
+
+package client
+
+import (
+	"net/url"
+
+	"github.com/NebulousLabs/Sia/node/api"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// WalletFaucetPost uses the /wallet/faucet endpoint to send 'amount' from the
+// faucet of a test network to 'dest'.
+func (c *Client) WalletFaucetPost(amount types.Currency, dest types.UnlockHash) (wfp api.WalletFaucetPOST, err error) {
+	values := url.Values{}
+	values.Set("amount", amount.String())
+	values.Set("destination", dest.String())
+	err = c.post("/wallet/faucet", values.Encode(), &wfp)
+	return
+}
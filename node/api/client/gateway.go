@@ -1,6 +1,9 @@
 package client
 
 import (
+	"net/url"
+	"strconv"
+
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/node/api"
 	"github.com/NebulousLabs/errors"
@@ -30,8 +33,50 @@ func (c *Client) GatewayDisconnectPost(address modules.NetAddress) (err error) {
 	return
 }
 
+// GatewayPinPost uses the /gateway/pin/:address endpoint to pin a peer.
+func (c *Client) GatewayPinPost(address modules.NetAddress) (err error) {
+	err = c.post("/gateway/pin/"+string(address), "", nil)
+	return
+}
+
+// GatewayUnpinPost uses the /gateway/unpin/:address endpoint to unpin a peer.
+func (c *Client) GatewayUnpinPost(address modules.NetAddress) (err error) {
+	err = c.post("/gateway/unpin/"+string(address), "", nil)
+	return
+}
+
+// GatewayBlockPost uses the /gateway/block/:host endpoint to add a host to
+// the gateway's blocklist.
+func (c *Client) GatewayBlockPost(host string) (err error) {
+	err = c.post("/gateway/block/"+host, "", nil)
+	return
+}
+
+// GatewayUnblockPost uses the /gateway/unblock/:host endpoint to remove a
+// host from the gateway's blocklist.
+func (c *Client) GatewayUnblockPost(host string) (err error) {
+	err = c.post("/gateway/unblock/"+host, "", nil)
+	return
+}
+
+// GatewayRateLimitPost uses the /gateway endpoint to change the gateway's
+// bandwidth rate limit.
+func (c *Client) GatewayRateLimitPost(downloadSpeed, uploadSpeed int64) (err error) {
+	values := url.Values{}
+	values.Set("maxdownloadspeed", strconv.FormatInt(downloadSpeed, 10))
+	values.Set("maxuploadspeed", strconv.FormatInt(uploadSpeed, 10))
+	err = c.post("/gateway", values.Encode(), nil)
+	return
+}
+
 // GatewayGet requests the /gateway api resource
 func (c *Client) GatewayGet() (gwg api.GatewayGET, err error) {
 	err = c.get("/gateway", &gwg)
 	return
 }
+
+// GatewayPeersGet requests the /gateway/peers api resource
+func (c *Client) GatewayPeersGet() (gpg api.GatewayPeersGET, err error) {
+	err = c.get("/gateway/peers", &gpg)
+	return
+}
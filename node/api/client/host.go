@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/node/api"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 // HostParam is a parameter in the host's settings that can be changed via the
@@ -40,13 +42,72 @@ const (
 	HostParamMaxDuration = HostParam("maxduration")
 	// HostParamWindowSize is the size of the proof window in blocks.
 	HostParamWindowSize = HostParam("windowsize")
+	// HostParamArchiveDir is the directory where the data of resolved
+	// storage obligations is archived.
+	HostParamArchiveDir = HostParam("archivedir")
+	// HostParamArchiveRetention is the number of blocks that archived storage
+	// obligations are kept for.
+	HostParamArchiveRetention = HostParam("archiveretention")
+	// HostParamMaxConcurrentProofs is the maximum number of storage proofs
+	// that the host builds at the same time.
+	HostParamMaxConcurrentProofs = HostParam("maxconcurrentproofs")
+	// HostParamMaintenanceEnd is the block height at which the host's
+	// maintenance window ends.
+	HostParamMaintenanceEnd = HostParam("maintenanceend")
+	// HostParamMaintenanceStart is the block height at which the host's
+	// maintenance window starts.
+	HostParamMaintenanceStart = HostParam("maintenancestart")
 	// HostParamMaxDownloadBatchSize is the maximum size of the download batch
 	// size in bytes.
 	HostParamMaxDownloadBatchSize = HostParam("maxdownloadbatchsize")
 	// HostParamMaxReviseBatchSize is the maximum size of the revise batch size.
 	HostParamMaxReviseBatchSize = HostParam("maxrevisebatchsize")
+	// HostParamMinDuration is the min duration of a contract in blocks.
+	HostParamMinDuration = HostParam("minduration")
+	// HostParamMinFileSize is the minimum size in bytes of a contract that
+	// the host renews.
+	HostParamMinFileSize = HostParam("minfilesize")
 	// HostParamNetAddress is the announced netaddress of the host.
 	HostParamNetAddress = HostParam("netaddress")
+	// HostParamReannounceInterval is the number of blocks after which the
+	// host automatically re-announces itself.
+	HostParamReannounceInterval = HostParam("reannounceinterval")
+	// HostParamResubmissionJitter is the maximum number of blocks of random
+	// jitter added to the resubmission of contract transactions.
+	HostParamResubmissionJitter = HostParam("resubmissionjitter")
+	// HostParamMaxRenterRequestRate is the maximum number of RPCs per minute
+	// that the host accepts from a single renter.
+	HostParamMaxRenterRequestRate = HostParam("maxrenterrequestrate")
+	// HostParamMaxRenterSessions is the maximum number of connections that a
+	// single renter can have open with the host.
+	HostParamMaxRenterSessions = HostParam("maxrentersessions")
+	// HostParamTrustedRenterCollateral is the amount of collateral that the
+	// host must have locked in a renter's contract for the renter to get
+	// higher limits.
+	HostParamTrustedRenterCollateral = HostParam("trustedrentercollateral")
+	// HostParamShutdownTimeout is the number of seconds that the host waits
+	// for storage proofs in progress when it shuts down.
+	HostParamShutdownTimeout = HostParam("shutdowntimeout")
+	// HostParamMaxConfirmationDelay is the number of blocks that a submitted
+	// transaction of a contract may wait for confirmation before the contract
+	// is reported as unconfirmed.
+	HostParamMaxConfirmationDelay = HostParam("maxconfirmationdelay")
+	// HostParamMaxContractRevisions is the maximum number of revisions per
+	// day that the host accepts for a single contract.
+	HostParamMaxContractRevisions = HostParam("maxcontractrevisions")
+	// HostParamMaxDiskLatency is the average disk latency in milliseconds
+	// above which the host declines new contracts.
+	HostParamMaxDiskLatency = HostParam("maxdisklatency")
+	// HostParamMaxObligations is the number of storage obligations at which
+	// the host stops accepting new contracts.
+	HostParamMaxObligations = HostParam("maxobligations")
+	// HostParamEncryptSectors determines whether the host encrypts new
+	// sectors on disk.
+	HostParamEncryptSectors = HostParam("encryptsectors")
+	// HostParamStoragePriceTiers is a comma separated list of storage price
+	// tiers in the form "minduration:storageprice", with the price in
+	// hastings/byte/block.
+	HostParamStoragePriceTiers = HostParam("storagepricetiers")
 )
 
 // HostAnnouncePost uses the /host/announce endpoint to announce the host to
@@ -63,6 +124,53 @@ func (c *Client) HostAnnounceAddrPost(address modules.NetAddress) (err error) {
 	return
 }
 
+// HostAnnounceCheckedPost uses the /host/announce endpoint to announce the
+// host to the network after checking that the host can reach itself through
+// the announced address. If the address is empty, the host's current address
+// is announced.
+func (c *Client) HostAnnounceCheckedPost(address modules.NetAddress) (err error) {
+	values := url.Values{}
+	values.Set("checkreachable", "true")
+	if address != "" {
+		values.Set("netaddress", string(address))
+	}
+	err = c.post("/host/announce", values.Encode(), nil)
+	return
+}
+
+// HostBackupPost uses the /host/backup endpoint to write the storage
+// obligations of the host to a backup file.
+func (c *Client) HostBackupPost(destination string) (err error) {
+	values := url.Values{}
+	values.Set("destination", destination)
+	err = c.post("/host/backup", values.Encode(), nil)
+	return
+}
+
+// HostRestorePost uses the /host/restore endpoint to load the storage
+// obligations from a backup file into the host.
+func (c *Client) HostRestorePost(source string) (err error) {
+	values := url.Values{}
+	values.Set("source", source)
+	err = c.post("/host/restore", values.Encode(), nil)
+	return
+}
+
+// HostSectorKeysRotatePost uses the /host/sectorkeys/rotate endpoint to
+// generate a new key for the host to encrypt sectors with.
+func (c *Client) HostSectorKeysRotatePost() (err error) {
+	err = c.post("/host/sectorkeys/rotate", "", nil)
+	return
+}
+
+// HostStorageProofPost uses the /host/storageproof/:id endpoint to make the
+// host rebuild and submit the storage proof of a storage obligation right
+// away.
+func (c *Client) HostStorageProofPost(id types.FileContractID) (err error) {
+	err = c.post("/host/storageproof/"+id.String(), "", nil)
+	return
+}
+
 // HostContractInfoGet uses the /host/contracts endpoint to get information
 // about contracts on the host.
 func (c *Client) HostContractInfoGet() (cg api.ContractInfoGET, err error) {
@@ -70,12 +178,86 @@ func (c *Client) HostContractInfoGet() (cg api.ContractInfoGET, err error) {
 	return
 }
 
+// HostActiveContractInfoGet uses the /host/contracts endpoint to get
+// information about the unresolved contracts on the host.
+func (c *Client) HostActiveContractInfoGet() (cg api.ContractInfoGET, err error) {
+	err = c.get("/host/contracts?active=true", &cg)
+	return
+}
+
+// HostUnconfirmedContractInfoGet uses the /host/contracts endpoint to get
+// information about the contracts on the host whose transactions have been
+// waiting for confirmation for too long.
+func (c *Client) HostUnconfirmedContractInfoGet() (cg api.ContractInfoGET, err error) {
+	err = c.get("/host/contracts?unconfirmed=true", &cg)
+	return
+}
+
+// HostTaggedContractInfoGet uses the /host/contracts endpoint to get
+// information about the contracts on the host which carry every tag. A tag is
+// either a key or a 'key=value' pair.
+func (c *Client) HostTaggedContractInfoGet(tags ...string) (cg api.ContractInfoGET, err error) {
+	values := url.Values{}
+	for _, tag := range tags {
+		values.Add("tag", tag)
+	}
+	err = c.get("/host/contracts?"+values.Encode(), &cg)
+	return
+}
+
+// HostContractTagsPost uses the /host/contracts/:id/tags endpoint to set and
+// remove tags on a contract of the host.
+func (c *Client) HostContractTagsPost(id types.FileContractID, set map[string]string, remove []string) (err error) {
+	var pairs []string
+	for k, v := range set {
+		pairs = append(pairs, k+"="+v)
+	}
+	values := url.Values{}
+	values.Set("set", strings.Join(pairs, ","))
+	values.Set("remove", strings.Join(remove, ","))
+	err = c.post("/host/contracts/"+id.String()+"/tags", values.Encode(), nil)
+	return
+}
+
 // HostEstimateScoreGet requests the /host/estimatescore endpoint.
 func (c *Client) HostEstimateScoreGet(param, value string) (eg api.HostEstimateScoreGET, err error) {
 	err = c.get(fmt.Sprintf("/host/estimatescore?%v=%v", param, value), &eg)
 	return
 }
 
+// HostQuoteGet requests the /host/quote endpoint to get the price that the
+// host would charge for a contract. A zero collateral selects the collateral
+// that the host would risk on the data.
+func (c *Client) HostQuoteGet(fileSize uint64, duration types.BlockHeight, collateral types.Currency) (q modules.HostContractQuote, err error) {
+	values := url.Values{}
+	values.Set("filesize", fmt.Sprint(fileSize))
+	values.Set("duration", fmt.Sprint(duration))
+	if !collateral.IsZero() {
+		values.Set("collateral", collateral.String())
+	}
+	err = c.get("/host/quote?"+values.Encode(), &q)
+	return
+}
+
+// HostFinancialsGet requests the /host/financials endpoint.
+func (c *Client) HostFinancialsGet() (hfg api.HostFinancialsGET, err error) {
+	err = c.get("/host/financials", &hfg)
+	return
+}
+
+// HostInvariantsGet requests the /host/invariants endpoint.
+func (c *Client) HostInvariantsGet() (hig api.HostInvariantsGET, err error) {
+	err = c.get("/host/invariants", &hig)
+	return
+}
+
+// HostInvariantsRepairPost uses the /host/invariants/repair endpoint to
+// re-derive the host's aggregate metrics from its storage obligations.
+func (c *Client) HostInvariantsRepairPost() (err error) {
+	err = c.post("/host/invariants/repair", "", nil)
+	return
+}
+
 // HostGet requests the /host endpoint.
 func (c *Client) HostGet() (hg api.HostGET, err error) {
 	err = c.get("/host", &hg)
@@ -99,6 +281,13 @@ func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error)
 	return
 }
 
+// HostStorageFoldersDefragPost uses the /host/storage/folders/defrag api
+// endpoint to start a defrag of the host's storage folders.
+func (c *Client) HostStorageFoldersDefragPost() (err error) {
+	err = c.post("/host/storage/folders/defrag", "", nil)
+	return
+}
+
 // HostStorageFoldersRemovePost uses the /host/storage/folders/remove api
 // endpoint to remove a storage folder from a host.
 func (c *Client) HostStorageFoldersRemovePost(path string) (err error) {
@@ -12,6 +12,13 @@ func (c *Client) MinerGet() (mg api.MinerGET, err error) {
 	return
 }
 
+// MinerPayoutAddressPost uses the /miner endpoint to set the address that
+// block rewards are paid to.
+func (c *Client) MinerPayoutAddressPost(addr types.UnlockHash) (err error) {
+	err = c.post("/miner", "payoutaddress="+addr.String(), nil)
+	return
+}
+
 // MinerHeaderGet uses the /miner/header endpoint to get a header for work.
 func (c *Client) MinerHeaderGet() (target types.Target, bh types.BlockHeader, err error) {
 	targetAndHeader, err := c.getRawResponse("/miner/header")
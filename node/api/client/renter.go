@@ -4,23 +4,115 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/node/api"
+	"github.com/NebulousLabs/Sia/types"
 )
 
+// RenterBlacklistGet requests the /renter/blacklist resource.
+func (c *Client) RenterBlacklistGet() (rbg api.RenterBlacklistGET, err error) {
+	err = c.get("/renter/blacklist", &rbg)
+	return
+}
+
+// RenterBlacklistPost uses the /renter/blacklist endpoint to blacklist a host.
+func (c *Client) RenterBlacklistPost(key types.SiaPublicKey) (err error) {
+	err = c.post("/renter/blacklist/"+key.String(), "", nil)
+	return
+}
+
+// RenterBackupPost uses the /renter/backup endpoint to write the contracts
+// and files of the renter to an encrypted backup file.
+func (c *Client) RenterBackupPost(destination string) (err error) {
+	values := url.Values{}
+	values.Set("destination", destination)
+	err = c.post("/renter/backup", values.Encode(), nil)
+	return
+}
+
+// RenterRestorePost uses the /renter/restore endpoint to load the contracts
+// and files of an encrypted backup file into the renter.
+func (c *Client) RenterRestorePost(source string) (err error) {
+	values := url.Values{}
+	values.Set("source", source)
+	err = c.post("/renter/restore", values.Encode(), nil)
+	return
+}
+
+// RenterUnblacklistPost uses the /renter/unblacklist endpoint to remove a host
+// from the blacklist.
+func (c *Client) RenterUnblacklistPost(key types.SiaPublicKey) (err error) {
+	err = c.post("/renter/unblacklist/"+key.String(), "", nil)
+	return
+}
+
 // RenterContractsGet requests the /renter/contracts resource
 func (c *Client) RenterContractsGet() (rc api.RenterContracts, err error) {
 	err = c.get("/renter/contracts", &rc)
 	return
 }
 
+// RenterContractsFormPost uses the /renter/contracts/form endpoint to form
+// contracts with the given hosts.
+func (c *Client) RenterContractsFormPost(keys []types.SiaPublicKey) (rc api.RenterContracts, err error) {
+	hosts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		hosts = append(hosts, key.String())
+	}
+	values := url.Values{}
+	values.Set("hosts", strings.Join(hosts, ","))
+	err = c.post("/renter/contracts/form", values.Encode(), &rc)
+	return
+}
+
+// RenterContractsExpiringGet requests the /renter/contracts/expiring resource
+// with the given threshold.
+func (c *Client) RenterContractsExpiringGet(threshold types.BlockHeight) (rc api.RenterContracts, err error) {
+	err = c.get(fmt.Sprintf("/renter/contracts/expiring?threshold=%v", threshold), &rc)
+	return
+}
+
+// RenterContractsHealthGet requests the /renter/contracts/health resource
+func (c *Client) RenterContractsHealthGet() (rchg api.RenterContractsHealthGET, err error) {
+	err = c.get("/renter/contracts/health", &rchg)
+	return
+}
+
 // RenterDeletePost uses the /renter/delete endpoint to delete a file.
 func (c *Client) RenterDeletePost(siaPath string) (err error) {
 	err = c.post(fmt.Sprintf("/renter/delete/%s", siaPath), "", nil)
 	return err
 }
 
+// RenterPauseUploadPost uses the /renter/pauseupload endpoint to pause the
+// upload of a file.
+func (c *Client) RenterPauseUploadPost(siaPath string) (err error) {
+	err = c.post(fmt.Sprintf("/renter/pauseupload/%s", siaPath), "", nil)
+	return err
+}
+
+// RenterResumeUploadPost uses the /renter/resumeupload endpoint to resume the
+// upload of a paused file.
+func (c *Client) RenterResumeUploadPost(siaPath string) (err error) {
+	err = c.post(fmt.Sprintf("/renter/resumeupload/%s", siaPath), "", nil)
+	return err
+}
+
+// RenterMigratePost uses the /renter/migrate endpoint to migrate the pieces of
+// a file away from the given hosts.
+func (c *Client) RenterMigratePost(siaPath string, hosts []types.SiaPublicKey) (err error) {
+	keys := make([]string, len(hosts))
+	for i, host := range hosts {
+		keys[i] = host.String()
+	}
+	values := url.Values{}
+	values.Set("hosts", strings.Join(keys, ","))
+	err = c.post(fmt.Sprintf("/renter/migrate/%s", siaPath), values.Encode(), nil)
+	return err
+}
+
 // RenterDownloadGet uses the /renter/download endpoint to download a file to a
 // destination on disk.
 func (c *Client) RenterDownloadGet(siaPath, destination string, offset, length uint64, async bool) (err error) {
@@ -53,18 +145,38 @@ func (c *Client) RenterDownloadHTTPResponseGet(siaPath string, offset, length ui
 	return
 }
 
+// RenterDownloadBytesGet uses the /renter/downloadbytes endpoint to download a
+// small file and return its data.
+func (c *Client) RenterDownloadBytesGet(siaPath string) (resp []byte, err error) {
+	resp, err = c.getRawResponse("/renter/downloadbytes/" + siaPath)
+	return
+}
+
 // RenterFilesGet requests the /renter/files resource.
 func (c *Client) RenterFilesGet() (rf api.RenterFiles, err error) {
 	err = c.get("/renter/files", &rf)
 	return
 }
 
+// RenterHealthGet requests the /renter/health resource to report the piece
+// health of a file.
+func (c *Client) RenterHealthGet(siaPath string) (rfh api.RenterFileHealthGET, err error) {
+	err = c.get("/renter/health/"+siaPath, &rfh)
+	return
+}
+
 // RenterGet requests the /renter resource.
 func (c *Client) RenterGet() (rg api.RenterGET, err error) {
 	err = c.get("/renter", &rg)
 	return
 }
 
+// RenterSpendingGet requests the /renter/spending resource.
+func (c *Client) RenterSpendingGet() (rsg api.RenterSpendingGET, err error) {
+	err = c.get("/renter/spending", &rsg)
+	return
+}
+
 // RenterPostAllowance uses the /renter endpoint to change the renter's allowance
 func (c *Client) RenterPostAllowance(allowance modules.Allowance) (err error) {
 	values := url.Values{}
@@ -128,6 +240,13 @@ func (c *Client) RenterUploadPost(path, siaPath string, dataPieces, parityPieces
 	return
 }
 
+// RenterUploadBytesPost uses the /renter/uploadbytes endpoint to upload data as
+// a small file.
+func (c *Client) RenterUploadBytesPost(siaPath string, data []byte) (err error) {
+	_, err = c.postRawResponse("/renter/uploadbytes/"+siaPath, string(data))
+	return
+}
+
 // RenterUploadDefaultPost uses the /renter/upload endpoint with default
 // redundancy settings to upload a file.
 func (c *Client) RenterUploadDefaultPost(path, siaPath string) (err error) {
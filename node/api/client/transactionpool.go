@@ -1,6 +1,16 @@
 package client
 
-import "github.com/NebulousLabs/Sia/node/api"
+import (
+	"github.com/NebulousLabs/Sia/node/api"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TransactionPoolAddressGet uses the /tpool/address/:addr endpoint to get the
+// unconfirmed transactions that affect an address.
+func (c *Client) TransactionPoolAddressGet(addr types.UnlockHash) (tag api.TpoolAddressGET, err error) {
+	err = c.get("/tpool/address/"+addr.String(), &tag)
+	return
+}
 
 // TransactionPoolFeeGet uses the /tpool/fee endpoint to get a fee estimation.
 func (c *Client) TransactionPoolFeeGet() (tfg api.TpoolFeeGET, err error) {
@@ -1,11 +1,13 @@
 package client
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
 
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/node/api"
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -33,6 +35,59 @@ func (c *Client) WalletChangePasswordPost(currentPassword, newPassword string) (
 	return
 }
 
+// WalletFeeGet requests the /wallet/fee endpoint and returns the low, medium
+// and high fee recommendations of the wallet.
+func (c *Client) WalletFeeGet() (wfg api.WalletFeeGET, err error) {
+	err = c.get("/wallet/fee", &wfg)
+	return
+}
+
+// WalletHistoryGet requests the /wallet/history endpoint and returns the
+// categorized transaction history of the wallet.
+func (c *Client) WalletHistoryGet() (whg api.WalletHistoryGET, err error) {
+	err = c.get("/wallet/history", &whg)
+	return
+}
+
+// WalletLabelPost uses the /wallet/label endpoint to set the label of an
+// address. An empty label removes the label.
+func (c *Client) WalletLabelPost(addr types.UnlockHash, label string) (err error) {
+	values := url.Values{}
+	values.Set("address", addr.String())
+	values.Set("label", label)
+	err = c.post("/wallet/label", values.Encode(), nil)
+	return
+}
+
+// WalletSignPost uses the /wallet/sign endpoint to sign a message with the key
+// of an address owned by the wallet.
+func (c *Client) WalletSignPost(addr types.UnlockHash, message string) (ms modules.MessageSignature, err error) {
+	values := url.Values{}
+	values.Set("address", addr.String())
+	values.Set("message", message)
+	err = c.post("/wallet/sign", values.Encode(), &ms)
+	return
+}
+
+// WalletVerifyMessageGet uses the /wallet/verify/message endpoint to check a
+// message signature.
+func (c *Client) WalletVerifyMessageGet(message string, ms modules.MessageSignature) (wvmg api.WalletVerifyMessageGET, err error) {
+	values := url.Values{}
+	values.Set("message", message)
+	values.Set("address", ms.Address.String())
+	values.Set("publickey", ms.PublicKey.String())
+	values.Set("signature", base64.StdEncoding.EncodeToString(ms.Signature))
+	err = c.get("/wallet/verify/message?"+values.Encode(), &wvmg)
+	return
+}
+
+// WalletLabelsGet requests the /wallet/labels endpoint and returns the
+// labels of all labeled addresses.
+func (c *Client) WalletLabelsGet() (wlg api.WalletLabelsGET, err error) {
+	err = c.get("/wallet/labels", &wlg)
+	return
+}
+
 // WalletInitPost uses the /wallet/init endpoint to initialize and encrypt a
 // wallet
 func (c *Client) WalletInitPost(password string, force bool) (wip api.WalletInitPOST, err error) {
@@ -106,6 +161,17 @@ func (c *Client) WalletSiacoinsPost(amount types.Currency, destination types.Unl
 	return
 }
 
+// WalletSiacoinsFeeTierPost uses the /wallet/siacoins api endpoint to send
+// money to a single address, paying the fee recommended for the provided tier.
+func (c *Client) WalletSiacoinsFeeTierPost(amount types.Currency, destination types.UnlockHash, tier modules.FeeTier) (wsp api.WalletSiacoinsPOST, err error) {
+	values := url.Values{}
+	values.Set("amount", amount.String())
+	values.Set("destination", destination.String())
+	values.Set("feetier", string(tier))
+	err = c.post("/wallet/siacoins", values.Encode(), &wsp)
+	return
+}
+
 // WalletSiafundsPost uses the /wallet/siafunds api endpoint to send siafunds
 // to a single address.
 func (c *Client) WalletSiafundsPost(amount types.Currency, destination types.UnlockHash) (wsp api.WalletSiafundsPOST, err error) {
@@ -116,6 +182,13 @@ func (c *Client) WalletSiafundsPost(amount types.Currency, destination types.Unl
 	return
 }
 
+// WalletSiafundsGet uses the /wallet/siafunds endpoint to get the siafund
+// outputs of the wallet and their claims.
+func (c *Client) WalletSiafundsGet() (wsg api.WalletSiafundsGET, err error) {
+	err = c.get("/wallet/siafunds", &wsg)
+	return
+}
+
 // WalletSiagKeyPost uses the /wallet/siagkey endpoint to load a siag key into
 // the wallet.
 func (c *Client) WalletSiagKeyPost(keyfiles, password string) (err error) {
@@ -135,6 +208,22 @@ func (c *Client) WalletSweepPost(seed string) (wsp api.WalletSweepPOST, err erro
 	return
 }
 
+// WalletSweepClaimPost uses the /wallet/sweep/claim endpoint to claim the
+// siacoins accumulated by the wallet's siafunds.
+func (c *Client) WalletSweepClaimPost() (wscp api.WalletSweepClaimPOST, err error) {
+	err = c.post("/wallet/sweep/claim", "", &wscp)
+	return
+}
+
+// WalletSweepDustPost uses the /wallet/sweep/dust endpoint to consolidate the
+// wallet's outputs below threshold into a single output.
+func (c *Client) WalletSweepDustPost(threshold types.Currency) (wsdp api.WalletSweepDustPOST, err error) {
+	values := url.Values{}
+	values.Set("threshold", threshold.String())
+	err = c.post("/wallet/sweep/dust", values.Encode(), &wsdp)
+	return
+}
+
 // WalletTransactionsGet requests the/wallet/transactions api resource for a
 // certain startheight and endheight
 func (c *Client) WalletTransactionsGet(startHeight types.BlockHeight, endHeight types.BlockHeight) (wtg api.WalletTransactionsGET, err error) {
@@ -161,3 +250,23 @@ func (c *Client) Wallet033xPost(path, password string) (err error) {
 	err = c.post("/wallet/033x", values.Encode(), nil)
 	return
 }
+
+// WalletWatchGet requests the /wallet/watch endpoint and returns the
+// watch-only addresses and their balance.
+func (c *Client) WalletWatchGet() (wwg api.WalletWatchGET, err error) {
+	err = c.get("/wallet/watch", &wwg)
+	return
+}
+
+// WalletWatchPost uses the /wallet/watch endpoint to add a set of watch-only
+// addresses to the wallet.
+func (c *Client) WalletWatchPost(addrs []types.UnlockHash) (err error) {
+	values := url.Values{}
+	marshaledAddrs, err := json.Marshal(addrs)
+	if err != nil {
+		return err
+	}
+	values.Set("addresses", string(marshaledAddrs))
+	err = c.post("/wallet/watch", values.Encode(), nil)
+	return
+}
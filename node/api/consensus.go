@@ -20,6 +20,23 @@ type ConsensusGET struct {
 	Difficulty   types.Currency    `json:"difficulty"`
 }
 
+// ConsensusSiacoinOutputGET reports whether a siacoin output is in the
+// unspent set of the current block, along with the output if it is.
+type ConsensusSiacoinOutputGET struct {
+	Unspent    bool             `json:"unspent"`
+	Value      types.Currency   `json:"value"`
+	UnlockHash types.UnlockHash `json:"unlockhash"`
+}
+
+// ConsensusSiafundOutputGET reports whether a siafund output is in the
+// unspent set of the current block, along with the output if it is.
+type ConsensusSiafundOutputGET struct {
+	Unspent    bool             `json:"unspent"`
+	Value      types.Currency   `json:"value"`
+	UnlockHash types.UnlockHash `json:"unlockhash"`
+	ClaimStart types.Currency   `json:"claimstart"`
+}
+
 // ConsensusHeadersGET contains information from a blocks header.
 type ConsensusHeadersGET struct {
 	BlockID types.BlockID `json:"blockid"`
@@ -80,6 +97,39 @@ func (api *API) consensusBlocksHandler(w http.ResponseWriter, req *http.Request,
 	WriteJSON(w, b)
 }
 
+// consensusSiacoinOutputsHandler handles the API calls to
+// /consensus/siacoinoutputs/:id.
+func (api *API) consensusSiacoinOutputsHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	id, err := scanHash(ps.ByName("id"))
+	if err != nil {
+		WriteError(w, Error{"failed to unmarshal output id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	sco, exists := api.cs.SiacoinOutput(types.SiacoinOutputID(id))
+	WriteJSON(w, ConsensusSiacoinOutputGET{
+		Unspent:    exists,
+		Value:      sco.Value,
+		UnlockHash: sco.UnlockHash,
+	})
+}
+
+// consensusSiafundOutputsHandler handles the API calls to
+// /consensus/siafundoutputs/:id.
+func (api *API) consensusSiafundOutputsHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	id, err := scanHash(ps.ByName("id"))
+	if err != nil {
+		WriteError(w, Error{"failed to unmarshal output id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	sfo, exists := api.cs.SiafundOutput(types.SiafundOutputID(id))
+	WriteJSON(w, ConsensusSiafundOutputGET{
+		Unspent:    exists,
+		Value:      sfo.Value,
+		UnlockHash: sfo.UnlockHash,
+		ClaimStart: sfo.ClaimStart,
+	})
+}
+
 // consensusValidateTransactionsetHandler handles the API calls to
 // /consensus/validate/transactionset.
 func (api *API) consensusValidateTransactionsetHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
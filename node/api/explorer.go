@@ -12,6 +12,10 @@ import (
 	"github.com/julienschmidt/httprouter"
 )
 
+// maxExplorerBlockStats is the maximum number of blocks whose statistics are
+// returned by a single call to /explorer/blockstats.
+const maxExplorerBlockStats = 1000
+
 type (
 	// ExplorerBlock is a block with some extra information such as the id and
 	// height. This information is provided for programs that may not be
@@ -59,6 +63,23 @@ type (
 		Block ExplorerBlock `json:"block"`
 	}
 
+	// ExplorerBlockStatsGET is the object returned as a response to a GET
+	// request to /explorer/blockstats. If the requested range did not fit in
+	// a single page, More is set and NextHeight is the startheight of the
+	// next page.
+	ExplorerBlockStatsGET struct {
+		Stats      []modules.BlockStats `json:"stats"`
+		More       bool                 `json:"more"`
+		NextHeight types.BlockHeight    `json:"nextheight"`
+	}
+
+	// ExplorerContractsGET is the object returned as a response to a GET
+	// request to /explorer/contracts/:unlockhash. It lists the file contracts
+	// whose valid or missed proof outputs pay the unlock hash.
+	ExplorerContractsGET struct {
+		FileContractIDs []types.FileContractID `json:"filecontractids"`
+	}
+
 	// ExplorerHashGET is the object returned as a response to a GET request to
 	// /explorer/hash. The HashType will indicate whether the hash corresponds
 	// to a block id, a transaction id, a siacoin output id, a file contract
@@ -351,6 +372,60 @@ func (api *API) explorerHashHandler(w http.ResponseWriter, req *http.Request, ps
 	WriteError(w, Error{"unrecognized hash used as input to /explorer/hash"}, http.StatusBadRequest)
 }
 
+// explorerBlockStatsHandler handles GET requests to /explorer/blockstats.
+func (api *API) explorerBlockStatsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	latest := api.explorer.LatestBlockFacts().Height
+	start, end, limit := types.BlockHeight(0), latest, types.BlockHeight(maxExplorerBlockStats)
+	for _, p := range []struct {
+		name string
+		val  *types.BlockHeight
+	}{
+		{"startheight", &start},
+		{"endheight", &end},
+		{"limit", &limit},
+	} {
+		if s := req.FormValue(p.name); s != "" {
+			if _, err := fmt.Sscan(s, p.val); err != nil {
+				WriteError(w, Error{"could not parse " + p.name + ": " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if start > end {
+		WriteError(w, Error{"startheight must not be greater than endheight"}, http.StatusBadRequest)
+		return
+	}
+	if limit == 0 || limit > maxExplorerBlockStats {
+		limit = maxExplorerBlockStats
+	}
+	if end > latest {
+		end = latest
+	}
+
+	// Only return a single page of the range.
+	var resp ExplorerBlockStatsGET
+	if start <= end && end-start >= limit {
+		end = start + limit - 1
+		resp.More = true
+		resp.NextHeight = end + 1
+	}
+	resp.Stats = api.explorer.BlockStats(start, end)
+	WriteJSON(w, resp)
+}
+
+// explorerContractsHandler handles GET requests to
+// /explorer/contracts/:unlockhash.
+func (api *API) explorerContractsHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	uh, err := scanAddress(ps.ByName("unlockhash"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, ExplorerContractsGET{
+		FileContractIDs: api.explorer.UnlockHashFileContracts(uh),
+	})
+}
+
 // explorerHandler handles API calls to /explorer
 func (api *API) explorerHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	facts := api.explorer.LatestBlockFacts()
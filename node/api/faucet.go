@@ -0,0 +1,53 @@
+// +build dev testing
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// faucet is implemented by wallets that are built for a test network.
+	faucet interface {
+		FaucetSend(types.Currency, types.UnlockHash) (types.Transaction, error)
+	}
+
+	// WalletFaucetPOST contains the ID of the transaction sent by the faucet.
+	WalletFaucetPOST struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+	}
+)
+
+// buildFaucetRoutes adds the faucet routes to the router. The faucet is only
+// available when build.Release is "dev" or "testing".
+func (api *API) buildFaucetRoutes(router *httprouter.Router, requiredPassword string) {
+	if _, ok := api.wallet.(faucet); ok {
+		router.POST("/wallet/faucet", RequirePassword(api.walletFaucetHandler, requiredPassword))
+	}
+}
+
+// walletFaucetHandler handles API calls to /wallet/faucet.
+func (api *API) walletFaucetHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	amount, ok := scanAmount(req.FormValue("amount"))
+	if !ok {
+		WriteError(w, Error{"could not read amount from POST call to /wallet/faucet"}, http.StatusBadRequest)
+		return
+	}
+	dest, err := scanAddress(req.FormValue("destination"))
+	if err != nil {
+		WriteError(w, Error{"could not read address from POST call to /wallet/faucet"}, http.StatusBadRequest)
+		return
+	}
+	txn, err := api.wallet.(faucet).FaucetSend(amount, dest)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/faucet: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, WalletFaucetPOST{
+		TransactionID: txn.ID(),
+	})
+}
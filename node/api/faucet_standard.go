@@ -0,0 +1,11 @@
+// +build !testing,!dev
+
+package api
+
+import (
+	"github.com/julienschmidt/httprouter"
+)
+
+// buildFaucetRoutes does nothing, as the faucet is not available on the
+// standard release.
+func (api *API) buildFaucetRoutes(router *httprouter.Router, requiredPassword string) {}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/NebulousLabs/Sia/modules"
@@ -10,8 +11,19 @@ import (
 
 // GatewayGET contains the fields returned by a GET call to "/gateway".
 type GatewayGET struct {
-	NetAddress modules.NetAddress `json:"netaddress"`
-	Peers      []modules.Peer     `json:"peers"`
+	NetAddress  modules.NetAddress   `json:"netaddress"`
+	Peers       []modules.Peer       `json:"peers"`
+	PinnedPeers []modules.NetAddress `json:"pinnedpeers"`
+	Blocklist   []string             `json:"blocklist"`
+
+	MaxDownloadSpeed int64 `json:"maxdownloadspeed"`
+	MaxUploadSpeed   int64 `json:"maxuploadspeed"`
+}
+
+// GatewayPeersGET contains the fields returned by a GET call to
+// "/gateway/peers".
+type GatewayPeersGET struct {
+	Peers []modules.PeerStats `json:"peers"`
 }
 
 // gatewayHandler handles the API call asking for the gatway status.
@@ -23,7 +35,51 @@ func (api *API) gatewayHandler(w http.ResponseWriter, req *http.Request, _ httpr
 	if peers == nil {
 		peers = make([]modules.Peer, 0)
 	}
-	WriteJSON(w, GatewayGET{api.gateway.Address(), peers})
+	downloadSpeed, uploadSpeed := api.gateway.RateLimits()
+	WriteJSON(w, GatewayGET{
+		NetAddress:  api.gateway.Address(),
+		Peers:       peers,
+		PinnedPeers: api.gateway.PinnedPeers(),
+		Blocklist:   api.gateway.Blocklist(),
+
+		MaxDownloadSpeed: downloadSpeed,
+		MaxUploadSpeed:   uploadSpeed,
+	})
+}
+
+// gatewayHandlerPOST handles the API call changing the gateway's settings.
+func (api *API) gatewayHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	downloadSpeed, uploadSpeed := api.gateway.RateLimits()
+	// Scan the download speed limit. (optional parameter)
+	if d := req.FormValue("maxdownloadspeed"); d != "" {
+		if _, err := fmt.Sscan(d, &downloadSpeed); err != nil {
+			WriteError(w, Error{"unable to parse downloadspeed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	// Scan the upload speed limit. (optional parameter)
+	if u := req.FormValue("maxuploadspeed"); u != "" {
+		if _, err := fmt.Sscan(u, &uploadSpeed); err != nil {
+			WriteError(w, Error{"unable to parse uploadspeed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	err := api.gateway.SetRateLimits(downloadSpeed, uploadSpeed)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// gatewayPeersHandler handles the API call asking for the statistics of the
+// gateway's peers.
+func (api *API) gatewayPeersHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	peers := api.gateway.PeerStats()
+	if peers == nil {
+		peers = make([]modules.PeerStats, 0)
+	}
+	WriteJSON(w, GatewayPeersGET{peers})
 }
 
 // gatewayConnectHandler handles the API call to add a peer to the gateway.
@@ -49,3 +105,50 @@ func (api *API) gatewayDisconnectHandler(w http.ResponseWriter, req *http.Reques
 
 	WriteSuccess(w)
 }
+
+// gatewayPinHandler handles the API call to pin a peer.
+func (api *API) gatewayPinHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	addr := modules.NetAddress(ps.ByName("netaddress"))
+	err := api.gateway.PinPeer(addr)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
+// gatewayUnpinHandler handles the API call to unpin a peer.
+func (api *API) gatewayUnpinHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	addr := modules.NetAddress(ps.ByName("netaddress"))
+	err := api.gateway.UnpinPeer(addr)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
+// gatewayBlockHandler handles the API call to add a host to the blocklist.
+func (api *API) gatewayBlockHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	err := api.gateway.BlockHost(ps.ByName("host"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
+// gatewayUnblockHandler handles the API call to remove a host from the
+// blocklist.
+func (api *API) gatewayUnblockHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	err := api.gateway.UnblockHost(ps.ByName("host"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
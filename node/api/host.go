@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
@@ -41,6 +44,28 @@ type (
 		WorkingStatus        modules.HostWorkingStatus        `json:"workingstatus"`
 	}
 
+	// HostFinancialsGET contains the information that is returned after a GET
+	// request to /host/financials - a summary of the host's revenue and of how
+	// the host's storage obligations have been resolved.
+	HostFinancialsGET struct {
+		AnticipatedRevenue types.Currency `json:"anticipatedrevenue"`
+		LostRevenue        types.Currency `json:"lostrevenue"`
+		Revenue            types.Currency `json:"revenue"`
+
+		ActiveObligations    uint64  `json:"activeobligations"`
+		FailedObligations    uint64  `json:"failedobligations"`
+		RejectedObligations  uint64  `json:"rejectedobligations"`
+		SucceededObligations uint64  `json:"succeededobligations"`
+		SuccessRate          float64 `json:"successrate"`
+	}
+
+	// HostInvariantsGET contains the information that is returned after a GET
+	// request to /host/invariants - the list of aggregate metrics which do not
+	// match the host's storage obligations.
+	HostInvariantsGET struct {
+		Errors []string `json:"errors"`
+	}
+
 	// HostEstimateScoreGET contains the information that is returned from a
 	// /host/estimatescore call.
 	HostEstimateScoreGET struct {
@@ -69,13 +94,176 @@ func folderIndex(folderPath string, storageFolders []modules.StorageFolderMetada
 
 // hostContractInfoHandler handles the API call to get the contract information of the host.
 // Information is retrieved via the storage obligations from the host database.
+// If the 'active' query parameter is set, only the storage obligations that
+// have not yet been resolved are returned. If the 'unconfirmed' query parameter
+// is set, only the storage obligations whose origin or revision transaction
+// has been waiting for confirmation for longer than the host allows are
+// returned. Every 'tag' query parameter narrows the result down to the storage
+// obligations carrying the tag, given either as 'key' or as 'key=value'.
 func (api *API) hostContractInfoHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var onlyActive, onlyUnconfirmed bool
+	var err error
+	if active := req.FormValue("active"); active != "" {
+		onlyActive, err = strconv.ParseBool(active)
+		if err != nil {
+			WriteError(w, Error{"error when calling /host/contracts: unable to parse 'active': " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if unconfirmed := req.FormValue("unconfirmed"); unconfirmed != "" {
+		onlyUnconfirmed, err = strconv.ParseBool(unconfirmed)
+		if err != nil {
+			WriteError(w, Error{"error when calling /host/contracts: unable to parse 'unconfirmed': " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	sos := api.host.StorageObligations()
+	if onlyActive {
+		var active []modules.StorageObligation
+		for _, so := range sos {
+			if so.ObligationStatus == modules.ObligationStatusUnresolved {
+				active = append(active, so)
+			}
+		}
+		sos = active
+	}
+	if onlyUnconfirmed {
+		var unconfirmed []modules.StorageObligation
+		for _, so := range sos {
+			if so.Unconfirmed {
+				unconfirmed = append(unconfirmed, so)
+			}
+		}
+		sos = unconfirmed
+	}
+	if tags := req.Form["tag"]; len(tags) > 0 {
+		var tagged []modules.StorageObligation
+		for _, so := range sos {
+			if hasTags(so, tags) {
+				tagged = append(tagged, so)
+			}
+		}
+		sos = tagged
+	}
 	cg := ContractInfoGET{
-		Contracts: api.host.StorageObligations(),
+		Contracts: sos,
 	}
 	WriteJSON(w, cg)
 }
 
+// hasTags returns true if the storage obligation carries every tag. A tag is
+// either a key, which matches any value, or a 'key=value' pair.
+func hasTags(so modules.StorageObligation, tags []string) bool {
+	for _, tag := range tags {
+		key, value := tag, ""
+		i := strings.Index(tag, "=")
+		if i >= 0 {
+			key, value = tag[:i], tag[i+1:]
+		}
+		v, exists := so.Tags[key]
+		if !exists || (i >= 0 && v != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTags parses a comma-separated list of 'key=value' pairs.
+func parseTags(s string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("tag %q is not of the form key=value", pair)
+		}
+		tags[pair[:i]] = pair[i+1:]
+	}
+	return tags, nil
+}
+
+// hostContractTagsHandler handles POST requests to the /host/contracts/:id/tags
+// API endpoint, setting and removing tags on a storage obligation.
+func (api *API) hostContractTagsHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	id, err := scanHash(ps.ByName("id"))
+	if err != nil {
+		WriteError(w, Error{"error when calling /host/contracts/:id/tags: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var set map[string]string
+	if s := req.FormValue("set"); s != "" {
+		set, err = parseTags(s)
+		if err != nil {
+			WriteError(w, Error{"error when calling /host/contracts/:id/tags: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	var remove []string
+	if s := req.FormValue("remove"); s != "" {
+		remove = strings.Split(s, ",")
+	}
+	err = api.host.UpdateStorageObligationTags(types.FileContractID(id), set, remove)
+	if err != nil {
+		WriteError(w, Error{"error when calling /host/contracts/:id/tags: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostFinancialsHandler handles the API call to get a summary of the host's
+// revenue and the outcomes of the host's storage obligations. The success rate
+// is the fraction of resolved storage obligations, excluding rejected ones,
+// which ended with a successful storage proof.
+func (api *API) hostFinancialsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	fm := api.host.FinancialMetrics()
+	hf := HostFinancialsGET{
+		AnticipatedRevenue: fm.PotentialContractCompensation.Add(fm.PotentialStorageRevenue).Add(fm.PotentialDownloadBandwidthRevenue).Add(fm.PotentialUploadBandwidthRevenue),
+		LostRevenue:        fm.LostRevenue,
+		Revenue:            fm.ContractCompensation.Add(fm.StorageRevenue).Add(fm.DownloadBandwidthRevenue).Add(fm.UploadBandwidthRevenue),
+	}
+	for _, so := range api.host.StorageObligations() {
+		switch so.ObligationStatus {
+		case modules.ObligationStatusUnresolved:
+			hf.ActiveObligations++
+		case modules.ObligationStatusRejected:
+			hf.RejectedObligations++
+		case modules.ObligationStatusSucceeded:
+			hf.SucceededObligations++
+		case modules.ObligationStatusFailed:
+			hf.FailedObligations++
+		}
+	}
+	if resolved := hf.SucceededObligations + hf.FailedObligations; resolved > 0 {
+		hf.SuccessRate = float64(hf.SucceededObligations) / float64(resolved)
+	}
+	WriteJSON(w, hf)
+}
+
+// hostInvariantsHandlerGET handles GET requests to the /host/invariants API
+// endpoint, checking the host's aggregate metrics against its storage
+// obligations.
+func (api *API) hostInvariantsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	hi := HostInvariantsGET{
+		Errors: []string{},
+	}
+	for _, err := range api.host.CheckObligationInvariants() {
+		hi.Errors = append(hi.Errors, err.Error())
+	}
+	WriteJSON(w, hi)
+}
+
+// hostInvariantsRepairHandler handles POST requests to the
+// /host/invariants/repair API endpoint, re-deriving the host's aggregate
+// metrics from its storage obligations.
+func (api *API) hostInvariantsRepairHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	err := api.host.RepairObligationInvariants()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // hostHandlerGET handles GET requests to the /host API endpoint, returning key
 // information about the host.
 func (api *API) hostHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -110,6 +298,81 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.AcceptingContracts = x
 	}
+	if req.FormValue("archivedir") != "" {
+		settings.ArchiveDir = req.FormValue("archivedir")
+	}
+	if req.FormValue("archiveretention") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("archiveretention"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ArchiveRetention = x
+	}
+	if req.FormValue("encryptsectors") != "" {
+		var x bool
+		_, err := fmt.Sscan(req.FormValue("encryptsectors"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.EncryptSectors = x
+	}
+	if req.FormValue("maintenanceend") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("maintenanceend"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaintenanceEnd = x
+	}
+	if req.FormValue("maintenancestart") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("maintenancestart"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaintenanceStart = x
+	}
+	if req.FormValue("maxconcurrentproofs") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxconcurrentproofs"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxConcurrentProofs = x
+	}
+	if req.FormValue("maxconfirmationdelay") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("maxconfirmationdelay"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxConfirmationDelay = x
+	}
+	if req.FormValue("maxdisklatency") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxdisklatency"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxDiskLatency = x
+	}
+	if req.FormValue("maxobligations") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxobligations"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxObligations = x
+	}
+	if req.FormValue("maxcontractrevisions") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxcontractrevisions"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxContractRevisions = x
+	}
 	if req.FormValue("maxdownloadbatchsize") != "" {
 		var x uint64
 		_, err := fmt.Sscan(req.FormValue("maxdownloadbatchsize"), &x)
@@ -126,6 +389,22 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.MaxDuration = x
 	}
+	if req.FormValue("maxrenterrequestrate") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxrenterrequestrate"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxRenterRequestRate = x
+	}
+	if req.FormValue("maxrentersessions") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxrentersessions"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxRenterSessions = x
+	}
 	if req.FormValue("maxrevisebatchsize") != "" {
 		var x uint64
 		_, err := fmt.Sscan(req.FormValue("maxrevisebatchsize"), &x)
@@ -134,6 +413,22 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.MaxReviseBatchSize = x
 	}
+	if req.FormValue("minduration") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("minduration"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MinDuration = x
+	}
+	if req.FormValue("minfilesize") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("minfilesize"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MinFileSize = x
+	}
 	if req.FormValue("netaddress") != "" {
 		var x modules.NetAddress
 		_, err := fmt.Sscan(req.FormValue("netaddress"), &x)
@@ -142,6 +437,30 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.NetAddress = x
 	}
+	if req.FormValue("reannounceinterval") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("reannounceinterval"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ReannounceInterval = x
+	}
+	if req.FormValue("resubmissionjitter") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("resubmissionjitter"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ResubmissionJitter = x
+	}
+	if req.FormValue("shutdowntimeout") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("shutdowntimeout"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ShutdownTimeout = x
+	}
 	if req.FormValue("windowsize") != "" {
 		var x types.BlockHeight
 		_, err := fmt.Sscan(req.FormValue("windowsize"), &x)
@@ -175,6 +494,14 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.MaxCollateral = x
 	}
+	if req.FormValue("trustedrentercollateral") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("trustedrentercollateral"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.TrustedRenterCollateral = x
+	}
 
 	if req.FormValue("mincontractprice") != "" {
 		var x types.Currency
@@ -208,10 +535,43 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.MinUploadBandwidthPrice = x
 	}
+	if req.FormValue("storagepricetiers") != "" {
+		x, err := parseStoragePriceTiers(req.FormValue("storagepricetiers"))
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.StoragePriceTiers = x
+	}
 
 	return settings, nil
 }
 
+// parseStoragePriceTiers parses a comma separated list of storage price tiers
+// in the form "minduration:storageprice". The value "none" removes all tiers.
+func parseStoragePriceTiers(s string) ([]modules.HostStoragePriceTier, error) {
+	if s == "none" {
+		return nil, nil
+	}
+	var tiers []modules.HostStoragePriceTier
+	for _, t := range strings.Split(s, ",") {
+		parts := strings.Split(t, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("storage price tier %q is not of the form minduration:storageprice", t)
+		}
+		var tier modules.HostStoragePriceTier
+		_, err := fmt.Sscan(parts[0], &tier.MinDuration)
+		if err != nil {
+			return nil, err
+		}
+		_, err = fmt.Sscan(parts[1], &tier.StoragePrice)
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, tier)
+	}
+	return tiers, nil
+}
+
 // hostEstimateScoreGET handles the POST request to /host/estimatescore and
 // computes an estimated HostDB score for the provided settings.
 func (api *API) hostEstimateScoreGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -282,6 +642,26 @@ func (api *API) hostHandlerPOST(w http.ResponseWriter, req *http.Request, _ http
 // hostAnnounceHandler handles the API call to get the host to announce itself
 // to the network.
 func (api *API) hostAnnounceHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// Optionally check that the host can reach itself through the address
+	// before announcing it.
+	var check bool
+	if c := req.FormValue("checkreachable"); c != "" {
+		if _, err := fmt.Sscan(c, &check); err != nil {
+			WriteError(w, Error{"unable to parse checkreachable: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if check {
+		addr := modules.NetAddress(req.FormValue("netaddress"))
+		if addr == "" {
+			addr = api.host.ExternalSettings().NetAddress
+		}
+		if err := api.host.CheckReachable(addr); err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
 	var err error
 	if addr := req.FormValue("netaddress"); addr != "" {
 		err = api.host.AnnounceAddress(modules.NetAddress(addr))
@@ -295,6 +675,98 @@ func (api *API) hostAnnounceHandler(w http.ResponseWriter, req *http.Request, _
 	WriteSuccess(w)
 }
 
+// hostBackupHandler handles POST requests to the /host/backup API endpoint,
+// writing the host's storage obligations to a backup file.
+func (api *API) hostBackupHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	destination := req.FormValue("destination")
+	if !filepath.IsAbs(destination) {
+		WriteError(w, Error{"error when calling /host/backup: destination must be an absolute path"}, http.StatusBadRequest)
+		return
+	}
+	err := api.host.BackupStorageObligations(destination)
+	if err != nil {
+		WriteError(w, Error{"error when calling /host/backup: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostRestoreHandler handles POST requests to the /host/restore API endpoint,
+// loading the storage obligations from a backup file into the host.
+func (api *API) hostRestoreHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	source := req.FormValue("source")
+	if !filepath.IsAbs(source) {
+		WriteError(w, Error{"error when calling /host/restore: source must be an absolute path"}, http.StatusBadRequest)
+		return
+	}
+	err := api.host.RestoreStorageObligations(source)
+	if err != nil {
+		WriteError(w, Error{"error when calling /host/restore: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostSectorKeysRotateHandler handles POST requests to the
+// /host/sectorkeys/rotate API endpoint, generating a new key for the host to
+// encrypt sectors with.
+func (api *API) hostSectorKeysRotateHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	err := api.host.RotateSectorKey()
+	if err != nil {
+		WriteError(w, Error{"error when calling /host/sectorkeys/rotate: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostStorageProofHandler handles POST requests to the /host/storageproof/:id
+// API endpoint, making the host rebuild and submit the storage proof of a
+// storage obligation right away.
+func (api *API) hostStorageProofHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	id, err := scanHash(ps.ByName("id"))
+	if err != nil {
+		WriteError(w, Error{"error when calling /host/storageproof: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.host.SubmitStorageProof(types.FileContractID(id))
+	if err != nil {
+		WriteError(w, Error{"error when calling /host/storageproof: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostQuoteHandlerGET handles the API call to quote the price of a file
+// contract without forming it.
+func (api *API) hostQuoteHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var fileSize uint64
+	if _, err := fmt.Sscan(req.FormValue("filesize"), &fileSize); err != nil {
+		WriteError(w, Error{"unable to parse filesize: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var duration types.BlockHeight
+	if _, err := fmt.Sscan(req.FormValue("duration"), &duration); err != nil {
+		WriteError(w, Error{"unable to parse duration: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Default to the collateral that the host would risk. (optional parameter)
+	var collateral types.Currency
+	if c := req.FormValue("collateral"); c != "" {
+		var ok bool
+		collateral, ok = scanAmount(c)
+		if !ok {
+			WriteError(w, Error{"unable to parse collateral"}, http.StatusBadRequest)
+			return
+		}
+	}
+	quote, err := api.host.QuoteContract(fileSize, duration, collateral)
+	if err != nil {
+		WriteError(w, Error{"error when calling /host/quote: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, quote)
+}
+
 // storageHandler returns a bunch of information about storage management on
 // the host.
 func (api *API) storageHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -374,6 +846,17 @@ func (api *API) storageFoldersRemoveHandler(w http.ResponseWriter, req *http.Req
 	WriteSuccess(w)
 }
 
+// storageFoldersDefragHandler handles the API call that starts a defrag of
+// the host's storage folders.
+func (api *API) storageFoldersDefragHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	err := api.host.DefragStorageFolders()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // storageSectorsDeleteHandler handles the call to delete a sector from the
 // storage manager.
 func (api *API) storageSectorsDeleteHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -304,6 +305,67 @@ func TestConnectabilityStatus(t *testing.T) {
 	}
 }
 
+// TestHostFinancials checks that /host/financials reports an empty summary for
+// a host without any storage obligations.
+func TestHostFinancials(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var hf HostFinancialsGET
+	err = st.getAPI("/host/financials", &hf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hf.Revenue.IsZero() || !hf.AnticipatedRevenue.IsZero() || !hf.LostRevenue.IsZero() {
+		t.Error("host without storage obligations reports revenue:", hf)
+	}
+	if hf.ActiveObligations != 0 || hf.SucceededObligations != 0 || hf.FailedObligations != 0 || hf.RejectedObligations != 0 {
+		t.Error("host without storage obligations reports obligations:", hf)
+	}
+	if hf.SuccessRate != 0 {
+		t.Error("success rate should be zero when no obligations have resolved:", hf.SuccessRate)
+	}
+}
+
+// TestHostContractsFilterParsing checks that /host/contracts accepts the
+// boolean values understood by strconv.ParseBool for its filters and rejects
+// anything else.
+func TestHostContractsFilterParsing(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var cts ContractInfoGET
+	for _, query := range []string{"", "?active=1", "?active=false", "?unconfirmed=TRUE", "?active=t&unconfirmed=f"} {
+		if err := st.getAPI("/host/contracts"+query, &cts); err != nil {
+			t.Errorf("%q was rejected: %v", query, err)
+		}
+	}
+	for _, query := range []string{"?active=yes", "?unconfirmed=2"} {
+		resp, err := HttpGET("http://" + st.server.listener.Addr().String() + "/host/contracts" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%q: expected status %v, got %v", query, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
 // TestStorageHandler tests that host storage is being reported correctly.
 func TestStorageHandler(t *testing.T) {
 	if testing.Short() {
@@ -13,10 +13,11 @@ type (
 	// MinerGET contains the information that is returned after a GET request
 	// to /miner.
 	MinerGET struct {
-		BlocksMined      int  `json:"blocksmined"`
-		CPUHashrate      int  `json:"cpuhashrate"`
-		CPUMining        bool `json:"cpumining"`
-		StaleBlocksMined int  `json:"staleblocksmined"`
+		BlocksMined      int              `json:"blocksmined"`
+		CPUHashrate      int              `json:"cpuhashrate"`
+		CPUMining        bool             `json:"cpumining"`
+		PayoutAddress    types.UnlockHash `json:"payoutaddress"`
+		StaleBlocksMined int              `json:"staleblocksmined"`
 	}
 )
 
@@ -27,11 +28,33 @@ func (api *API) minerHandler(w http.ResponseWriter, req *http.Request, _ httprou
 		BlocksMined:      blocksMined,
 		CPUHashrate:      api.miner.CPUHashrate(),
 		CPUMining:        api.miner.CPUMining(),
+		PayoutAddress:    api.miner.PayoutAddress(),
 		StaleBlocksMined: staleMined,
 	}
 	WriteJSON(w, mg)
 }
 
+// minerHandlerPOST handles the API call that changes the miner's settings.
+func (api *API) minerHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// Scan the payout address. An empty address pays the rewards to the
+	// wallet again.
+	var addr types.UnlockHash
+	if a := req.FormValue("payoutaddress"); a != "" {
+		var err error
+		addr, err = scanAddress(a)
+		if err != nil {
+			WriteError(w, Error{"unable to parse payoutaddress: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	err := api.miner.SetPayoutAddress(addr)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // minerStartHandler handles the API call that starts the miner.
 func (api *API) minerStartHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	api.miner.StartCPUMining()
@@ -2,6 +2,7 @@ package api
 
 import (
 	"io/ioutil"
+	"net/url"
 	"testing"
 	"time"
 
@@ -44,6 +45,53 @@ func TestMinerGET(t *testing.T) {
 	}
 }
 
+// TestMinerPayoutAddress checks that the payout address of the miner can be
+// set and reset through the /miner endpoint.
+func TestMinerPayoutAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Malformed addresses should be rejected.
+	values := url.Values{}
+	values.Set("payoutaddress", "foo")
+	if err := st.stdPostAPI("/miner", values); err == nil {
+		t.Fatal("expected an error when setting a malformed payout address")
+	}
+
+	// Set a valid address.
+	addr := types.UnlockHash{1, 2, 3}
+	values.Set("payoutaddress", addr.String())
+	if err := st.stdPostAPI("/miner", values); err != nil {
+		t.Fatal(err)
+	}
+	var mg MinerGET
+	if err := st.getAPI("/miner", &mg); err != nil {
+		t.Fatal(err)
+	}
+	if mg.PayoutAddress != addr {
+		t.Fatal("payout address was not set:", mg.PayoutAddress)
+	}
+
+	// Reset the address.
+	values.Set("payoutaddress", "")
+	if err := st.stdPostAPI("/miner", values); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.getAPI("/miner", &mg); err != nil {
+		t.Fatal(err)
+	}
+	if mg.PayoutAddress != (types.UnlockHash{}) {
+		t.Fatal("payout address was not reset:", mg.PayoutAddress)
+	}
+}
+
 // TestMinerStartStop checks that the miner start and miner stop api endpoints
 // toggle the cpu miner.
 func TestMinerStartStop(t *testing.T) {
@@ -2,6 +2,8 @@ package api
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"path/filepath"
 	"sort"
@@ -69,6 +71,25 @@ type (
 		Settings         modules.RenterSettings     `json:"settings"`
 		FinancialMetrics modules.ContractorSpending `json:"financialmetrics"`
 		CurrentPeriod    types.BlockHeight          `json:"currentperiod"`
+		PriceCapReport   modules.PriceCapReport     `json:"pricecapreport"`
+	}
+
+	// RenterSpendingGET breaks down how the allowance has been spent during
+	// the current period.
+	RenterSpendingGET struct {
+		Allowance        types.Currency    `json:"allowance"`
+		PeriodStart      types.BlockHeight `json:"periodstart"`
+		StorageSpending  types.Currency    `json:"storagespending"`
+		UploadSpending   types.Currency    `json:"uploadspending"`
+		DownloadSpending types.Currency    `json:"downloadspending"`
+		ContractFees     types.Currency    `json:"contractfees"`
+		Unspent          types.Currency    `json:"unspent"`
+	}
+
+	// RenterBlacklistGET contains the public keys of the hosts that the
+	// renter won't form contracts with.
+	RenterBlacklistGET struct {
+		Hosts []types.SiaPublicKey `json:"hosts"`
 	}
 
 	// RenterContract represents a contract formed by the renter.
@@ -118,6 +139,12 @@ type (
 		Contracts []RenterContract `json:"contracts"`
 	}
 
+	// RenterContractsHealthGET contains the results of the health scans of
+	// the hosts of the renter's contracts.
+	RenterContractsHealthGET struct {
+		Contracts []modules.ContractHealth `json:"contracts"`
+	}
+
 	// RenterDownloadQueue contains the renter's download queue.
 	RenterDownloadQueue struct {
 		Downloads []DownloadInfo `json:"downloads"`
@@ -128,6 +155,11 @@ type (
 		Files []modules.FileInfo `json:"files"`
 	}
 
+	// RenterFileHealthGET contains the piece health of a file.
+	RenterFileHealthGET struct {
+		modules.FileHealth
+	}
+
 	// RenterLoad lists files that were loaded into the renter.
 	RenterLoad struct {
 		FilesAdded []string `json:"filesadded"`
@@ -170,6 +202,21 @@ func (api *API) renterHandlerGET(w http.ResponseWriter, req *http.Request, _ htt
 		Settings:         settings,
 		FinancialMetrics: api.renter.PeriodSpending(),
 		CurrentPeriod:    periodStart,
+		PriceCapReport:   api.renter.PriceCapReport(),
+	})
+}
+
+// renterSpendingHandler handles the API call to /renter/spending.
+func (api *API) renterSpendingHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	spending := api.renter.PeriodSpending()
+	WriteJSON(w, RenterSpendingGET{
+		Allowance:        api.renter.Settings().Allowance.Funds,
+		PeriodStart:      api.renter.CurrentPeriod(),
+		StorageSpending:  spending.StorageSpending,
+		UploadSpending:   spending.UploadSpending,
+		DownloadSpending: spending.DownloadSpending,
+		ContractFees:     spending.ContractFees,
+		Unspent:          spending.Unspent,
 	})
 }
 
@@ -248,6 +295,73 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		}
 		settings.MaxUploadSpeed = uploadSpeed
 	}
+	// Scan the repair limits. (optional parameters)
+	limits := []struct {
+		param string
+		limit *int
+	}{
+		{"maxconcurrentrepairs", &settings.MaxConcurrentRepairs},
+		{"maxhostuploadqueue", &settings.MaxHostUploadQueue},
+	}
+	for _, l := range limits {
+		if v := req.FormValue(l.param); v != "" {
+			if _, err := fmt.Sscan(v, l.limit); err != nil {
+				WriteError(w, Error{"unable to parse " + l.param + ": " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	// Scan the maximum host downtime. (optional parameter)
+	if d := req.FormValue("maxhostdowntime"); d != "" {
+		if _, err := fmt.Sscan(d, &settings.MaxHostDowntime); err != nil {
+			WriteError(w, Error{"unable to parse maxhostdowntime: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	// Scan the maximum small file size. (optional parameter)
+	if s := req.FormValue("maxsmallfilesize"); s != "" {
+		if _, err := fmt.Sscan(s, &settings.MaxSmallFileSize); err != nil {
+			WriteError(w, Error{"unable to parse maxsmallfilesize: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	// Scan the price caps. (optional parameters)
+	caps := []struct {
+		param string
+		price *types.Currency
+	}{
+		{"maxstorageprice", &settings.MaxStoragePrice},
+		{"maxuploadbandwidthprice", &settings.MaxUploadBandwidthPrice},
+		{"maxdownloadbandwidthprice", &settings.MaxDownloadBandwidthPrice},
+	}
+	for _, pc := range caps {
+		if v := req.FormValue(pc.param); v != "" {
+			price, ok := scanAmount(v)
+			if !ok {
+				WriteError(w, Error{"unable to parse " + pc.param}, http.StatusBadRequest)
+				return
+			}
+			*pc.price = price
+		}
+	}
+	// Scan the host score weights. (optional parameters)
+	weights := []struct {
+		param  string
+		weight *float64
+	}{
+		{"collateralweight", &settings.ScoreWeights.Collateral},
+		{"priceweight", &settings.ScoreWeights.Price},
+		{"storageweight", &settings.ScoreWeights.Storage},
+		{"uptimeweight", &settings.ScoreWeights.Uptime},
+	}
+	for _, sw := range weights {
+		if v := req.FormValue(sw.param); v != "" {
+			if _, err := fmt.Sscan(v, sw.weight); err != nil {
+				WriteError(w, Error{"unable to parse " + sw.param + ": " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		}
+	}
 	// Set the settings in the renter.
 	err := api.renter.SetSettings(settings)
 	if err != nil {
@@ -257,54 +371,207 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 	WriteSuccess(w)
 }
 
+// renterBlacklistHandlerGET handles the API call to request the renter's host
+// blacklist.
+func (api *API) renterBlacklistHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, RenterBlacklistGET{
+		Hosts: api.renter.HostBlacklist(),
+	})
+}
+
+// renterBlacklistHandlerPOST handles the API call to add a host to the
+// renter's blacklist.
+func (api *API) renterBlacklistHandlerPOST(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	var pk types.SiaPublicKey
+	pk.LoadString(ps.ByName("pubkey"))
+	if len(pk.Key) == 0 {
+		WriteError(w, Error{"unable to parse host public key"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.BlacklistHost(pk); err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterBackupHandlerPOST handles the API call to write the contracts and
+// files of the renter to an encrypted backup file. The backup is encrypted
+// with a key derived from the wallet seed, so the wallet must be unlocked.
+func (api *API) renterBackupHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	destination := req.FormValue("destination")
+	if !filepath.IsAbs(destination) {
+		WriteError(w, Error{"error when calling /renter/backup: destination must be an absolute path"}, http.StatusBadRequest)
+		return
+	}
+	seed, _, err := api.wallet.PrimarySeed()
+	if err != nil {
+		WriteError(w, Error{"error when calling /renter/backup: unable to get the wallet seed: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.renter.CreateBackup(destination, seed)
+	if err != nil {
+		WriteError(w, Error{"error when calling /renter/backup: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterRestoreHandlerPOST handles the API call to load the contracts and
+// files of an encrypted backup file into the renter.
+func (api *API) renterRestoreHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	source := req.FormValue("source")
+	if !filepath.IsAbs(source) {
+		WriteError(w, Error{"error when calling /renter/restore: source must be an absolute path"}, http.StatusBadRequest)
+		return
+	}
+	seed, _, err := api.wallet.PrimarySeed()
+	if err != nil {
+		WriteError(w, Error{"error when calling /renter/restore: unable to get the wallet seed: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.renter.RestoreBackup(source, seed)
+	if err != nil {
+		WriteError(w, Error{"error when calling /renter/restore: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterUnblacklistHandlerPOST handles the API call to remove a host from the
+// renter's blacklist.
+func (api *API) renterUnblacklistHandlerPOST(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	var pk types.SiaPublicKey
+	pk.LoadString(ps.ByName("pubkey"))
+	if len(pk.Key) == 0 {
+		WriteError(w, Error{"unable to parse host public key"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.UnblacklistHost(pk); err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // renterContractsHandler handles the API call to request the Renter's contracts.
 func (api *API) renterContractsHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	contracts := []RenterContract{}
 	for _, c := range api.renter.Contracts() {
-		var size uint64
-		if len(c.Transaction.FileContractRevisions) != 0 {
-			size = c.Transaction.FileContractRevisions[0].NewFileSize
-		}
+		contracts = append(contracts, api.renterContract(c))
+	}
+	WriteJSON(w, RenterContracts{
+		Contracts: contracts,
+	})
+}
 
-		// Fetch host address
-		var netAddress modules.NetAddress
-		hdbe, exists := api.renter.Host(c.HostPublicKey)
-		if exists {
-			netAddress = hdbe.NetAddress
+// renterContractsExpiringHandler handles the API call to request the renter's
+// contracts that are about to expire.
+func (api *API) renterContractsExpiringHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// Default to the renew window of the allowance. (optional parameter)
+	threshold := api.renter.Settings().Allowance.RenewWindow
+	if t := req.FormValue("threshold"); t != "" {
+		if _, err := fmt.Sscan(t, &threshold); err != nil {
+			WriteError(w, Error{"unable to parse threshold: " + err.Error()}, http.StatusBadRequest)
+			return
 		}
+	}
+	contracts := []RenterContract{}
+	for _, c := range api.renter.ExpiringContracts(threshold) {
+		contracts = append(contracts, api.renterContract(c))
+	}
+	WriteJSON(w, RenterContracts{
+		Contracts: contracts,
+	})
+}
 
-		// Fetch utilities for contract
-		var goodForUpload bool
-		var goodForRenew bool
-		if utility, ok := api.renter.ContractUtility(c.ID); ok {
-			goodForUpload = utility.GoodForUpload
-			goodForRenew = utility.GoodForRenew
+// renterContractsFormHandlerPOST handles the API call to form contracts with
+// a set of hosts chosen by the renter. The formed contracts are returned. If
+// some of the contracts could not be formed, an error is returned instead, and
+// the contracts that were formed show up in /renter/contracts.
+func (api *API) renterContractsFormHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var keys []types.SiaPublicKey
+	for _, s := range strings.Split(req.FormValue("hosts"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
 		}
-
-		contracts = append(contracts, RenterContract{
-			DownloadSpending:          c.DownloadSpending,
-			EndHeight:                 c.EndHeight,
-			Fees:                      c.TxnFee.Add(c.SiafundFee).Add(c.ContractFee),
-			GoodForUpload:             goodForUpload,
-			GoodForRenew:              goodForRenew,
-			HostPublicKey:             c.HostPublicKey,
-			ID:                        c.ID,
-			LastTransaction:           c.Transaction,
-			NetAddress:                netAddress,
-			RenterFunds:               c.RenterFunds,
-			Size:                      size,
-			StartHeight:               c.StartHeight,
-			StorageSpending:           c.StorageSpending,
-			StorageSpendingDeprecated: c.StorageSpending,
-			TotalCost:                 c.TotalCost,
-			UploadSpending:            c.UploadSpending,
-		})
+		var pk types.SiaPublicKey
+		pk.LoadString(s)
+		if len(pk.Key) == 0 {
+			WriteError(w, Error{"unable to parse host public key " + s}, http.StatusBadRequest)
+			return
+		}
+		keys = append(keys, pk)
+	}
+	formed, err := api.renter.FormContracts(keys)
+	if err != nil {
+		WriteError(w, Error{fmt.Sprintf("formed %v of %v contracts: %v", len(formed), len(keys), err)}, http.StatusBadRequest)
+		return
+	}
+	contracts := []RenterContract{}
+	for _, c := range formed {
+		contracts = append(contracts, api.renterContract(c))
 	}
 	WriteJSON(w, RenterContracts{
 		Contracts: contracts,
 	})
 }
 
+// renterContractsHealthHandler handles the API call to request the results of
+// the health scans of the hosts of the renter's contracts.
+func (api *API) renterContractsHealthHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	contracts := api.renter.ContractHealth()
+	if contracts == nil {
+		contracts = []modules.ContractHealth{}
+	}
+	WriteJSON(w, RenterContractsHealthGET{
+		Contracts: contracts,
+	})
+}
+
+// renterContract converts a modules.RenterContract into the API's
+// representation of a contract.
+func (api *API) renterContract(c modules.RenterContract) RenterContract {
+	var size uint64
+	if len(c.Transaction.FileContractRevisions) != 0 {
+		size = c.Transaction.FileContractRevisions[0].NewFileSize
+	}
+
+	// Fetch host address
+	var netAddress modules.NetAddress
+	hdbe, exists := api.renter.Host(c.HostPublicKey)
+	if exists {
+		netAddress = hdbe.NetAddress
+	}
+
+	// Fetch utilities for contract
+	var goodForUpload bool
+	var goodForRenew bool
+	if utility, ok := api.renter.ContractUtility(c.ID); ok {
+		goodForUpload = utility.GoodForUpload
+		goodForRenew = utility.GoodForRenew
+	}
+
+	return RenterContract{
+		DownloadSpending:          c.DownloadSpending,
+		EndHeight:                 c.EndHeight,
+		Fees:                      c.TxnFee.Add(c.SiafundFee).Add(c.ContractFee),
+		GoodForUpload:             goodForUpload,
+		GoodForRenew:              goodForRenew,
+		HostPublicKey:             c.HostPublicKey,
+		ID:                        c.ID,
+		LastTransaction:           c.Transaction,
+		NetAddress:                netAddress,
+		RenterFunds:               c.RenterFunds,
+		Size:                      size,
+		StartHeight:               c.StartHeight,
+		StorageSpending:           c.StorageSpending,
+		StorageSpendingDeprecated: c.StorageSpending,
+		TotalCost:                 c.TotalCost,
+		UploadSpending:            c.UploadSpending,
+	}
+}
+
 // renterDownloadsHandler handles the API call to request the download queue.
 func (api *API) renterDownloadsHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	var downloads []DownloadInfo
@@ -373,6 +640,54 @@ func (api *API) renterRenameHandler(w http.ResponseWriter, req *http.Request, ps
 	WriteSuccess(w)
 }
 
+// renterPauseUploadHandler handles the API call to pause the upload of a file.
+func (api *API) renterPauseUploadHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	err := api.renter.PauseUpload(strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
+// renterResumeUploadHandler handles the API call to resume the upload of a
+// paused file.
+func (api *API) renterResumeUploadHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	err := api.renter.ResumeUpload(strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
+// renterMigrateHandler handles the API call to migrate the pieces of a file
+// away from a set of hosts.
+func (api *API) renterMigrateHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var hosts []types.SiaPublicKey
+	for _, s := range strings.Split(req.FormValue("hosts"), ",") {
+		if s == "" {
+			continue
+		}
+		var pk types.SiaPublicKey
+		pk.LoadString(s)
+		if len(pk.Key) == 0 {
+			WriteError(w, Error{"unable to parse host public key " + s}, http.StatusBadRequest)
+			return
+		}
+		hosts = append(hosts, pk)
+	}
+	err := api.renter.MigrateFile(strings.TrimPrefix(ps.ByName("siapath"), "/"), hosts)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
 // renterFilesHandler handles the API call to list all of the files.
 func (api *API) renterFilesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	WriteJSON(w, RenterFiles{
@@ -380,6 +695,19 @@ func (api *API) renterFilesHandler(w http.ResponseWriter, req *http.Request, _ h
 	})
 }
 
+// renterHealthHandler handles the API call to report the piece health of a
+// file.
+func (api *API) renterHealthHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	health, err := api.renter.FileHealth(strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterFileHealthGET{
+		FileHealth: health,
+	})
+}
+
 // renterPricesHandler reports the expected costs of various actions given the
 // renter settings and the set of available hosts.
 func (api *API) renterPricesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -475,7 +803,7 @@ func parseDownloadParameters(w http.ResponseWriter, req *http.Request, ps httpro
 	if len(lengthparam) > 0 {
 		_, err := fmt.Sscan(lengthparam, &length)
 		if err != nil {
-			return modules.RenterDownloadParameters{}, build.ExtendErr("could not decode the offset as uint64: ", err)
+			return modules.RenterDownloadParameters{}, build.ExtendErr("could not decode the length as uint64: ", err)
 		}
 	}
 
@@ -584,12 +912,12 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 		// Verify that sane values for parityPieces and redundancy are being
 		// supplied.
 		if parityPieces < requiredParityPieces {
-			WriteError(w, Error{fmt.Sprintf("a minimum of %v parity pieces is required, but %v parity pieces requested", parityPieces, requiredParityPieces)}, http.StatusBadRequest)
+			WriteError(w, Error{fmt.Sprintf("a minimum of %v parity pieces is required, but %v parity pieces requested", requiredParityPieces, parityPieces)}, http.StatusBadRequest)
 			return
 		}
 		redundancy := float64(dataPieces+parityPieces) / float64(dataPieces)
-		if float64(dataPieces+parityPieces)/float64(dataPieces) < requiredRedundancy {
-			WriteError(w, Error{fmt.Sprintf("a redundancy of %.2f is required, but redundancy of %.2f supplied", redundancy, requiredRedundancy)}, http.StatusBadRequest)
+		if redundancy < requiredRedundancy {
+			WriteError(w, Error{fmt.Sprintf("a redundancy of %.2f is required, but redundancy of %.2f supplied", requiredRedundancy, redundancy)}, http.StatusBadRequest)
 			return
 		}
 
@@ -613,3 +941,34 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 	}
 	WriteSuccess(w)
 }
+
+// renterUploadBytesHandler handles the API call to upload the request body as
+// a small file, returning once the file has been uploaded.
+func (api *API) renterUploadBytesHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// Read one byte more than the limit so that the renter rejects files
+	// that are too large.
+	limit := int64(api.renter.Settings().MaxSmallFileSize) + 1
+	data, err := ioutil.ReadAll(io.LimitReader(req.Body, limit))
+	if err != nil {
+		WriteError(w, Error{"unable to read the request body: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.renter.UploadBytes(strings.TrimPrefix(ps.ByName("siapath"), "/"), data)
+	if err != nil {
+		WriteError(w, Error{"upload failed: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterDownloadBytesHandler handles the API call to download a small file,
+// writing its contents to the response once the download has completed.
+func (api *API) renterDownloadBytesHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	data, err := api.renter.DownloadBytes(strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{"download failed: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(data)
+}
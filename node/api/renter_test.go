@@ -126,8 +126,10 @@ func runDownloadTest(t *testing.T, filesize, offset, length int64, useHttpResp b
 	if err != nil {
 		return err
 	}
+	// The renter clamps ranges that reach past the end of the file, so the
+	// section may be shorter than the requested length.
 	_, err = io.CopyN(&originalBytes, uf, length)
-	if err != nil {
+	if err != nil && err != io.EOF {
 		return err
 	}
 
@@ -191,8 +193,8 @@ func runDownloadTest(t *testing.T, filesize, offset, length int64, useHttpResp b
 	}
 
 	// should have correct length
-	if int64(downbytes.Len()) != length {
-		return fmt.Errorf("downloaded file has incorrect size: %d, %d expected", downbytes.Len(), length)
+	if downbytes.Len() != originalBytes.Len() {
+		return fmt.Errorf("downloaded file has incorrect size: %d, %d expected", downbytes.Len(), originalBytes.Len())
 	}
 
 	// should be byte-for-byte equal to the original uploaded file
@@ -264,6 +266,8 @@ func TestValidDownloads(t *testing.T) {
 		{sectorSize * 2, 50, int64(float64(sectorSize*2) * 0.75), false, "ShortLengthAndOffsetTwoChunk"},
 		{sectorSize * 3, 50, int64(float64(sectorSize*3) * 0.5), false, "ShortLengthAndOffsetThreeChunkInSecondChunk"},
 		{sectorSize * 3, 50, int64(float64(sectorSize*3) * 0.75), false, "ShortLengthAndOffsetThreeChunkInThirdChunk"},
+		{sectorSize, 40, sectorSize, false, "LengthPastEOFSingleChunk"},
+		{sectorSize * 2, sectorSize - 10, sectorSize * 2, false, "LengthPastEOFTwoChunk"},
 
 		// http response tests.
 		{sectorSize, 40, sectorSize - 40, true, "HttpRespOffsetSingleChunk"},
@@ -273,6 +277,7 @@ func TestValidDownloads(t *testing.T) {
 		{sectorSize * 2, 80, 3 * (sectorSize * 2) / 4, true, "RespOffsetAndLengthTwoChunk"},
 		{sectorSize * 5, 150, 3 * (sectorSize * 5) / 4, true, "HttpRespOffsetAndLengthManyChunks"},
 		{sectorSize * 5, 150, sectorSize * 5 / 4, true, "HttpRespOffsetAndLengthManyChunksSubsetOfChunks"},
+		{sectorSize * 3, sectorSize + 30, sectorSize * 3, true, "HttpRespLengthPastEOFManyChunks"},
 	}
 	for i, params := range testParams {
 		params := params
@@ -315,8 +320,7 @@ func TestInvalidDownloadParameters(t *testing.T) {
 	}{
 		{0, -10, 1e4, "/download not prompting error when passing negative offset."},
 		{0, 1e4, 1e4, "/download not prompting error when passing offset equal to filesize."},
-		{1e4 + 1, 0, 1e4, "/download not prompting error when passing length exceeding filesize."},
-		{1e4 + 11, 10, 1e4, "/download not prompting error when passing length exceeding filesize with non-zero offset."},
+		{0, 1e4 + 10, 1e4, "/download not prompting error when passing offset exceeding filesize."},
 		{-1, 0, 1e4, "/download not prompting error when passing negative length."},
 	}
 
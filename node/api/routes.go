@@ -27,6 +27,8 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 	if api.cs != nil {
 		router.GET("/consensus", api.consensusHandler)
 		router.GET("/consensus/blocks", api.consensusBlocksHandler)
+		router.GET("/consensus/siacoinoutputs/:id", api.consensusSiacoinOutputsHandler)
+		router.GET("/consensus/siafundoutputs/:id", api.consensusSiafundOutputsHandler)
 		router.POST("/consensus/validate/transactionset", api.consensusValidateTransactionsetHandler)
 	}
 
@@ -34,14 +36,22 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 	if api.explorer != nil {
 		router.GET("/explorer", api.explorerHandler)
 		router.GET("/explorer/blocks/:height", api.explorerBlocksHandler)
+		router.GET("/explorer/blockstats", api.explorerBlockStatsHandler)
 		router.GET("/explorer/hashes/:hash", api.explorerHashHandler)
+		router.GET("/explorer/contracts/:unlockhash", api.explorerContractsHandler)
 	}
 
 	// Gateway API Calls
 	if api.gateway != nil {
 		router.GET("/gateway", api.gatewayHandler)
+		router.POST("/gateway", RequirePassword(api.gatewayHandlerPOST, requiredPassword))
+		router.GET("/gateway/peers", api.gatewayPeersHandler)
 		router.POST("/gateway/connect/:netaddress", RequirePassword(api.gatewayConnectHandler, requiredPassword))
 		router.POST("/gateway/disconnect/:netaddress", RequirePassword(api.gatewayDisconnectHandler, requiredPassword))
+		router.POST("/gateway/pin/:netaddress", RequirePassword(api.gatewayPinHandler, requiredPassword))
+		router.POST("/gateway/unpin/:netaddress", RequirePassword(api.gatewayUnpinHandler, requiredPassword))
+		router.POST("/gateway/block/:host", RequirePassword(api.gatewayBlockHandler, requiredPassword))
+		router.POST("/gateway/unblock/:host", RequirePassword(api.gatewayUnblockHandler, requiredPassword))
 	}
 
 	// Host API Calls
@@ -50,12 +60,22 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 		router.GET("/host", api.hostHandlerGET)                                                   // Get the host status.
 		router.POST("/host", RequirePassword(api.hostHandlerPOST, requiredPassword))              // Change the settings of the host.
 		router.POST("/host/announce", RequirePassword(api.hostAnnounceHandler, requiredPassword)) // Announce the host to the network.
+		router.POST("/host/backup", RequirePassword(api.hostBackupHandler, requiredPassword))     // Back up the storage obligations of the host.
 		router.GET("/host/contracts", api.hostContractInfoHandler)                                // Get info about contracts.
+		router.POST("/host/contracts/:id/tags", RequirePassword(api.hostContractTagsHandler, requiredPassword))
 		router.GET("/host/estimatescore", api.hostEstimateScoreGET)
+		router.GET("/host/financials", api.hostFinancialsHandler)
+		router.GET("/host/invariants", api.hostInvariantsHandlerGET)
+		router.GET("/host/quote", api.hostQuoteHandlerGET)
+		router.POST("/host/invariants/repair", RequirePassword(api.hostInvariantsRepairHandler, requiredPassword))
+		router.POST("/host/restore", RequirePassword(api.hostRestoreHandler, requiredPassword))
+		router.POST("/host/sectorkeys/rotate", RequirePassword(api.hostSectorKeysRotateHandler, requiredPassword))
+		router.POST("/host/storageproof/:id", RequirePassword(api.hostStorageProofHandler, requiredPassword))
 
 		// Calls pertaining to the storage manager that the host uses.
 		router.GET("/host/storage", api.storageHandler)
 		router.POST("/host/storage/folders/add", RequirePassword(api.storageFoldersAddHandler, requiredPassword))
+		router.POST("/host/storage/folders/defrag", RequirePassword(api.storageFoldersDefragHandler, requiredPassword))
 		router.POST("/host/storage/folders/remove", RequirePassword(api.storageFoldersRemoveHandler, requiredPassword))
 		router.POST("/host/storage/folders/resize", RequirePassword(api.storageFoldersResizeHandler, requiredPassword))
 		router.POST("/host/storage/sectors/delete/:merkleroot", RequirePassword(api.storageSectorsDeleteHandler, requiredPassword))
@@ -64,6 +84,7 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 	// Miner API Calls
 	if api.miner != nil {
 		router.GET("/miner", api.minerHandler)
+		router.POST("/miner", RequirePassword(api.minerHandlerPOST, requiredPassword))
 		router.GET("/miner/header", RequirePassword(api.minerHeaderHandlerGET, requiredPassword))
 		router.POST("/miner/header", RequirePassword(api.minerHeaderHandlerPOST, requiredPassword))
 		router.GET("/miner/start", RequirePassword(api.minerStartHandler, requiredPassword))
@@ -74,10 +95,19 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 	if api.renter != nil {
 		router.GET("/renter", api.renterHandlerGET)
 		router.POST("/renter", RequirePassword(api.renterHandlerPOST, requiredPassword))
+		router.POST("/renter/backup", RequirePassword(api.renterBackupHandlerPOST, requiredPassword))
+		router.GET("/renter/blacklist", api.renterBlacklistHandlerGET)
+		router.POST("/renter/blacklist/:pubkey", RequirePassword(api.renterBlacklistHandlerPOST, requiredPassword))
+		router.POST("/renter/unblacklist/:pubkey", RequirePassword(api.renterUnblacklistHandlerPOST, requiredPassword))
 		router.GET("/renter/contracts", api.renterContractsHandler)
+		router.GET("/renter/contracts/expiring", api.renterContractsExpiringHandler)
+		router.POST("/renter/contracts/form", RequirePassword(api.renterContractsFormHandlerPOST, requiredPassword))
+		router.GET("/renter/contracts/health", api.renterContractsHealthHandler)
+		router.GET("/renter/spending", api.renterSpendingHandler)
 		router.GET("/renter/downloads", api.renterDownloadsHandler)
 		router.GET("/renter/files", api.renterFilesHandler)
 		router.GET("/renter/prices", api.renterPricesHandler)
+		router.POST("/renter/restore", RequirePassword(api.renterRestoreHandlerPOST, requiredPassword))
 
 		// TODO: re-enable these routes once the new .sia format has been
 		// standardized and implemented.
@@ -89,9 +119,15 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 		router.POST("/renter/delete/*siapath", RequirePassword(api.renterDeleteHandler, requiredPassword))
 		router.GET("/renter/download/*siapath", RequirePassword(api.renterDownloadHandler, requiredPassword))
 		router.GET("/renter/downloadasync/*siapath", RequirePassword(api.renterDownloadAsyncHandler, requiredPassword))
+		router.GET("/renter/health/*siapath", api.renterHealthHandler)
+		router.POST("/renter/pauseupload/*siapath", RequirePassword(api.renterPauseUploadHandler, requiredPassword))
 		router.POST("/renter/rename/*siapath", RequirePassword(api.renterRenameHandler, requiredPassword))
+		router.POST("/renter/resumeupload/*siapath", RequirePassword(api.renterResumeUploadHandler, requiredPassword))
+		router.POST("/renter/migrate/*siapath", RequirePassword(api.renterMigrateHandler, requiredPassword))
 		router.GET("/renter/stream/*siapath", Unrestricted(api.renterStreamHandler))
 		router.POST("/renter/upload/*siapath", RequirePassword(api.renterUploadHandler, requiredPassword))
+		router.POST("/renter/uploadbytes/*siapath", RequirePassword(api.renterUploadBytesHandler, requiredPassword))
+		router.GET("/renter/downloadbytes/*siapath", RequirePassword(api.renterDownloadBytesHandler, requiredPassword))
 
 		// HostDB endpoints.
 		router.GET("/hostdb/active", api.hostdbActiveHandler)
@@ -101,6 +137,7 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 
 	// Transaction pool API Calls
 	if api.tpool != nil {
+		router.GET("/tpool/address/:addr", api.tpoolAddressHandlerGET)
 		router.GET("/tpool/fee", api.tpoolFeeHandlerGET)
 		router.GET("/tpool/raw/:id", api.tpoolRawHandlerGET)
 		router.POST("/tpool/raw", api.tpoolRawHandlerPOST)
@@ -117,21 +154,33 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 		router.GET("/wallet/address", RequirePassword(api.walletAddressHandler, requiredPassword))
 		router.GET("/wallet/addresses", api.walletAddressesHandler)
 		router.GET("/wallet/backup", RequirePassword(api.walletBackupHandler, requiredPassword))
+		router.GET("/wallet/fee", api.walletFeeHandler)
+		router.GET("/wallet/history", api.walletHistoryHandler)
 		router.POST("/wallet/init", RequirePassword(api.walletInitHandler, requiredPassword))
 		router.POST("/wallet/init/seed", RequirePassword(api.walletInitSeedHandler, requiredPassword))
+		router.POST("/wallet/label", RequirePassword(api.walletLabelHandler, requiredPassword))
+		router.GET("/wallet/labels", api.walletLabelsHandler)
 		router.POST("/wallet/lock", RequirePassword(api.walletLockHandler, requiredPassword))
 		router.POST("/wallet/seed", RequirePassword(api.walletSeedHandler, requiredPassword))
 		router.GET("/wallet/seeds", RequirePassword(api.walletSeedsHandler, requiredPassword))
 		router.POST("/wallet/siacoins", RequirePassword(api.walletSiacoinsHandler, requiredPassword))
+		router.GET("/wallet/siafunds", api.walletSiafundsHandlerGET)
 		router.POST("/wallet/siafunds", RequirePassword(api.walletSiafundsHandler, requiredPassword))
 		router.POST("/wallet/siagkey", RequirePassword(api.walletSiagkeyHandler, requiredPassword))
+		router.POST("/wallet/sign", RequirePassword(api.walletSignHandler, requiredPassword))
+		router.POST("/wallet/sweep/claim", RequirePassword(api.walletSweepClaimHandler, requiredPassword))
+		router.POST("/wallet/sweep/dust", RequirePassword(api.walletSweepDustHandler, requiredPassword))
 		router.POST("/wallet/sweep/seed", RequirePassword(api.walletSweepSeedHandler, requiredPassword))
 		router.GET("/wallet/transaction/:id", api.walletTransactionHandler)
 		router.GET("/wallet/transactions", api.walletTransactionsHandler)
 		router.GET("/wallet/transactions/:addr", api.walletTransactionsAddrHandler)
 		router.GET("/wallet/verify/address/:addr", api.walletVerifyAddressHandler)
+		router.GET("/wallet/verify/message", api.walletVerifyMessageHandler)
 		router.POST("/wallet/unlock", RequirePassword(api.walletUnlockHandler, requiredPassword))
+		router.GET("/wallet/watch", api.walletWatchHandlerGET)
+		router.POST("/wallet/watch", RequirePassword(api.walletWatchHandlerPOST, requiredPassword))
 		router.POST("/wallet/changepassword", RequirePassword(api.walletChangePasswordHandler, requiredPassword))
+		api.buildFaucetRoutes(router, requiredPassword)
 	}
 
 	// Apply UserAgent middleware and return the Router
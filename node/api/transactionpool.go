@@ -13,10 +13,30 @@ import (
 )
 
 type (
+	// TpoolAddressGET contains the unconfirmed transactions that create or
+	// spend outputs of an address, and the value that they add to and remove
+	// from the balance of the address.
+	TpoolAddressGET struct {
+		Transactions []TpoolAddressTransaction `json:"transactions"`
+
+		IncomingSiacoins types.Currency `json:"incomingsiacoins"`
+		OutgoingSiacoins types.Currency `json:"outgoingsiacoins"`
+		IncomingSiafunds types.Currency `json:"incomingsiafunds"`
+		OutgoingSiafunds types.Currency `json:"outgoingsiafunds"`
+	}
+
+	// TpoolAddressTransaction is an unconfirmed transaction along with its
+	// id.
+	TpoolAddressTransaction struct {
+		ID          types.TransactionID `json:"id"`
+		Transaction types.Transaction   `json:"transaction"`
+	}
+
 	// TpoolFeeGET contains the current estimated fee
 	TpoolFeeGET struct {
-		Minimum types.Currency `json:"minimum"`
-		Maximum types.Currency `json:"maximum"`
+		Minimum         types.Currency `json:"minimum"`
+		Maximum         types.Currency `json:"maximum"`
+		MinimumAccepted types.Currency `json:"minimumaccepted"`
 	}
 
 	// TpoolRawGET contains the requested transaction encoded to the raw
@@ -44,13 +64,41 @@ func decodeTransactionID(txidStr string) (types.TransactionID, error) {
 	return types.TransactionID(*txid), nil
 }
 
+// tpoolAddressHandlerGET returns the unconfirmed transactions that create or
+// spend outputs of an address, along with the pending change to the balance of
+// the address.
+func (api *API) tpoolAddressHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	addr, err := scanAddress(ps.ByName("addr"))
+	if err != nil {
+		WriteError(w, Error{"error decoding address:" + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	uas := api.tpool.AddressTransactions(addr)
+	txns := make([]TpoolAddressTransaction, 0, len(uas.Transactions))
+	for _, txn := range uas.Transactions {
+		txns = append(txns, TpoolAddressTransaction{
+			ID:          txn.ID(),
+			Transaction: txn,
+		})
+	}
+	WriteJSON(w, TpoolAddressGET{
+		Transactions: txns,
+
+		IncomingSiacoins: uas.IncomingSiacoins,
+		OutgoingSiacoins: uas.OutgoingSiacoins,
+		IncomingSiafunds: uas.IncomingSiafunds,
+		OutgoingSiafunds: uas.OutgoingSiafunds,
+	})
+}
+
 // tpoolFeeHandlerGET returns the current estimated fee. Transactions with
 // fees are lower than the estimated fee may take longer to confirm.
 func (api *API) tpoolFeeHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	min, max := api.tpool.FeeEstimation()
 	WriteJSON(w, TpoolFeeGET{
-		Minimum: min,
-		Maximum: max,
+		Minimum:         min,
+		Maximum:         max,
+		MinimumAccepted: api.tpool.MinimumFee(),
 	})
 }
 
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"path/filepath"
@@ -45,22 +46,48 @@ type (
 		Addresses []types.UnlockHash `json:"addresses"`
 	}
 
+	// WalletFeeGET contains the fee recommendations returned by a GET call to
+	// /wallet/fee.
+	WalletFeeGET struct {
+		modules.FeeEstimate
+	}
+
+	// WalletHistoryGET contains the transaction history returned by a GET
+	// call to /wallet/history.
+	WalletHistoryGET struct {
+		Entries []modules.HistoryEntry `json:"entries"`
+	}
+
 	// WalletInitPOST contains the primary seed that gets generated during a
 	// POST call to /wallet/init.
 	WalletInitPOST struct {
 		PrimarySeed string `json:"primaryseed"`
 	}
 
+	// WalletLabelsGET contains the address labels returned by a GET call to
+	// /wallet/labels.
+	WalletLabelsGET struct {
+		Labels []modules.AddressLabel `json:"labels"`
+	}
+
 	// WalletSiacoinsPOST contains the transaction sent in the POST call to
 	// /wallet/siacoins.
 	WalletSiacoinsPOST struct {
-		TransactionIDs []types.TransactionID `json:"transactionids"`
+		TransactionIDs []types.TransactionID  `json:"transactionids"`
+		Labels         []modules.AddressLabel `json:"labels"`
 	}
 
 	// WalletSiafundsPOST contains the transaction sent in the POST call to
 	// /wallet/siafunds.
 	WalletSiafundsPOST struct {
-		TransactionIDs []types.TransactionID `json:"transactionids"`
+		TransactionIDs []types.TransactionID  `json:"transactionids"`
+		Labels         []modules.AddressLabel `json:"labels"`
+	}
+
+	// WalletSiafundsGET contains the confirmed siafund outputs of the wallet
+	// and the siacoins that they can claim.
+	WalletSiafundsGET struct {
+		SiafundOutputs []modules.SiafundOutputClaim `json:"siafundoutputs"`
 	}
 
 	// WalletSeedsGET contains the seeds used by the wallet.
@@ -77,6 +104,20 @@ type (
 		Funds types.Currency `json:"funds"`
 	}
 
+	// WalletSweepClaimPOST contains the estimated siacoin claim and the
+	// transactions submitted by a call to /wallet/sweep/claim.
+	WalletSweepClaimPOST struct {
+		Claim          types.Currency        `json:"claim"`
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+	}
+
+	// WalletSweepDustPOST contains the number of outputs consolidated by a
+	// call to /wallet/sweep/dust and the fee that was paid.
+	WalletSweepDustPOST struct {
+		Outputs uint64         `json:"outputs"`
+		Fee     types.Currency `json:"fee"`
+	}
+
 	// WalletTransactionGETid contains the transaction returned by a call to
 	// /wallet/transaction/:id
 	WalletTransactionGETid struct {
@@ -88,6 +129,7 @@ type (
 	WalletTransactionsGET struct {
 		ConfirmedTransactions   []modules.ProcessedTransaction `json:"confirmedtransactions"`
 		UnconfirmedTransactions []modules.ProcessedTransaction `json:"unconfirmedtransactions"`
+		Labels                  []modules.AddressLabel         `json:"labels"`
 	}
 
 	// WalletTransactionsGETaddr contains the set of wallet transactions
@@ -96,6 +138,14 @@ type (
 	WalletTransactionsGETaddr struct {
 		ConfirmedTransactions   []modules.ProcessedTransaction `json:"confirmedtransactions"`
 		UnconfirmedTransactions []modules.ProcessedTransaction `json:"unconfirmedtransactions"`
+		Labels                  []modules.AddressLabel         `json:"labels"`
+	}
+
+	// WalletWatchGET contains the set of watch-only addresses tracked by the
+	// wallet and their confirmed siacoin balance.
+	WalletWatchGET struct {
+		Addresses               []types.UnlockHash `json:"addresses"`
+		ConfirmedSiacoinBalance types.Currency     `json:"confirmedsiacoinbalance"`
 	}
 
 	// WalletVerifyAddressGET contains a bool indicating if the address passed to
@@ -103,8 +153,33 @@ type (
 	WalletVerifyAddressGET struct {
 		Valid bool `json:"valid"`
 	}
+
+	// WalletVerifyMessageGET contains a bool indicating if the signature
+	// passed to /wallet/verify/message is a valid signature of the message.
+	WalletVerifyMessageGET struct {
+		Valid bool `json:"valid"`
+	}
 )
 
+// relatedAddresses returns the addresses of the inputs and outputs of the
+// provided processed transactions.
+func relatedAddresses(ptss ...[]modules.ProcessedTransaction) []types.UnlockHash {
+	var addrs []types.UnlockHash
+	for _, pts := range ptss {
+		for _, pt := range pts {
+			for _, input := range pt.Inputs {
+				addrs = append(addrs, input.RelatedAddress)
+			}
+			for _, output := range pt.Outputs {
+				if output.FundType != types.SpecifierMinerFee {
+					addrs = append(addrs, output.RelatedAddress)
+				}
+			}
+		}
+	}
+	return addrs
+}
+
 // encryptionKeys enumerates the possible encryption keys that can be derived
 // from an input string.
 func encryptionKeys(seedStr string) (validKeys []crypto.TwofishKey) {
@@ -200,6 +275,56 @@ func (api *API) walletBackupHandler(w http.ResponseWriter, req *http.Request, _
 	WriteSuccess(w)
 }
 
+// walletFeeHandler handles API calls to /wallet/fee.
+func (api *API) walletFeeHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, WalletFeeGET{api.wallet.EstimateFee()})
+}
+
+// walletHistoryHandler handles API calls to /wallet/history.
+func (api *API) walletHistoryHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	history, err := api.wallet.History()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/history: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletHistoryGET{Entries: history})
+}
+
+// walletLabelHandler handles API calls to /wallet/label.
+func (api *API) walletLabelHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	addr, err := scanAddress(req.FormValue("address"))
+	if err != nil {
+		WriteError(w, Error{"could not read address from POST call to /wallet/label"}, http.StatusBadRequest)
+		return
+	}
+	err = api.wallet.SetAddressLabel(addr, req.FormValue("label"))
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/label: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletSignHandler handles API calls to /wallet/sign.
+func (api *API) walletSignHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	addr, err := scanAddress(req.FormValue("address"))
+	if err != nil {
+		WriteError(w, Error{"could not read address from POST call to /wallet/sign"}, http.StatusBadRequest)
+		return
+	}
+	ms, err := api.wallet.SignMessage(addr, []byte(req.FormValue("message")))
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/sign: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, ms)
+}
+
+// walletLabelsHandler handles API calls to /wallet/labels.
+func (api *API) walletLabelsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, WalletLabelsGET{Labels: api.wallet.AddressLabels()})
+}
+
 // walletInitHandler handles API calls to /wallet/init.
 func (api *API) walletInitHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var encryptionKey crypto.TwofishKey
@@ -376,12 +501,17 @@ func (api *API) walletSeedsHandler(w http.ResponseWriter, req *http.Request, _ h
 // walletSiacoinsHandler handles API calls to /wallet/siacoins.
 func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var txns []types.Transaction
+	var dests []types.UnlockHash
 	if req.FormValue("outputs") != "" {
 		// multiple amounts + destinations
 		if req.FormValue("amount") != "" || req.FormValue("destination") != "" {
 			WriteError(w, Error{"cannot supply both 'outputs' and single amount+destination pair"}, http.StatusInternalServerError)
 			return
 		}
+		if req.FormValue("feetier") != "" {
+			WriteError(w, Error{"'feetier' is only supported for a single amount+destination pair"}, http.StatusBadRequest)
+			return
+		}
 
 		var outputs []types.SiacoinOutput
 		err := json.Unmarshal([]byte(req.FormValue("outputs")), &outputs)
@@ -389,6 +519,9 @@ func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request,
 			WriteError(w, Error{"could not decode outputs: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
+		for _, sco := range outputs {
+			dests = append(dests, sco.UnlockHash)
+		}
 		txns, err = api.wallet.SendSiacoinsMulti(outputs)
 		if err != nil {
 			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
@@ -406,8 +539,20 @@ func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request,
 			WriteError(w, Error{"could not read address from POST call to /wallet/siacoins"}, http.StatusBadRequest)
 			return
 		}
+		dests = append(dests, dest)
 
-		txns, err = api.wallet.SendSiacoins(amount, dest)
+		tier := modules.FeeTier(req.FormValue("feetier"))
+		switch tier {
+		case "", modules.FeeTierLow, modules.FeeTierMedium, modules.FeeTierHigh:
+		default:
+			WriteError(w, Error{"error when calling /wallet/siacoins: " + modules.ErrUnknownFeeTier.Error() + ": " + string(tier)}, http.StatusBadRequest)
+			return
+		}
+		if tier != "" {
+			txns, err = api.wallet.SendSiacoinsWithFeeTier(amount, dest, tier)
+		} else {
+			txns, err = api.wallet.SendSiacoins(amount, dest)
+		}
 		if err != nil {
 			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
@@ -421,6 +566,7 @@ func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request,
 	}
 	WriteJSON(w, WalletSiacoinsPOST{
 		TransactionIDs: txids,
+		Labels:         api.wallet.LabelsForAddresses(dests),
 	})
 }
 
@@ -448,6 +594,49 @@ func (api *API) walletSiafundsHandler(w http.ResponseWriter, req *http.Request,
 	}
 	WriteJSON(w, WalletSiafundsPOST{
 		TransactionIDs: txids,
+		Labels:         api.wallet.LabelsForAddresses([]types.UnlockHash{dest}),
+	})
+}
+
+// walletSiafundsHandlerGET handles GET calls to /wallet/siafunds.
+func (api *API) walletSiafundsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, WalletSiafundsGET{
+		SiafundOutputs: api.wallet.SiafundOutputs(),
+	})
+}
+
+// walletSweepClaimHandler handles API calls to /wallet/sweep/claim.
+func (api *API) walletSweepClaimHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	claim, txns, err := api.wallet.ClaimSiafunds()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/sweep/claim: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletSweepClaimPOST{
+		Claim:          claim,
+		TransactionIDs: txids,
+	})
+}
+
+// walletSweepDustHandler handles API calls to /wallet/sweep/dust.
+func (api *API) walletSweepDustHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	threshold, ok := scanAmount(req.FormValue("threshold"))
+	if !ok {
+		WriteError(w, Error{"could not read 'threshold' from POST call to /wallet/sweep/dust"}, http.StatusBadRequest)
+		return
+	}
+	outputs, fee, err := api.wallet.SweepDust(threshold)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/sweep/dust: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletSweepDustPOST{
+		Outputs: outputs,
+		Fee:     fee,
 	})
 }
 
@@ -524,6 +713,7 @@ func (api *API) walletTransactionsHandler(w http.ResponseWriter, req *http.Reque
 	WriteJSON(w, WalletTransactionsGET{
 		ConfirmedTransactions:   confirmedTxns,
 		UnconfirmedTransactions: unconfirmedTxns,
+		Labels:                  api.wallet.LabelsForAddresses(relatedAddresses(confirmedTxns, unconfirmedTxns)),
 	})
 }
 
@@ -544,6 +734,7 @@ func (api *API) walletTransactionsAddrHandler(w http.ResponseWriter, req *http.R
 	WriteJSON(w, WalletTransactionsGETaddr{
 		ConfirmedTransactions:   confirmedATs,
 		UnconfirmedTransactions: unconfirmedATs,
+		Labels:                  api.wallet.LabelsForAddresses(relatedAddresses(confirmedATs, unconfirmedATs)),
 	})
 }
 
@@ -589,6 +780,30 @@ func (api *API) walletChangePasswordHandler(w http.ResponseWriter, req *http.Req
 	WriteError(w, Error{"error when calling /wallet/changepassword: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
 }
 
+// walletWatchHandlerGET handles GET calls to /wallet/watch.
+func (api *API) walletWatchHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, WalletWatchGET{
+		Addresses:               api.wallet.WatchOnlyAddresses(),
+		ConfirmedSiacoinBalance: api.wallet.WatchOnlyBalance(),
+	})
+}
+
+// walletWatchHandlerPOST handles POST calls to /wallet/watch.
+func (api *API) walletWatchHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var addrs []types.UnlockHash
+	err := json.Unmarshal([]byte(req.FormValue("addresses")), &addrs)
+	if err != nil {
+		WriteError(w, Error{"could not decode addresses: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.wallet.WatchAddresses(addrs)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/watch: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // walletVerifyAddressHandler handles API calls to /wallet/verify/address/:addr.
 func (api *API) walletVerifyAddressHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	addrString := ps.ByName("addr")
@@ -596,3 +811,26 @@ func (api *API) walletVerifyAddressHandler(w http.ResponseWriter, req *http.Requ
 	err := new(types.UnlockHash).LoadString(addrString)
 	WriteJSON(w, WalletVerifyAddressGET{Valid: err == nil})
 }
+
+// walletVerifyMessageHandler handles API calls to /wallet/verify/message.
+func (api *API) walletVerifyMessageHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var ms modules.MessageSignature
+	addr, err := scanAddress(req.FormValue("address"))
+	if err != nil {
+		WriteError(w, Error{"could not read address from GET call to /wallet/verify/message"}, http.StatusBadRequest)
+		return
+	}
+	ms.Address = addr
+	ms.PublicKey.LoadString(req.FormValue("publickey"))
+	if len(ms.PublicKey.Key) == 0 {
+		WriteError(w, Error{"could not read publickey from GET call to /wallet/verify/message"}, http.StatusBadRequest)
+		return
+	}
+	ms.Signature, err = base64.StdEncoding.DecodeString(req.FormValue("signature"))
+	if err != nil {
+		WriteError(w, Error{"could not read signature from GET call to /wallet/verify/message: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = modules.VerifyMessageSignature([]byte(req.FormValue("message")), ms)
+	WriteJSON(w, WalletVerifyMessageGET{Valid: err == nil})
+}
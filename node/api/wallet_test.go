@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -1318,6 +1319,47 @@ func TestWalletChangePassword(t *testing.T) {
 	}
 }
 
+// TestWalletSiacoinsFeeTier checks that /wallet/siacoins rejects an unknown
+// fee tier as a bad request, without sending any coins.
+func TestWalletSiacoinsFeeTier(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	uc, err := st.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendSiacoinsValues := url.Values{}
+	sendSiacoinsValues.Set("amount", types.SiacoinPrecision.String())
+	sendSiacoinsValues.Set("destination", uc.UnlockHash().String())
+	sendSiacoinsValues.Set("feetier", "fastest")
+	resp, err := HttpPOST("http://"+st.server.listener.Addr().String()+"/wallet/siacoins", sendSiacoinsValues.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status %v, got %v", http.StatusBadRequest, resp.StatusCode)
+	}
+	if txns := st.tpool.TransactionList(); len(txns) != 0 {
+		t.Fatal("coins were sent with an unknown fee tier")
+	}
+
+	// A known fee tier is accepted.
+	sendSiacoinsValues.Set("feetier", string(modules.FeeTierLow))
+	err = st.stdPostAPI("/wallet/siacoins", sendSiacoinsValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestWalletSiacoins tests the /wallet/siacoins endpoint, including sending
 // to multiple addresses.
 func TestWalletSiacoins(t *testing.T) {
@@ -150,6 +150,21 @@ func (x Currency) MulTax() (c Currency) {
 	return c
 }
 
+// Percentage returns a new Currency value c = x * num / denom. The result is
+// rounded down to the nearest integer. The multiplication happens before the
+// division on unbounded integers, so no precision is lost and the result
+// never overflows. If denom is zero, build.Critical is called and zero is
+// returned.
+func (x Currency) Percentage(num, denom uint64) (c Currency) {
+	if denom == 0 {
+		build.Critical("percentage with a zero denominator")
+		return ZeroCurrency
+	}
+	c.i.Mul(&x.i, new(big.Int).SetUint64(num))
+	c.i.Div(&c.i, new(big.Int).SetUint64(denom))
+	return
+}
+
 // RoundDown returns the largest multiple of y <= x.
 func (x Currency) RoundDown(y Currency) (c Currency) {
 	diff := new(big.Int).Mod(&x.i, &y.i)
@@ -183,6 +198,16 @@ func (x Currency) Sub(y Currency) (c Currency) {
 	return
 }
 
+// SubErr returns a new Currency value c = x - y. Unlike Sub, an error is
+// returned instead of panicking when x < y.
+func (x Currency) SubErr(y Currency) (c Currency, err error) {
+	if x.Cmp(y) < 0 {
+		return ZeroCurrency, ErrNegativeCurrency
+	}
+	c.i.Sub(&x.i, &y.i)
+	return c, nil
+}
+
 // Uint64 converts a Currency to a uint64. An error is returned because this
 // function is sometimes called on values that can be determined by users -
 // rather than have all user-facing points do input checking, the input
@@ -198,6 +198,45 @@ func TestCurrencyMulRat(t *testing.T) {
 	}
 }
 
+// TestCurrencyPercentage probes the Percentage function of the currency type,
+// including the rounding of values near zero and the handling of values near
+// the maximum currency size.
+func TestCurrencyPercentage(t *testing.T) {
+	tests := []struct {
+		x          Currency
+		num, denom uint64
+		want       Currency
+	}{
+		{NewCurrency64(100), 10, 100, NewCurrency64(10)},
+		{NewCurrency64(5), 3, 2, NewCurrency64(7)},
+		{ZeroCurrency, 10, 100, ZeroCurrency},
+		{NewCurrency64(1), 0, 100, ZeroCurrency},
+		{NewCurrency64(1), 99, 100, ZeroCurrency},
+		{NewCurrency64(1), 100, 100, NewCurrency64(1)},
+		{NewCurrency64(3), 1, 2, NewCurrency64(1)},
+		{NewCurrency64(199), 1, 100, NewCurrency64(1)},
+		{NewCurrency64(math.MaxUint64), math.MaxUint64, 1, NewCurrency(new(big.Int).Mul(new(big.Int).SetUint64(math.MaxUint64), new(big.Int).SetUint64(math.MaxUint64)))},
+	}
+	for _, test := range tests {
+		if got := test.x.Percentage(test.num, test.denom); !got.Equals(test.want) {
+			t.Errorf("%v * %v / %v: expected %v, got %v", test.x, test.num, test.denom, test.want, got)
+		}
+	}
+
+	// The largest currency that can be sent over the wire should not lose
+	// precision or overflow.
+	max := NewCurrency(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255*8), big.NewInt(1)))
+	if !max.Percentage(math.MaxUint64, math.MaxUint64).Equals(max) {
+		t.Error("max currency changed when multiplied by 100%")
+	}
+	if !max.Percentage(1, 2).Equals(NewCurrency(new(big.Int).Rsh(max.Big(), 1))) {
+		t.Error("half of max currency is incorrect")
+	}
+	if !max.Percentage(math.MaxUint64-1, math.MaxUint64).Equals(max.Sub(max.Div64(math.MaxUint64)).Sub(NewCurrency64(1))) {
+		t.Error("rounding of max currency is incorrect")
+	}
+}
+
 // TestCurrencyRoundDown probes the RoundDown function of the currency type.
 func TestCurrencyRoundDown(t *testing.T) {
 	// 10,000 is chosen because that's how many siafunds there usually are.
@@ -246,6 +285,24 @@ func TestCurrencySub(t *testing.T) {
 	}
 }
 
+// TestCurrencySubErr probes the SubErr function of the currency type.
+func TestCurrencySubErr(t *testing.T) {
+	c3 := NewCurrency64(3)
+	c5 := NewCurrency64(5)
+	c, err := c5.SubErr(c3)
+	if err != nil || !c.Equals64(2) {
+		t.Error("5 - 3 should return 2 without an error, got", c, err)
+	}
+	c, err = c5.SubErr(c5)
+	if err != nil || !c.IsZero() {
+		t.Error("5 - 5 should return 0 without an error, got", c, err)
+	}
+	c, err = c3.SubErr(c5)
+	if err != ErrNegativeCurrency || !c.IsZero() {
+		t.Error("3 - 5 should return ErrNegativeCurrency, got", c, err)
+	}
+}
+
 // TestNegativeCurrencyMulRat checks that negative numbers are rejected when
 // calling MulRat on the currency type.
 func TestNegativeCurrencyMulRat(t *testing.T) {